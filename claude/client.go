@@ -76,9 +76,19 @@ func (c *Client) GenerateCommitMessage(status, diff, branch, log, customPrompt s
 	return subject, nil
 }
 
+// NamingRules are the branch naming conventions a generated name must follow
+// Implemented by config.BranchNamingRules (defined there since config imports this package)
+type NamingRules interface {
+	GetPrefixes() []string
+	SplitName(name string) (prefix, ticket, rest string)
+	FormatName(prefix, ticket, description string, maxDefault int) string
+	Validate(name string) error
+}
+
 // GenerateBranchName generates a semantic branch name based on git diff
-// If customPrompt is empty, uses the default prompt
-func (c *Client) GenerateBranchName(diff, customPrompt string) (string, error) {
+// If customPrompt is empty, uses the default prompt. The name follows the naming rules
+// (nil = plain kebab-case) and keeps the given ticket ID, if any.
+func (c *Client) GenerateBranchName(diff, customPrompt string, rules NamingRules, ticket string) (string, error) {
 	// Limit diff to reasonable size
 	if len(diff) > 3000 {
 		diff = diff[:3000]
@@ -91,17 +101,36 @@ func (c *Client) GenerateBranchName(diff, customPrompt string) (string, error) {
 	}
 	// Replace {diff} placeholder with actual diff
 	prompt = strings.ReplaceAll(prompt, "{diff}", diff)
+	var prefixes []string
+	if rules != nil {
+		prefixes = rules.GetPrefixes()
+	}
+	if len(prefixes) > 0 {
+		prompt += fmt.Sprintf("\n\nStart the branch name with one of these prefixes followed by a slash: %s", strings.Join(prefixes, ", "))
+	}
 
-	name, err := c.callAPI(prompt)
+	response, err := c.callAPI(prompt)
 	if err != nil {
 		return "", err
 	}
 
+	// Keep an allowed prefix the AI picked (e.g. "feat/"); the ticket ID comes from the caller
+	prefix, name := "", strings.TrimSpace(response)
+	if rules != nil {
+		prefix, _, name = rules.SplitName(name)
+	}
+	if prefix == "" && len(prefixes) > 0 {
+		prefix = prefixes[0]
+	}
+
 	// Clean up response
-	name = strings.TrimSpace(name)
 	name = strings.ToLower(name)
+	if ticket != "" {
+		name = strings.TrimPrefix(name, strings.ToLower(ticket))
+	}
 	name = strings.ReplaceAll(name, " ", "-")
 	name = strings.ReplaceAll(name, "_", "-")
+	name = strings.ReplaceAll(name, "/", "-")
 
 	// Remove non-alphanumeric except hyphens
 	var result []rune
@@ -112,18 +141,30 @@ func (c *Client) GenerateBranchName(diff, customPrompt string) (string, error) {
 	}
 	name = string(result)
 
-	// Remove leading/trailing hyphens
-	name = strings.Trim(name, "-")
-
-	// Limit to 40 chars
-	if len(name) > 40 {
-		name = name[:40]
+	// Collapse repeated hyphens and remove leading/trailing ones
+	for strings.Contains(name, "--") {
+		name = strings.ReplaceAll(name, "--", "-")
 	}
+	name = strings.Trim(name, "-")
 
 	if name == "" {
 		return "", fmt.Errorf("AI generated invalid branch name")
 	}
 
+	if rules == nil {
+		// Limit to 40 chars
+		if len(name) > 40 {
+			name = strings.TrimRight(name[:40], "-")
+		}
+		return name, nil
+	}
+
+	// Add prefix and ticket, limited to 40 chars unless the rules set a max length
+	name = rules.FormatName(prefix, ticket, name, 40)
+	if err := rules.Validate(name); err != nil {
+		return "", fmt.Errorf("AI generated branch name %q that %v", name, err)
+	}
+
 	return name, nil
 }
 
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 
 	"github.com/coollabsio/jean-tui/claude"
 )
@@ -16,19 +17,27 @@ type AIPrompts struct {
 	PRContent     string `json:"pr_content,omitempty"`     // Custom prompt for PR title and description generation
 }
 
+// AISettings groups the AI integration settings
+type AISettings struct {
+	Model             string     `json:"model,omitempty"`               // Claude model, "" = default haiku
+	CommitEnabled     bool       `json:"commit_enabled,omitempty"`      // Enable AI commit message generation
+	BranchNameEnabled bool       `json:"branch_name_enabled,omitempty"` // Enable AI branch name generation
+	Prompts           *AIPrompts `json:"prompts,omitempty"`             // Customizable AI prompts
+}
+
 // Config represents the global jean configuration
 type Config struct {
+	Version             int                    `json:"version"` // Schema version, see CurrentConfigVersion and MigrateConfigFile
 	Repositories        map[string]*RepoConfig `json:"repositories"`
-	LastUpdateCheckTime string                 `json:"lastUpdateCheckTime"` // RFC3339 format
+	LastUpdateCheckTime string                 `json:"last_update_check_time"` // RFC3339 format
 	DefaultTheme        string                 `json:"default_theme,omitempty"` // Global default theme, "" = matrix
-	AnthropicAPIKey     string                 `json:"anthropic_api_key,omitempty"` // API key for Anthropic Claude
-	ClaudeModel         string                 `json:"claude_model,omitempty"` // Claude model, "" = default haiku
-	AICommitEnabled     bool                   `json:"ai_commit_enabled,omitempty"` // Enable AI commit message generation
-	AIBranchNameEnabled bool                   `json:"ai_branch_name_enabled,omitempty"` // Enable AI branch name generation
+	AnthropicAPIKey     string                 `json:"anthropic_api_key,omitempty"` // Plaintext API key of older versions, moved to the credential store on load
+	AI                  *AISettings            `json:"ai,omitempty"` // AI integration settings
 	DebugLoggingEnabled bool                   `json:"debug_logging_enabled"` // Enable debug logging to temp files
-	AIPrompts           *AIPrompts             `json:"ai_prompts,omitempty"` // Customizable AI prompts
 	WrapperChecksums    map[string]string      `json:"wrapper_checksums,omitempty"` // Shell -> SHA256 checksum of installed wrapper
 	Onboarded           bool                   `json:"onboarded"` // Whether the user has completed the onboarding flow
+	StatusConcurrency   int                    `json:"status_concurrency,omitempty"` // Max worktrees whose status is loaded in parallel, 0 = default (4)
+	WorktreePathTemplate string                `json:"worktree_path_template,omitempty"` // Global default worktree location, e.g. "~/wt/{repo}/{branch}", "" = <repo>/.workspaces/{branch}
 }
 
 // PRInfo represents information about a pull request
@@ -47,35 +56,54 @@ type RepoConfig struct {
 	BaseBranch         string            `json:"base_branch"`
 	LastSelectedBranch string            `json:"last_selected_branch,omitempty"`
 	Editor             string            `json:"editor,omitempty"`
-	AutoFetchInterval  int               `json:"auto_fetch_interval,omitempty"` // in seconds, 0 = use default (10s)
+	AutoFetchInterval  int               `json:"auto_fetch_interval,omitempty"` // in seconds, 0 = use default (10s), negative = disabled
+	UpdateStrategy     string            `json:"update_strategy,omitempty"`     // How base changes are brought in: "merge" (default), "rebase", or "ff-only"
+	AutoStash          bool              `json:"auto_stash,omitempty"`          // Stash uncommitted changes around refresh pulls instead of skipping dirty worktrees
+	CleanupStaleDays   int               `json:"cleanup_stale_days,omitempty"`  // Days without changes before cleanup suggests a worktree, 0 = use default (14)
+	PushRemote         string            `json:"push_remote,omitempty"`         // Remote branches are pushed to and PRs opened from, "" = "origin"
+	BaseRemote         string            `json:"base_remote,omitempty"`         // Remote the base branch comes from and PRs target (e.g. "upstream"), "" = push remote
+	WorktreePathTemplate string          `json:"worktree_path_template,omitempty"` // Where new worktrees go, supports {repo}, {branch} and {user}, "" = global default
 	Theme              string            `json:"theme,omitempty"`               // Per-repo theme override, "" = use global default
 	PRDefaultState     string            `json:"pr_default_state,omitempty"`    // "draft" or "ready", "" = use default (ready)
 	PRs                map[string][]PRInfo `json:"prs,omitempty"`                 // branch -> list of PRs
 	InitializedClaudes map[string]bool   `json:"initialized_claudes,omitempty"` // branch -> whether Claude has been started
+	PortBlocks         map[string]int    `json:"port_blocks,omitempty"`         // branch -> first port of the block reserved for its worktree
 }
 
 // Manager handles configuration loading and saving
 type Manager struct {
 	configPath string
 	config     *Config
+	base       map[string]any // Config as last read from or written to disk, to tell which values changed since
+	mu         sync.Mutex     // Serializes saves within this process
+
+	credentials CredentialStore // Where secrets are saved (keyring or credentials file)
+	apiKey      string          // Anthropic API key read from the credential store
+	apiKeyStore CredentialStore // Store the API key was found in or saved to
+}
+
+// ConfigPath returns the path of the global config file, ~/.config/jean/config.json
+func ConfigPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "jean", "config.json"), nil
 }
 
 // NewManager creates a new configuration manager
 func NewManager() (*Manager, error) {
-	// Get user home directory
-	home, err := os.UserHomeDir()
+	configPath, err := ConfigPath()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %w", err)
+		return nil, err
 	}
 
 	// Create config directory: ~/.config/jean
-	configDir := filepath.Join(home, ".config", "jean")
+	configDir := filepath.Dir(configPath)
 	if err := os.MkdirAll(configDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create config directory: %w", err)
 	}
 
-	configPath := filepath.Join(configDir, "config.json")
-
 	m := &Manager{
 		configPath: configPath,
 	}
@@ -84,32 +112,91 @@ func NewManager() (*Manager, error) {
 	if err := m.load(); err != nil {
 		// If file doesn't exist, create empty config
 		m.config = &Config{
+			Version:      CurrentConfigVersion,
 			Repositories: make(map[string]*RepoConfig),
 		}
 	}
+	m.base, _ = snapshot(m.config)
+
+	// Keep the API key out of config.json
+	m.credentials = NewCredentialStore(configDir)
+	m.loadAPIKey()
 
 	return m, nil
 }
 
-// load reads the configuration from disk
+// load reads the configuration from disk, upgrading files written by older versions first
 func (m *Manager) load() error {
-	data, err := os.ReadFile(m.configPath)
-	if err != nil {
+	migration, err := MigrateConfigFile(m.configPath, false)
+	if migration == nil {
 		return err
 	}
+	if err != nil {
+		// Go on with the upgraded config in memory, the next save writes it
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
 
 	m.config = &Config{}
-	return json.Unmarshal(data, m.config)
+	return json.Unmarshal(migration.Config, m.config)
 }
 
 // save writes the configuration to disk
+// Other jean instances may have saved since this one read the file, so under a file lock the file is
+// re-read and only the values changed here are written over it; the merged result is written atomically
 func (m *Manager) save() error {
-	data, err := json.MarshalIndent(m.config, "", "  ")
+	return m.update(nil)
+}
+
+// update saves the configuration like save, applying edit to the merged configuration before it's written
+// edit sees what other instances saved, so choices that depend on all of it (e.g. free port blocks) hold
+func (m *Manager) update(edit func(config *Config) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	unlock, err := lockFile(m.configPath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	local, err := snapshot(m.config)
+	if err != nil {
+		return err
+	}
+	disk, err := m.readDisk()
+	if err != nil {
+		return err
+	}
+	mergedValues := mergeChanges(m.base, local, disk)
+	mergedJSON, err := json.Marshal(mergedValues)
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
+	merged := &Config{}
+	if err := json.Unmarshal(mergedJSON, merged); err != nil {
+		return fmt.Errorf("failed to merge config: %w", err)
+	}
+	if merged.Repositories == nil {
+		merged.Repositories = make(map[string]*RepoConfig)
+	}
+	if edit != nil {
+		if err := edit(merged); err != nil {
+			return err
+		}
+	}
+
+	data, err := json.MarshalIndent(merged, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	if err := writeAtomic(m.configPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
 
-	return os.WriteFile(m.configPath, data, 0644)
+	// Pick up what other instances saved
+	*m.config = *merged
+	m.base, _ = snapshot(m.config)
+	return nil
 }
 
 // GetBaseBranch returns the base branch for a repository
@@ -189,17 +276,21 @@ func (m *Manager) SetEditor(repoPath, editor string) error {
 }
 
 // GetAutoFetchInterval returns the auto-fetch interval for a repository
-// Returns the configured interval in seconds, or 10 if not set
+// Returns the configured interval in seconds, 10 if not set, or 0 if auto-fetch is disabled
 func (m *Manager) GetAutoFetchInterval(repoPath string) int {
 	if repo, ok := m.config.Repositories[repoPath]; ok {
 		if repo.AutoFetchInterval > 0 {
 			return repo.AutoFetchInterval
 		}
+		if repo.AutoFetchInterval < 0 {
+			return 0 // Disabled
+		}
 	}
 	return 10 // Default to 10 seconds
 }
 
 // SetAutoFetchInterval sets the auto-fetch interval for a repository
+// Use a negative interval to disable auto-fetch, or 0 to restore the default
 func (m *Manager) SetAutoFetchInterval(repoPath string, interval int) error {
 	if m.config.Repositories == nil {
 		m.config.Repositories = make(map[string]*RepoConfig)
@@ -213,6 +304,146 @@ func (m *Manager) SetAutoFetchInterval(repoPath string, interval int) error {
 	return m.save()
 }
 
+// GetUpdateStrategy returns the update strategy for a repository
+// Returns "merge", "rebase" or "ff-only", defaulting to "merge" if not set
+func (m *Manager) GetUpdateStrategy(repoPath string) string {
+	if repo, ok := m.config.Repositories[repoPath]; ok {
+		switch repo.UpdateStrategy {
+		case "merge", "rebase", "ff-only":
+			return repo.UpdateStrategy
+		}
+	}
+	return "merge"
+}
+
+// SetUpdateStrategy sets the update strategy for a repository
+func (m *Manager) SetUpdateStrategy(repoPath, strategy string) error {
+	if m.config.Repositories == nil {
+		m.config.Repositories = make(map[string]*RepoConfig)
+	}
+
+	if _, ok := m.config.Repositories[repoPath]; !ok {
+		m.config.Repositories[repoPath] = &RepoConfig{}
+	}
+
+	m.config.Repositories[repoPath].UpdateStrategy = strategy
+	return m.save()
+}
+
+// GetAutoStash returns whether refresh should stash, pull and re-apply changes in dirty worktrees
+// Defaults to false (dirty worktrees are skipped)
+func (m *Manager) GetAutoStash(repoPath string) bool {
+	if repo, ok := m.config.Repositories[repoPath]; ok {
+		return repo.AutoStash
+	}
+	return false
+}
+
+// SetAutoStash enables or disables auto-stash during refresh for a repository
+func (m *Manager) SetAutoStash(repoPath string, enabled bool) error {
+	if m.config.Repositories == nil {
+		m.config.Repositories = make(map[string]*RepoConfig)
+	}
+
+	if _, ok := m.config.Repositories[repoPath]; !ok {
+		m.config.Repositories[repoPath] = &RepoConfig{}
+	}
+
+	m.config.Repositories[repoPath].AutoStash = enabled
+	return m.save()
+}
+
+// GetPushRemote returns the remote branches are pushed to (default "origin")
+func (m *Manager) GetPushRemote(repoPath string) string {
+	if repo, ok := m.config.Repositories[repoPath]; ok && repo.PushRemote != "" {
+		return repo.PushRemote
+	}
+	return "origin"
+}
+
+// GetBaseRemote returns the remote the base branch comes from (defaults to the push remote)
+func (m *Manager) GetBaseRemote(repoPath string) string {
+	if repo, ok := m.config.Repositories[repoPath]; ok && repo.BaseRemote != "" {
+		return repo.BaseRemote
+	}
+	return m.GetPushRemote(repoPath)
+}
+
+// SetRemotes sets the push and base remotes for a repository
+func (m *Manager) SetRemotes(repoPath, pushRemote, baseRemote string) error {
+	if m.config.Repositories == nil {
+		m.config.Repositories = make(map[string]*RepoConfig)
+	}
+
+	if _, ok := m.config.Repositories[repoPath]; !ok {
+		m.config.Repositories[repoPath] = &RepoConfig{}
+	}
+
+	m.config.Repositories[repoPath].PushRemote = pushRemote
+	m.config.Repositories[repoPath].BaseRemote = baseRemote
+	return m.save()
+}
+
+// GetWorktreePathTemplate returns the template for new worktree paths
+// Returns the per-repo template if set, otherwise the global default ("" = <repo>/.workspaces/{branch})
+func (m *Manager) GetWorktreePathTemplate(repoPath string) string {
+	if repo, ok := m.config.Repositories[repoPath]; ok && repo.WorktreePathTemplate != "" {
+		return repo.WorktreePathTemplate
+	}
+	return m.config.WorktreePathTemplate
+}
+
+// SetWorktreePathTemplate sets the worktree path template for a specific repository
+// If template is empty string, it will use the global default
+func (m *Manager) SetWorktreePathTemplate(repoPath, template string) error {
+	if m.config.Repositories == nil {
+		m.config.Repositories = make(map[string]*RepoConfig)
+	}
+
+	if _, ok := m.config.Repositories[repoPath]; !ok {
+		m.config.Repositories[repoPath] = &RepoConfig{}
+	}
+
+	m.config.Repositories[repoPath].WorktreePathTemplate = template
+	return m.save()
+}
+
+// GetGlobalWorktreePathTemplate returns the global default worktree path template
+func (m *Manager) GetGlobalWorktreePathTemplate() string {
+	return m.config.WorktreePathTemplate
+}
+
+// SetGlobalWorktreePathTemplate sets the worktree path template used by repositories without their own
+func (m *Manager) SetGlobalWorktreePathTemplate(template string) error {
+	m.config.WorktreePathTemplate = template
+	return m.save()
+}
+
+// DefaultCleanupStaleDays is how long a worktree can go untouched before cleanup suggests removing it
+const DefaultCleanupStaleDays = 14
+
+// GetCleanupStaleDays returns the number of days without changes after which cleanup lists a worktree
+func (m *Manager) GetCleanupStaleDays(repoPath string) int {
+	if repo, ok := m.config.Repositories[repoPath]; ok && repo.CleanupStaleDays > 0 {
+		return repo.CleanupStaleDays
+	}
+	return DefaultCleanupStaleDays
+}
+
+// SetCleanupStaleDays sets the number of days without changes after which cleanup lists a worktree
+func (m *Manager) SetCleanupStaleDays(repoPath string, days int) error {
+	if m.config.Repositories == nil {
+		m.config.Repositories = make(map[string]*RepoConfig)
+	}
+
+	if _, ok := m.config.Repositories[repoPath]; !ok {
+		m.config.Repositories[repoPath] = &RepoConfig{}
+	}
+
+	m.config.Repositories[repoPath].CleanupStaleDays = days
+	return m.save()
+}
+
 // GetLastUpdateCheckTime returns the last update check time
 func (m *Manager) GetLastUpdateCheckTime() string {
 	return m.config.LastUpdateCheckTime
@@ -275,57 +506,84 @@ func (m *Manager) GetGlobalTheme() string {
 }
 
 // GetAnthropicAPIKey returns the Anthropic API key
-// Checks CLAUDE_CODE_OAUTH_TOKEN environment variable first, falls back to config
+// Checks CLAUDE_CODE_OAUTH_TOKEN environment variable first, falls back to the credential store
 func (m *Manager) GetAnthropicAPIKey() string {
 	// Check environment variable first
 	if envKey := os.Getenv("CLAUDE_CODE_OAUTH_TOKEN"); envKey != "" {
 		return envKey
 	}
-	// Fall back to stored config
-	return m.config.AnthropicAPIKey
+	// Fall back to the stored key
+	return m.apiKey
 }
 
-// SetAnthropicAPIKey sets the Anthropic API key
+// SetAnthropicAPIKey saves the Anthropic API key in the credential store ("" removes it)
 func (m *Manager) SetAnthropicAPIKey(apiKey string) error {
-	m.config.AnthropicAPIKey = apiKey
-	return m.save()
+	if apiKey == "" {
+		if err := m.credentials.Delete(anthropicAPIKeyName); err != nil {
+			return err
+		}
+		if m.credentials.Kind() != CredentialStoreFile {
+			if err := m.fileCredentials().Delete(anthropicAPIKeyName); err != nil {
+				return err
+			}
+		}
+		m.apiKeyStore = nil
+	} else if err := m.storeAPIKey(apiKey); err != nil {
+		return err
+	}
+	m.apiKey = apiKey
+
+	// Never leave a plaintext copy behind
+	if m.config.AnthropicAPIKey != "" {
+		m.config.AnthropicAPIKey = ""
+		return m.save()
+	}
+	return nil
+}
+
+// aiSettings returns the AI settings, creating them for setters
+func (m *Manager) aiSettings() *AISettings {
+	if m.config.AI == nil {
+		m.config.AI = &AISettings{}
+	}
+	return m.config.AI
 }
 
 // GetClaudeModel returns the Claude model
 // Returns the default Haiku model if not set
-// Note: This is kept for backward compatibility but not used by the Claude CLI
+// Note: The Claude CLI doesn't use it yet
 func (m *Manager) GetClaudeModel() string {
-	if m.config.ClaudeModel != "" {
-		return m.config.ClaudeModel
+	if m.config.AI != nil && m.config.AI.Model != "" {
+		return m.config.AI.Model
 	}
 	return "claude-haiku-4-5-20251001" // Default model (not used by CLI)
 }
 
 // SetClaudeModel sets the Claude model
 func (m *Manager) SetClaudeModel(model string) error {
-	m.config.ClaudeModel = model
+	m.aiSettings().Model = model
 	return m.save()
 }
 
 // GetAICommitEnabled returns whether AI commit message generation is enabled
 func (m *Manager) GetAICommitEnabled() bool {
-	return m.config.AICommitEnabled
+	return m.config.AI != nil && m.config.AI.CommitEnabled
 }
 
 // SetAICommitEnabled sets whether AI commit message generation is enabled
 func (m *Manager) SetAICommitEnabled(enabled bool) error {
-	m.config.AICommitEnabled = enabled
+	m.aiSettings().CommitEnabled = enabled
 	return m.save()
 }
 
 // GetAIBranchNameEnabled returns whether AI branch name generation is enabled
 func (m *Manager) GetAIBranchNameEnabled() bool {
-	return m.config.AIBranchNameEnabled
+	return m.config.AI != nil && m.config.AI.BranchNameEnabled
 }
 
 // SetAIBranchNameEnabled sets whether AI branch name generation is enabled
 func (m *Manager) SetAIBranchNameEnabled(enabled bool) error {
-	m.config.AIBranchNameEnabled = enabled
+	m.aiSettings().BranchNameEnabled = enabled
 	return m.save()
 }
 
@@ -340,6 +598,21 @@ func (m *Manager) SetDebugLoggingEnabled(enabled bool) error {
 	return m.save()
 }
 
+// GetStatusConcurrency returns the max number of worktrees whose status is loaded in parallel
+// Returns 0 if not set, meaning the git package default is used
+func (m *Manager) GetStatusConcurrency() int {
+	if m.config.StatusConcurrency > 0 {
+		return m.config.StatusConcurrency
+	}
+	return 0
+}
+
+// SetStatusConcurrency sets the max number of worktrees whose status is loaded in parallel
+func (m *Manager) SetStatusConcurrency(n int) error {
+	m.config.StatusConcurrency = n
+	return m.save()
+}
+
 // GetPRs returns all pull requests for a given branch
 func (m *Manager) GetPRs(repoPath, branch string) []PRInfo {
 	if repo, ok := m.config.Repositories[repoPath]; ok {
@@ -471,6 +744,7 @@ func (m *Manager) SetClaudeInitialized(repoPath, branch string) error {
 // This includes:
 // - All pull requests for the branch
 // - Claude initialization flag
+// - Reserved port block
 // - Last selected branch reference (if it matches the deleted branch)
 func (m *Manager) CleanupBranch(repoPath, branch string) error {
 	repo, ok := m.config.Repositories[repoPath]
@@ -488,6 +762,11 @@ func (m *Manager) CleanupBranch(repoPath, branch string) error {
 		delete(repo.InitializedClaudes, branch)
 	}
 
+	// Release the branch's ports for new worktrees
+	if repo.PortBlocks != nil {
+		delete(repo.PortBlocks, branch)
+	}
+
 	// Clear last selected branch if it matches the deleted branch
 	if repo.LastSelectedBranch == branch {
 		repo.LastSelectedBranch = ""
@@ -499,60 +778,63 @@ func (m *Manager) CleanupBranch(repoPath, branch string) error {
 // GetCommitPrompt returns the custom commit message prompt
 // Returns the custom prompt if set, otherwise returns the default prompt
 func (m *Manager) GetCommitPrompt() string {
-	if m.config.AIPrompts != nil && m.config.AIPrompts.CommitMessage != "" {
-		return m.config.AIPrompts.CommitMessage
+	if m.config.AI != nil && m.config.AI.Prompts != nil && m.config.AI.Prompts.CommitMessage != "" {
+		return m.config.AI.Prompts.CommitMessage
 	}
 	return claude.GetDefaultCommitPrompt()
 }
 
 // SetCommitPrompt sets the custom commit message prompt
 func (m *Manager) SetCommitPrompt(prompt string) error {
-	if m.config.AIPrompts == nil {
-		m.config.AIPrompts = &AIPrompts{}
+	ai := m.aiSettings()
+	if ai.Prompts == nil {
+		ai.Prompts = &AIPrompts{}
 	}
-	m.config.AIPrompts.CommitMessage = prompt
+	ai.Prompts.CommitMessage = prompt
 	return m.save()
 }
 
 // GetBranchNamePrompt returns the custom branch name prompt
 // Returns the custom prompt if set, otherwise returns the default prompt
 func (m *Manager) GetBranchNamePrompt() string {
-	if m.config.AIPrompts != nil && m.config.AIPrompts.BranchName != "" {
-		return m.config.AIPrompts.BranchName
+	if m.config.AI != nil && m.config.AI.Prompts != nil && m.config.AI.Prompts.BranchName != "" {
+		return m.config.AI.Prompts.BranchName
 	}
 	return claude.GetDefaultBranchNamePrompt()
 }
 
 // SetBranchNamePrompt sets the custom branch name prompt
 func (m *Manager) SetBranchNamePrompt(prompt string) error {
-	if m.config.AIPrompts == nil {
-		m.config.AIPrompts = &AIPrompts{}
+	ai := m.aiSettings()
+	if ai.Prompts == nil {
+		ai.Prompts = &AIPrompts{}
 	}
-	m.config.AIPrompts.BranchName = prompt
+	ai.Prompts.BranchName = prompt
 	return m.save()
 }
 
 // GetPRPrompt returns the custom PR content prompt
 // Returns the custom prompt if set, otherwise returns the default prompt
 func (m *Manager) GetPRPrompt() string {
-	if m.config.AIPrompts != nil && m.config.AIPrompts.PRContent != "" {
-		return m.config.AIPrompts.PRContent
+	if m.config.AI != nil && m.config.AI.Prompts != nil && m.config.AI.Prompts.PRContent != "" {
+		return m.config.AI.Prompts.PRContent
 	}
 	return claude.GetDefaultPRPrompt()
 }
 
 // SetPRPrompt sets the custom PR content prompt
 func (m *Manager) SetPRPrompt(prompt string) error {
-	if m.config.AIPrompts == nil {
-		m.config.AIPrompts = &AIPrompts{}
+	ai := m.aiSettings()
+	if ai.Prompts == nil {
+		ai.Prompts = &AIPrompts{}
 	}
-	m.config.AIPrompts.PRContent = prompt
+	ai.Prompts.PRContent = prompt
 	return m.save()
 }
 
 // ResetAIPromptsToDefaults resets all AI prompts to their default values
 func (m *Manager) ResetAIPromptsToDefaults() error {
-	m.config.AIPrompts = &AIPrompts{} // Empty AIPrompts means use defaults
+	m.aiSettings().Prompts = &AIPrompts{} // Empty AIPrompts means use defaults
 	return m.save()
 }
 
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Copy modes of jean.json "copyPaths" entries
+const (
+	CopyModeCopy     = "copy"     // Copy the files (default)
+	CopyModeSymlink  = "symlink"  // Symlink to the main repository's copy, shared by every worktree
+	CopyModeHardlink = "hardlink" // Hardlink each file; falls back to copying across filesystems
+	CopyModeReflink  = "reflink"  // Copy-on-write clone (btrfs, XFS, APFS); falls back to copying
+)
+
+// DefaultCopyPaths are copied into new worktrees when jean.json doesn't list copyPaths
+var DefaultCopyPaths = []string{".claude", ".husky"}
+
+// CopyPath is an entry of jean.json "copyPaths", written either as a plain path or glob
+// (".env", "config/*.local.json", "!*.log" to exclude) or as an object with options
+type CopyPath struct {
+	Path       string   `json:"path"`       // Path or glob relative to the repository root; "**" matches any number of directories
+	Mode       string   `json:"mode"`       // CopyModeCopy (default), CopyModeSymlink, CopyModeHardlink or CopyModeReflink
+	Exclude    []string `json:"exclude"`    // Globs of paths inside the entry that are left out
+	Gitignored bool     `json:"gitignored"` // Match Path against the main repository's gitignored files (e.g. ".env*")
+}
+
+// UnmarshalJSON accepts a plain string as well as an object
+func (c *CopyPath) UnmarshalJSON(data []byte) error {
+	var path string
+	if err := json.Unmarshal(data, &path); err == nil {
+		*c = CopyPath{Path: path}
+		return nil
+	}
+	type copyPath CopyPath // Without the UnmarshalJSON method
+	var entry copyPath
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return fmt.Errorf("copyPaths entries must be a path or an object: %w", err)
+	}
+	*c = CopyPath(entry)
+	return nil
+}
+
+// GetMode returns the entry's copy mode, CopyModeCopy if not set
+func (c CopyPath) GetMode() (string, error) {
+	switch c.Mode {
+	case "":
+		return CopyModeCopy, nil
+	case CopyModeCopy, CopyModeSymlink, CopyModeHardlink, CopyModeReflink:
+		return c.Mode, nil
+	}
+	return "", fmt.Errorf("unknown mode %q for %s (use copy, symlink, hardlink or reflink)", c.Mode, c.Path)
+}
+
+// IsExclusion reports whether the entry excludes paths ("!pattern") instead of copying them
+func (c CopyPath) IsExclusion() bool {
+	return strings.HasPrefix(c.Path, "!") && !c.Gitignored
+}
+
+// GetCopyPaths returns the entries to copy from the base repo to worktrees, without exclusions
+// Defaults to DefaultCopyPaths if not configured
+func (s *ScriptConfig) GetCopyPaths() []CopyPath {
+	if s == nil || len(s.CopyPaths) == 0 {
+		// Default: copy .claude directory (for Claude Code settings) and .husky (for git hooks)
+		paths := make([]CopyPath, len(DefaultCopyPaths))
+		for i, path := range DefaultCopyPaths {
+			paths[i] = CopyPath{Path: path}
+		}
+		return paths
+	}
+	paths := make([]CopyPath, 0, len(s.CopyPaths))
+	for _, entry := range s.CopyPaths {
+		if entry.Path != "" && !entry.IsExclusion() {
+			paths = append(paths, entry)
+		}
+	}
+	return paths
+}
+
+// GetCopyExcludes returns the globs of the "!pattern" copyPaths entries, which apply to every entry
+func (s *ScriptConfig) GetCopyExcludes() []string {
+	if s == nil {
+		return nil
+	}
+	var excludes []string
+	for _, entry := range s.CopyPaths {
+		if entry.IsExclusion() {
+			excludes = append(excludes, strings.TrimPrefix(entry.Path, "!"))
+		}
+	}
+	return excludes
+}
@@ -0,0 +1,284 @@
+package config
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Credential stores secrets can be kept in, see NewCredentialStore
+const (
+	CredentialStoreKeychain      = "keychain"       // macOS Keychain
+	CredentialStoreSecretService = "secret-service" // Secret Service keyring (GNOME Keyring, KWallet) via secret-tool
+	CredentialStoreFile          = "file"           // credentials.json in the config directory, readable only by the user
+)
+
+// credentialService is the service name secrets are stored under in keyrings
+const credentialService = "jean"
+
+// anthropicAPIKeyName is the name the Anthropic API key is stored under
+const anthropicAPIKeyName = "anthropic_api_key"
+
+// CredentialStore keeps secrets such as API keys out of config.json
+type CredentialStore interface {
+	Kind() string                    // One of the CredentialStore* constants
+	Description() string             // Where secrets are kept, for display
+	Get(name string) (string, error) // "" if nothing is stored under name
+	Set(name, value string) error
+	Delete(name string) error
+}
+
+// NewCredentialStore returns the system keyring if one is available, otherwise a credentials file in configDir
+// JEAN_CREDENTIAL_STORE ("keychain", "secret-service" or "file") overrides the choice
+func NewCredentialStore(configDir string) CredentialStore {
+	kind := os.Getenv("JEAN_CREDENTIAL_STORE")
+	if kind == "" {
+		switch {
+		case runtime.GOOS == "darwin" && commandExists("security"):
+			kind = CredentialStoreKeychain
+		case runtime.GOOS == "linux" && os.Getenv("DBUS_SESSION_BUS_ADDRESS") != "" && commandExists("secret-tool"):
+			kind = CredentialStoreSecretService
+		}
+	}
+
+	switch kind {
+	case CredentialStoreKeychain:
+		return keychainCredentialStore{}
+	case CredentialStoreSecretService:
+		return secretServiceCredentialStore{}
+	}
+	return &fileCredentialStore{path: filepath.Join(configDir, "credentials.json")}
+}
+
+// commandExists reports whether a command is on the PATH
+func commandExists(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
+
+// keychainCredentialStore keeps secrets in the macOS Keychain with the security command
+type keychainCredentialStore struct{}
+
+func (keychainCredentialStore) Kind() string        { return CredentialStoreKeychain }
+func (keychainCredentialStore) Description() string { return "macOS Keychain" }
+
+func (keychainCredentialStore) Get(name string) (string, error) {
+	output, err := exec.Command("security", "find-generic-password", "-s", credentialService, "-a", name, "-w").Output()
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			return "", nil // Not stored
+		}
+		return "", fmt.Errorf("failed to read from the Keychain: %w", err)
+	}
+	return strings.TrimRight(string(output), "\n"), nil
+}
+
+func (s keychainCredentialStore) Set(name, value string) error {
+	// The secret goes through stdin so it never shows up in the process list
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader(keychainAddCommand(name, value))
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to save to the Keychain: %s", strings.TrimSpace(string(output)))
+	}
+	// Interactive mode doesn't fail when a command does, so read the secret back
+	stored, err := s.Get(name)
+	if err != nil {
+		return err
+	}
+	if stored != value {
+		return fmt.Errorf("failed to save to the Keychain: %s", strings.TrimSpace("the secret wasn't stored "+string(output)))
+	}
+	return nil
+}
+
+// keychainAddCommand returns the line that saves a secret in "security -i" (interactive mode)
+// The secret is hex encoded (-X), so it needs no quoting
+func keychainAddCommand(name, value string) string {
+	return fmt.Sprintf("add-generic-password -U -s %s -a %s -X %s\n", credentialService, name, hex.EncodeToString([]byte(value)))
+}
+
+func (s keychainCredentialStore) Delete(name string) error {
+	if value, err := s.Get(name); err != nil || value == "" {
+		return err
+	}
+	cmd := exec.Command("security", "delete-generic-password", "-s", credentialService, "-a", name)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to delete from the Keychain: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// secretServiceCredentialStore keeps secrets in the Secret Service keyring with secret-tool (libsecret)
+type secretServiceCredentialStore struct{}
+
+func (secretServiceCredentialStore) Kind() string        { return CredentialStoreSecretService }
+func (secretServiceCredentialStore) Description() string { return "system keyring (Secret Service)" }
+
+func (secretServiceCredentialStore) Get(name string) (string, error) {
+	output, err := exec.Command("secret-tool", "lookup", "service", credentialService, "key", name).Output()
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); ok && len(output) == 0 {
+			return "", nil // Not stored
+		}
+		return "", fmt.Errorf("failed to read from the keyring: %w", err)
+	}
+	return strings.TrimRight(string(output), "\n"), nil
+}
+
+func (secretServiceCredentialStore) Set(name, value string) error {
+	// The secret goes through stdin so it never shows up in the process list
+	cmd := exec.Command("secret-tool", "store", "--label", credentialService+" "+name, "service", credentialService, "key", name)
+	cmd.Stdin = strings.NewReader(value)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to save to the keyring: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func (secretServiceCredentialStore) Delete(name string) error {
+	cmd := exec.Command("secret-tool", "clear", "service", credentialService, "key", name)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to delete from the keyring: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// fileCredentialStore keeps secrets in a JSON file only the user can read (0600)
+type fileCredentialStore struct {
+	path string
+}
+
+func (s *fileCredentialStore) Kind() string { return CredentialStoreFile }
+
+func (s *fileCredentialStore) Description() string {
+	if home, err := os.UserHomeDir(); err == nil && strings.HasPrefix(s.path, home+string(filepath.Separator)) {
+		return "~" + strings.TrimPrefix(s.path, home) + " (owner-only)"
+	}
+	return s.path + " (owner-only)"
+}
+
+// load reads all stored secrets
+func (s *fileCredentialStore) load() (map[string]string, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]string{}, nil
+		}
+		return nil, err
+	}
+	secrets := map[string]string{}
+	if err := json.Unmarshal(data, &secrets); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", s.path, err)
+	}
+	return secrets, nil
+}
+
+// save writes all secrets, readable only by the user
+func (s *fileCredentialStore) save(secrets map[string]string) error {
+	data, err := json.MarshalIndent(secrets, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeAtomic(s.path, data, 0600)
+}
+
+func (s *fileCredentialStore) Get(name string) (string, error) {
+	secrets, err := s.load()
+	if err != nil {
+		return "", err
+	}
+	return secrets[name], nil
+}
+
+func (s *fileCredentialStore) Set(name, value string) error {
+	secrets, err := s.load()
+	if err != nil {
+		return err
+	}
+	secrets[name] = value
+	return s.save(secrets)
+}
+
+func (s *fileCredentialStore) Delete(name string) error {
+	secrets, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := secrets[name]; !ok {
+		return nil
+	}
+	delete(secrets, name)
+	return s.save(secrets)
+}
+
+// fileCredentials returns the credentials file next to config.json, the fallback when no keyring works
+func (m *Manager) fileCredentials() CredentialStore {
+	return &fileCredentialStore{path: filepath.Join(filepath.Dir(m.configPath), "credentials.json")}
+}
+
+// loadAPIKey reads the API key from the credential store
+// A plaintext key left in config.json by older versions is moved to the credential store first
+func (m *Manager) loadAPIKey() {
+	if plaintext := m.config.AnthropicAPIKey; plaintext != "" {
+		m.apiKey = plaintext
+		if err := m.storeAPIKey(plaintext); err == nil {
+			m.config.AnthropicAPIKey = ""
+			_ = m.save()
+		}
+		return
+	}
+
+	stores := []CredentialStore{m.credentials}
+	if m.credentials.Kind() != CredentialStoreFile {
+		// The key ends up in the file when the keyring was unavailable while saving
+		stores = append(stores, m.fileCredentials())
+	}
+	for _, store := range stores {
+		if key, err := store.Get(anthropicAPIKeyName); err == nil && key != "" {
+			m.apiKey = key
+			m.apiKeyStore = store
+			return
+		}
+	}
+}
+
+// storeAPIKey saves the API key in the credential store, falling back to the credentials file
+// if the keyring can't be used (locked, no session bus, ...)
+func (m *Manager) storeAPIKey(apiKey string) error {
+	err := m.credentials.Set(anthropicAPIKeyName, apiKey)
+	if err != nil && m.credentials.Kind() != CredentialStoreFile {
+		m.credentials = m.fileCredentials()
+		err = m.credentials.Set(anthropicAPIKeyName, apiKey)
+	}
+	if err != nil {
+		return err
+	}
+	m.apiKeyStore = m.credentials
+	return nil
+}
+
+// APIKeyLocation describes where the API key in use comes from, "" if there is none
+func (m *Manager) APIKeyLocation() string {
+	switch {
+	case os.Getenv("CLAUDE_CODE_OAUTH_TOKEN") != "":
+		return "CLAUDE_CODE_OAUTH_TOKEN environment variable"
+	case m.apiKey == "":
+		return ""
+	case m.config.AnthropicAPIKey != "":
+		return "config.json (plaintext, moving it to the credential store failed)"
+	case m.apiKeyStore != nil:
+		return m.apiKeyStore.Description()
+	}
+	return m.credentials.Description()
+}
+
+// CredentialStoreDescription describes where a newly saved API key goes
+func (m *Manager) CredentialStoreDescription() string {
+	return m.credentials.Description()
+}
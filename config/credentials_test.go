@@ -0,0 +1,53 @@
+package config
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPlaintextAPIKeyMovedToCredentialStore(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("CLAUDE_CODE_OAUTH_TOKEN", "")
+	t.Setenv("JEAN_CREDENTIAL_STORE", CredentialStoreFile)
+	configDir := filepath.Join(home, ".config", "jean")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(configDir, "config.json"), []byte(`{"anthropic_api_key": "sk-ant-secret"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := NewManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.GetAnthropicAPIKey() != "sk-ant-secret" {
+		t.Fatalf("Expected the migrated key, got %q", m.GetAnthropicAPIKey())
+	}
+	data, _ := os.ReadFile(filepath.Join(configDir, "config.json"))
+	if strings.Contains(string(data), "sk-ant-secret") {
+		t.Error("Expected the plaintext key to be removed from config.json")
+	}
+	info, err := os.Stat(filepath.Join(configDir, "credentials.json"))
+	if err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Expected an owner-only credentials file, got %v (%v)", info, err)
+	}
+	if !strings.Contains(m.APIKeyLocation(), "credentials.json") {
+		t.Errorf("Expected the key location to name the credentials file, got %q", m.APIKeyLocation())
+	}
+}
+
+func TestKeychainAddCommandKeepsSecretOutOfArguments(t *testing.T) {
+	secret := `sk-ant-'quoted" \value`
+	line := keychainAddCommand(anthropicAPIKeyName, secret)
+	if strings.Contains(line, "sk-ant") {
+		t.Errorf("Expected the secret to be hex encoded, got %q", line)
+	}
+	if want := "add-generic-password -U -s jean -a anthropic_api_key -X " + hex.EncodeToString([]byte(secret)) + "\n"; line != want {
+		t.Errorf("Expected %q, got %q", want, line)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package config
+
+// lockFile has no advisory locks to take outside Unix: saves of concurrent jean instances
+// aren't serialized, but each still merges its changes into what's on disk
+func lockFile(configPath string) (func(), error) {
+	return func() {}, nil
+}
@@ -0,0 +1,210 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// CurrentConfigVersion is the config.json schema version this build reads and writes
+// Raise it together with a new entry in configMigrations
+const CurrentConfigVersion = 2
+
+// ErrConfigTooNew is returned when config.json was written by a newer jean
+var ErrConfigTooNew = errors.New("config.json was written by a newer version of jean")
+
+// configMigration upgrades config.json from one schema version to the next
+type configMigration struct {
+	description string
+	apply       func(values map[string]any) []string // Changes the generic JSON in place and describes each change
+}
+
+// configMigrations[i] upgrades version i to version i+1
+var configMigrations = []configMigration{
+	{"Use snake_case for lastUpdateCheckTime", migrateSnakeCaseKeys},
+	{`Group the AI settings under "ai"`, migrateAISettings},
+}
+
+// MigrationStep is one schema upgrade of config.json
+type MigrationStep struct {
+	From        int
+	To          int
+	Description string
+	Changes     []string // One line per moved, renamed or removed value
+}
+
+// Migration describes the upgrade of a config.json to CurrentConfigVersion
+type Migration struct {
+	Path        string
+	FromVersion int
+	ToVersion   int
+	Steps       []MigrationStep // Empty if the file is up to date
+	BackupPath  string          // Copy of the file before the upgrade, "" if nothing was written
+	Config      []byte          // The upgraded file
+}
+
+// secretConfigKeys are the config.json keys whose values are masked when the file is shown
+var secretConfigKeys = []string{anthropicAPIKeyName}
+
+// RedactedConfig returns the upgraded file with secrets (e.g. a plaintext API key of older versions) masked,
+// for showing it on screen
+func (m *Migration) RedactedConfig() ([]byte, error) {
+	var values map[string]any
+	if err := json.Unmarshal(m.Config, &values); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", m.Path, err)
+	}
+	for _, key := range secretConfigKeys {
+		if value, ok := values[key].(string); ok && value != "" {
+			values[key] = "********"
+		}
+	}
+	return json.MarshalIndent(values, "", "  ")
+}
+
+// withoutSecrets returns a config file with the secretConfigKeys removed, or the file as is if it holds none
+// Backups mustn't keep a plaintext API key around after it has moved to the credential store
+func withoutSecrets(data []byte) ([]byte, error) {
+	var values map[string]any
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+	found := false
+	for _, key := range secretConfigKeys {
+		if _, ok := values[key]; ok {
+			delete(values, key)
+			found = true
+		}
+	}
+	if !found {
+		return data, nil
+	}
+	return json.MarshalIndent(values, "", "  ")
+}
+
+// MigrateConfigFile upgrades a config.json step by step to CurrentConfigVersion
+// The previous file is kept as config.json.v<version>.bak (without the API key); with dryRun nothing is written.
+// On a write error the upgraded config is still returned along with the error.
+func MigrateConfigFile(path string, dryRun bool) (*Migration, error) {
+	unlock, err := lockFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]any
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if values == nil {
+		values = map[string]any{}
+	}
+
+	from, err := configVersion(values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	migration := &Migration{Path: path, FromVersion: from, ToVersion: CurrentConfigVersion, Config: data}
+	if from > CurrentConfigVersion {
+		migration.ToVersion = from
+		return migration, fmt.Errorf("%w (version %d, this one supports up to %d), update jean", ErrConfigTooNew, from, CurrentConfigVersion)
+	}
+	if from == CurrentConfigVersion {
+		return migration, nil
+	}
+
+	for version := from; version < CurrentConfigVersion; version++ {
+		step := configMigrations[version]
+		migration.Steps = append(migration.Steps, MigrationStep{
+			From:        version,
+			To:          version + 1,
+			Description: step.description,
+			Changes:     step.apply(values),
+		})
+	}
+	values["version"] = CurrentConfigVersion
+
+	migration.Config, err = json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	if dryRun {
+		return migration, nil
+	}
+
+	// The upgraded file keeps the API key until it's moved to the credential store, the backup never has it
+	backup, err := withoutSecrets(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	backupPath := fmt.Sprintf("%s.v%d.bak", path, from)
+	if err := writeAtomic(backupPath, backup, 0600); err != nil {
+		return migration, fmt.Errorf("failed to back up config before upgrading it: %w", err)
+	}
+	migration.BackupPath = backupPath
+	if err := writeAtomic(path, migration.Config, 0644); err != nil {
+		return migration, fmt.Errorf("failed to write upgraded config: %w", err)
+	}
+	return migration, nil
+}
+
+// configVersion returns the schema version of a config, 0 for files written before versioning
+func configVersion(values map[string]any) (int, error) {
+	raw, ok := values["version"]
+	if !ok {
+		return 0, nil
+	}
+	version, ok := raw.(float64)
+	if !ok || version < 0 || version != float64(int(version)) {
+		return 0, fmt.Errorf("invalid version %v", raw)
+	}
+	return int(version), nil
+}
+
+// moveKey moves a value to another key (possibly in another object), keeping a value already at the destination
+func moveKey(from map[string]any, fromKey string, to map[string]any, toKey string) bool {
+	value, ok := from[fromKey]
+	if !ok {
+		return false
+	}
+	delete(from, fromKey)
+	if _, exists := to[toKey]; !exists {
+		to[toKey] = value
+	}
+	return true
+}
+
+// migrateSnakeCaseKeys renames the one camelCase key (version 0 to 1)
+func migrateSnakeCaseKeys(values map[string]any) []string {
+	if moveKey(values, "lastUpdateCheckTime", values, "last_update_check_time") {
+		return []string{"renamed lastUpdateCheckTime to last_update_check_time"}
+	}
+	return nil
+}
+
+// migrateAISettings moves the top-level AI fields into the "ai" object (version 1 to 2)
+func migrateAISettings(values map[string]any) []string {
+	ai, _ := values["ai"].(map[string]any)
+	if ai == nil {
+		ai = map[string]any{}
+	}
+	var changes []string
+	for _, move := range [][2]string{
+		{"claude_model", "model"},
+		{"ai_commit_enabled", "commit_enabled"},
+		{"ai_branch_name_enabled", "branch_name_enabled"},
+		{"ai_prompts", "prompts"},
+	} {
+		if moveKey(values, move[0], ai, move[1]) {
+			changes = append(changes, fmt.Sprintf("moved %s to ai.%s", move[0], move[1]))
+		}
+	}
+	if len(ai) > 0 {
+		values["ai"] = ai
+	}
+	return changes
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOldConfigMigratedOnLoad(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("JEAN_CREDENTIAL_STORE", CredentialStoreFile)
+	configDir := filepath.Join(home, ".config", "jean")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	old := `{"lastUpdateCheckTime": "2025-01-01T00:00:00Z", "claude_model": "claude-sonnet-4-5", "ai_commit_enabled": true}`
+	if err := os.WriteFile(filepath.Join(configDir, "config.json"), []byte(old), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := NewManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.GetClaudeModel() != "claude-sonnet-4-5" || !m.GetAICommitEnabled() {
+		t.Errorf("Expected the AI settings to survive the upgrade, got model %q", m.GetClaudeModel())
+	}
+	if m.GetLastUpdateCheckTime() != "2025-01-01T00:00:00Z" {
+		t.Errorf("Expected the renamed update check time, got %q", m.GetLastUpdateCheckTime())
+	}
+	data, _ := os.ReadFile(filepath.Join(configDir, "config.json"))
+	if !strings.Contains(string(data), `"version": 2`) || strings.Contains(string(data), "claude_model") {
+		t.Errorf("Expected config.json to be upgraded, got %s", data)
+	}
+	backup, err := os.ReadFile(filepath.Join(configDir, "config.json.v0.bak"))
+	if err != nil || string(backup) != old {
+		t.Errorf("Expected the previous config to be backed up, got %q (%v)", backup, err)
+	}
+
+	migration, err := MigrateConfigFile(filepath.Join(configDir, "config.json"), true)
+	if err != nil || len(migration.Steps) != 0 {
+		t.Errorf("Expected nothing left to migrate, got %+v (%v)", migration, err)
+	}
+}
+
+func TestMigrationBackupLeavesOutAPIKey(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("JEAN_CREDENTIAL_STORE", CredentialStoreFile)
+	configDir := filepath.Join(home, ".config", "jean")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	old := `{"anthropic_api_key": "sk-ant-secret", "claude_model": "claude-sonnet-4-5"}`
+	if err := os.WriteFile(filepath.Join(configDir, "config.json"), []byte(old), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := NewManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.GetAnthropicAPIKey() != "sk-ant-secret" {
+		t.Errorf("Expected the API key to survive the upgrade, got %q", m.GetAnthropicAPIKey())
+	}
+	for _, name := range []string{"config.json", "config.json.v0.bak"} {
+		data, err := os.ReadFile(filepath.Join(configDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), "sk-ant-secret") {
+			t.Errorf("Expected %s not to hold the API key, got %s", name, data)
+		}
+	}
+	backup, _ := os.ReadFile(filepath.Join(configDir, "config.json.v0.bak"))
+	if !strings.Contains(string(backup), `"claude_model": "claude-sonnet-4-5"`) {
+		t.Errorf("Expected the rest of the previous config in the backup, got %s", backup)
+	}
+}
+
+func TestDryRunMasksSecrets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	old := `{"anthropic_api_key": "sk-ant-secret", "claude_model": "claude-sonnet-4-5"}`
+	if err := os.WriteFile(path, []byte(old), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	migration, err := MigrateConfigFile(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	redacted, err := migration.RedactedConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(redacted), "sk-ant-secret") || !strings.Contains(string(redacted), `"anthropic_api_key": "********"`) {
+		t.Errorf("Expected the API key to be masked, got %s", redacted)
+	}
+	if !strings.Contains(string(redacted), `"model": "claude-sonnet-4-5"`) {
+		t.Errorf("Expected the rest of the upgraded config, got %s", redacted)
+	}
+	if data, _ := os.ReadFile(path); string(data) != old {
+		t.Errorf("Expected a dry run to leave the file alone, got %s", data)
+	}
+}
@@ -0,0 +1,167 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// BranchNamingRules are the branch naming conventions from jean.json "branchNaming"
+// Names look like "<prefix>/<ticket>-<description>", e.g. "feat/ABC-123-short-desc"
+type BranchNamingRules struct {
+	Prefixes      []string `json:"prefixes"`      // Allowed prefixes (e.g. ["feat", "fix"]); names must start with "<prefix>/"
+	TicketPattern string   `json:"ticketPattern"` // Regex of the ticket ID that must follow the prefix (e.g. "[A-Z]+-[0-9]+")
+	MaxLength     int      `json:"maxLength"`     // Max length of the whole name, 0 = no limit
+	Pattern       string   `json:"pattern"`       // Regex the whole name must match
+}
+
+// GetBranchNaming returns the branch naming rules, or nil if jean.json doesn't define any
+func (s *ScriptConfig) GetBranchNaming() *BranchNamingRules {
+	if s == nil {
+		return nil
+	}
+	return s.BranchNaming
+}
+
+// GetPrefixes returns the allowed prefixes without trailing slashes
+func (r *BranchNamingRules) GetPrefixes() []string {
+	if r == nil {
+		return nil
+	}
+	prefixes := make([]string, 0, len(r.Prefixes))
+	for _, prefix := range r.Prefixes {
+		if prefix = strings.Trim(prefix, "/ "); prefix != "" {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	return prefixes
+}
+
+// SplitName splits a branch name into its allowed prefix, ticket ID and the rest
+// Parts that aren't present (or not configured) are returned empty
+func (r *BranchNamingRules) SplitName(name string) (prefix, ticket, rest string) {
+	rest = name
+	for _, p := range r.GetPrefixes() {
+		if strings.HasPrefix(rest, p+"/") {
+			prefix = p
+			rest = strings.TrimPrefix(rest, p+"/")
+			break
+		}
+	}
+	if re := r.ticketRegex(); re != nil {
+		if loc := re.FindStringIndex(rest); loc != nil {
+			ticket = rest[:loc[1]]
+			rest = strings.TrimLeft(rest[loc[1]:], "-_")
+		}
+	}
+	return prefix, ticket, rest
+}
+
+// FormatName joins prefix, ticket and description into a branch name,
+// shortening the description to fit MaxLength (or maxDefault if no limit is configured)
+func (r *BranchNamingRules) FormatName(prefix, ticket, description string, maxDefault int) string {
+	head := ""
+	if prefix != "" {
+		head = prefix + "/"
+	}
+	if ticket != "" {
+		head += ticket
+		if description != "" {
+			head += "-"
+		}
+	}
+
+	limit := maxDefault
+	if r != nil && r.MaxLength > 0 {
+		limit = r.MaxLength
+	}
+	if limit > 0 && len(head)+len(description) > limit {
+		description = strings.TrimRight(description[:max(0, limit-len(head))], "-")
+		if description == "" {
+			head = strings.TrimRight(head, "-")
+		}
+	}
+	return head + description
+}
+
+// CyclePrefix replaces the allowed prefix of a branch name with the next (step=1) or previous (step=-1) one
+// A name without an allowed prefix gets the first one
+func (r *BranchNamingRules) CyclePrefix(name string, step int) string {
+	prefixes := r.GetPrefixes()
+	if len(prefixes) == 0 {
+		return name
+	}
+
+	current := -1
+	for i, p := range prefixes {
+		if strings.HasPrefix(name, p+"/") {
+			current = i
+			name = strings.TrimPrefix(name, p+"/")
+			break
+		}
+	}
+
+	next := 0
+	if current >= 0 {
+		next = (current + step + len(prefixes)) % len(prefixes)
+	}
+	return prefixes[next] + "/" + name
+}
+
+// Validate checks a branch name against the rules; nil rules accept any name
+func (r *BranchNamingRules) Validate(name string) error {
+	if r == nil {
+		return nil
+	}
+
+	prefixes := r.GetPrefixes()
+	prefix, ticket, _ := r.SplitName(name)
+	if len(prefixes) > 0 && prefix == "" {
+		return fmt.Errorf("must start with one of: %s", strings.Join(withSlash(prefixes), ", "))
+	}
+	if r.TicketPattern != "" {
+		if r.ticketRegex() == nil {
+			return fmt.Errorf("invalid ticketPattern in jean.json")
+		}
+		if ticket == "" {
+			if prefix != "" {
+				return fmt.Errorf("needs a ticket ID matching %s after %s/", r.TicketPattern, prefix)
+			}
+			return fmt.Errorf("must start with a ticket ID matching %s", r.TicketPattern)
+		}
+	}
+	if r.MaxLength > 0 && len(name) > r.MaxLength {
+		return fmt.Errorf("is %d characters, max is %d", len(name), r.MaxLength)
+	}
+	if r.Pattern != "" {
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern in jean.json: %w", err)
+		}
+		if !re.MatchString(name) {
+			return fmt.Errorf("doesn't match %s", r.Pattern)
+		}
+	}
+	return nil
+}
+
+// ticketRegex returns the ticket pattern anchored to the start of a name, or nil if unset or invalid
+func (r *BranchNamingRules) ticketRegex() *regexp.Regexp {
+	if r == nil || r.TicketPattern == "" {
+		return nil
+	}
+	re, err := regexp.Compile(`^(?:` + r.TicketPattern + `)`)
+	if err != nil {
+		return nil
+	}
+	return re
+}
+
+// withSlash appends "/" to each prefix for display
+func withSlash(prefixes []string) []string {
+	result := make([]string, len(prefixes))
+	for i, p := range prefixes {
+		result[i] = p + "/"
+	}
+	return result
+}
@@ -0,0 +1,34 @@
+package config
+
+import "testing"
+
+func TestBranchNamingRules(t *testing.T) {
+	rules := &BranchNamingRules{
+		Prefixes:      []string{"feat", "fix/"},
+		TicketPattern: "[A-Z]+-[0-9]+",
+		MaxLength:     30,
+	}
+
+	tests := map[string]bool{
+		"feat/ABC-123-short-desc": true,
+		"fix/ABC-1":               true,
+		"chore/ABC-123-x":         false, // Unknown prefix
+		"feat/short-desc":         false, // Missing ticket
+		"feat/ABC-123-a-much-too-long-description": false, // Longer than 30
+	}
+	for name, valid := range tests {
+		if err := rules.Validate(name); (err == nil) != valid {
+			t.Errorf("Validate(%q) = %v, want valid=%v", name, err, valid)
+		}
+	}
+
+	if got := rules.CyclePrefix("ABC-1-x", 1); got != "feat/ABC-1-x" {
+		t.Errorf("Expected first prefix to be added, got %q", got)
+	}
+	if got := rules.CyclePrefix("feat/ABC-1-x", 1); got != "fix/ABC-1-x" {
+		t.Errorf("Expected prefix to cycle to fix, got %q", got)
+	}
+	if prefix, ticket, rest := rules.SplitName("fix/ABC-9-login"); prefix != "fix" || ticket != "ABC-9" || rest != "login" {
+		t.Errorf("SplitName = %q, %q, %q; want fix, ABC-9, login", prefix, ticket, rest)
+	}
+}
@@ -0,0 +1,90 @@
+package config
+
+import "fmt"
+
+// Ports handed out to worktrees for jean.json "ports"
+// Every worktree gets a block of PortBlockSize ports, so blocks never overlap however many ports a repo uses
+const (
+	PortRangeStart = 40000 // First port of the first block
+	PortRangeEnd   = 60000 // Blocks end before this port
+	PortBlockSize  = 10    // Ports reserved per worktree, the most jean.json "ports" can ask for
+)
+
+// GetPorts returns how many ports each worktree gets (jean.json "ports"), capped at PortBlockSize
+func (s *ScriptConfig) GetPorts() int {
+	if s == nil || s.Ports <= 0 {
+		return 0
+	}
+	return min(s.Ports, PortBlockSize)
+}
+
+// GetPortBlock returns the first port of the block reserved for a branch, or 0 if it has none
+func (m *Manager) GetPortBlock(repoPath, branch string) int {
+	if repo, ok := m.config.Repositories[repoPath]; ok {
+		return repo.PortBlocks[branch]
+	}
+	return 0
+}
+
+// AllocatePortBlock returns the first port of the block reserved for a branch, reserving one if needed
+// Blocks are unique across all repositories and jean instances: the free block is picked from the
+// config on disk under the config lock. usable reports whether a free block can be taken
+// (e.g. nothing else listens on its ports)
+func (m *Manager) AllocatePortBlock(repoPath, branch string, usable func(first int) bool) (int, error) {
+	if first := m.GetPortBlock(repoPath, branch); first != 0 {
+		return first, nil
+	}
+
+	var allocated int
+	err := m.update(func(config *Config) error {
+		repo, ok := config.Repositories[repoPath]
+		if !ok {
+			repo = &RepoConfig{}
+			config.Repositories[repoPath] = repo
+		}
+		// Another instance may have reserved one since this one read the config
+		if first := repo.PortBlocks[branch]; first != 0 {
+			allocated = first
+			return nil
+		}
+
+		taken := make(map[int]bool)
+		for _, other := range config.Repositories {
+			for _, first := range other.PortBlocks {
+				taken[first] = true
+			}
+		}
+		for first := PortRangeStart; first+PortBlockSize <= PortRangeEnd; first += PortBlockSize {
+			if taken[first] || (usable != nil && !usable(first)) {
+				continue
+			}
+			if repo.PortBlocks == nil {
+				repo.PortBlocks = make(map[string]int)
+			}
+			repo.PortBlocks[branch] = first
+			allocated = first
+			return nil
+		}
+		return fmt.Errorf("no free port block left between %d and %d", PortRangeStart, PortRangeEnd)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return allocated, nil
+}
+
+// RenamePortBlock moves the block reserved for a branch to its new name, so the renamed worktree keeps its ports
+func (m *Manager) RenamePortBlock(repoPath, oldBranch, newBranch string) error {
+	if m.GetPortBlock(repoPath, oldBranch) == 0 {
+		return nil
+	}
+	return m.update(func(config *Config) error {
+		repo, ok := config.Repositories[repoPath]
+		if !ok || repo.PortBlocks[oldBranch] == 0 {
+			return nil
+		}
+		repo.PortBlocks[newBranch] = repo.PortBlocks[oldBranch]
+		delete(repo.PortBlocks, oldBranch)
+		return nil
+	})
+}
@@ -0,0 +1,55 @@
+package config
+
+import "testing"
+
+func TestAllocatePortBlock(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("JEAN_CREDENTIAL_STORE", CredentialStoreFile)
+	m, err := NewManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A second jean instance that read the config before m reserved anything
+	other, err := NewManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := m.AllocatePortBlock("/repo", "feature", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := m.AllocatePortBlock("/repo", "feature", nil); again != first {
+		t.Errorf("Expected the block to stay %d, got %d", first, again)
+	}
+	otherRepo, err := other.AllocatePortBlock("/other", "feature", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if otherRepo == first {
+		t.Fatalf("Expected another instance to pick a block not taken on disk, both got %d", first)
+	}
+	if got := m.GetPortBlock("/other", "feature"); got != 0 {
+		t.Errorf("Expected m not to see the other instance's block until its next save, got %d", got)
+	}
+
+	// Blocks rejected by usable are skipped
+	usable := func(first int) bool { return first != PortRangeStart+2*PortBlockSize }
+	if third, _ := m.AllocatePortBlock("/repo", "third", usable); third != PortRangeStart+3*PortBlockSize {
+		t.Errorf("Expected the unusable and taken blocks to be skipped, got %d", third)
+	}
+
+	// A renamed branch keeps its ports
+	if err := m.RenamePortBlock("/repo", "feature", "feat/ABC-1-feature"); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.GetPortBlock("/repo", "feat/ABC-1-feature"); got != first {
+		t.Errorf("Expected the renamed branch to keep block %d, got %d", first, got)
+	}
+	if got := m.GetPortBlock("/repo", "feature"); got != 0 {
+		t.Errorf("Expected the old name to have no block, got %d", got)
+	}
+	if got := other.GetPortBlock("/other", "feature"); got != otherRepo {
+		t.Errorf("Expected the other instance's block to survive m's saves, got %d", got)
+	}
+}
@@ -3,13 +3,30 @@ package config
 import (
 	"encoding/json"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
 )
 
+// Branch deletion policies for jean.json "deletionPolicy"
+const (
+	DeletionPolicySafe  = "safe"  // Keep branches with commits that aren't on any remote or the base branch unless confirmed
+	DeletionPolicyForce = "force" // Always delete the branch with the worktree
+)
+
+// DefaultProtectedBranches are never deleted when jean.json doesn't list protected branches
+var DefaultProtectedBranches = []string{"main", "master", "develop", "development", "staging", "production"}
+
 // ScriptConfig represents the jean.json configuration file
 type ScriptConfig struct {
-	Scripts   map[string]string `json:"scripts"`
-	CopyPaths []string          `json:"copyPaths"` // Paths to copy from base repo to worktrees
+	Scripts           map[string]string  `json:"scripts"`
+	CopyPaths         []CopyPath         `json:"copyPaths"`         // Paths to copy from base repo to worktrees
+	ProtectedBranches []string           `json:"protectedBranches"` // Glob patterns of branches that are never deleted (e.g. "release/*")
+	DeletionPolicy    string             `json:"deletionPolicy"`    // "safe" (default) or "force"
+	BranchNaming      *BranchNamingRules `json:"branchNaming"`      // Branch naming conventions, nil = any name
+	Services          []string           `json:"services"`          // Scripts that run as long-lived services (e.g. "dev")
+	Ports             int                `json:"ports"`             // Ports reserved per worktree, exported as JEAN_PORT, JEAN_PORT_1, ...
+	EnvTemplates      []string           `json:"envTemplates"`      // Templates rendered into each worktree, e.g. ".env.jean.tmpl" -> ".env"
 }
 
 // LoadScripts loads the jean.json file from a repository path
@@ -58,6 +75,7 @@ func (s *ScriptConfig) GetScriptNames() []string {
 	for name := range s.Scripts {
 		names = append(names, name)
 	}
+	sort.Strings(names)
 	return names
 }
 
@@ -69,12 +87,43 @@ func (s *ScriptConfig) HasScripts() bool {
 	return len(s.Scripts) > 0
 }
 
-// GetCopyPaths returns the paths to copy from base repo to worktrees
-// Defaults to [".claude", ".husky"] if not configured
-func (s *ScriptConfig) GetCopyPaths() []string {
-	if s == nil || len(s.CopyPaths) == 0 {
-		// Default: copy .claude directory (for Claude Code settings) and .husky (for git hooks)
-		return []string{".claude", ".husky"}
+// IsService reports whether a script is marked as a long-running service
+func (s *ScriptConfig) IsService(name string) bool {
+	if s == nil {
+		return false
+	}
+	for _, service := range s.Services {
+		if service == name {
+			return true
+		}
+	}
+	return false
+}
+
+// GetProtectedBranches returns the glob patterns of branches that must never be deleted
+// Defaults to DefaultProtectedBranches if not configured
+func (s *ScriptConfig) GetProtectedBranches() []string {
+	if s == nil || len(s.ProtectedBranches) == 0 {
+		return DefaultProtectedBranches
+	}
+	return s.ProtectedBranches
+}
+
+// IsProtectedBranch checks if a branch matches one of the protected branch patterns
+func (s *ScriptConfig) IsProtectedBranch(branch string) bool {
+	for _, pattern := range s.GetProtectedBranches() {
+		if matched, err := path.Match(pattern, branch); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// GetDeletionPolicy returns how branches of deleted worktrees are handled
+// Defaults to DeletionPolicySafe if not configured or unknown
+func (s *ScriptConfig) GetDeletionPolicy() string {
+	if s != nil && s.DeletionPolicy == DeletionPolicyForce {
+		return DeletionPolicyForce
 	}
-	return s.CopyPaths
+	return DeletionPolicySafe
 }
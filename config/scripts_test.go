@@ -0,0 +1,20 @@
+package config
+
+import "testing"
+
+func TestScriptConfigProtectedBranches(t *testing.T) {
+	defaults := &ScriptConfig{}
+	if !defaults.IsProtectedBranch("master") || defaults.IsProtectedBranch("feature/x") {
+		t.Error("Expected the default list to protect master but not feature/x")
+	}
+
+	custom := &ScriptConfig{ProtectedBranches: []string{"main", "release/*"}}
+	for branch, want := range map[string]bool{"main": true, "release/1.2": true, "release": false, "master": false} {
+		if got := custom.IsProtectedBranch(branch); got != want {
+			t.Errorf("IsProtectedBranch(%q) = %v, want %v", branch, got, want)
+		}
+	}
+	if custom.GetDeletionPolicy() != DeletionPolicySafe {
+		t.Errorf("Expected deletion policy to default to safe, got %q", custom.GetDeletionPolicy())
+	}
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+)
+
+// absent marks a JSON value that doesn't exist while merging
+var absent = new(struct{})
+
+// snapshot returns the configuration as generic JSON, the form changes are merged in
+func snapshot(config *Config) (map[string]any, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	var values map[string]any
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// readDisk returns the configuration currently on disk as generic JSON
+// A missing file (e.g. before the first save) counts as unchanged since base was read, so nothing
+// is lost by merging onto it; a file that can't be read or parsed is an error rather than an empty config
+func (m *Manager) readDisk() (map[string]any, error) {
+	data, err := os.ReadFile(m.configPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return m.base, nil
+		}
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	var values map[string]any
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", m.configPath, err)
+	}
+	if values == nil {
+		values = map[string]any{}
+	}
+	return values, nil
+}
+
+// mergeChanges applies the changes made to local since base was read onto disk
+// Values this instance didn't change keep what other instances wrote; objects are merged key by key,
+// so e.g. PRs recorded for different branches or repositories are all kept
+func mergeChanges(base, local, disk any) any {
+	if reflect.DeepEqual(local, base) {
+		return disk
+	}
+	localMap, localIsMap := local.(map[string]any)
+	diskMap, diskIsMap := disk.(map[string]any)
+	if !localIsMap || !diskIsMap {
+		return local
+	}
+	baseMap, _ := base.(map[string]any)
+
+	merged := make(map[string]any, len(diskMap))
+	for key, value := range diskMap {
+		merged[key] = value
+	}
+	keys := make(map[string]bool, len(localMap)+len(baseMap))
+	for key := range localMap {
+		keys[key] = true
+	}
+	for key := range baseMap {
+		keys[key] = true
+	}
+	for key := range keys {
+		if value := mergeChanges(lookup(baseMap, key), lookup(localMap, key), lookup(diskMap, key)); value == absent {
+			delete(merged, key)
+		} else {
+			merged[key] = value
+		}
+	}
+	return merged
+}
+
+// lookup returns a key's value, or absent
+func lookup(values map[string]any, key string) any {
+	if value, ok := values[key]; ok {
+		return value
+	}
+	return absent
+}
+
+// writeAtomic replaces a file by writing a temporary file next to it and renaming it over the original,
+// so readers never see a half-written file
+func writeAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
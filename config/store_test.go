@@ -0,0 +1,140 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+)
+
+// newTestManagers returns n config managers, like n jean instances started on the same config file
+func newTestManagers(t *testing.T, n int) []*Manager {
+	t.Helper()
+	managers := make([]*Manager, n)
+	for i := range managers {
+		m, err := NewManager()
+		if err != nil {
+			t.Fatal(err)
+		}
+		managers[i] = m
+	}
+	return managers
+}
+
+// saveConcurrently runs edit on every manager at once and fails the test if one of them fails
+func saveConcurrently(t *testing.T, managers []*Manager, edit func(i int, m *Manager) error) {
+	t.Helper()
+	var wg sync.WaitGroup
+	errs := make([]error, len(managers))
+	for i, m := range managers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = edit(i, m)
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestConcurrentSavesKeepEachOthersChanges(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("JEAN_CREDENTIAL_STORE", CredentialStoreFile)
+	const instances = 8
+
+	// Edits to different repositories
+	saveConcurrently(t, newTestManagers(t, instances), func(i int, m *Manager) error {
+		return m.SetBaseBranch(fmt.Sprintf("/repo%d", i), fmt.Sprintf("base%d", i))
+	})
+	seed := newTestManagers(t, 1)[0]
+	for i := 0; i < instances; i++ {
+		if got, want := seed.GetBaseBranch(fmt.Sprintf("/repo%d", i)), fmt.Sprintf("base%d", i); got != want {
+			t.Errorf("Expected /repo%d to keep base branch %s, got %q", i, want, got)
+		}
+	}
+
+	// Deletions of different branches; a branch nobody touches is kept
+	for i := 0; i < instances; i++ {
+		if err := seed.AddPR("/repo0", fmt.Sprintf("branch%d", i), fmt.Sprintf("https://example.com/pr/%d", i), i, "PR", "jean"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := seed.AddPR("/repo0", "kept", "https://example.com/pr/kept", 100, "PR", "jean"); err != nil {
+		t.Fatal(err)
+	}
+	saveConcurrently(t, newTestManagers(t, instances), func(i int, m *Manager) error {
+		return m.CleanupBranch("/repo0", fmt.Sprintf("branch%d", i))
+	})
+	final := newTestManagers(t, 1)[0]
+	for i := 0; i < instances; i++ {
+		if prs := final.GetPRs("/repo0", fmt.Sprintf("branch%d", i)); len(prs) != 0 {
+			t.Errorf("Expected the PRs of branch%d to be deleted, got %v", i, prs)
+		}
+	}
+	if prs := final.GetPRs("/repo0", "kept"); len(prs) != 1 {
+		t.Errorf("Expected the untouched branch to keep its PR, got %v", prs)
+	}
+	if got := final.GetBaseBranch("/repo3"); got != "base3" {
+		t.Errorf("Expected other repositories to be untouched, got base branch %q", got)
+	}
+}
+
+func TestFirstSaveWithoutConfigFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("JEAN_CREDENTIAL_STORE", CredentialStoreFile)
+	managers := newTestManagers(t, 2)
+	if _, err := os.Stat(managers[0].configPath); !os.IsNotExist(err) {
+		t.Fatalf("Expected no config file before the first save, got %v", err)
+	}
+
+	if err := managers[0].SetBaseBranch("/repo", "main"); err != nil {
+		t.Fatal(err)
+	}
+	if got := managers[0].GetBaseBranch("/repo"); got != "main" {
+		t.Errorf("Expected the first save to keep the repository in memory, got base branch %q", got)
+	}
+	data, err := os.ReadFile(managers[0].configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var values map[string]any
+	if err := json.Unmarshal(data, &values); err != nil {
+		t.Fatal(err)
+	}
+	if version, _ := values["version"].(float64); int(version) != CurrentConfigVersion {
+		t.Errorf("Expected version %d to be written, got %v", CurrentConfigVersion, values["version"])
+	}
+
+	// The second instance also started without a file
+	if err := managers[1].SetBaseBranch("/other", "develop"); err != nil {
+		t.Fatal(err)
+	}
+	if got := managers[1].GetBaseBranch("/repo"); got != "main" {
+		t.Errorf("Expected the first instance's save to be picked up, got base branch %q", got)
+	}
+}
+
+func TestSaveFailsOnUnreadableConfig(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("JEAN_CREDENTIAL_STORE", CredentialStoreFile)
+	m := newTestManagers(t, 1)[0]
+	if err := m.SetBaseBranch("/repo", "main"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Another program left a broken file behind: don't replace it with this instance's view alone
+	if err := os.WriteFile(m.configPath, []byte("{broken"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.SetBaseBranch("/other", "develop"); err == nil {
+		t.Error("Expected saving over an unparseable config to fail")
+	}
+	if data, _ := os.ReadFile(m.configPath); string(data) != "{broken" {
+		t.Errorf("Expected the file to be left alone, got %q", data)
+	}
+}
@@ -0,0 +1,296 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ConflictVersions holds the three index stages of an unmerged file
+// A missing stage (e.g. file added on only one side) is left empty with the Has* flag false
+type ConflictVersions struct {
+	Base      string // Stage 1: common ancestor
+	Ours      string // Stage 2: current branch
+	Theirs    string // Stage 3: branch being merged in
+	HasBase   bool
+	HasOurs   bool
+	HasTheirs bool
+}
+
+// GetConflictedFiles returns the unmerged paths in a worktree
+func (m *Manager) GetConflictedFiles(worktreePath string) ([]string, error) {
+	cmd := exec.Command("git", "-C", worktreePath, "diff", "--name-only", "--diff-filter=U")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list conflicted files: %w", err)
+	}
+
+	var files []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
+
+// GetConflictVersions returns the base, ours and theirs contents of an unmerged file
+func (m *Manager) GetConflictVersions(worktreePath, filePath string) (ConflictVersions, error) {
+	var versions ConflictVersions
+
+	for stage, dst := range map[int]*string{1: &versions.Base, 2: &versions.Ours, 3: &versions.Theirs} {
+		cmd := exec.Command("git", "-C", worktreePath, "show", fmt.Sprintf(":%d:%s", stage, filePath))
+		output, err := cmd.Output()
+		if err != nil {
+			// Stage doesn't exist (added/deleted on one side only)
+			continue
+		}
+		*dst = string(output)
+		switch stage {
+		case 1:
+			versions.HasBase = true
+		case 2:
+			versions.HasOurs = true
+		case 3:
+			versions.HasTheirs = true
+		}
+	}
+
+	if !versions.HasBase && !versions.HasOurs && !versions.HasTheirs {
+		return versions, fmt.Errorf("'%s' is not an unmerged file", filePath)
+	}
+	return versions, nil
+}
+
+// ResolveWithOurs resolves a conflicted file by taking the current branch's version
+func (m *Manager) ResolveWithOurs(worktreePath, filePath string) error {
+	return m.resolveWithSide(worktreePath, filePath, "--ours", 2)
+}
+
+// ResolveWithTheirs resolves a conflicted file by taking the incoming branch's version
+func (m *Manager) ResolveWithTheirs(worktreePath, filePath string) error {
+	return m.resolveWithSide(worktreePath, filePath, "--theirs", 3)
+}
+
+// resolveWithSide checks out one side of a conflict and stages the result
+// If that side deleted the file, the deletion is staged instead
+func (m *Manager) resolveWithSide(worktreePath, filePath, sideFlag string, stage int) error {
+	// Check whether this side has the file at all
+	checkCmd := exec.Command("git", "-C", worktreePath, "cat-file", "-e", fmt.Sprintf(":%d:%s", stage, filePath))
+	if err := checkCmd.Run(); err != nil {
+		cmd := exec.Command("git", "-C", worktreePath, "rm", "--quiet", "--", filePath)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s: %s", filePath, string(output))
+		}
+		return nil
+	}
+
+	cmd := exec.Command("git", "-C", worktreePath, "checkout", sideFlag, "--", filePath)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %s", filePath, string(output))
+	}
+
+	return m.MarkResolved(worktreePath, filePath)
+}
+
+// MarkResolved stages a conflicted file as resolved (after editing it manually)
+func (m *Manager) MarkResolved(worktreePath, filePath string) error {
+	cmd := exec.Command("git", "-C", worktreePath, "add", "--", filePath)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to mark %s as resolved: %s", filePath, string(output))
+	}
+	return nil
+}
+
+// Operations that can stop and wait for conflicts to be resolved
+const (
+	OperationMerge      = "merge"
+	OperationRebase     = "rebase"
+	OperationCherryPick = "cherry-pick"
+)
+
+// GetInProgressOperation returns the operation waiting for conflict resolution in the worktree
+// Returns "" if no merge, rebase or cherry-pick is in progress
+func (m *Manager) GetInProgressOperation(worktreePath string) string {
+	if m.IsRebaseInProgress(worktreePath) {
+		return OperationRebase
+	}
+	if m.IsCherryPickInProgress(worktreePath) {
+		return OperationCherryPick
+	}
+	if m.IsMergeInProgress(worktreePath) {
+		return OperationMerge
+	}
+	return ""
+}
+
+// IsRebaseInProgress checks whether a rebase has stopped in the worktree
+func (m *Manager) IsRebaseInProgress(worktreePath string) bool {
+	for _, dir := range []string{"rebase-merge", "rebase-apply"} {
+		cmd := exec.Command("git", "-C", worktreePath, "rev-parse", "--git-path", dir)
+		output, err := cmd.Output()
+		if err != nil {
+			continue
+		}
+		path := strings.TrimSpace(string(output))
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(worktreePath, path)
+		}
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			return true
+		}
+	}
+	return false
+}
+
+// ContinueRebase continues a stopped rebase after conflicts have been resolved
+// Returns a "rebase conflict" error if the next commit stops on conflicts again
+func (m *Manager) ContinueRebase(worktreePath string) error {
+	files, err := m.GetConflictedFiles(worktreePath)
+	if err != nil {
+		return err
+	}
+	if len(files) > 0 {
+		return fmt.Errorf("%d file(s) still have conflicts", len(files))
+	}
+
+	// Keep the original commit messages instead of opening an editor
+	cmd := exec.Command("git", "-C", worktreePath, "-c", "core.editor=true", "rebase", "--continue")
+	return sequencerStepError(OperationRebase, "continue", cmd)
+}
+
+// SkipRebase drops the commit the rebase stopped on and moves on to the next one
+func (m *Manager) SkipRebase(worktreePath string) error {
+	cmd := exec.Command("git", "-C", worktreePath, "rebase", "--skip")
+	return sequencerStepError(OperationRebase, "skip", cmd)
+}
+
+// AbortRebase aborts a stopped rebase and restores the branch to its original state
+func (m *Manager) AbortRebase(worktreePath string) error {
+	cmd := exec.Command("git", "-C", worktreePath, "rebase", "--abort")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to abort rebase: %s", string(output))
+	}
+	return nil
+}
+
+// IsCherryPickInProgress checks whether a cherry-pick has stopped in the worktree
+func (m *Manager) IsCherryPickInProgress(worktreePath string) bool {
+	cmd := exec.Command("git", "-C", worktreePath, "rev-parse", "-q", "--verify", "CHERRY_PICK_HEAD")
+	return cmd.Run() == nil
+}
+
+// ContinueCherryPick commits the resolved commit and picks the remaining ones
+// Returns a "cherry-pick conflict" error if the next commit stops on conflicts again
+func (m *Manager) ContinueCherryPick(worktreePath string) error {
+	files, err := m.GetConflictedFiles(worktreePath)
+	if err != nil {
+		return err
+	}
+	if len(files) > 0 {
+		return fmt.Errorf("%d file(s) still have conflicts", len(files))
+	}
+
+	cmd := exec.Command("git", "-C", worktreePath, "-c", "core.editor=true", "cherry-pick", "--continue")
+	return sequencerStepError(OperationCherryPick, "continue", cmd)
+}
+
+// SkipCherryPick drops the commit the cherry-pick stopped on and picks the remaining ones
+func (m *Manager) SkipCherryPick(worktreePath string) error {
+	cmd := exec.Command("git", "-C", worktreePath, "cherry-pick", "--skip")
+	return sequencerStepError(OperationCherryPick, "skip", cmd)
+}
+
+// AbortCherryPick aborts a stopped cherry-pick and restores the branch to its original state
+func (m *Manager) AbortCherryPick(worktreePath string) error {
+	cmd := exec.Command("git", "-C", worktreePath, "cherry-pick", "--abort")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to abort cherry-pick: %s", string(output))
+	}
+	return nil
+}
+
+// sequencerStepError runs a rebase or cherry-pick --continue/--skip command and classifies its failure
+func sequencerStepError(operation, step string, cmd *exec.Cmd) error {
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		outputStr := string(output)
+		if strings.Contains(outputStr, "CONFLICT") || strings.Contains(outputStr, "could not apply") {
+			return fmt.Errorf("%s conflict occurred. Use 'git %s --abort' to abort the %s", operation, operation, operation)
+		}
+		return fmt.Errorf("failed to %s %s: %s", step, operation, outputStr)
+	}
+	return nil
+}
+
+// IsMergeInProgress checks whether a merge is waiting to be concluded in the worktree
+func (m *Manager) IsMergeInProgress(worktreePath string) bool {
+	cmd := exec.Command("git", "-C", worktreePath, "rev-parse", "-q", "--verify", "MERGE_HEAD")
+	return cmd.Run() == nil
+}
+
+// mergeMsgBranchRegex matches the branch named in the message git prepares for a merge ("Merge branch 'x' into y")
+var mergeMsgBranchRegex = regexp.MustCompile(`^Merge branch '([^']+)'`)
+
+// MergingBranch returns the local branch being merged by the merge in progress, "" if it can't be told
+// The branch is the one whose tip is MERGE_HEAD; the prepared merge message settles ties
+func (m *Manager) MergingBranch(worktreePath string) string {
+	var candidates []string
+	cmd := exec.Command("git", "-C", worktreePath, "for-each-ref", "--points-at", "MERGE_HEAD", "--format=%(refname:short)", "refs/heads")
+	if output, err := cmd.Output(); err == nil {
+		candidates = strings.Fields(string(output))
+	}
+	if len(candidates) == 1 {
+		return candidates[0]
+	}
+
+	cmd = exec.Command("git", "-C", worktreePath, "rev-parse", "--git-path", "MERGE_MSG")
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	msgPath := strings.TrimSpace(string(output))
+	if !filepath.IsAbs(msgPath) {
+		msgPath = filepath.Join(worktreePath, msgPath)
+	}
+	data, err := os.ReadFile(msgPath)
+	if err != nil {
+		return ""
+	}
+	match := mergeMsgBranchRegex.FindSubmatch(data)
+	if match == nil {
+		return ""
+	}
+	branch := string(match[1])
+	if len(candidates) > 0 && !slices.Contains(candidates, branch) {
+		return ""
+	}
+	return branch
+}
+
+// CommitMerge concludes a merge once all conflicts are resolved, using the prepared merge message
+func (m *Manager) CommitMerge(worktreePath string) error {
+	files, err := m.GetConflictedFiles(worktreePath)
+	if err != nil {
+		return err
+	}
+	if len(files) > 0 {
+		return fmt.Errorf("%d file(s) still have conflicts", len(files))
+	}
+
+	cmd := exec.Command("git", "-C", worktreePath, "commit", "--no-edit")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to commit merge: %s", string(output))
+	}
+	return nil
+}
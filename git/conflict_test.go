@@ -0,0 +1,50 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// startConflictingMerge leaves main in repo in the middle of a merge of branch that conflicts on README.md
+func startConflictingMerge(t *testing.T, repo, branch string) {
+	t.Helper()
+	runGit(t, repo, "checkout", "-q", "-b", branch)
+	if err := os.WriteFile(filepath.Join(repo, "README.md"), []byte(branch+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, repo, "commit", "-q", "-am", branch)
+	runGit(t, repo, "checkout", "-q", "main")
+	if err := os.WriteFile(filepath.Join(repo, "README.md"), []byte("main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, repo, "commit", "-q", "-am", "main")
+	if err := gitCommand(t, repo, "merge", branch, "--no-edit").Run(); err == nil {
+		t.Fatal("Expected the merge to stop on conflicts")
+	}
+}
+
+func TestMergingBranch(t *testing.T) {
+	repo := newTestRepo(t, nil)
+	startConflictingMerge(t, repo, "feature")
+	runGit(t, repo, "branch", "other")
+
+	m := NewManager(repo)
+	if !m.IsMergeInProgress(repo) {
+		t.Fatal("Expected a merge in progress")
+	}
+	if got := m.MergingBranch(repo); got != "feature" {
+		t.Errorf("Expected feature to be the branch being merged, got %q", got)
+	}
+
+	// Two branches at MERGE_HEAD: the merge message tells them apart
+	runGit(t, repo, "branch", "feature-copy", "feature")
+	if got := m.MergingBranch(repo); got != "feature" {
+		t.Errorf("Expected the merge message to pick feature, got %q", got)
+	}
+
+	runGit(t, repo, "merge", "--abort")
+	if got := m.MergingBranch(repo); got != "" {
+		t.Errorf("Expected no branch without a merge in progress, got %q", got)
+	}
+}
@@ -0,0 +1,355 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/coollabsio/jean-tui/config"
+)
+
+// copyLocalFiles copies the jean.json copyPaths (by default .claude and .husky) from the base repo to a worktree
+// This enables sharing files like .claude/settings.local.json or .env that are not tracked by git.
+// Entries that match nothing are skipped; a failing entry doesn't stop the others.
+func (m *Manager) copyLocalFiles(repoRoot, workspacePath string, scriptConfig *config.ScriptConfig) error {
+	excludes := scriptConfig.GetCopyExcludes()
+	worktreeDirs := m.worktreeDirs(repoRoot)
+	var errs []error
+	for _, entry := range scriptConfig.GetCopyPaths() {
+		mode, err := entry.GetMode()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		matches, err := m.matchCopyPath(repoRoot, entry, worktreeDirs)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to find %s: %w", entry.Path, err))
+			continue
+		}
+
+		entryExcludes := append(append([]string{}, excludes...), entry.Exclude...)
+		for _, rel := range matches {
+			if isExcluded(rel, entryExcludes) {
+				continue
+			}
+			if err := m.copyPath(repoRoot, workspacePath, rel, mode, entryExcludes); err != nil {
+				errs = append(errs, fmt.Errorf("failed to %s %s: %w", mode, rel, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// worktreeDirs returns the worktree directory (.workspaces by default) and the registered worktrees
+// inside the repository, relative to repoRoot; copyPaths never copy them
+func (m *Manager) worktreeDirs(repoRoot string) []string {
+	var dirs []string
+	if dir, err := m.GetWorkspacesDir(); err == nil {
+		dirs = append(dirs, dir)
+	}
+	if worktrees, err := m.ListLightweight(); err == nil {
+		for _, wt := range worktrees {
+			dirs = append(dirs, wt.Path)
+		}
+	}
+
+	var rels []string
+	for _, dir := range dirs {
+		if rel, err := filepath.Rel(repoRoot, dir); err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+			rels = append(rels, filepath.ToSlash(rel))
+		}
+	}
+	return rels
+}
+
+// overlapsWorktreeDir reports whether rel is, contains or is inside one of the worktree directories
+func overlapsWorktreeDir(rel string, worktreeDirs []string) bool {
+	for _, dir := range worktreeDirs {
+		if rel == dir || strings.HasPrefix(dir, rel+"/") || strings.HasPrefix(rel, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// matchCopyPath returns the paths (relative to repoRoot) a copyPaths entry refers to
+// Paths that are or contain worktrees (see worktreeDirs) are left out
+func (m *Manager) matchCopyPath(repoRoot string, entry config.CopyPath, worktreeDirs []string) ([]string, error) {
+	pattern := path.Clean(filepath.ToSlash(entry.Path))
+	if path.IsAbs(pattern) || filepath.IsAbs(entry.Path) || pattern == "." || pattern == ".." || strings.HasPrefix(pattern, "../") {
+		return nil, fmt.Errorf("%s is not a path inside the repository", entry.Path)
+	}
+
+	if entry.Gitignored {
+		ignored, err := m.listIgnored(repoRoot)
+		if err != nil {
+			return nil, err
+		}
+		var matches []string
+		for _, rel := range ignored {
+			if matchGlob(pattern, rel) && !overlapsWorktreeDir(rel, worktreeDirs) && !isNestedRepo(filepath.Join(repoRoot, rel)) {
+				matches = append(matches, rel)
+			}
+		}
+		return matches, nil
+	}
+
+	if !strings.ContainsAny(pattern, "*?[") {
+		if overlapsWorktreeDir(pattern, worktreeDirs) {
+			return nil, nil
+		}
+		if _, err := os.Lstat(filepath.Join(repoRoot, pattern)); err != nil {
+			if os.IsNotExist(err) {
+				return nil, nil // Source doesn't exist, skip silently
+			}
+			return nil, err
+		}
+		return []string{pattern}, nil
+	}
+
+	// Walk from the part of the pattern without wildcards, never into .git or worktrees and their directory
+	segments := strings.Split(pattern, "/")
+	static := 0
+	for static < len(segments) && !strings.ContainsAny(segments[static], "*?[") {
+		static++
+	}
+	start := filepath.Join(repoRoot, filepath.FromSlash(strings.Join(segments[:static], "/")))
+	var matches []string
+	err := filepath.WalkDir(start, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		rel, err := filepath.Rel(repoRoot, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == "." {
+			return nil
+		}
+		if d.IsDir() && (d.Name() == ".git" || isNestedRepo(p) || overlapsWorktreeDir(rel, worktreeDirs)) {
+			return filepath.SkipDir
+		}
+		depth := strings.Count(rel, "/") + 1
+		if matchSegments(segments, strings.Split(rel, "/")) {
+			matches = append(matches, rel)
+			if d.IsDir() {
+				return filepath.SkipDir // Copied as a whole
+			}
+		} else if d.IsDir() && depth >= len(segments) && !strings.Contains(pattern, "**") {
+			return filepath.SkipDir // Too deep to match
+		}
+		return nil
+	})
+	return matches, err
+}
+
+// isNestedRepo reports whether a directory is a repository or worktree of its own (e.g. .workspaces/<branch>)
+func isNestedRepo(dir string) bool {
+	_, err := os.Lstat(filepath.Join(dir, ".git"))
+	return err == nil
+}
+
+// listIgnored lists the gitignored files of the main repository; ignored directories are listed
+// once (e.g. "node_modules") instead of file by file
+func (m *Manager) listIgnored(repoRoot string) ([]string, error) {
+	cmd := exec.Command("git", "-C", repoRoot, "ls-files", "--others", "--ignored", "--exclude-standard", "--directory", "-z")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list gitignored files: %w", err)
+	}
+	var paths []string
+	for _, p := range strings.Split(string(output), "\x00") {
+		if p = strings.TrimSuffix(p, "/"); p != "" {
+			paths = append(paths, p)
+		}
+	}
+	return paths, nil
+}
+
+// matchGlob matches a slash-separated path against a glob where "**" matches any number of directories
+// A pattern without a slash matches the last path element at any depth, like in .gitignore ("*.log", ".env*");
+// used for exclusions and gitignored files, while copied paths are matched from the repository root
+func matchGlob(pattern, name string) bool {
+	if !strings.Contains(pattern, "/") {
+		pattern = "**/" + pattern
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+// matchSegments matches path elements against glob elements
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], name[0]); err != nil || !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// isExcluded reports whether a path matches one of the exclusion globs
+func isExcluded(rel string, excludes []string) bool {
+	for _, exclude := range excludes {
+		if matchGlob(path.Clean(filepath.ToSlash(exclude)), rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// copyPath brings a file or directory from the base repo into a worktree using one of the copy modes
+func (m *Manager) copyPath(repoRoot, workspacePath, rel, mode string, excludes []string) error {
+	src := filepath.Join(repoRoot, filepath.FromSlash(rel))
+	dst := filepath.Join(workspacePath, filepath.FromSlash(rel))
+
+	switch mode {
+	case config.CopyModeSymlink:
+		return linkPath(src, dst)
+	case config.CopyModeHardlink:
+		return m.copyTree(src, dst, rel, excludes, m.hardlinkFile)
+	case config.CopyModeReflink:
+		if _, err := os.Lstat(dst); os.IsNotExist(err) {
+			if err := reflinkTree(src, dst); err == nil {
+				return removeExcluded(dst, rel, excludes)
+			}
+			_ = os.RemoveAll(dst) // Unsupported here, copy instead
+		}
+	}
+	return m.copyTree(src, dst, rel, excludes, m.copyFile)
+}
+
+// copyTree copies a file or directory with copyOne for every file, skipping excluded paths
+// Symlinks inside the tree are recreated rather than followed
+func (m *Manager) copyTree(src, dst, rel string, excludes []string, copyOne func(src, dst string) error) error {
+	if resolved, err := filepath.EvalSymlinks(src); err == nil {
+		src = resolved
+	}
+	return filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		sub, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, sub)
+		if sub != "." && isExcluded(path.Join(rel, filepath.ToSlash(sub)), excludes) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		switch {
+		case d.IsDir():
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			return os.MkdirAll(target, info.Mode().Perm())
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(p)
+			if err != nil {
+				return err
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			_ = os.Remove(target)
+			return os.Symlink(link, target)
+		default:
+			return copyOne(p, target)
+		}
+	})
+}
+
+// linkPath symlinks dst to src, replacing an existing symlink
+func linkPath(src, dst string) error {
+	if info, err := os.Lstat(dst); err == nil {
+		if info.Mode()&fs.ModeSymlink == 0 {
+			return fmt.Errorf("%s already exists in the worktree", filepath.Base(dst))
+		}
+		if err := os.Remove(dst); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	return os.Symlink(src, dst)
+}
+
+// hardlinkFile hardlinks dst to src, copying when that isn't possible (e.g. across filesystems)
+func (m *Manager) hardlinkFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	_ = os.Remove(dst)
+	if err := os.Link(src, dst); err == nil {
+		return nil
+	}
+	return m.copyFile(src, dst)
+}
+
+// reflinkTree clones a file or directory copy-on-write with cp
+// On Linux cp falls back to a regular copy by itself; on macOS it fails where cloning isn't supported
+func reflinkTree(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	args := []string{"-R", "-P", "--reflink=auto", src, dst}
+	if runtime.GOOS == "darwin" {
+		args = []string{"-R", "-P", "-c", src, dst}
+	}
+	cmd := exec.Command("cp", args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("cp failed: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// removeExcluded deletes the excluded paths from a cloned tree
+func removeExcluded(dst, rel string, excludes []string) error {
+	if len(excludes) == 0 {
+		return nil
+	}
+	return filepath.WalkDir(dst, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		sub, err := filepath.Rel(dst, p)
+		if err != nil || sub == "." {
+			return err
+		}
+		if isExcluded(path.Join(rel, filepath.ToSlash(sub)), excludes) {
+			if err := os.RemoveAll(p); err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+		}
+		return nil
+	})
+}
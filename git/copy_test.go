@@ -0,0 +1,138 @@
+package git
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/coollabsio/jean-tui/config"
+)
+
+func TestMatchSegments(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{".env", ".env", true},
+		{".env", "app/.env", false},
+		{".env*", ".env.local", true},
+		{"config/*.json", "config/app.json", true},
+		{"config/*.json", "config/sub/app.json", false},
+		{"**/*.json", "app.json", true},
+		{"**/*.json", "a/b/c/app.json", true},
+		{"apps/**/.env", "apps/.env", true},
+		{"apps/**/.env", "apps/web/api/.env", true},
+		{"apps/**/.env", "libs/web/.env", false},
+		{"apps/**", "apps/web/.env", true},
+		{"apps/*", "apps", false},
+		{"[ab].txt", "b.txt", true},
+		{"[ab].txt", "c.txt", false},
+		{"[", "[", false}, // Malformed patterns match nothing
+	}
+	for _, tt := range tests {
+		if got := matchSegments(strings.Split(tt.pattern, "/"), strings.Split(tt.name, "/")); got != tt.want {
+			t.Errorf("matchSegments(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		// Without a slash the last element is matched at any depth, like .gitignore
+		{"*.log", "debug.log", true},
+		{"*.log", "logs/app/debug.log", true},
+		{".env*", "apps/web/.env.local", true},
+		{"node_modules", "apps/web/node_modules", true},
+		// With a slash the pattern is anchored at the root
+		{"logs/*.log", "logs/debug.log", true},
+		{"logs/*.log", "apps/logs/debug.log", false},
+		{"**/cache", "a/b/cache", true},
+	}
+	for _, tt := range tests {
+		if got := matchGlob(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIsExcluded(t *testing.T) {
+	excludes := []string{"*.log", "node_modules", "./.claude/cache/", "dist/**"}
+	tests := []struct {
+		rel  string
+		want bool
+	}{
+		{"debug.log", true},
+		{".claude/logs/run.log", true},
+		{"apps/web/node_modules", true},
+		{".claude/cache", true},
+		{".claude/cache.json", false},
+		{".claude/settings.local.json", false},
+		{"dist/app.js", true},
+		{"src/dist/app.js", false},
+	}
+	for _, tt := range tests {
+		if got := isExcluded(tt.rel, excludes); got != tt.want {
+			t.Errorf("isExcluded(%q) = %v, want %v", tt.rel, got, tt.want)
+		}
+	}
+	if isExcluded("debug.log", nil) {
+		t.Error("Expected nothing to be excluded without exclusions")
+	}
+}
+
+func TestMatchCopyPath(t *testing.T) {
+	repo := newTestRepo(t, map[string]string{
+		".gitignore": ".env*\n.workspaces/\nnode_modules/\n",
+		"README.md":  "hello\n",
+	})
+	writeFiles(t, repo, map[string]string{
+		".env":                      "SECRET=1\n",
+		".env.local":                "SECRET=2\n",
+		".claude/settings.json":     "{}\n",
+		"node_modules/pkg/index.js": "\n",
+		"apps/web/.env":             "WEB=1\n",
+	})
+	m := NewManager(repo)
+	runGit(t, repo, "worktree", "add", "-q", "-b", "feature", filepath.Join(repo, ".workspaces", "feature"))
+	writeFiles(t, repo, map[string]string{".workspaces/feature/.env": "OTHER=1\n"})
+	worktreeDirs := m.worktreeDirs(repo)
+
+	tests := []struct {
+		name    string
+		entry   config.CopyPath
+		want    []string
+		wantErr bool
+	}{
+		{"literal", config.CopyPath{Path: ".claude"}, []string{".claude"}, false},
+		{"missing", config.CopyPath{Path: ".husky"}, nil, false},
+		{"dotfiles skip the worktrees", config.CopyPath{Path: ".*"}, []string{".claude", ".env", ".env.local", ".gitignore"}, false},
+		{"recursive", config.CopyPath{Path: "**/.env"}, []string{".env", "apps/web/.env"}, false},
+		{"worktree directory", config.CopyPath{Path: ".workspaces"}, nil, false},
+		{"inside a worktree", config.CopyPath{Path: ".workspaces/*/.env"}, nil, false},
+		{"gitignored", config.CopyPath{Path: ".env*", Gitignored: true}, []string{".env", ".env.local", "apps/web/.env"}, false},
+		{"gitignored dotfiles skip the worktrees", config.CopyPath{Path: ".*", Gitignored: true}, []string{".env", ".env.local", "apps/web/.env"}, false},
+		{"gitignored directory", config.CopyPath{Path: "node_modules", Gitignored: true}, []string{"node_modules"}, false},
+		{"parent", config.CopyPath{Path: "../secrets"}, nil, true},
+		{"parent after cleaning", config.CopyPath{Path: "apps/../../secrets"}, nil, true},
+		{"absolute", config.CopyPath{Path: "/etc/passwd"}, nil, true},
+		{"repository root", config.CopyPath{Path: "."}, nil, true},
+	}
+	for _, tt := range tests {
+		got, err := m.matchCopyPath(repo, tt.entry, worktreeDirs)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: matchCopyPath(%q) error = %v, want error %v", tt.name, tt.entry.Path, err, tt.wantErr)
+			continue
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: matchCopyPath(%q) = %v, want %v", tt.name, tt.entry.Path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Diff modes for GetFileDiffs
+const (
+	DiffUnstaged = "unstaged" // Working tree vs index (including untracked files)
+	DiffStaged   = "staged"   // Index vs HEAD
+	DiffBase     = "base"     // Working tree vs the merge base with the base branch
+)
+
+// GetFileDiffs returns the per-file diffs of a worktree for the given mode
+// baseBranch is only used by DiffBase
+func (m *Manager) GetFileDiffs(worktreePath, mode, baseBranch string) ([]*FileDiff, error) {
+	args := []string{"-C", worktreePath, "diff", "--no-color", "--no-ext-diff", "-M"}
+	switch mode {
+	case DiffStaged:
+		args = append(args, "--cached")
+	case DiffBase:
+		if baseBranch == "" {
+			return nil, fmt.Errorf("base branch not specified")
+		}
+		// Diff against the fork point so changes made on base since then don't show up
+		cmd := exec.Command("git", "-C", worktreePath, "merge-base", baseBranch, "HEAD")
+		output, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to find merge base with '%s': %w", baseBranch, err)
+		}
+		args = append(args, strings.TrimSpace(string(output)))
+	}
+
+	cmd := exec.Command("git", args...)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get diff: %w", err)
+	}
+	files := ParseDiff(string(output))
+
+	// Untracked files aren't part of `git diff`, but they're changes worth reviewing too
+	if mode != DiffStaged {
+		cmd = exec.Command("git", "-C", worktreePath, "ls-files", "--others", "--exclude-standard", "-z")
+		output, err = cmd.Output()
+		if err != nil {
+			return files, nil
+		}
+		for _, path := range strings.Split(string(output), "\x00") {
+			if path == "" {
+				continue
+			}
+			if diff, err := m.GetFileDiff(worktreePath, path, false); err == nil {
+				files = append(files, diff)
+			}
+		}
+	}
+
+	return files, nil
+}
+
+// ParseDiff splits the unified diff output of several files into per-file diffs
+func ParseDiff(output string) []*FileDiff {
+	var files []*FileDiff
+	var current []string
+
+	flush := func() {
+		if len(current) == 0 {
+			return
+		}
+		diff := ParseFileDiff(strings.Join(current, "\n"))
+		diff.Path = diffPath(diff.Header)
+		files = append(files, diff)
+		current = nil
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "diff --git ") {
+			flush()
+		}
+		if len(current) == 0 && !strings.HasPrefix(line, "diff --git ") {
+			continue // Skip anything before the first file
+		}
+		current = append(current, line)
+	}
+	flush()
+
+	return files
+}
+
+// diffPath extracts the (new) file path from a file diff header
+func diffPath(header []string) string {
+	for _, line := range header {
+		if strings.HasPrefix(line, "+++ b/") {
+			return strings.TrimPrefix(line, "+++ b/")
+		}
+	}
+	for _, line := range header {
+		switch {
+		case strings.HasPrefix(line, "rename to "):
+			return strings.TrimPrefix(line, "rename to ")
+		case strings.HasPrefix(line, "--- a/"):
+			return strings.TrimPrefix(line, "--- a/")
+		}
+	}
+	// Fall back to "diff --git a/<path> b/<path>" (mode-only or binary changes)
+	if len(header) > 0 {
+		if i := strings.LastIndex(header[0], " b/"); i >= 0 {
+			return header[0][i+len(" b/"):]
+		}
+	}
+	return ""
+}
+
+// Stats returns the number of added and removed lines in the diff
+func (d *FileDiff) Stats() (int, int) {
+	added, removed := 0, 0
+	for _, hunk := range d.Hunks {
+		for _, line := range hunk.Lines {
+			switch {
+			case strings.HasPrefix(line, "+"):
+				added++
+			case strings.HasPrefix(line, "-"):
+				removed++
+			}
+		}
+	}
+	return added, removed
+}
+
+// Status returns a one-letter change type: A (added), D (deleted), R (renamed) or M (modified)
+func (d *FileDiff) Status() string {
+	switch {
+	case d.IsNew:
+		return "A"
+	case d.IsDeleted:
+		return "D"
+	}
+	for _, line := range d.Header {
+		if strings.HasPrefix(line, "rename from ") {
+			return "R"
+		}
+	}
+	return "M"
+}
@@ -0,0 +1,112 @@
+package git
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/coollabsio/jean-tui/config"
+)
+
+// SetPortAllocator sets how the port block of a branch is looked up (and reserved on first use)
+// Without an allocator scripts don't get JEAN_PORT variables
+func (m *Manager) SetPortAllocator(allocate func(branch string) (int, error)) {
+	m.portAllocator = allocate
+}
+
+// PortBlockFree reports whether nothing listens on the ports of the block starting at first
+func PortBlockFree(first int) bool {
+	for port := first; port < first+config.PortBlockSize; port++ {
+		listener, err := net.Listen("tcp", "127.0.0.1:"+strconv.Itoa(port))
+		if err != nil {
+			return false
+		}
+		listener.Close()
+	}
+	return true
+}
+
+// portEnv returns JEAN_PORT, JEAN_PORT_1, ... for the ports jean.json reserves per worktree
+func (m *Manager) portEnv(root, branch string) []string {
+	if m.portAllocator == nil || branch == "" {
+		return nil
+	}
+	scriptConfig, err := config.LoadScripts(root)
+	if err != nil || scriptConfig.GetPorts() == 0 {
+		return nil
+	}
+	first, err := m.portAllocator(branch)
+	if err != nil || first == 0 {
+		return nil
+	}
+
+	env := []string{"JEAN_PORT=" + strconv.Itoa(first)}
+	for i := 1; i < scriptConfig.GetPorts(); i++ {
+		env = append(env, fmt.Sprintf("JEAN_PORT_%d=%d", i, first+i))
+	}
+	return env
+}
+
+// EnvTemplateOutput returns the file an env template is rendered to, e.g. ".env.jean.tmpl" -> ".env"
+func EnvTemplateOutput(template string) (string, error) {
+	if !strings.HasSuffix(template, ".tmpl") {
+		return "", fmt.Errorf("env template %s must end in .tmpl", template)
+	}
+	output := strings.TrimSuffix(strings.TrimSuffix(template, ".tmpl"), ".jean")
+	if filepath.Base(output) == "" || filepath.Base(output) == "." {
+		return "", fmt.Errorf("env template %s has no output name", template)
+	}
+	return output, nil
+}
+
+// RenderEnvTemplates renders the jean.json envTemplates into a worktree, replacing {{JEAN_*}}
+// placeholders with the values scripts get (JEAN_BRANCH, JEAN_PORT, ...). Templates are read from
+// the worktree, or the repository root if the worktree doesn't have them. Returns the files written.
+func (m *Manager) RenderEnvTemplates(worktreePath, branch string) ([]string, error) {
+	root, err := m.GetRepoRoot()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repo root: %w", err)
+	}
+	scriptConfig, err := config.LoadScripts(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load jean.json: %w", err)
+	}
+	if len(scriptConfig.EnvTemplates) == 0 {
+		return nil, nil
+	}
+
+	var pairs []string
+	for _, env := range m.ScriptEnv(worktreePath, branch) {
+		key, value, _ := strings.Cut(env, "=")
+		pairs = append(pairs, "{{"+key+"}}", value)
+	}
+	replacer := strings.NewReplacer(pairs...)
+
+	var written []string
+	for _, template := range scriptConfig.EnvTemplates {
+		output, err := EnvTemplateOutput(template)
+		if err != nil {
+			return written, err
+		}
+		data, err := os.ReadFile(filepath.Join(worktreePath, template))
+		if os.IsNotExist(err) {
+			data, err = os.ReadFile(filepath.Join(root, template))
+		}
+		if err != nil {
+			return written, fmt.Errorf("failed to read env template %s: %w", template, err)
+		}
+
+		outputPath := filepath.Join(worktreePath, output)
+		if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+			return written, fmt.Errorf("failed to write %s: %w", output, err)
+		}
+		if err := os.WriteFile(outputPath, []byte(replacer.Replace(string(data))), 0644); err != nil {
+			return written, fmt.Errorf("failed to write %s: %w", output, err)
+		}
+		written = append(written, output)
+	}
+	return written, nil
+}
@@ -0,0 +1,117 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/coollabsio/jean-tui/config"
+)
+
+// Lifecycle hooks are jean.json scripts named after the event they run on
+const (
+	HookTeardown   = "teardown"    // Before a worktree is deleted
+	HookPostSwitch = "post-switch" // After switching to a worktree, before the shell or Claude starts
+	HookPreCommit  = "pre-commit"  // Before committing; failing aborts the commit
+	HookPrePush    = "pre-push"    // Before pushing; failing aborts the push
+	HookPrePR      = "pre-pr"      // Before opening a PR; failing aborts it
+	HookPostMerge  = "post-merge"  // After a local merge into the base branch
+)
+
+// Hooks lists every lifecycle hook
+var Hooks = []string{HookTeardown, HookPostSwitch, HookPreCommit, HookPrePush, HookPrePR, HookPostMerge}
+
+// IsHook reports whether a jean.json script name is a lifecycle hook
+func IsHook(name string) bool {
+	for _, hook := range Hooks {
+		if name == hook {
+			return true
+		}
+	}
+	return false
+}
+
+// hookOutputLines is how many lines of a failed hook's output are included in its error
+const hookOutputLines = 5
+
+// HookError is returned when a lifecycle hook exits with an error
+type HookError struct {
+	Hook    string
+	Output  string // Combined stdout and stderr of the hook
+	LogPath string // Log the full output was written to ("" if it couldn't be written)
+	Err     error
+}
+
+func (e *HookError) Error() string {
+	msg := fmt.Sprintf("%s hook failed: %v", e.Hook, e.Err)
+	lines := strings.Split(strings.TrimSpace(e.Output), "\n")
+	if len(lines) > hookOutputLines {
+		lines = lines[len(lines)-hookOutputLines:]
+	}
+	if tail := strings.Join(lines, "\n"); tail != "" {
+		msg += "\n" + tail
+	}
+	return msg
+}
+
+func (e *HookError) Unwrap() error {
+	return e.Err
+}
+
+// RunHook runs a lifecycle hook in a worktree with the JEAN_* variables, JEAN_HOOK and the event's vars
+// Output is also written to the hook's log (see ScriptLogPath). Does nothing if jean.json doesn't define the hook.
+func (m *Manager) RunHook(hook, worktreePath string, vars map[string]string) error {
+	root, err := m.GetRepoRoot()
+	if err != nil {
+		return fmt.Errorf("failed to get repo root: %w", err)
+	}
+	scriptConfig, err := config.LoadScripts(root)
+	if err != nil {
+		return fmt.Errorf("failed to load jean.json: %w", err)
+	}
+	script := scriptConfig.GetScript(hook)
+	if script == "" {
+		return nil
+	}
+
+	branch, _ := m.GetCurrentBranchForWorktree(worktreePath)
+	env := append(os.Environ(), m.ScriptEnv(worktreePath, branch)...)
+	env = append(env, "JEAN_HOOK="+hook)
+	keys := make([]string, 0, len(vars))
+	for key := range vars {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		env = append(env, key+"="+vars[key])
+	}
+
+	cmd := exec.Command("sh", "-c", script)
+	cmd.Dir = worktreePath
+	cmd.Env = env
+	output, runErr := cmd.CombinedOutput()
+
+	// Keep the output around for the log viewer (best effort)
+	// The teardown log goes to the main worktree: the worktree's own git directory is removed right after
+	logWorktree, logName := worktreePath, hook
+	if hook == HookTeardown {
+		logWorktree, logName = root, hook+"-"+branch
+	}
+	logPath, err := m.ScriptLogPath(logWorktree, logName)
+	if err == nil {
+		if err = os.MkdirAll(filepath.Dir(logPath), 0755); err == nil {
+			err = os.WriteFile(logPath, output, 0644)
+		}
+	}
+	if err != nil {
+		logPath = ""
+	}
+
+	if runErr != nil {
+		return &HookError{Hook: hook, Output: string(output), LogPath: logPath, Err: runErr}
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHookErrorShowsOutputTail(t *testing.T) {
+	output := "line 1\nline 2\nline 3\nline 4\nline 5\nline 6\n"
+	var err error = &HookError{Hook: HookPreCommit, Output: output, Err: errors.New("exit status 1")}
+
+	msg := err.Error()
+	if !strings.HasPrefix(msg, "pre-commit hook failed: exit status 1\n") {
+		t.Errorf("Expected hook name and exit status first, got %q", msg)
+	}
+	if strings.Contains(msg, "line 1") || !strings.HasSuffix(msg, "line 2\nline 3\nline 4\nline 5\nline 6") {
+		t.Errorf("Expected the last 5 output lines, got %q", msg)
+	}
+
+	var hookErr *HookError
+	if !errors.As(fmt.Errorf("failed to push: %w", err), &hookErr) || hookErr.Hook != HookPreCommit {
+		t.Error("Expected HookError to survive wrapping")
+	}
+	if !IsHook("post-merge") || IsHook("dev") {
+		t.Error("Expected only lifecycle hook names to count as hooks")
+	}
+}
+
+func TestTeardownLogOutlivesWorktree(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	repo := newTestRepo(t, map[string]string{"jean.json": `{"scripts": {"teardown": "echo stopping; exit 1"}}`})
+	feature := filepath.Join(t.TempDir(), "feature")
+	runGit(t, repo, "worktree", "add", "-q", "-b", "feature", feature)
+
+	m := NewManager(repo)
+	var hookErr *HookError
+	if err := m.RunHook(HookTeardown, feature, nil); !errors.As(err, &hookErr) {
+		t.Fatalf("Expected a HookError, got %v", err)
+	}
+	runGit(t, repo, "worktree", "remove", "--force", feature)
+
+	if want := filepath.Join(repo, ".git", "jean", "logs", "teardown-feature.log"); hookErr.LogPath != want {
+		t.Errorf("Expected the teardown log in the main repository at %s, got %q", want, hookErr.LogPath)
+	}
+	if log, err := os.ReadFile(hookErr.LogPath); err != nil || string(log) != "stopping\n" {
+		t.Errorf("Expected the teardown output to outlive the worktree, got %q (%v)", log, err)
+	}
+}
+
+// TestTeardownToldWhetherBranchIsDeleted tests that JEAN_DELETE_BRANCH matches what Remove does with the branch
+func TestTeardownToldWhetherBranchIsDeleted(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	out := filepath.Join(t.TempDir(), "delete-branch")
+	t.Setenv("OUT", out)
+	teardown := `{"scripts": {"teardown": "echo $JEAN_DELETE_BRANCH > \"$OUT\""}%s}`
+	repo := newTestRepo(t, map[string]string{"jean.json": fmt.Sprintf(teardown, "")})
+	m := NewManager(repo)
+
+	// Nothing of its own: deleted under the default "safe" policy
+	merged := filepath.Join(t.TempDir(), "merged")
+	runGit(t, repo, "worktree", "add", "-q", "-b", "merged", merged)
+	unpushed := filepath.Join(t.TempDir(), "unpushed")
+	runGit(t, repo, "worktree", "add", "-q", "-b", "unpushed", unpushed)
+	runGit(t, unpushed, "commit", "-q", "--allow-empty", "-m", "only here")
+
+	tests := []struct {
+		name           string
+		branch         string
+		policy         string
+		deleteUnpushed bool
+		want           bool
+	}{
+		{"merged", "merged", "", false, true},
+		{"unpushed commits", "unpushed", "", false, false},
+		{"unpushed commits confirmed", "unpushed", "", true, true},
+		{"protected", "main", "", true, false},
+		{"force policy", "unpushed", `, "deletionPolicy": "force"`, false, true},
+	}
+	for _, tt := range tests {
+		if err := os.WriteFile(filepath.Join(repo, "jean.json"), []byte(fmt.Sprintf(teardown, tt.policy)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		willDelete := m.WillDeleteBranch(tt.branch, "main", tt.deleteUnpushed)
+		if willDelete != tt.want {
+			t.Errorf("%s: WillDeleteBranch = %v, want %v", tt.name, willDelete, tt.want)
+		}
+		if err := m.RunHook(HookTeardown, repo, map[string]string{"JEAN_DELETE_BRANCH": fmt.Sprint(willDelete)}); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := os.ReadFile(out); strings.TrimSpace(string(got)) != fmt.Sprint(tt.want) {
+			t.Errorf("%s: hook got JEAN_DELETE_BRANCH=%q, want %v", tt.name, strings.TrimSpace(string(got)), tt.want)
+		}
+	}
+
+	// Remove does what the hook was told
+	if err := os.WriteFile(filepath.Join(repo, "jean.json"), []byte(fmt.Sprintf(teardown, "")), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Remove(merged, "main", false, false); err != nil {
+		t.Fatal(err)
+	}
+	if m.branchExists("merged") {
+		t.Error("Expected Remove to delete the merged branch as announced")
+	}
+	var kept *BranchKeptError
+	if err := m.Remove(unpushed, "main", false, false); !errors.As(err, &kept) || !m.branchExists("unpushed") {
+		t.Errorf("Expected Remove to keep the unpushed branch as announced, got %v", err)
+	}
+}
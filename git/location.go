@@ -0,0 +1,162 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// DefaultPathTemplate places worktrees in a .workspaces directory inside the repository
+const DefaultPathTemplate = ".workspaces/{branch}"
+
+// branchPlaceholder marks where the branch goes while the rest of a template is expanded
+const branchPlaceholder = "\x00"
+
+// placeholderRegex matches template placeholders like {branch}
+var placeholderRegex = regexp.MustCompile(`\{[^{}]*\}`)
+
+// SetPathTemplate sets the template new worktree paths are built from ("" = DefaultPathTemplate)
+// Supports {repo}, {branch} and {user}; relative paths are resolved against the repository root
+func (m *Manager) SetPathTemplate(template string) {
+	m.pathTemplate = template
+}
+
+// PathTemplate returns the template new worktree paths are built from
+func (m *Manager) PathTemplate() string {
+	if m.pathTemplate != "" {
+		return m.pathTemplate
+	}
+	return DefaultPathTemplate
+}
+
+// ValidatePathTemplate checks that a template only uses known placeholders and contains {branch}
+func ValidatePathTemplate(template string) error {
+	if strings.TrimSpace(template) == "" {
+		return nil
+	}
+	for _, placeholder := range placeholderRegex.FindAllString(template, -1) {
+		switch placeholder {
+		case "{repo}", "{branch}", "{user}":
+		default:
+			return fmt.Errorf("unknown placeholder %s (use {repo}, {branch} or {user})", placeholder)
+		}
+	}
+	if !strings.Contains(template, "{branch}") {
+		return fmt.Errorf("template must contain {branch} so every worktree gets its own directory")
+	}
+	return nil
+}
+
+// ExpandPathTemplate builds a worktree path from a template for the repository at root
+// "~" expands to the home directory; the branch should already be safe to use in a path
+func ExpandPathTemplate(template, root, branch string) (string, error) {
+	if strings.TrimSpace(template) == "" {
+		template = DefaultPathTemplate
+	}
+	if err := ValidatePathTemplate(template); err != nil {
+		return "", err
+	}
+
+	path := strings.NewReplacer(
+		"{repo}", filepath.Base(root),
+		"{branch}", branch,
+		"{user}", currentUsername(),
+	).Replace(template)
+
+	return resolvePath(path, root)
+}
+
+// resolvePath expands "~" and makes a relative path absolute against root
+func resolvePath(path, root string) (string, error) {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		path = filepath.Join(home, strings.TrimPrefix(path, "~"))
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+	return filepath.Clean(path), nil
+}
+
+// currentUsername returns the name of the user running jean, for the {user} placeholder
+func currentUsername() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return "user"
+}
+
+// worktreesDirIsOwned reports whether the directory new worktrees go into belongs to this repository alone
+// It doesn't when the template shares one directory across repositories (e.g. "~/wt/{repo}-{branch}"),
+// in which case directories there can't be treated as this repository's orphans
+func (m *Manager) worktreesDirIsOwned() bool {
+	template := m.PathTemplate()
+	prefix, _, _ := strings.Cut(template, "{branch}")
+	if strings.Contains(filepath.Dir(prefix+"x"), "{repo}") {
+		return true
+	}
+
+	root, err := m.GetRepoRoot()
+	if err != nil {
+		return false
+	}
+	dir, err := m.GetWorkspacesDir()
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(dir, root+string(filepath.Separator))
+}
+
+// RelocateWorktree moves a worktree to a new path, creating missing parent directories
+// newPath may start with "~" or be relative to the repository root. Falls back to copying
+// and "git worktree repair" when the target is on another filesystem. Returns the resolved path.
+func (m *Manager) RelocateWorktree(oldPath, newPath string) (string, error) {
+	root, err := m.GetRepoRoot()
+	if err != nil {
+		return "", err
+	}
+	newPath, err = resolvePath(strings.TrimSpace(newPath), root)
+	if err != nil {
+		return "", err
+	}
+	if filepath.Clean(oldPath) == newPath {
+		return "", fmt.Errorf("worktree is already at %s", newPath)
+	}
+	if _, err := os.Stat(newPath); err == nil {
+		return "", fmt.Errorf("%s already exists", newPath)
+	}
+	if err := os.MkdirAll(filepath.Dir(newPath), 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", filepath.Dir(newPath), err)
+	}
+
+	err = m.MoveWorktree(oldPath, newPath)
+	if err == nil || !strings.Contains(err.Error(), "cross-device") {
+		return newPath, err
+	}
+
+	// git worktree move can't rename across filesystems, so copy the directory and reconnect it
+	cmd := exec.Command("cp", "-a", oldPath, newPath)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		os.RemoveAll(newPath)
+		return "", fmt.Errorf("failed to copy worktree: %s", string(output))
+	}
+	cmd = exec.Command("git", "-C", newPath, "worktree", "repair")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		os.RemoveAll(newPath)
+		return "", fmt.Errorf("failed to repair moved worktree: %s", string(output))
+	}
+	if err := os.RemoveAll(oldPath); err != nil {
+		return newPath, fmt.Errorf("worktree moved but failed to delete old directory: %w", err)
+	}
+	return newPath, nil
+}
@@ -0,0 +1,29 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExpandPathTemplate(t *testing.T) {
+	home, _ := os.UserHomeDir()
+	tests := []struct {
+		template string
+		want     string
+		wantErr  bool
+	}{
+		{"", "/src/app/.workspaces/feat-x", false},
+		{"/wt/{repo}/{branch}", "/wt/app/feat-x", false},
+		{"../{repo}-{branch}", "/src/app-feat-x", false},
+		{"~/wt/{branch}", filepath.Join(home, "wt", "feat-x"), false},
+		{"/wt/{repo}", "", true},
+		{"/wt/{project}/{branch}", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ExpandPathTemplate(tt.template, "/src/app", "feat-x")
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ExpandPathTemplate(%q) = %q, %v; want %q (error %v)", tt.template, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
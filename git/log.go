@@ -0,0 +1,102 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// LogEntry is one line of `git log --graph` output
+// Lines that only continue the graph (merges, forks) have an empty Hash
+type LogEntry struct {
+	Graph     string // Graph drawing prefix (e.g. "* ", "| * ")
+	Hash      string
+	ShortHash string
+	Author    string
+	Date      string // Relative author date (e.g. "3 days ago")
+	Refs      string // Decorations (e.g. "HEAD -> main, origin/main")
+	Subject   string
+}
+
+// GetLog returns a page of the worktree's commit history with graph lines
+// skip and limit count commits, not graph-only lines
+func (m *Manager) GetLog(worktreePath string, skip, limit int) ([]LogEntry, error) {
+	cmd := exec.Command("git", "-C", worktreePath, "log", "--graph", "--no-color",
+		"--format=%x00%H%x00%h%x00%an%x00%ar%x00%D%x00%s",
+		fmt.Sprintf("--skip=%d", skip), fmt.Sprintf("-n%d", limit))
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get log: %w", err)
+	}
+
+	var entries []LogEntry
+	for _, line := range strings.Split(strings.TrimRight(string(output), "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		// Format: <graph>\x00<hash>\x00<short>\x00<author>\x00<date>\x00<refs>\x00<subject>
+		fields := strings.Split(line, "\x00")
+		if len(fields) != 7 {
+			entries = append(entries, LogEntry{Graph: line})
+			continue
+		}
+		entries = append(entries, LogEntry{
+			Graph:     fields[0],
+			Hash:      fields[1],
+			ShortHash: fields[2],
+			Author:    fields[3],
+			Date:      fields[4],
+			Refs:      fields[5],
+			Subject:   fields[6],
+		})
+	}
+	return entries, nil
+}
+
+// GetCommitDiff returns the full commit message, stat and patch of a commit
+func (m *Manager) GetCommitDiff(worktreePath, hash string) (string, error) {
+	cmd := exec.Command("git", "-C", worktreePath, "show", "--no-color", "--no-ext-diff", "--format=fuller", "--stat", "-p", hash)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to show commit %s: %w", hash, err)
+	}
+	return string(output), nil
+}
+
+// CherryPick applies the given commits (oldest first) onto the worktree's current branch
+// Merge commits are refused. Commits whose changes are already on the branch are skipped;
+// the returned count says how many. Returns a "cherry-pick conflict" error if it stops on conflicts,
+// leaving the cherry-pick in progress.
+func (m *Manager) CherryPick(worktreePath string, hashes []string) (int, error) {
+	if len(hashes) == 0 {
+		return 0, fmt.Errorf("no commits to cherry-pick")
+	}
+
+	// Picking a merge needs a mainline parent (-m); ask for its commits instead of guessing one
+	args := append([]string{"-C", worktreePath, "rev-list", "--no-walk", "--min-parents=2", "--abbrev-commit"}, hashes...)
+	output, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return 0, fmt.Errorf("failed to inspect commits to cherry-pick: %w", err)
+	}
+	if merges := strings.Fields(string(output)); len(merges) > 0 {
+		return 0, fmt.Errorf("can't cherry-pick merge commit %s, select the commits it merged instead", strings.Join(merges, ", "))
+	}
+
+	// --allow-empty keeps commits that were empty to begin with
+	args = append([]string{"-C", worktreePath, "cherry-pick", "--allow-empty"}, hashes...)
+	output, err = exec.Command("git", args...).CombinedOutput()
+	skipped := 0
+	for err != nil {
+		outputStr := string(output)
+		if strings.Contains(outputStr, "CONFLICT") || strings.Contains(outputStr, "could not apply") {
+			return skipped, fmt.Errorf("cherry-pick conflict occurred. Use 'git cherry-pick --abort' to abort the cherry-pick")
+		}
+		// The commit's changes are already on the branch: drop it and go on with the rest
+		if !strings.Contains(outputStr, "is now empty") {
+			return skipped, fmt.Errorf("failed to cherry-pick: %s", outputStr)
+		}
+		skipped++
+		output, err = exec.Command("git", "-C", worktreePath, "cherry-pick", "--skip").CombinedOutput()
+	}
+	return skipped, nil
+}
@@ -0,0 +1,59 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCherryPick(t *testing.T) {
+	// Manager runs git with the process environment; give it an identity to commit with
+	t.Setenv("GIT_COMMITTER_NAME", "jean")
+	t.Setenv("GIT_COMMITTER_EMAIL", "jean@example.com")
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("HOME", t.TempDir())
+
+	repo := newTestRepo(t, nil)
+	runGit(t, repo, "checkout", "-q", "-b", "feature")
+	if err := os.WriteFile(filepath.Join(repo, "feature.txt"), []byte("feature\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, repo, "add", "feature.txt")
+	runGit(t, repo, "commit", "-q", "-m", "add feature")
+	picked := runGit(t, repo, "rev-parse", "HEAD")
+	runGit(t, repo, "commit", "-q", "--allow-empty", "-m", "empty on purpose")
+	empty := runGit(t, repo, "rev-parse", "HEAD")
+	runGit(t, repo, "checkout", "-q", "main")
+	runGit(t, repo, "merge", "-q", "--no-ff", "--no-edit", "feature")
+	merge := runGit(t, repo, "rev-parse", "HEAD")
+	runGit(t, repo, "checkout", "-q", "-b", "target", "HEAD~1")
+
+	m := NewManager(repo)
+	if _, err := m.CherryPick(repo, []string{merge}); err == nil || !strings.Contains(err.Error(), "merge commit") {
+		t.Errorf("Expected a merge commit to be refused, got %v", err)
+	}
+
+	skipped, err := m.CherryPick(repo, []string{picked, empty})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if skipped != 0 {
+		t.Errorf("Expected a commit that was empty to begin with to be picked, %d skipped", skipped)
+	}
+
+	// Picking the same change again becomes empty and is skipped
+	skipped, err = m.CherryPick(repo, []string{picked})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if skipped != 1 {
+		t.Errorf("Expected the already applied commit to be skipped, %d skipped", skipped)
+	}
+	if m.IsCherryPickInProgress(repo) {
+		t.Error("Expected no cherry-pick left in progress")
+	}
+	if got := runGit(t, repo, "log", "--format=%s", "target"); got != "empty on purpose\nadd feature\ninitial" {
+		t.Errorf("Unexpected commits on target:\n%s", got)
+	}
+}
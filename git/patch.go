@@ -0,0 +1,249 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// hunkHeaderRegex matches unified diff hunk headers: @@ -oldStart[,oldLines] +newStart[,newLines] @@ [section]
+var hunkHeaderRegex = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@(.*)$`)
+
+// DiffHunk is a single @@ hunk of a file diff
+type DiffHunk struct {
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+	Section  string   // Text after the closing @@ (usually the enclosing function)
+	Lines    []string // Hunk body lines, each prefixed with ' ', '+', '-' or '\'
+}
+
+// FileDiff is the parsed unified diff of a single file
+type FileDiff struct {
+	Path      string
+	Header    []string // Lines before the first hunk (diff --git, index, mode, ---, +++)
+	Hunks     []DiffHunk
+	IsBinary  bool
+	IsNew     bool // File is created by the diff (--- /dev/null)
+	IsDeleted bool // File is deleted by the diff (+++ /dev/null)
+}
+
+// GetFileDiff returns the parsed diff of a single file
+// staged selects the index-vs-HEAD diff; otherwise the working-tree-vs-index diff is returned.
+// Untracked files are diffed against /dev/null so they can be staged piece by piece.
+func (m *Manager) GetFileDiff(worktreePath, filePath string, staged bool) (*FileDiff, error) {
+	var cmd *exec.Cmd
+	untracked := false
+	if staged {
+		cmd = exec.Command("git", "-C", worktreePath, "diff", "--cached", "--no-color", "--no-ext-diff", "--", filePath)
+	} else {
+		// Check whether git knows about the file at all
+		checkCmd := exec.Command("git", "-C", worktreePath, "ls-files", "--error-unmatch", "--", filePath)
+		untracked = checkCmd.Run() != nil
+		if untracked {
+			cmd = exec.Command("git", "-C", worktreePath, "diff", "--no-index", "--no-color", "--no-ext-diff", "--", "/dev/null", filePath)
+		} else {
+			cmd = exec.Command("git", "-C", worktreePath, "diff", "--no-color", "--no-ext-diff", "--", filePath)
+		}
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		// diff --no-index exits with 1 when the files differ
+		var exitErr *exec.ExitError
+		if !untracked || !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+			return nil, fmt.Errorf("failed to get diff for %s: %w", filePath, err)
+		}
+	}
+
+	diff := ParseFileDiff(string(output))
+	diff.Path = filePath
+	return diff, nil
+}
+
+// ParseFileDiff parses the unified diff output of a single file
+func ParseFileDiff(output string) *FileDiff {
+	diff := &FileDiff{}
+	var hunk *DiffHunk
+
+	for _, line := range strings.Split(strings.TrimSuffix(output, "\n"), "\n") {
+		if match := hunkHeaderRegex.FindStringSubmatch(line); match != nil {
+			if hunk != nil {
+				diff.Hunks = append(diff.Hunks, *hunk)
+			}
+			hunk = &DiffHunk{
+				OldStart: atoiDefault(match[1], 0),
+				OldLines: atoiDefault(match[2], 1),
+				NewStart: atoiDefault(match[3], 0),
+				NewLines: atoiDefault(match[4], 1),
+				Section:  match[5],
+			}
+			continue
+		}
+
+		if hunk == nil {
+			if line == "" {
+				continue
+			}
+			diff.Header = append(diff.Header, line)
+			switch {
+			case strings.HasPrefix(line, "Binary files ") || line == "GIT binary patch":
+				diff.IsBinary = true
+			case line == "--- /dev/null" || strings.HasPrefix(line, "new file mode"):
+				diff.IsNew = true
+			case line == "+++ /dev/null" || strings.HasPrefix(line, "deleted file mode"):
+				diff.IsDeleted = true
+			}
+			continue
+		}
+
+		hunk.Lines = append(hunk.Lines, line)
+	}
+	if hunk != nil {
+		diff.Hunks = append(diff.Hunks, *hunk)
+	}
+
+	return diff
+}
+
+// atoiDefault parses an optional hunk header count, returning def if it's missing
+func atoiDefault(s string, def int) int {
+	if s == "" {
+		return def
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return def
+	}
+	return n
+}
+
+// IsChangeLine reports whether a hunk body line adds or removes content
+func IsChangeLine(line string) bool {
+	return strings.HasPrefix(line, "+") || strings.HasPrefix(line, "-")
+}
+
+// BuildHunkPatch builds a patch for part of one hunk that can be applied with ApplyPatchToIndex.
+// selected reports whether the change line at a body index is included (nil selects the whole hunk).
+// For reverse patches (unstaging from a staged diff) unselected additions become context and
+// unselected removals are dropped; otherwise unselected additions are dropped and unselected
+// removals become context. Either way the side the patch is applied to is left unchanged.
+func (d *FileDiff) BuildHunkPatch(hunkIndex int, selected func(int) bool, reverse bool) (string, error) {
+	if d.IsBinary {
+		return "", fmt.Errorf("binary files can only be staged as a whole")
+	}
+	if hunkIndex < 0 || hunkIndex >= len(d.Hunks) {
+		return "", fmt.Errorf("invalid hunk index %d", hunkIndex)
+	}
+	hunk := d.Hunks[hunkIndex]
+
+	var body []string
+	oldCount, newCount := 0, 0
+	changes, selectedChanges := 0, 0
+	dropped := false // Whether the previous line was dropped (its "\ No newline" marker must go too)
+	for i, line := range hunk.Lines {
+		if line == "" {
+			line = " " // Some tools strip the trailing space of empty context lines
+		}
+		switch line[0] {
+		case '+', '-':
+			changes++
+			isSelected := selected == nil || selected(i)
+			if isSelected {
+				selectedChanges++
+				body = append(body, line)
+				if line[0] == '+' {
+					newCount++
+				} else {
+					oldCount++
+				}
+				dropped = false
+				continue
+			}
+			// Unselected change: keep the applied-to side intact
+			keepAsContext := (line[0] == '-') != reverse
+			if keepAsContext {
+				body = append(body, " "+line[1:])
+				oldCount++
+				newCount++
+				dropped = false
+			} else {
+				dropped = true
+			}
+		case '\\':
+			if !dropped {
+				body = append(body, line)
+			}
+		default:
+			body = append(body, line)
+			oldCount++
+			newCount++
+			dropped = false
+		}
+	}
+
+	if selectedChanges == 0 {
+		return "", fmt.Errorf("no changes selected")
+	}
+
+	// A partial patch can't create or delete the whole file
+	partial := selectedChanges < changes
+	if partial && ((d.IsDeleted && !reverse) || (d.IsNew && reverse)) {
+		return "", fmt.Errorf("partially staging a new or deleted file is not supported, stage the whole file instead")
+	}
+
+	// Only the side that isn't applied to changes; its start follows from the other side's start
+	oldStart, newStart := hunk.OldStart, hunk.NewStart
+	if reverse {
+		oldStart = counterpartStart(hunk.NewStart, newCount, oldCount)
+	} else {
+		newStart = counterpartStart(hunk.OldStart, oldCount, newCount)
+	}
+
+	var b strings.Builder
+	for _, line := range d.Header {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@%s\n", oldStart, oldCount, newStart, newCount, hunk.Section)
+	for _, line := range body {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	return b.String(), nil
+}
+
+// counterpartStart computes a hunk's start line on one side from the start on the other side.
+// Unified diffs give the line before the hunk as the start when a side has no lines.
+func counterpartStart(start, count, otherCount int) int {
+	switch {
+	case count == 0 && otherCount > 0:
+		return start + 1
+	case count > 0 && otherCount == 0:
+		return start - 1
+	}
+	return start
+}
+
+// ApplyPatchToIndex applies a patch to the index only (like `git add -p`)
+// reverse removes the patch from the index instead (like `git reset -p`)
+func (m *Manager) ApplyPatchToIndex(worktreePath, patch string, reverse bool) error {
+	args := []string{"-C", worktreePath, "apply", "--cached", "--whitespace=nowarn"}
+	if reverse {
+		args = append(args, "--reverse")
+	}
+	args = append(args, "-")
+
+	cmd := exec.Command("git", args...)
+	cmd.Stdin = bytes.NewBufferString(patch)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to apply patch: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
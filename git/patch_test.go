@@ -0,0 +1,214 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// numberedLines returns the lines "line 1" to "line n"
+func numberedLines(n int) []string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %d", i+1)
+	}
+	return lines
+}
+
+// stageHunk builds the patch for part of a hunk of a file's diff and applies it to the index
+// selected lists the indexes of the change lines to include, all of them if nil
+func stageHunk(t *testing.T, m *Manager, repo, file string, staged bool, hunk int, selected []int) {
+	t.Helper()
+	diff, err := m.GetFileDiff(repo, file, staged)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var selectedFunc func(int) bool
+	if selected != nil {
+		selectedFunc = func(i int) bool {
+			for _, s := range selected {
+				if s == i {
+					return true
+				}
+			}
+			return false
+		}
+	}
+	patch, err := diff.BuildHunkPatch(hunk, selectedFunc, staged)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.ApplyPatchToIndex(repo, patch, staged); err != nil {
+		t.Fatalf("%v\n%s", err, patch)
+	}
+}
+
+// indexContent returns a file's content in the index
+func indexContent(t *testing.T, repo, file string) string {
+	t.Helper()
+	output, err := gitCommand(t, repo, "show", ":"+file).Output()
+	if err != nil {
+		t.Fatalf("git show :%s: %v", file, err)
+	}
+	return string(output)
+}
+
+// TestParseFileDiff tests hunk headers, default counts and file flags
+func TestParseFileDiff(t *testing.T) {
+	diff := ParseFileDiff(`diff --git a/new.txt b/new.txt
+new file mode 100644
+index 0000000..3b18e51
+--- /dev/null
++++ b/new.txt
+@@ -0,0 +1 @@ func main
++hello
+\ No newline at end of file
+`)
+	if !diff.IsNew || diff.IsDeleted || diff.IsBinary || len(diff.Header) != 5 || len(diff.Hunks) != 1 {
+		t.Fatalf("Unexpected parse: %+v", diff)
+	}
+	hunk := diff.Hunks[0]
+	if hunk.OldStart != 0 || hunk.OldLines != 0 || hunk.NewStart != 1 || hunk.NewLines != 1 || hunk.Section != " func main" {
+		t.Errorf("Unexpected hunk header: %+v", hunk)
+	}
+	if len(hunk.Lines) != 2 || hunk.Lines[1] != `\ No newline at end of file` {
+		t.Errorf("Unexpected hunk body: %q", hunk.Lines)
+	}
+}
+
+// TestStageHunks tests staging hunks and parts of hunks while earlier hunks stay unstaged
+func TestStageHunks(t *testing.T) {
+	original := numberedLines(30)
+	repo := newTestRepo(t, map[string]string{"file.txt": strings.Join(original, "\n") + "\n"})
+	m := NewManager(repo)
+
+	// Hunk 0 adds three lines after line 2, shifting hunk 1 (a change of line 25) by three lines
+	changed := append([]string{}, original[:2]...)
+	changed = append(changed, "added 1", "added 2", "added 3")
+	changed = append(changed, original[2:]...)
+	changed[27] = "changed 25"
+	writeFiles(t, repo, map[string]string{"file.txt": strings.Join(changed, "\n") + "\n"})
+
+	diff, err := m.GetFileDiff(repo, "file.txt", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff.Hunks) != 2 || diff.Hunks[1].NewStart != diff.Hunks[1].OldStart+3 {
+		t.Fatalf("Expected two hunks, the second shifted by three lines, got %+v", diff.Hunks)
+	}
+
+	// Only the second hunk: the index gets line 25 changed at its original position
+	stageHunk(t, m, repo, "file.txt", false, 1, nil)
+	want := append([]string{}, original...)
+	want[24] = "changed 25"
+	if got := indexContent(t, repo, "file.txt"); got != strings.Join(want, "\n")+"\n" {
+		t.Errorf("Expected only line 25 to be staged, got:\n%s", got)
+	}
+
+	// Then only the middle addition of the first hunk
+	diff, _ = m.GetFileDiff(repo, "file.txt", false)
+	var middle int
+	for i, line := range diff.Hunks[0].Lines {
+		if line == "+added 2" {
+			middle = i
+		}
+	}
+	stageHunk(t, m, repo, "file.txt", false, 0, []int{middle})
+	want = append(append(append([]string{}, want[:2]...), "added 2"), want[2:]...)
+	if got := indexContent(t, repo, "file.txt"); got != strings.Join(want, "\n")+"\n" {
+		t.Errorf("Expected the selected line to be staged after line 2, got:\n%s", got)
+	}
+	if unstaged := runGit(t, repo, "diff", "--numstat"); unstaged != "2\t0\tfile.txt" {
+		t.Errorf("Expected the two unselected lines to stay unstaged, got %q", unstaged)
+	}
+}
+
+// TestUnstageHunks tests removing hunks and parts of hunks from the index with reverse patches
+func TestUnstageHunks(t *testing.T) {
+	original := numberedLines(30)
+	repo := newTestRepo(t, map[string]string{"file.txt": strings.Join(original, "\n") + "\n"})
+	m := NewManager(repo)
+
+	changed := append([]string{}, original[:2]...)
+	changed = append(changed, "added 1", "added 2")
+	changed = append(changed, original[2:]...)
+	changed[26] = "changed 25"
+	writeFiles(t, repo, map[string]string{"file.txt": strings.Join(changed, "\n") + "\n"})
+	runGit(t, repo, "add", "file.txt")
+
+	// Unstage the second hunk, after the staged first one shifted it
+	stageHunk(t, m, repo, "file.txt", true, 1, nil)
+	want := append([]string{}, changed...)
+	want[26] = "line 25"
+	if got := indexContent(t, repo, "file.txt"); got != strings.Join(want, "\n")+"\n" {
+		t.Errorf("Expected line 25 to be unstaged, got:\n%s", got)
+	}
+
+	// Unstage one of the two additions
+	diff, _ := m.GetFileDiff(repo, "file.txt", true)
+	var first int
+	for i, line := range diff.Hunks[0].Lines {
+		if line == "+added 1" {
+			first = i
+		}
+	}
+	stageHunk(t, m, repo, "file.txt", true, 0, []int{first})
+	want = append(append([]string{}, want[:2]...), want[3:]...)
+	if got := indexContent(t, repo, "file.txt"); got != strings.Join(want, "\n")+"\n" {
+		t.Errorf("Expected only the unselected addition to stay staged, got:\n%s", got)
+	}
+	if content, _ := os.ReadFile(filepath.Join(repo, "file.txt")); string(content) != strings.Join(changed, "\n")+"\n" {
+		t.Error("Expected unstaging to leave the working tree alone")
+	}
+}
+
+// TestStageUntrackedFile tests staging a new file whole and in part
+func TestStageUntrackedFile(t *testing.T) {
+	repo := newTestRepo(t, nil)
+	m := NewManager(repo)
+	writeFiles(t, repo, map[string]string{"new.txt": "one\ntwo\nthree\n", "other.txt": "a\nb\n"})
+
+	stageHunk(t, m, repo, "new.txt", false, 0, nil)
+	if got := indexContent(t, repo, "new.txt"); got != "one\ntwo\nthree\n" {
+		t.Errorf("Expected the whole file to be staged, got %q", got)
+	}
+
+	// Only the first line (body index 0)
+	stageHunk(t, m, repo, "other.txt", false, 0, []int{0})
+	if got := indexContent(t, repo, "other.txt"); got != "a\n" {
+		t.Errorf("Expected only the first line to be staged, got %q", got)
+	}
+}
+
+// TestStageWithoutNewlineAtEndOfFile tests hunks with "\ No newline at end of file" markers
+func TestStageWithoutNewlineAtEndOfFile(t *testing.T) {
+	repo := newTestRepo(t, map[string]string{"file.txt": "a\nb"})
+	m := NewManager(repo)
+
+	// Changing the last line keeps both markers
+	writeFiles(t, repo, map[string]string{"file.txt": "a\nB"})
+	stageHunk(t, m, repo, "file.txt", false, 0, nil)
+	if got := indexContent(t, repo, "file.txt"); got != "a\nB" {
+		t.Errorf("Expected the last line to be staged without a newline, got %q", got)
+	}
+	runGit(t, repo, "commit", "-q", "-m", "B")
+
+	// Appending a line: stage the newline fix but not the new line, whose marker is dropped with it
+	writeFiles(t, repo, map[string]string{"file.txt": "a\nB\nc"})
+	diff, err := m.GetFileDiff(repo, "file.txt", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var selected []int
+	for i, line := range diff.Hunks[0].Lines {
+		if line == "-B" || line == "+B" {
+			selected = append(selected, i)
+		}
+	}
+	stageHunk(t, m, repo, "file.txt", false, 0, selected)
+	if got := indexContent(t, repo, "file.txt"); got != "a\nB\n" {
+		t.Errorf("Expected only the newline to be staged, got %q", got)
+	}
+}
@@ -0,0 +1,69 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/coollabsio/jean-tui/config"
+)
+
+// BranchKeptError is returned by Remove when the worktree was removed but its branch was kept
+// because it has commits that exist nowhere else,
+// or because they couldn't be counted
+type BranchKeptError struct {
+	Branch   string
+	AtRisk   int   // Commits not on any remote or the base branch
+	CheckErr error // Set when counting them failed; the branch is kept to be safe
+}
+
+func (e *BranchKeptError) Error() string {
+	if e.CheckErr != nil {
+		return fmt.Sprintf("kept branch '%s': couldn't check for unpushed commits: %v", e.Branch, e.CheckErr)
+	}
+	return fmt.Sprintf("kept branch '%s': %d commit(s) not on any remote or the base branch", e.Branch, e.AtRisk)
+}
+
+func (e *BranchKeptError) Unwrap() error {
+	return e.CheckErr
+}
+
+// loadBranchPolicy loads the protected branches and deletion policy from jean.json
+// Falls back to the defaults if jean.json is missing or invalid
+func (m *Manager) loadBranchPolicy() *config.ScriptConfig {
+	root, err := m.GetRepoRoot()
+	if err != nil {
+		return nil
+	}
+	scriptConfig, err := config.LoadScripts(root)
+	if err != nil {
+		return nil
+	}
+	return scriptConfig
+}
+
+// IsProtectedBranch checks if a branch matches the protected branch patterns from jean.json
+func (m *Manager) IsProtectedBranch(branch string) bool {
+	return m.loadBranchPolicy().IsProtectedBranch(branch)
+}
+
+// DeletionPolicy returns the branch deletion policy from jean.json
+func (m *Manager) DeletionPolicy() string {
+	return m.loadBranchPolicy().GetDeletionPolicy()
+}
+
+// CountAtRiskCommits counts the commits on branch that aren't on any remote-tracking branch
+// or on the base branch, i.e. the commits that are lost if the branch is deleted
+func (m *Manager) CountAtRiskCommits(branch, baseBranch string) (int, error) {
+	args := []string{"-C", m.repoPath, "rev-list", "--count", "refs/heads/" + branch, "--not", "--remotes"}
+	if baseBranch != "" && baseBranch != branch && m.branchExists(baseBranch) {
+		args = append(args, baseBranch)
+	}
+	cmd := exec.Command("git", args...)
+	output, err := cmd.Output()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unpushed commits: %w", err)
+	}
+	return strconv.Atoi(strings.TrimSpace(string(output)))
+}
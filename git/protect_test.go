@@ -0,0 +1,31 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRemoveKeepsBranchWhenCommitsCantBeChecked(t *testing.T) {
+	repo := newTestRepo(t, nil)
+	feature := filepath.Join(t.TempDir(), "feature")
+	runGit(t, repo, "worktree", "add", "-q", "-b", "feature", feature)
+	runGit(t, feature, "commit", "-q", "--allow-empty", "-m", "unpushed")
+
+	// A missing commit object makes counting the unpushed commits fail
+	head := runGit(t, feature, "rev-parse", "HEAD")
+	if err := os.Remove(filepath.Join(repo, ".git", "objects", head[:2], head[2:])); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewManager(repo)
+	err := m.Remove(feature, "main", true, false)
+	var kept *BranchKeptError
+	if !errors.As(err, &kept) || kept.CheckErr == nil {
+		t.Fatalf("Expected the branch to be kept because it couldn't be checked, got %v", err)
+	}
+	if !m.branchExists("feature") {
+		t.Error("Expected the feature branch to still exist")
+	}
+}
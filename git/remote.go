@@ -0,0 +1,135 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// DefaultRemote is used for pushing and for the base branch when no remote is configured
+const DefaultRemote = "origin"
+
+// SetRemotes sets the remote branches are pushed to and the remote the base branch comes from
+// Empty names fall back to DefaultRemote. In a fork workflow push is usually "origin" and base "upstream".
+func (m *Manager) SetRemotes(push, base string) {
+	m.pushRemote = push
+	m.baseRemote = base
+}
+
+// PushRemote returns the remote branches are pushed to and PRs are opened from
+func (m *Manager) PushRemote() string {
+	if m.pushRemote != "" {
+		return m.pushRemote
+	}
+	return DefaultRemote
+}
+
+// BaseRemote returns the remote the base branch is fetched from and PRs are opened against
+func (m *Manager) BaseRemote() string {
+	if m.baseRemote != "" {
+		return m.baseRemote
+	}
+	return m.PushRemote()
+}
+
+// Remotes returns the names of all configured remotes
+func (m *Manager) Remotes() ([]string, error) {
+	cmd := exec.Command("git", "-C", m.repoPath, "remote")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remotes: %w", err)
+	}
+
+	var remotes []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line != "" {
+			remotes = append(remotes, line)
+		}
+	}
+	return remotes, nil
+}
+
+// FetchAllRemotes fetches every remote without prompting for credentials
+func (m *Manager) FetchAllRemotes() error {
+	cmd := exec.Command("git", "-C", m.repoPath, "fetch", "--all")
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to fetch remotes: %s", string(output))
+	}
+	return nil
+}
+
+// remoteExists checks whether a remote with a URL is configured
+func (m *Manager) remoteExists(path, remote string) bool {
+	cmd := exec.Command("git", "-C", path, "remote", "get-url", remote)
+	output, err := cmd.CombinedOutput()
+	return err == nil && strings.TrimSpace(string(output)) != ""
+}
+
+// remoteForBranch returns the remote a branch is pulled from: its upstream remote if it tracks one,
+// otherwise the push remote
+func (m *Manager) remoteForBranch(path, branch string) string {
+	cmd := exec.Command("git", "-C", path, "config", "--get", "branch."+branch+".remote")
+	if output, err := cmd.Output(); err == nil {
+		if remote := strings.TrimSpace(string(output)); remote != "" && remote != "." {
+			return remote
+		}
+	}
+	return m.PushRemote()
+}
+
+// splitRemoteBranch splits a remote-tracking branch like "upstream/next" into remote and branch name
+// Returns ok=false (and the branch unchanged) for local branches
+func (m *Manager) splitRemoteBranch(branch string) (string, string, bool) {
+	if m.branchExists("refs/heads/" + branch) {
+		return "", branch, false
+	}
+	remotes, err := m.Remotes()
+	if err != nil {
+		return "", branch, false
+	}
+	for _, remote := range remotes {
+		if strings.HasPrefix(branch, remote+"/") {
+			return remote, strings.TrimPrefix(branch, remote+"/"), true
+		}
+	}
+	return "", branch, false
+}
+
+// GetRemoteURLFor returns the URL of the given remote
+func (m *Manager) GetRemoteURLFor(remote string) (string, error) {
+	cmd := exec.Command("git", "-C", m.repoPath, "remote", "get-url", remote)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get URL of remote '%s': %w", remote, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// githubRepoRegex matches the owner and name in GitHub HTTPS and SSH remote URLs
+var githubRepoRegex = regexp.MustCompile(`github\.com[:/]([^/]+)/([^/]+?)(?:\.git)?/?$`)
+
+// ParseGitHubRepo extracts the owner and repository name from a GitHub remote URL
+func ParseGitHubRepo(url string) (string, string, bool) {
+	match := githubRepoRegex.FindStringSubmatch(strings.TrimSpace(url))
+	if match == nil {
+		return "", "", false
+	}
+	return match[1], match[2], true
+}
+
+// GetGitHubRepo returns "owner/name" of the GitHub repository behind a remote
+func (m *Manager) GetGitHubRepo(remote string) (string, error) {
+	url, err := m.GetRemoteURLFor(remote)
+	if err != nil {
+		return "", err
+	}
+	owner, name, ok := ParseGitHubRepo(url)
+	if !ok {
+		return "", fmt.Errorf("remote '%s' is not a GitHub repository", remote)
+	}
+	return owner + "/" + name, nil
+}
@@ -0,0 +1,23 @@
+package git
+
+import "testing"
+
+func TestParseGitHubRepo(t *testing.T) {
+	tests := []struct {
+		url   string
+		owner string
+		name  string
+		ok    bool
+	}{
+		{"https://github.com/coollabsio/jean-tui.git", "coollabsio", "jean-tui", true},
+		{"git@github.com:me/jean-tui.git", "me", "jean-tui", true},
+		{"ssh://git@github.com/me/jean-tui", "me", "jean-tui", true},
+		{"https://gitlab.com/me/jean-tui.git", "", "", false},
+	}
+	for _, tt := range tests {
+		owner, name, ok := ParseGitHubRepo(tt.url)
+		if owner != tt.owner || name != tt.name || ok != tt.ok {
+			t.Errorf("ParseGitHubRepo(%q) = %q, %q, %v; want %q, %q, %v", tt.url, owner, name, ok, tt.owner, tt.name, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// IsMissing reports whether the worktree's directory no longer exists on disk
+// Locked worktrees are never reported as prunable by git, so this checks the directory directly
+func (wt Worktree) IsMissing() bool {
+	if wt.IsPrunable {
+		return true
+	}
+	_, err := os.Stat(wt.Path)
+	return os.IsNotExist(err)
+}
+
+// FindOrphanDirs returns directories in the worktree directory (.workspaces by default) that git doesn't track
+// These are usually left behind after "git worktree prune" or a failed removal.
+// Nothing is reported when the path template shares that directory with other repositories.
+func (m *Manager) FindOrphanDirs(worktrees []Worktree) ([]string, error) {
+	if !m.worktreesDirIsOwned() {
+		return nil, nil
+	}
+	dir, err := m.GetWorkspacesDir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read worktree directory: %w", err)
+	}
+
+	tracked := make(map[string]bool, len(worktrees))
+	for _, wt := range worktrees {
+		tracked[filepath.Clean(wt.Path)] = true
+	}
+
+	var orphans []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		if !tracked[path] && !m.belongsToOtherRepo(path) {
+			orphans = append(orphans, path)
+		}
+	}
+	return orphans, nil
+}
+
+// belongsToOtherRepo reports whether dir is a checkout of some other repository
+// The path template's {repo} is only the directory name, so two clones named alike share
+// a worktree directory; a .git gitfile pointing outside this repository marks the other's worktree.
+func (m *Manager) belongsToOtherRepo(dir string) bool {
+	info, err := os.Lstat(filepath.Join(dir, ".git"))
+	if err != nil {
+		return false // No .git: a leftover that's nobody's worktree
+	}
+	if !info.Mode().IsRegular() {
+		return true // A repository of its own
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, ".git"))
+	if err != nil {
+		return true
+	}
+	gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+	if !ok {
+		return true
+	}
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(dir, gitDir)
+	}
+	_, commonDir := resolveGitDirs(m.repoPath)
+	if commonDir == "" {
+		return true
+	}
+	// <common dir>/worktrees/<name>; the name itself is gone once the worktree was pruned
+	parent := filepath.Dir(filepath.Clean(gitDir))
+	return filepath.Base(parent) != "worktrees" || evalSymlinks(filepath.Dir(parent)) != evalSymlinks(commonDir)
+}
+
+// evalSymlinks resolves symlinks in path, returning it cleaned as is when it doesn't exist
+func evalSymlinks(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return filepath.Clean(path)
+}
+
+// PruneWorktree removes git's record of a worktree whose directory was deleted
+// Unlike "git worktree prune" this only touches the given worktree, and also works when it's locked
+func (m *Manager) PruneWorktree(path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("worktree directory %s still exists", path)
+	}
+
+	// A doubled --force is required to remove locked worktrees
+	cmd := exec.Command("git", "-C", m.repoPath, "worktree", "remove", "--force", "--force", path)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to prune worktree: %s", string(output))
+	}
+	return nil
+}
+
+// DeleteOrphanDir deletes a directory in the worktree directory that isn't a registered worktree
+func (m *Manager) DeleteOrphanDir(path string, worktrees []Worktree) error {
+	if !m.worktreesDirIsOwned() {
+		return fmt.Errorf("worktree directory is shared with other repositories")
+	}
+	dir, err := m.GetWorkspacesDir()
+	if err != nil {
+		return err
+	}
+
+	// Refuse anything outside the worktree directory or still tracked by git
+	path = filepath.Clean(path)
+	if filepath.Dir(path) != dir {
+		return fmt.Errorf("%s is not inside %s", path, dir)
+	}
+	for _, wt := range worktrees {
+		if filepath.Clean(wt.Path) == path {
+			return fmt.Errorf("%s is a registered worktree", path)
+		}
+	}
+	if m.belongsToOtherRepo(path) {
+		return fmt.Errorf("%s is a checkout of another repository", path)
+	}
+
+	if err := os.RemoveAll(path); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", strings.TrimPrefix(path, dir+string(filepath.Separator)), err)
+	}
+	return nil
+}
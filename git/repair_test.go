@@ -0,0 +1,62 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOrphanDirsOfOtherCheckouts(t *testing.T) {
+	// Two clones named "api" share <wt>/api with a {repo} template
+	template := filepath.Join(t.TempDir(), "{repo}", "{branch}")
+	var managers []*Manager
+	for i := 0; i < 2; i++ {
+		repo := filepath.Join(evalSymlinks(t.TempDir()), "api")
+		if err := os.Rename(newTestRepo(t, nil), repo); err != nil {
+			t.Fatal(err)
+		}
+		m := NewManager(repo)
+		m.SetPathTemplate(template)
+		managers = append(managers, m)
+	}
+	ours, theirs := managers[0], managers[1]
+
+	theirPath, err := theirs.GetDefaultPath("feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, theirs.repoPath, "worktree", "add", "-q", "-b", "feature", theirPath)
+	leftover := filepath.Join(filepath.Dir(theirPath), "leftover")
+	if err := os.Mkdir(leftover, 0755); err != nil {
+		t.Fatal(err)
+	}
+	// One of ours whose registration was pruned
+	prunedPath, _ := ours.GetDefaultPath("pruned")
+	runGit(t, ours.repoPath, "worktree", "add", "-q", "-b", "pruned", prunedPath)
+	runGit(t, ours.repoPath, "worktree", "remove", "--force", prunedPath)
+	if err := os.MkdirAll(prunedPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(prunedPath, ".git"), []byte("gitdir: "+filepath.Join(ours.repoPath, ".git", "worktrees", "pruned")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	orphans, err := ours.FindOrphanDirs(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := map[string]bool{}
+	for _, orphan := range orphans {
+		found[filepath.Base(orphan)] = true
+	}
+	if found["feature"] || !found["leftover"] || !found["pruned"] || len(orphans) != 2 {
+		t.Errorf("Expected leftover and pruned as orphans, not the other checkout's worktree; got %v", orphans)
+	}
+
+	if err := ours.DeleteOrphanDir(theirPath, nil); err == nil {
+		t.Error("Expected deleting another checkout's worktree to fail")
+	}
+	if _, err := os.Stat(filepath.Join(theirPath, "README.md")); err != nil {
+		t.Errorf("Expected the other checkout's worktree to be intact: %v", err)
+	}
+}
@@ -0,0 +1,280 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coollabsio/jean-tui/config"
+)
+
+// SetupScript is the jean.json script that prepares new worktrees
+const SetupScript = "setup"
+
+// maxLogBytes is how much of the end of a script log is read for display
+const maxLogBytes = 256 * 1024
+
+// ScriptRun is a jean.json script running in the background with its output written to a log file
+type ScriptRun struct {
+	Name    string    // Script name from jean.json
+	Path    string    // Worktree the script runs in
+	Branch  string    // Branch of that worktree
+	LogPath string    // File stdout and stderr are written to
+	Started time.Time // When the script was started
+
+	cmd     *exec.Cmd
+	done    chan struct{}
+	mu      sync.Mutex
+	err     error
+	stopped bool
+}
+
+// ScriptEnv returns the JEAN_* environment variables scripts run with
+// JEAN_PORT, JEAN_PORT_1, ... are included when jean.json reserves ports for worktrees
+func (m *Manager) ScriptEnv(worktreePath, branch string) []string {
+	root, err := m.GetRepoRoot()
+	if err != nil {
+		root = m.repoPath
+	}
+	env := []string{
+		"JEAN_WORKSPACE_PATH=" + worktreePath,
+		"JEAN_ROOT_PATH=" + root,
+		"JEAN_BRANCH=" + branch,
+	}
+	return append(env, m.portEnv(root, branch)...)
+}
+
+// loadScript returns the command of a named script from jean.json
+func (m *Manager) loadScript(name string) (string, error) {
+	root, err := m.GetRepoRoot()
+	if err != nil {
+		return "", fmt.Errorf("failed to get repo root: %w", err)
+	}
+	scriptConfig, err := config.LoadScripts(root)
+	if err != nil {
+		return "", fmt.Errorf("failed to load jean.json: %w", err)
+	}
+	script := scriptConfig.GetScript(name)
+	if script == "" {
+		return "", fmt.Errorf("script '%s' not found in jean.json", name)
+	}
+	return script, nil
+}
+
+// logNameRegex matches characters that aren't safe in a log file name
+var logNameRegex = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// ScriptLogPath returns the log file background runs of a script in a worktree write to
+// Logs live in the worktree's git directory, so they go away together with the worktree
+func (m *Manager) ScriptLogPath(worktreePath, name string) (string, error) {
+	cmd := exec.Command("git", "-C", worktreePath, "rev-parse", "--absolute-git-dir")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to find git directory of %s: %w", worktreePath, err)
+	}
+	gitDir := strings.TrimSpace(string(output))
+	return filepath.Join(gitDir, "jean", "logs", logNameRegex.ReplaceAllString(name, "_")+".log"), nil
+}
+
+// ScriptCommand returns the command that runs a jean.json script in a worktree with the JEAN_* variables
+func (m *Manager) ScriptCommand(worktreePath, branch, name string) (*exec.Cmd, error) {
+	script, err := m.loadScript(name)
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command("sh", "-c", script)
+	cmd.Dir = worktreePath
+	cmd.Env = append(os.Environ(), append(m.ScriptEnv(worktreePath, branch), "JEAN_SCRIPT="+name)...)
+	return cmd, nil
+}
+
+// StartScript runs a jean.json script in a worktree in the background
+// Output goes to ScriptLogPath, replacing the log of the previous run
+func (m *Manager) StartScript(worktreePath, branch, name string) (*ScriptRun, error) {
+	cmd, err := m.ScriptCommand(worktreePath, branch, name)
+	if err != nil {
+		return nil, err
+	}
+	logPath, err := m.ScriptLogPath(worktreePath, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(logPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	logFile, err := os.Create(logPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create log file: %w", err)
+	}
+
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	// Own process group so Stop also reaches the processes the script spawns
+	setProcessGroup(cmd)
+	if err := cmd.Start(); err != nil {
+		logFile.Close()
+		return nil, fmt.Errorf("failed to start script '%s': %w", name, err)
+	}
+
+	run := &ScriptRun{
+		Name:    name,
+		Path:    worktreePath,
+		Branch:  branch,
+		LogPath: logPath,
+		Started: time.Now(),
+		cmd:     cmd,
+		done:    make(chan struct{}),
+	}
+	go func() {
+		err := cmd.Wait()
+		logFile.Close()
+		run.mu.Lock()
+		run.err = err
+		run.mu.Unlock()
+		close(run.done)
+	}()
+	return run, nil
+}
+
+// StartSetup runs the setup script from jean.json in the background in a new worktree
+// Returns nil if no setup script is configured
+func (m *Manager) StartSetup(worktreePath string) (*ScriptRun, error) {
+	root, err := m.GetRepoRoot()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repo root: %w", err)
+	}
+	scriptConfig, err := config.LoadScripts(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load jean.json: %w", err)
+	}
+	if scriptConfig.GetScript(SetupScript) == "" {
+		return nil, nil
+	}
+	branch, _ := m.GetCurrentBranchForWorktree(worktreePath)
+	return m.StartScript(worktreePath, branch, SetupScript)
+}
+
+// Wait blocks until the script exits and returns its error (nil on exit status 0)
+func (r *ScriptRun) Wait() error {
+	<-r.done
+	return r.Err()
+}
+
+// Running reports whether the script is still running
+func (r *ScriptRun) Running() bool {
+	select {
+	case <-r.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// Err returns the error the script exited with (nil while running or on success)
+func (r *ScriptRun) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// Stopped reports whether the script was ended by Stop
+func (r *ScriptRun) Stopped() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stopped
+}
+
+// Stop terminates the script and everything it started
+func (r *ScriptRun) Stop() error {
+	if !r.Running() {
+		return nil
+	}
+	r.mu.Lock()
+	r.stopped = true
+	r.mu.Unlock()
+	if err := terminateProcessGroup(r.cmd.Process); err != nil {
+		return fmt.Errorf("failed to stop script '%s': %w", r.Name, err)
+	}
+	return nil
+}
+
+// ReadLog returns the lines at the end of a log file
+func ReadLog(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	offset := info.Size() - maxLogBytes
+	if offset < 0 {
+		offset = 0
+	}
+	data := make([]byte, info.Size()-offset)
+	if _, err := file.ReadAt(data, offset); err != nil {
+		return nil, err
+	}
+	if offset > 0 {
+		// Drop the partial first line
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			data = data[i+1:]
+		}
+	}
+
+	text := strings.TrimRight(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	if text == "" {
+		return []string{}, nil
+	}
+	return strings.Split(text, "\n"), nil
+}
+
+// WriteScriptRunner writes a throwaway shell script that runs a jean.json script in the foreground
+// The shell wrapper executes it (passed as SwitchInfo.ScriptCommand) after jean exits; it deletes
+// itself, runs the script in the worktree with the JEAN_* variables and waits for Enter at the end
+func (m *Manager) WriteScriptRunner(worktreePath, branch, name string) (string, error) {
+	script, err := m.loadScript(name)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString("#!/bin/sh\n")
+	b.WriteString("rm -f \"$0\"\n")
+	b.WriteString("cd " + shellQuote(worktreePath) + " || exit 1\n")
+	for _, env := range append(m.ScriptEnv(worktreePath, branch), "JEAN_SCRIPT="+name) {
+		key, value, _ := strings.Cut(env, "=")
+		b.WriteString("export " + key + "=" + shellQuote(value) + "\n")
+	}
+	b.WriteString("printf '\\n▶ Running %s on %s\\n\\n' " + shellQuote(name) + " " + shellQuote(branch) + "\n")
+	b.WriteString("sh -c " + shellQuote(script) + "\n")
+	b.WriteString("status=$?\n")
+	b.WriteString("printf '\\n%s exited with status %d. Press Enter to continue...' " + shellQuote(name) + " \"$status\"\n")
+	b.WriteString("read _\n")
+	b.WriteString("exit $status\n")
+
+	file, err := os.CreateTemp("", "jean-run-*.sh")
+	if err != nil {
+		return "", fmt.Errorf("failed to create script runner: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.WriteString(b.String()); err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("failed to write script runner: %w", err)
+	}
+	return file.Name(), nil
+}
+
+// shellQuote quotes a string for use as a single word in sh
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
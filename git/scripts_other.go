@@ -0,0 +1,21 @@
+//go:build !unix
+
+package git
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+)
+
+// setProcessGroup leaves the command as is: there are no process groups to start it in
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// terminateProcessGroup kills the process; what it started keeps running without process groups
+// A process that's already gone isn't an error
+func terminateProcessGroup(process *os.Process) error {
+	if err := process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+//go:build unix
+
+package git
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// processGone reports whether a process has exited (zombies that nobody reaped count as gone)
+func processGone(pid int) bool {
+	if err := syscall.Kill(pid, 0); err != nil {
+		return true
+	}
+	stat, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return false
+	}
+	// The state follows the parenthesized command name
+	fields := strings.Fields(string(stat[strings.LastIndexByte(string(stat), ')')+1:]))
+	return len(fields) > 0 && fields[0] == "Z"
+}
+
+// TestStartScript tests that a background script's output lands in its log with the JEAN_* variables
+func TestStartScript(t *testing.T) {
+	repo := newTestRepo(t, map[string]string{"jean.json": `{"scripts": {"hello": "echo out; echo err >&2; echo $JEAN_BRANCH $JEAN_SCRIPT"}}`})
+	m := NewManager(repo)
+
+	run, err := m.StartScript(repo, "main", "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := run.Wait(); err != nil {
+		t.Fatalf("Expected the script to succeed, got %v", err)
+	}
+	if run.Running() || run.Stopped() || run.Err() != nil {
+		t.Errorf("Expected a finished successful run, got running=%v stopped=%v err=%v", run.Running(), run.Stopped(), run.Err())
+	}
+	lines, err := ReadLog(run.LogPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(lines, "|"); got != "out|err|main hello" {
+		t.Errorf("Expected stdout and stderr in the log, got %q", got)
+	}
+
+	// The next run replaces the log
+	writeFiles(t, repo, map[string]string{"jean.json": `{"scripts": {"hello": "echo again"}}`})
+	run, err = m.StartScript(repo, "main", "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = run.Wait()
+	if lines, _ := ReadLog(run.LogPath); strings.Join(lines, "|") != "again" {
+		t.Errorf("Expected the log of the previous run to be replaced, got %q", lines)
+	}
+
+	if _, err := m.StartScript(repo, "main", "missing"); err == nil {
+		t.Error("Expected an error for a script that isn't in jean.json")
+	}
+}
+
+// TestScriptRunErr tests that a failing script's exit status is reported through Err
+func TestScriptRunErr(t *testing.T) {
+	repo := newTestRepo(t, map[string]string{"jean.json": `{"scripts": {"fail": "exit 3"}}`})
+
+	run, err := NewManager(repo).StartScript(repo, "main", "fail")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = run.Wait()
+	var exitErr *exec.ExitError
+	if !errors.As(run.Err(), &exitErr) || exitErr.ExitCode() != 3 {
+		t.Errorf("Expected exit status 3, got %v", run.Err())
+	}
+	if run.Stopped() {
+		t.Error("Expected a script that failed on its own not to count as stopped")
+	}
+}
+
+// TestScriptRunStop tests that Stop ends the script together with the processes it started
+func TestScriptRunStop(t *testing.T) {
+	repo := newTestRepo(t, map[string]string{"jean.json": `{"scripts": {"serve": "sleep 30 & echo $! > child.pid; wait"}}`})
+
+	run, err := NewManager(repo).StartScript(repo, "main", "serve")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var child int
+	if !waitFor(func() bool {
+		data, _ := os.ReadFile(filepath.Join(repo, "child.pid"))
+		child, _ = strconv.Atoi(strings.TrimSpace(string(data)))
+		return child > 0
+	}) {
+		t.Fatal("Expected the script to start its child process")
+	}
+	if !run.Running() {
+		t.Fatal("Expected the script to still be running")
+	}
+
+	if err := run.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- run.Wait() }()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("Expected a stopped script to report the signal")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the script to exit after Stop")
+	}
+	if !run.Stopped() {
+		t.Error("Expected the run to count as stopped")
+	}
+	if !waitFor(func() bool { return processGone(child) }) {
+		syscall.Kill(child, syscall.SIGKILL)
+		t.Error("Expected Stop to also end the script's child process")
+	}
+	if err := run.Stop(); err != nil {
+		t.Errorf("Expected stopping a finished script to be a no-op, got %v", err)
+	}
+}
+
+// TestStartSetup tests that setup runs on the worktree's branch, and is skipped when not configured
+func TestStartSetup(t *testing.T) {
+	repo := newTestRepo(t, nil)
+	path := filepath.Join(t.TempDir(), "feature")
+	runGit(t, repo, "worktree", "add", "-q", "-b", "feature", path)
+	m := NewManager(repo)
+
+	if run, err := m.StartSetup(path); run != nil || err != nil {
+		t.Fatalf("Expected no run without a setup script, got %v, %v", run, err)
+	}
+
+	writeFiles(t, repo, map[string]string{"jean.json": `{"scripts": {"setup": "echo $JEAN_BRANCH; pwd"}}`})
+	run, err := m.StartSetup(path)
+	if err != nil || run == nil {
+		t.Fatalf("Expected setup to start, got %v, %v", run, err)
+	}
+	if err := run.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if run.Name != SetupScript || run.Branch != "feature" || run.Path != path {
+		t.Errorf("Unexpected run: %+v", run)
+	}
+	if lines, _ := ReadLog(run.LogPath); strings.Join(lines, "|") != "feature|"+path {
+		t.Errorf("Expected setup to run in the worktree, got %q", lines)
+	}
+}
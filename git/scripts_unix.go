@@ -0,0 +1,23 @@
+//go:build unix
+
+package git
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup starts the command as the leader of its own process group
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// terminateProcessGroup sends SIGTERM to the process group led by a process
+// A group that's already gone isn't an error
+func terminateProcessGroup(process *os.Process) error {
+	if err := syscall.Kill(-process.Pid, syscall.SIGTERM); err != nil && err != syscall.ESRCH {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Stash represents a single entry of the repository's stash list
+// Stashes are shared by all worktrees; Branch records which branch the stash was taken on
+type Stash struct {
+	Ref     string // Stash reference (e.g. "stash@{0}")
+	Branch  string // Branch the stash was created on ("" if unknown)
+	Message string // Stash message (custom message or "WIP" summary)
+	Date    string // Relative creation date (e.g. "2 hours ago")
+}
+
+// ListStashes returns all stash entries, most recent first
+func (m *Manager) ListStashes(worktreePath string) ([]Stash, error) {
+	cmd := exec.Command("git", "-C", worktreePath, "stash", "list", "--format=%gd%x00%gs%x00%cr")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stashes: %w", err)
+	}
+
+	var stashes []Stash
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		// Format: stash@{N}\x00<subject>\x00<relative date>
+		fields := strings.SplitN(line, "\x00", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		branch, message := parseStashSubject(fields[1])
+		stashes = append(stashes, Stash{
+			Ref:     fields[0],
+			Branch:  branch,
+			Message: message,
+			Date:    fields[2],
+		})
+	}
+	return stashes, nil
+}
+
+// parseStashSubject splits a stash subject into branch and message
+// Subjects look like "On <branch>: <message>" or "WIP on <branch>: <sha> <commit title>"
+func parseStashSubject(subject string) (string, string) {
+	rest := subject
+	switch {
+	case strings.HasPrefix(rest, "WIP on "):
+		rest = strings.TrimPrefix(rest, "WIP on ")
+	case strings.HasPrefix(rest, "On "):
+		rest = strings.TrimPrefix(rest, "On ")
+	default:
+		return "", subject
+	}
+
+	branch, message, ok := strings.Cut(rest, ": ")
+	if !ok {
+		return "", subject
+	}
+	if strings.HasPrefix(subject, "WIP on ") {
+		message = "WIP: " + message
+	}
+	return branch, message
+}
+
+// PushStash stashes the worktree's uncommitted changes (including untracked files) with a message
+func (m *Manager) PushStash(worktreePath, message string) error {
+	args := []string{"-C", worktreePath, "stash", "push", "--include-untracked"}
+	if message != "" {
+		args = append(args, "-m", message)
+	}
+
+	cmd := exec.Command("git", args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to stash changes: %s", string(output))
+	}
+	if strings.Contains(string(output), "No local changes to save") {
+		return fmt.Errorf("no local changes to stash")
+	}
+	return nil
+}
+
+// StashCommit returns the commit SHA of a stash entry
+// Unlike stash@{N}, the SHA keeps identifying the entry when other stashes are pushed or dropped
+func (m *Manager) StashCommit(worktreePath, ref string) (string, error) {
+	cmd := exec.Command("git", "-C", worktreePath, "rev-parse", "--verify", "--quiet", ref)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve stash %s: %w", ref, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// ApplyStash applies a stash to the worktree, keeping it in the stash list
+func (m *Manager) ApplyStash(worktreePath, ref string) error {
+	return m.restoreStash(worktreePath, "apply", ref)
+}
+
+// PopStash applies a stash to the worktree and drops it
+// If applying conflicts, the stash is kept so nothing is lost
+func (m *Manager) PopStash(worktreePath, ref string) error {
+	return m.restoreStash(worktreePath, "pop", ref)
+}
+
+// restoreStash runs `git stash apply|pop` and reports conflicts separately from other failures
+func (m *Manager) restoreStash(worktreePath, action, ref string) error {
+	cmd := exec.Command("git", "-C", worktreePath, "stash", action, ref)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		outputStr := string(output)
+		if strings.Contains(outputStr, "CONFLICT") {
+			return fmt.Errorf("stash conflict occurred while applying %s. The stash was kept; resolve the conflicts and drop it manually", ref)
+		}
+		return fmt.Errorf("failed to %s stash: %s", action, outputStr)
+	}
+	return nil
+}
+
+// DropStash removes a stash from the stash list
+func (m *Manager) DropStash(worktreePath, ref string) error {
+	cmd := exec.Command("git", "-C", worktreePath, "stash", "drop", ref)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to drop stash: %s", string(output))
+	}
+	return nil
+}
+
+// DropStashCommit removes the stash entry whose commit is sha, wherever it is in the stash list
+func (m *Manager) DropStashCommit(worktreePath, sha string) error {
+	cmd := exec.Command("git", "-C", worktreePath, "stash", "list", "--format=%H %gd")
+	output, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("failed to list stashes: %w", err)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		commit, ref, ok := strings.Cut(line, " ")
+		if ok && commit == sha {
+			return m.DropStash(worktreePath, ref)
+		}
+	}
+	return fmt.Errorf("stash %s is no longer in the stash list", sha)
+}
+
+// ShowStash returns the diff stat and patch of a stash
+func (m *Manager) ShowStash(worktreePath, ref string) (string, error) {
+	cmd := exec.Command("git", "-C", worktreePath, "stash", "show", "--stat", "-p", "--include-untracked", ref)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("failed to show stash: %s", string(output))
+	}
+	return string(output), nil
+}
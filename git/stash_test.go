@@ -0,0 +1,47 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStashCommitSurvivesNewerStashes(t *testing.T) {
+	repo := newTestRepo(t, nil)
+	m := NewManager(repo)
+	readme := filepath.Join(repo, "README.md")
+
+	if err := os.WriteFile(readme, []byte("auto\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, repo, "stash", "push", "-q", "-m", "auto")
+	sha, err := m.StashCommit(repo, "stash@{0}")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Another stash lands on top before the first one is restored
+	if err := os.WriteFile(readme, []byte("other\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, repo, "stash", "push", "-q", "-m", "other")
+
+	if err := m.ApplyStash(repo, sha); err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := os.ReadFile(readme); string(content) != "auto\n" {
+		t.Errorf("Expected the recorded stash to be applied, got %q", content)
+	}
+	if err := m.DropStashCommit(repo, sha); err != nil {
+		t.Fatal(err)
+	}
+
+	list := runGit(t, repo, "stash", "list", "--format=%gs")
+	if strings.Contains(list, "auto") || !strings.Contains(list, "other") {
+		t.Errorf("Expected only the other stash to remain, got %q", list)
+	}
+	if err := m.DropStashCommit(repo, sha); err == nil {
+		t.Error("Expected dropping an already dropped stash to fail")
+	}
+}
@@ -0,0 +1,326 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	gover "github.com/hashicorp/go-version"
+)
+
+// DefaultStatusConcurrency is the number of worktrees inspected in parallel when no limit is configured
+const DefaultStatusConcurrency = 4
+
+// WorktreeStatus holds the expensive status fields computed for a single worktree
+type WorktreeStatus struct {
+	Index          int    // Index of the worktree in the slice passed to CollectStatus
+	Path           string // Worktree path (used to match results after the list is re-sorted)
+	HasUncommitted bool
+	AheadCount     int
+	BehindCount    int
+	Err            error // First error encountered while collecting status (partial results are still set)
+}
+
+// aheadBehindMinVersion is the first git release supporting %(ahead-behind:<committish>) in for-each-ref
+var aheadBehindMinVersion = gover.Must(gover.NewVersion("2.41.0"))
+
+// BranchStatus holds the ahead/behind counts of a branch relative to the base branch
+type BranchStatus struct {
+	Ahead  int // Commits in the branch not in base
+	Behind int // Commits in base not in the branch
+}
+
+// SetStatusConcurrency sets the maximum number of worktrees whose status is collected in parallel
+// Values <= 0 reset the limit to DefaultStatusConcurrency
+func (m *Manager) SetStatusConcurrency(n int) {
+	m.statusConcurrency = n
+}
+
+// getStatusConcurrency returns the configured worker pool size, falling back to the default
+func (m *Manager) getStatusConcurrency() int {
+	if m.statusConcurrency > 0 {
+		return m.statusConcurrency
+	}
+	return DefaultStatusConcurrency
+}
+
+// CollectStatus computes uncommitted changes and ahead/behind counts for each worktree.
+// Ahead/behind counts for all branches are computed up front in a single GetBranchStatuses pass,
+// then the per-worktree `git status` checks run on a bounded worker pool. Results are sent on the
+// returned channel as soon as each worktree finishes, so callers can update progressively. The
+// channel is closed once every worktree has been processed or ctx is cancelled; git processes still
+// running at cancellation time are killed.
+func (m *Manager) CollectStatus(ctx context.Context, worktrees []Worktree, baseBranch string) <-chan WorktreeStatus {
+	results := make(chan WorktreeStatus, len(worktrees))
+
+	workers := m.getStatusConcurrency()
+	if workers > len(worktrees) {
+		workers = len(worktrees)
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	go func() {
+		defer close(results)
+
+		// Batch ahead/behind for every branch before fanning out
+		var branchStatuses map[string]BranchStatus
+		var branchErr error
+		if baseBranch != "" {
+			branchStatuses, branchErr = m.GetBranchStatuses(ctx, baseBranch, worktreeBranches(worktrees))
+		}
+
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range jobs {
+					status := m.collectWorktreeStatus(ctx, worktrees[i], baseBranch, branchStatuses, branchErr)
+					status.Index = i
+					select {
+					case results <- status:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}()
+		}
+
+		defer wg.Wait()
+		defer close(jobs)
+		for i := range worktrees {
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return results
+}
+
+// worktreeBranches returns the branch names of all non-detached worktrees
+func worktreeBranches(worktrees []Worktree) []string {
+	branches := make([]string, 0, len(worktrees))
+	for _, wt := range worktrees {
+		if wt.Branch == "" || strings.HasPrefix(wt.Branch, "(detached") {
+			continue
+		}
+		branches = append(branches, wt.Branch)
+	}
+	return branches
+}
+
+// collectWorktreeStatus runs the status checks for a single worktree
+// Ahead/behind counts are taken from the precomputed branch statuses
+func (m *Manager) collectWorktreeStatus(ctx context.Context, wt Worktree, baseBranch string, branchStatuses map[string]BranchStatus, branchErr error) WorktreeStatus {
+	status := WorktreeStatus{Path: wt.Path}
+
+	hasUncommitted, err := m.hasUncommittedChangesContext(ctx, wt.Path)
+	if err != nil {
+		status.Err = err
+	} else {
+		status.HasUncommitted = hasUncommitted
+	}
+
+	// Skip detached HEAD worktrees and missing base branch
+	if baseBranch == "" || strings.HasPrefix(wt.Branch, "(detached") {
+		return status
+	}
+
+	if branchErr != nil {
+		if status.Err == nil {
+			status.Err = branchErr
+		}
+		return status
+	}
+	if bs, ok := branchStatuses[wt.Branch]; ok {
+		status.AheadCount = bs.Ahead
+		status.BehindCount = bs.Behind
+	}
+
+	return status
+}
+
+// GetBranchStatuses returns ahead/behind counts against baseBranch for every given local branch.
+// On git >= 2.41 all counts come from a single `git for-each-ref` pass using %(ahead-behind:<base>);
+// older versions fall back to one `rev-list --left-right --count` per branch.
+// Branches that don't exist locally are omitted from the result.
+func (m *Manager) GetBranchStatuses(ctx context.Context, baseBranch string, branches []string) (map[string]BranchStatus, error) {
+	if baseBranch == "" {
+		return nil, fmt.Errorf("base branch not specified")
+	}
+
+	// Check if base branch exists
+	cmd := exec.CommandContext(ctx, "git", "-C", m.repoPath, "rev-parse", "--verify", "--quiet", baseBranch)
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("base branch '%s' does not exist", baseBranch)
+	}
+
+	statuses := make(map[string]BranchStatus, len(branches))
+	if len(branches) == 0 {
+		return statuses, nil
+	}
+
+	if m.supportsAheadBehind() {
+		err := m.branchStatusesForEachRef(ctx, baseBranch, branches, statuses)
+		if err == nil {
+			return statuses, nil
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		// Fall through to the per-branch fallback if the batch call failed
+	}
+
+	for _, branch := range branches {
+		ahead, behind, err := m.branchStatusRevList(ctx, baseBranch, branch)
+		if err != nil {
+			// Branch might not exist locally (e.g. worktree being created), skip it
+			continue
+		}
+		statuses[branch] = BranchStatus{Ahead: ahead, Behind: behind}
+	}
+
+	return statuses, nil
+}
+
+// branchStatusesForEachRef fills statuses using a single for-each-ref call with %(ahead-behind:<base>)
+func (m *Manager) branchStatusesForEachRef(ctx context.Context, baseBranch string, branches []string, statuses map[string]BranchStatus) error {
+	wanted := make(map[string]bool, len(branches))
+	args := []string{"-C", m.repoPath, "for-each-ref",
+		fmt.Sprintf("--format=%%(refname) %%(ahead-behind:%s)", baseBranch)}
+	for _, branch := range branches {
+		wanted[branch] = true
+		args = append(args, "refs/heads/"+branch)
+	}
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	output, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("failed to get branch statuses: %w", err)
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		// Format: refs/heads/<branch> <ahead> <behind>
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+		branch := strings.TrimPrefix(fields[0], "refs/heads/")
+		if !wanted[branch] {
+			continue
+		}
+		ahead, err := parseCount(fields[1])
+		if err != nil {
+			continue
+		}
+		behind, err := parseCount(fields[2])
+		if err != nil {
+			continue
+		}
+		statuses[branch] = BranchStatus{Ahead: ahead, Behind: behind}
+	}
+
+	return nil
+}
+
+// branchStatusRevList computes ahead/behind for a single branch with one rev-list call
+func (m *Manager) branchStatusRevList(ctx context.Context, baseBranch, branch string) (int, int, error) {
+	cmd := exec.CommandContext(ctx, "git", "-C", m.repoPath, "rev-list", "--left-right", "--count", fmt.Sprintf("%s...%s", baseBranch, branch))
+	output, err := cmd.Output()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get branch status: %w", err)
+	}
+
+	// Output: <behind>\t<ahead> (left side is base, right side is branch)
+	fields := strings.Fields(string(output))
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("unexpected rev-list output: %s", strings.TrimSpace(string(output)))
+	}
+	behind, err := parseCount(fields[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	ahead, err := parseCount(fields[1])
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return ahead, behind, nil
+}
+
+// supportsAheadBehind reports whether the installed git understands %(ahead-behind:...)
+// The version is detected once per Manager
+func (m *Manager) supportsAheadBehind() bool {
+	m.gitVersionOnce.Do(func() {
+		output, err := exec.Command("git", "version").Output()
+		if err != nil {
+			return
+		}
+		// Format: "git version 2.42.0" (optionally followed by vendor suffixes)
+		fields := strings.Fields(string(output))
+		if len(fields) < 3 {
+			return
+		}
+		v, err := gover.NewVersion(fields[2])
+		if err != nil {
+			return
+		}
+		m.hasAheadBehind = v.Core().GreaterThanOrEqual(aheadBehindMinVersion)
+	})
+	return m.hasAheadBehind
+}
+
+// hasUncommittedChangesContext is HasUncommittedChanges with cancellation support
+func (m *Manager) hasUncommittedChangesContext(ctx context.Context, worktreePath string) (bool, error) {
+	// Check for staged and unstaged changes
+	cmd := exec.CommandContext(ctx, "git", "-C", worktreePath, "status", "--porcelain")
+	// Don't refresh the index as a side effect; that would wake up the filesystem watcher again
+	cmd.Env = append(os.Environ(), "GIT_OPTIONAL_LOCKS=0")
+	output, err := cmd.Output()
+	if err != nil {
+		return false, fmt.Errorf("failed to check git status: %w", err)
+	}
+
+	// If output is not empty, there are uncommitted changes
+	return len(strings.TrimSpace(string(output))) > 0, nil
+}
+
+// getBranchStatusContext is GetBranchStatus with cancellation support
+func (m *Manager) getBranchStatusContext(ctx context.Context, worktreePath, branch, baseBranch string) (int, int, error) {
+	if baseBranch == "" {
+		return 0, 0, fmt.Errorf("base branch not specified")
+	}
+
+	// Check if base branch exists
+	cmd := exec.CommandContext(ctx, "git", "-C", worktreePath, "rev-parse", "--verify", baseBranch)
+	if err := cmd.Run(); err != nil {
+		return 0, 0, fmt.Errorf("base branch '%s' does not exist", baseBranch)
+	}
+
+	// Get ahead count (commits in current branch not in base)
+	cmd = exec.CommandContext(ctx, "git", "-C", worktreePath, "rev-list", "--count", fmt.Sprintf("%s..%s", baseBranch, branch))
+	output, err := cmd.Output()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get ahead count: %w", err)
+	}
+	aheadCount := 0
+	fmt.Sscanf(strings.TrimSpace(string(output)), "%d", &aheadCount)
+
+	// Get behind count (commits in base not in current branch)
+	cmd = exec.CommandContext(ctx, "git", "-C", worktreePath, "rev-list", "--count", fmt.Sprintf("%s..%s", branch, baseBranch))
+	output, err = cmd.Output()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get behind count: %w", err)
+	}
+	behindCount := 0
+	fmt.Sscanf(strings.TrimSpace(string(output)), "%d", &behindCount)
+
+	return aheadCount, behindCount, nil
+}
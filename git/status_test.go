@@ -0,0 +1,78 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCollectStatus(t *testing.T) {
+	repo := newTestRepo(t, nil)
+	feature := filepath.Join(t.TempDir(), "feature")
+	runGit(t, repo, "worktree", "add", "-q", "-b", "feature", feature)
+	runGit(t, feature, "commit", "-q", "--allow-empty", "-m", "feature work")
+	if err := os.WriteFile(filepath.Join(repo, "README.md"), []byte("changed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	worktrees := []Worktree{
+		{Path: repo, Branch: "main"},
+		{Path: feature, Branch: "feature"},
+		{Path: filepath.Join(t.TempDir(), "gone"), Branch: "gone"},
+	}
+	m := NewManager(repo)
+	m.SetStatusConcurrency(1) // Fewer workers than worktrees
+	results := map[int]WorktreeStatus{}
+	for status := range m.CollectStatus(context.Background(), worktrees, "main") {
+		results[status.Index] = status
+	}
+
+	if len(results) != len(worktrees) {
+		t.Fatalf("Expected a result per worktree, got %v", results)
+	}
+	if main := results[0]; !main.HasUncommitted || main.Path != repo || main.Err != nil {
+		t.Errorf("Expected main to be dirty, got %+v", main)
+	}
+	if got := results[1]; got.HasUncommitted || got.AheadCount != 1 || got.BehindCount != 0 {
+		t.Errorf("Expected feature to be clean and 1 ahead, got %+v", got)
+	}
+	if results[2].Err == nil {
+		t.Error("Expected an error for a worktree whose directory is missing")
+	}
+}
+
+func TestGetBranchStatuses(t *testing.T) {
+	repo := newTestRepo(t, nil)
+	runGit(t, repo, "branch", "feature")
+	runGit(t, repo, "branch", "stale")
+	runGit(t, repo, "commit", "-q", "--allow-empty", "-m", "main work")
+	runGit(t, repo, "checkout", "-q", "feature")
+	runGit(t, repo, "commit", "-q", "--allow-empty", "-m", "feature 1")
+	runGit(t, repo, "commit", "-q", "--allow-empty", "-m", "feature 2")
+
+	m := NewManager(repo)
+	statuses, err := m.GetBranchStatuses(context.Background(), "main", []string{"feature", "stale", "missing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]BranchStatus{"feature": {Ahead: 2, Behind: 1}, "stale": {Ahead: 0, Behind: 1}}
+	if len(statuses) != len(want) || statuses["feature"] != want["feature"] || statuses["stale"] != want["stale"] {
+		t.Errorf("GetBranchStatuses = %v, want %v", statuses, want)
+	}
+
+	// The single for-each-ref pass (git >= 2.41) must agree with the per-branch fallback
+	if m.supportsAheadBehind() {
+		batched := map[string]BranchStatus{}
+		if err := m.branchStatusesForEachRef(context.Background(), "main", []string{"feature", "stale", "missing"}, batched); err != nil {
+			t.Fatal(err)
+		}
+		if len(batched) != len(want) || batched["feature"] != want["feature"] || batched["stale"] != want["stale"] {
+			t.Errorf("for-each-ref statuses = %v, want %v", batched, want)
+		}
+	}
+
+	if _, err := m.GetBranchStatuses(context.Background(), "nope", []string{"feature"}); err == nil {
+		t.Error("Expected an error for a missing base branch")
+	}
+}
@@ -0,0 +1,353 @@
+package git
+
+import (
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultWatchDebounce is how long the watcher waits for a burst of filesystem events to settle
+const DefaultWatchDebounce = 300 * time.Millisecond
+
+// maxWatchedDirsPerWorktree caps the number of working tree directories watched per worktree
+// so a single huge checkout can't exhaust the inotify watch limit
+const maxWatchedDirsPerWorktree = 2048
+
+// Watcher watches each worktree's working tree and gitdir (HEAD, index, refs) and reports
+// debounced per-worktree change notifications. Paths sent on Events() are worktree paths.
+type Watcher struct {
+	fsw      *fsnotify.Watcher
+	debounce time.Duration
+	events   chan string
+	done     chan struct{}
+
+	mu        sync.Mutex
+	worktrees []string               // Worktree paths currently being watched
+	owners    map[string]string      // Watched directory -> owning worktree path ("" = shared by all worktrees)
+	gitDirs   map[string]bool        // Watched gitdirs and common dirs (only a few files inside matter)
+	timers    map[string]*time.Timer // Worktree path -> pending debounce timer
+	closeOnce sync.Once
+}
+
+// NewWatcher creates a filesystem watcher with the given debounce interval
+// A debounce <= 0 uses DefaultWatchDebounce
+func NewWatcher(debounce time.Duration) (*Watcher, error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if debounce <= 0 {
+		debounce = DefaultWatchDebounce
+	}
+
+	w := &Watcher{
+		fsw:      fsw,
+		debounce: debounce,
+		events:   make(chan string, 64),
+		done:     make(chan struct{}),
+		owners:   make(map[string]string),
+		gitDirs:  make(map[string]bool),
+		timers:   make(map[string]*time.Timer),
+	}
+	go w.run()
+	return w, nil
+}
+
+// Events returns the channel of debounced worktree paths that changed
+func (w *Watcher) Events() <-chan string {
+	return w.events
+}
+
+// Close stops the watcher and releases all inotify watches
+func (w *Watcher) Close() error {
+	var err error
+	w.closeOnce.Do(func() {
+		close(w.done)
+		err = w.fsw.Close()
+
+		w.mu.Lock()
+		for _, t := range w.timers {
+			t.Stop()
+		}
+		w.mu.Unlock()
+	})
+	return err
+}
+
+// SetWorktrees replaces the watched set with the given worktrees
+// Watches for worktrees no longer present are removed
+func (w *Watcher) SetWorktrees(worktrees []Worktree) {
+	owners := make(map[string]string)
+	gitDirs := make(map[string]bool)
+	paths := make([]string, 0, len(worktrees))
+
+	for _, wt := range worktrees {
+		paths = append(paths, wt.Path)
+
+		// Working tree directories (tracked files only, so node_modules and friends are skipped)
+		for _, dir := range watchableDirs(wt.Path) {
+			owners[dir] = wt.Path
+		}
+
+		// Per-worktree gitdir holds HEAD, index and MERGE_HEAD
+		gitDir, commonDir := resolveGitDirs(wt.Path)
+		if gitDir != "" {
+			owners[gitDir] = wt.Path
+			gitDirs[gitDir] = true
+		}
+
+		// Shared refs affect every worktree (branch updates, packed-refs rewrites)
+		if commonDir != "" {
+			if _, ok := owners[commonDir]; !ok {
+				owners[commonDir] = ""
+			}
+			gitDirs[commonDir] = true
+			for _, dir := range refDirs(filepath.Join(commonDir, "refs", "heads")) {
+				owners[dir] = ""
+			}
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// Drop watches that are no longer needed
+	for dir := range w.owners {
+		if _, ok := owners[dir]; !ok {
+			_ = w.fsw.Remove(dir)
+		}
+	}
+
+	// Add new watches (ignore failures, e.g. a directory removed in the meantime)
+	for dir := range owners {
+		if _, ok := w.owners[dir]; !ok {
+			if err := w.fsw.Add(dir); err != nil {
+				delete(owners, dir)
+			}
+		}
+	}
+
+	w.owners = owners
+	w.gitDirs = gitDirs
+	w.worktrees = paths
+}
+
+// run consumes raw fsnotify events until the watcher is closed
+func (w *Watcher) run() {
+	for {
+		select {
+		case <-w.done:
+			return
+		case event, ok := <-w.fsw.Events:
+			if !ok {
+				return
+			}
+			w.handleEvent(event)
+		case _, ok := <-w.fsw.Errors:
+			if !ok {
+				return
+			}
+			// Errors (e.g. queue overflow) are not fatal; the next event or refresh will catch up
+		}
+	}
+}
+
+// handleEvent maps a raw event to its worktree(s) and schedules a debounced notification
+func (w *Watcher) handleEvent(event fsnotify.Event) {
+	if event.Op == fsnotify.Chmod || isIgnoredGitFile(event.Name) {
+		return
+	}
+
+	dir := filepath.Dir(event.Name)
+	w.mu.Lock()
+	owner, ok := w.owners[dir]
+	inGitDir := w.gitDirs[dir]
+	w.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	// Events directly inside a gitdir or common dir only matter for a few files
+	if inGitDir && !isRelevantGitFile(filepath.Base(event.Name)) {
+		return
+	}
+
+	// Stop watching directories that were deleted or moved away (a move shows up again as a Create)
+	if event.Op&(fsnotify.Remove|fsnotify.Rename) != 0 {
+		w.unwatch(event.Name)
+	}
+
+	// Start watching directories created inside a watched working tree or refs directory,
+	// unless they're gitignored (node_modules, dist, .next, ... would take thousands of watches)
+	if event.Op&fsnotify.Create != 0 && !inGitDir && filepath.Base(event.Name) != ".git" {
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() && (owner == "" || !isGitIgnored(owner, event.Name)) {
+			w.watch(event.Name, owner)
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// packed-refs lives in the common dir and is shared by every worktree
+	if owner == "" || filepath.Base(event.Name) == "packed-refs" {
+		for _, path := range w.worktrees {
+			w.schedule(path)
+		}
+		return
+	}
+	w.schedule(owner)
+}
+
+// watch adds a watch for a new directory, unless its parent stopped being watched in the meantime
+func (w *Watcher) watch(dir, owner string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if parentOwner, ok := w.owners[filepath.Dir(dir)]; !ok || parentOwner != owner {
+		return
+	}
+	if _, ok := w.owners[dir]; ok || w.countOwned(owner) >= maxWatchedDirsPerWorktree {
+		return
+	}
+	if err := w.fsw.Add(dir); err == nil {
+		w.owners[dir] = owner
+	}
+}
+
+// unwatch drops the watches of a directory and everything below it
+func (w *Watcher) unwatch(dir string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	prefix := dir + string(filepath.Separator)
+	for watched := range w.owners {
+		if watched == dir || strings.HasPrefix(watched, prefix) {
+			_ = w.fsw.Remove(watched) // Already gone if the directory was deleted
+			delete(w.owners, watched)
+			delete(w.gitDirs, watched)
+		}
+	}
+}
+
+// schedule (re)starts the debounce timer for a worktree; must be called with w.mu held
+func (w *Watcher) schedule(path string) {
+	if t, ok := w.timers[path]; ok {
+		t.Reset(w.debounce)
+		return
+	}
+	w.timers[path] = time.AfterFunc(w.debounce, func() {
+		w.mu.Lock()
+		delete(w.timers, path)
+		w.mu.Unlock()
+
+		select {
+		case w.events <- path:
+		case <-w.done:
+		}
+	})
+}
+
+// countOwned returns how many directories are watched for a worktree; must be called with w.mu held
+func (w *Watcher) countOwned(path string) int {
+	count := 0
+	for _, owner := range w.owners {
+		if owner == path {
+			count++
+		}
+	}
+	return count
+}
+
+// watchableDirs returns the worktree root plus every directory containing tracked files
+func watchableDirs(worktreePath string) []string {
+	dirs := []string{worktreePath}
+
+	cmd := exec.Command("git", "-C", worktreePath, "ls-files", "-z")
+	output, err := cmd.Output()
+	if err != nil {
+		return dirs
+	}
+
+	seen := map[string]bool{worktreePath: true}
+	for _, file := range strings.Split(string(output), "\x00") {
+		if file == "" {
+			continue
+		}
+		// Add the file's directory and all of its parents
+		for dir := filepath.Dir(file); dir != "." && dir != "/"; dir = filepath.Dir(dir) {
+			abs := filepath.Join(worktreePath, dir)
+			if seen[abs] {
+				break
+			}
+			seen[abs] = true
+			dirs = append(dirs, abs)
+		}
+		if len(dirs) >= maxWatchedDirsPerWorktree {
+			break
+		}
+	}
+
+	return dirs
+}
+
+// resolveGitDirs returns the absolute per-worktree gitdir and the shared common dir
+func resolveGitDirs(worktreePath string) (string, string) {
+	cmd := exec.Command("git", "-C", worktreePath, "rev-parse", "--absolute-git-dir", "--git-common-dir")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", ""
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) != 2 {
+		return "", ""
+	}
+
+	gitDir := lines[0]
+	commonDir := lines[1]
+	if !filepath.IsAbs(commonDir) {
+		commonDir = filepath.Join(worktreePath, commonDir)
+	}
+	return filepath.Clean(gitDir), filepath.Clean(commonDir)
+}
+
+// refDirs returns a refs directory and all of its subdirectories (for branches like feature/x)
+func refDirs(root string) []string {
+	var dirs []string
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	return dirs
+}
+
+// isGitIgnored reports whether a path in a worktree is ignored by .gitignore or the exclude files
+func isGitIgnored(worktreePath, path string) bool {
+	cmd := exec.Command("git", "-C", worktreePath, "check-ignore", "-q", path)
+	return cmd.Run() == nil // Exit status 1 means not ignored
+}
+
+// isRelevantGitFile reports whether a file directly inside a gitdir affects worktree status
+func isRelevantGitFile(name string) bool {
+	switch name {
+	case "HEAD", "index", "MERGE_HEAD", "packed-refs":
+		return true
+	}
+	return false
+}
+
+// isIgnoredGitFile filters out lock files that git creates and removes around every write
+func isIgnoredGitFile(path string) bool {
+	return strings.HasSuffix(path, ".lock")
+}
@@ -0,0 +1,126 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// gitCommand returns a git command in dir with a committer identity and no user or system config
+func gitCommand(t *testing.T, dir string, args ...string) *exec.Cmd {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=jean", "GIT_AUTHOR_EMAIL=jean@example.com",
+		"GIT_COMMITTER_NAME=jean", "GIT_COMMITTER_EMAIL=jean@example.com",
+		"GIT_CONFIG_NOSYSTEM=1", "HOME="+t.TempDir(),
+	)
+	return cmd
+}
+
+// runGit runs a git command in dir and returns its trimmed output, failing the test on error
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	output, err := gitCommand(t, dir, args...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// newTestRepo creates a repository on main with one commit containing the given files
+func newTestRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+	runGit(t, dir, "init", "-q", "-b", "main")
+	if files == nil {
+		files = map[string]string{"README.md": "hello\n"}
+	}
+	writeFiles(t, dir, files)
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "commit", "-q", "-m", "initial")
+	return dir
+}
+
+// writeFiles writes files (path relative to dir -> content), creating missing directories
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// watching reports whether the watcher has a watch on dir
+func (w *Watcher) watching(dir string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, ok := w.owners[dir]
+	return ok
+}
+
+// waitFor polls cond until it holds or a second has passed
+func waitFor(cond func() bool) bool {
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if cond() {
+			return true
+		}
+	}
+	return cond()
+}
+
+func TestWatcherSkipsIgnoredDirectories(t *testing.T) {
+	repo := newTestRepo(t, map[string]string{".gitignore": "node_modules/\n", "src/main.go": "package main\n"})
+	w, err := NewWatcher(10 * time.Millisecond)
+	if err != nil {
+		t.Skipf("no filesystem watcher: %v", err)
+	}
+	defer w.Close()
+	w.SetWorktrees([]Worktree{{Path: repo, Branch: "main"}})
+	if !w.watching(filepath.Join(repo, "src")) {
+		t.Fatal("Expected the directory with tracked files to be watched")
+	}
+
+	ignored := filepath.Join(repo, "node_modules")
+	created := filepath.Join(repo, "lib")
+	for _, dir := range []string{ignored, created} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !waitFor(func() bool { return w.watching(created) }) {
+		t.Error("Expected a new directory to be watched")
+	}
+	if w.watching(ignored) {
+		t.Error("Expected a gitignored directory not to be watched")
+	}
+
+	if err := os.Rename(created, filepath.Join(t.TempDir(), "lib")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(filepath.Join(repo, "src")); err != nil {
+		t.Fatal(err)
+	}
+	if !waitFor(func() bool { return !w.watching(created) && !w.watching(filepath.Join(repo, "src")) }) {
+		t.Error("Expected moved and deleted directories to be unwatched")
+	}
+
+	select {
+	case path := <-w.Events():
+		if path != repo {
+			t.Errorf("Expected changes to be reported for %s, got %s", repo, path)
+		}
+	case <-time.After(time.Second):
+		t.Error("Expected a change notification")
+	}
+}
@@ -1,6 +1,7 @@
 package git
 
 import (
+	"context"
 	"crypto/rand"
 	"fmt"
 	"io"
@@ -9,6 +10,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/coollabsio/jean-tui/config"
@@ -20,6 +22,7 @@ type Worktree struct {
 	Branch            string
 	Commit            string
 	IsCurrent         bool
+	IsMain            bool             // The repository's main worktree (not created with "git worktree add")
 	BehindCount       int              // Commits behind base branch
 	AheadCount        int              // Commits ahead of base branch
 	IsOutdated        bool             // Convenience flag: true if behind > 0
@@ -28,11 +31,24 @@ type Worktree struct {
 	LastModified      time.Time        // Last modification time of the worktree directory
 	ClaudeSessionName string           // Sanitized session name for Claude (e.g., "jean-feature-add-status")
 	RecentCommits     []string         // Last 5 commit titles for this worktree
+	IsLocked          bool             // Worktree is locked ("git worktree lock") and won't be pruned
+	LockReason        string           // Reason given when locking, if any
+	IsPrunable        bool             // Worktree directory is gone; "git worktree prune" would remove it
+	PruneReason       string           // Why git considers the worktree prunable
 }
 
 // Manager handles Git worktree operations
 type Manager struct {
-	repoPath string
+	repoPath          string
+	statusConcurrency int       // Max worktrees inspected in parallel by CollectStatus (0 = DefaultStatusConcurrency)
+	gitVersionOnce    sync.Once // Guards detection of git features below
+	hasAheadBehind    bool      // Whether for-each-ref supports %(ahead-behind:...) (git >= 2.41)
+	pushRemote        string    // Remote branches are pushed to ("" = DefaultRemote)
+	pathTemplate      string    // Template for new worktree paths ("" = DefaultPathTemplate)
+	baseRemote        string    // Remote the base branch comes from ("" = push remote)
+
+	// Returns the first port of a branch's block (nil = no JEAN_PORT variables)
+	portAllocator func(branch string) (int, error)
 }
 
 // NewManager creates a new worktree manager
@@ -42,43 +58,37 @@ func NewManager(repoPath string) *Manager {
 
 // List returns all worktrees in the repository with status relative to the base branch
 func (m *Manager) List(baseBranch string) ([]Worktree, error) {
-	cmd := exec.Command("git", "-C", m.repoPath, "worktree", "list", "--porcelain")
-	output, err := cmd.Output()
-	if err != nil {
-		return nil, fmt.Errorf("failed to list worktrees: %w", err)
-	}
-
-	return m.parseWorktrees(string(output), baseBranch, false)
+	return m.ListContext(context.Background(), baseBranch, false)
 }
 
 // ListWithLightweight returns all worktrees with optional lightweight mode
 // When lightweight=true, skips expensive status checks (uncommitted changes, ahead/behind counts)
 // for faster initial loading. Status can be loaded asynchronously afterwards.
 func (m *Manager) ListWithLightweight(baseBranch string, lightweight bool) ([]Worktree, error) {
-	cmd := exec.Command("git", "-C", m.repoPath, "worktree", "list", "--porcelain")
-	output, err := cmd.Output()
-	if err != nil {
-		return nil, fmt.Errorf("failed to list worktrees: %w", err)
-	}
-
-	return m.parseWorktrees(string(output), baseBranch, lightweight)
+	return m.ListContext(context.Background(), baseBranch, lightweight)
 }
 
 // ListLightweight returns all worktrees without expensive status checks (for quick refreshes)
 func (m *Manager) ListLightweight() ([]Worktree, error) {
-	cmd := exec.Command("git", "-C", m.repoPath, "worktree", "list", "--porcelain")
+	// Pass empty baseBranch to skip status calculations
+	return m.ListContext(context.Background(), "", true)
+}
+
+// ListContext returns all worktrees, collecting status in parallel unless lightweight is set
+// Cancelling ctx stops any in-flight status checks; worktrees are still returned without status
+func (m *Manager) ListContext(ctx context.Context, baseBranch string, lightweight bool) ([]Worktree, error) {
+	cmd := exec.CommandContext(ctx, "git", "-C", m.repoPath, "worktree", "list", "--porcelain")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("failed to list worktrees: %w", err)
 	}
 
-	// Pass empty baseBranch to skip status calculations
-	return m.parseWorktrees(string(output), "", true)
+	return m.parseWorktrees(ctx, string(output), baseBranch, lightweight)
 }
 
 // parseWorktrees parses the output of 'git worktree list --porcelain' and calculates branch status
 // When lightweight=true, skips expensive checks like uncommitted changes and ahead/behind counts
-func (m *Manager) parseWorktrees(output string, baseBranch string, lightweight bool) ([]Worktree, error) {
+func (m *Manager) parseWorktrees(ctx context.Context, output string, baseBranch string, lightweight bool) ([]Worktree, error) {
 	var worktrees []Worktree
 	var current Worktree
 
@@ -100,24 +110,27 @@ func (m *Manager) parseWorktrees(output string, baseBranch string, lightweight b
 			continue
 		}
 
-		parts := strings.SplitN(line, " ", 2)
-		if len(parts) < 2 {
-			continue
-		}
-
-		key := parts[0]
-		value := parts[1]
+		// Attributes like "locked" and "detached" may come without a value
+		key, value, _ := strings.Cut(line, " ")
 
 		switch key {
 		case "worktree":
 			current.Path = value
+			// git always lists the main worktree first
+			current.IsMain = len(worktrees) == 0
 		case "HEAD":
 			current.Commit = value
 		case "branch":
 			// Remove "refs/heads/" prefix
 			current.Branch = strings.TrimPrefix(value, "refs/heads/")
 		case "detached":
-			current.Branch = fmt.Sprintf("(detached at %s)", current.Commit[:7])
+			current.Branch = fmt.Sprintf("(detached at %s)", current.Commit[:min(7, len(current.Commit))])
+		case "locked":
+			current.IsLocked = true
+			current.LockReason = value
+		case "prunable":
+			current.IsPrunable = true
+			current.PruneReason = value
 		}
 	}
 
@@ -144,34 +157,14 @@ func (m *Manager) parseWorktrees(output string, baseBranch string, lightweight b
 		}
 	}
 
-	// Check for uncommitted changes in each worktree (skip if lightweight mode)
+	// Collect uncommitted changes and branch status in parallel (skip if lightweight mode)
 	if !lightweight {
-		for i := range worktrees {
-			hasUncommitted, err := m.HasUncommittedChanges(worktrees[i].Path)
-			if err == nil {
-				worktrees[i].HasUncommitted = hasUncommitted
-			}
-		}
-	}
-
-	// Calculate branch status relative to base branch (skip if lightweight mode)
-	if !lightweight && baseBranch != "" {
-		for i := range worktrees {
-			// Skip detached HEAD and main repo worktrees
-			if strings.HasPrefix(worktrees[i].Branch, "(detached") {
-				continue
-			}
-
-			// Skip if we can't get branch status (base branch might not exist locally)
-			aheadCount, behindCount, err := m.GetBranchStatus(worktrees[i].Path, worktrees[i].Branch, baseBranch)
-			if err != nil {
-				// Silent skip - base branch might not exist locally or there might be other issues
-				continue
-			}
-
-			worktrees[i].AheadCount = aheadCount
-			worktrees[i].BehindCount = behindCount
-			worktrees[i].IsOutdated = behindCount > 0
+		for status := range m.CollectStatus(ctx, worktrees, baseBranch) {
+			wt := &worktrees[status.Index]
+			wt.HasUncommitted = status.HasUncommitted
+			wt.AheadCount = status.AheadCount
+			wt.BehindCount = status.BehindCount
+			wt.IsOutdated = status.BehindCount > 0
 		}
 	}
 
@@ -199,13 +192,14 @@ func (m *Manager) GetCurrentBranch() (string, error) {
 
 // GetDefaultBranch tries to determine the default branch (main, master, etc.)
 func (m *Manager) GetDefaultBranch() (string, error) {
-	// First try to get the default branch from remote
-	cmd := exec.Command("git", "-C", m.repoPath, "symbolic-ref", "refs/remotes/origin/HEAD")
+	// First try to get the default branch from the base remote
+	remotePrefix := "refs/remotes/" + m.BaseRemote() + "/"
+	cmd := exec.Command("git", "-C", m.repoPath, "symbolic-ref", remotePrefix+"HEAD")
 	output, err := cmd.Output()
 	if err == nil {
 		// Extract branch name from refs/remotes/origin/HEAD -> refs/remotes/origin/main
 		branch := strings.TrimSpace(string(output))
-		branch = strings.TrimPrefix(branch, "refs/remotes/origin/")
+		branch = strings.TrimPrefix(branch, remotePrefix)
 		if branch != "" {
 			return branch, nil
 		}
@@ -245,25 +239,10 @@ func (m *Manager) getCurrentPath() (string, error) {
 }
 
 // sanitizeBranchForPath converts a branch name to a safe directory name
-// Strips origin/ prefix and replaces slashes with hyphens
+// Replaces slashes with hyphens; remote prefixes must already be stripped
 func sanitizeBranchForPath(branch string) string {
-	// Strip origin/ prefix for remote tracking branches
-	path := strings.TrimPrefix(branch, "origin/")
-	// Replace remaining slashes with hyphens to avoid nested directories
-	path = strings.ReplaceAll(path, "/", "-")
-	return path
-}
-
-// getLocalBranchName extracts the local branch name from a branch reference
-// For remote branches like "origin/next", returns "next"
-// For local branches, returns the branch as-is
-func getLocalBranchName(branch string) string {
-	return strings.TrimPrefix(branch, "origin/")
-}
-
-// isRemoteBranch checks if a branch reference is a remote tracking branch
-func isRemoteBranch(branch string) bool {
-	return strings.HasPrefix(branch, "origin/")
+	// Replace slashes with hyphens to avoid nested directories
+	return strings.ReplaceAll(branch, "/", "-")
 }
 
 // branchExists checks if a local branch exists in the repository
@@ -272,13 +251,14 @@ func (m *Manager) branchExists(branch string) bool {
 	return cmd.Run() == nil
 }
 
-// Create creates a new worktree
-func (m *Manager) Create(path, branch string, newBranch bool, baseBranch string) error {
+// Create creates a new worktree and returns its path, which gets a unique suffix when a remote
+// branch's local name is already taken. The setup script isn't run here, see StartSetup.
+func (m *Manager) Create(path, branch string, newBranch bool, baseBranch string) (string, error) {
 	// Validate base branch exists if specified
 	if newBranch && baseBranch != "" {
 		cmd := exec.Command("git", "-C", m.repoPath, "rev-parse", "--verify", baseBranch)
 		if err := cmd.Run(); err != nil {
-			return fmt.Errorf("base branch '%s' does not exist. Use 'c' to change the base branch", baseBranch)
+			return "", fmt.Errorf("base branch '%s' does not exist. Use 'c' to change the base branch", baseBranch)
 		}
 	}
 
@@ -287,9 +267,8 @@ func (m *Manager) Create(path, branch string, newBranch bool, baseBranch string)
 
 	if newBranch {
 		args = append(args, "-b", branch)
-	} else if isRemoteBranch(branch) {
-		// For remote branches, check if local branch already exists
-		localBranch := getLocalBranchName(branch)
+	} else if _, localBranch, ok := m.splitRemoteBranch(branch); ok {
+		// For remote branches (from any remote), check if local branch already exists
 		if m.branchExists(localBranch) {
 			// Local branch already exists, generate a unique name
 			// e.g., "next" -> "next-happy-panda-42"
@@ -313,7 +292,7 @@ func (m *Manager) Create(path, branch string, newBranch bool, baseBranch string)
 
 	cmd := exec.Command("git", args...)
 	if output, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("failed to create worktree: %s", string(output))
+		return "", fmt.Errorf("failed to create worktree: %s", string(output))
 	}
 
 	// Copy local files from base repo to worktree (e.g., .claude/settings.local.json)
@@ -321,124 +300,20 @@ func (m *Manager) Create(path, branch string, newBranch bool, baseBranch string)
 	if err == nil {
 		scriptConfig, err := config.LoadScripts(repoRoot)
 		if err == nil {
-			copyPaths := scriptConfig.GetCopyPaths()
-			if err := m.copyLocalFiles(repoRoot, workspacePath, copyPaths); err != nil {
+			if err := m.copyLocalFiles(repoRoot, workspacePath, scriptConfig); err != nil {
 				// Log warning but don't fail - worktree is still usable
 				fmt.Fprintf(os.Stderr, "Warning: failed to copy local files: %v\n", err)
 			}
 		}
 	}
 
-	// Execute setup script if configured (non-blocking - errors are returned but don't prevent worktree usage)
-	if err := m.executeSetupScript(workspacePath); err != nil {
-		return fmt.Errorf("setup script failed: %w", err)
-	}
-
-	return nil
-}
-
-// executeSetupScript runs the setup script from jean.json if configured
-// Returns error if script execution fails, nil if no script configured or script succeeds
-func (m *Manager) executeSetupScript(workspacePath string) error {
-	// Load script config from repository root
-	repoRoot, err := m.GetRepoRoot()
-	if err != nil {
-		return fmt.Errorf("failed to get repo root: %w", err)
-	}
-
-	scriptConfig, err := config.LoadScripts(repoRoot)
-	if err != nil {
-		return fmt.Errorf("failed to load jean.json: %w", err)
-	}
-
-	// Get the setup script
-	script := scriptConfig.GetScript("setup")
-	if script == "" {
-		// No setup script configured, skip
-		return nil
-	}
-
-	// Set environment variables for the script
-	cmd := exec.Command("sh", "-c", script)
-	cmd.Dir = workspacePath // Run script in the worktree directory
-	cmd.Env = append(os.Environ(),
-		fmt.Sprintf("JEAN_WORKSPACE_PATH=%s", workspacePath),
-		fmt.Sprintf("JEAN_ROOT_PATH=%s", repoRoot),
-	)
-
-	// Capture both stdout and stderr for error reporting
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		// Script failed - return error with output for user debugging
-		return fmt.Errorf("%s\n\nScript output:\n%s", err.Error(), string(output))
-	}
-
-	return nil
-}
-
-// copyLocalFiles copies gitignored files/directories from base repo to worktree
-// This enables copying files like .claude/settings.local.json that are not tracked by git
-func (m *Manager) copyLocalFiles(repoRoot, workspacePath string, paths []string) error {
-	for _, relPath := range paths {
-		srcPath := filepath.Join(repoRoot, relPath)
-		dstPath := filepath.Join(workspacePath, relPath)
-
-		// Check if source exists
-		srcInfo, err := os.Stat(srcPath)
-		if os.IsNotExist(err) {
-			// Source doesn't exist, skip silently
-			continue
-		}
-		if err != nil {
-			return fmt.Errorf("failed to stat source %s: %w", relPath, err)
-		}
-
-		if srcInfo.IsDir() {
-			// Copy directory recursively
-			if err := m.copyDir(srcPath, dstPath); err != nil {
-				return fmt.Errorf("failed to copy directory %s: %w", relPath, err)
-			}
-		} else {
-			// Copy single file
-			if err := m.copyFile(srcPath, dstPath); err != nil {
-				return fmt.Errorf("failed to copy file %s: %w", relPath, err)
-			}
-		}
-	}
-	return nil
-}
-
-// copyDir recursively copies a directory from src to dst
-func (m *Manager) copyDir(src, dst string) error {
-	// Create destination directory
-	srcInfo, err := os.Stat(src)
-	if err != nil {
-		return err
-	}
-	if err := os.MkdirAll(dst, srcInfo.Mode()); err != nil {
-		return err
-	}
-
-	entries, err := os.ReadDir(src)
-	if err != nil {
-		return err
+	// Render env templates (e.g. .env.jean.tmpl -> .env) with the worktree's branch and ports
+	localBranch, _ := m.GetCurrentBranchForWorktree(workspacePath)
+	if _, err := m.RenderEnvTemplates(workspacePath, localBranch); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to render env templates: %v\n", err)
 	}
 
-	for _, entry := range entries {
-		srcPath := filepath.Join(src, entry.Name())
-		dstPath := filepath.Join(dst, entry.Name())
-
-		if entry.IsDir() {
-			if err := m.copyDir(srcPath, dstPath); err != nil {
-				return err
-			}
-		} else {
-			if err := m.copyFile(srcPath, dstPath); err != nil {
-				return err
-			}
-		}
-	}
-	return nil
+	return workspacePath, nil
 }
 
 // copyFile copies a single file from src to dst, preserving permissions
@@ -470,8 +345,11 @@ func (m *Manager) copyFile(src, dst string) error {
 }
 
 // Remove removes a worktree and automatically deletes the associated branch
-// Protects common base branches (main, master, develop, etc.) from deletion
-func (m *Manager) Remove(path string, force bool) error {
+// Branches matching the protected patterns in jean.json (main, master, develop, etc. by default) are kept.
+// With the "safe" deletion policy, a branch with commits that aren't on any remote or the base branch
+// is only deleted if deleteUnpushed is set; otherwise it is kept and a *BranchKeptError is returned.
+// The branch is also kept if its commits can't be checked.
+func (m *Manager) Remove(path, baseBranch string, force, deleteUnpushed bool) error {
 	// Get the branch name before removing the worktree
 	branchName, err := m.GetCurrentBranchForWorktree(path)
 	if err != nil {
@@ -480,6 +358,9 @@ func (m *Manager) Remove(path string, force bool) error {
 		branchName = ""
 	}
 
+	// Decide before the worktree (and possibly its branch) is gone
+	deleteBranch, kept := m.decideBranchDeletion(branchName, baseBranch, deleteUnpushed)
+
 	// Remove the worktree
 	args := []string{"-C", m.repoPath, "worktree", "remove"}
 
@@ -494,8 +375,11 @@ func (m *Manager) Remove(path string, force bool) error {
 		return fmt.Errorf("failed to remove worktree: %s", string(output))
 	}
 
-	// Delete the branch if it's not a protected base branch
-	if branchName != "" && !isProtectedBranch(branchName) {
+	if kept != nil {
+		return kept
+	}
+
+	if deleteBranch {
 		// Attempt to delete the branch - don't fail the operation if this fails
 		if err := m.DeleteBranch(branchName); err != nil {
 			// Log the warning but don't return error - worktree was already removed successfully
@@ -506,23 +390,29 @@ func (m *Manager) Remove(path string, force bool) error {
 	return nil
 }
 
-// isProtectedBranch checks if a branch name is a common base branch that should not be deleted
-func isProtectedBranch(branchName string) bool {
-	protectedBranches := []string{
-		"main",
-		"master",
-		"develop",
-		"development",
-		"staging",
-		"production",
-	}
+// WillDeleteBranch reports whether Remove would delete branch along with its worktree
+// Lets the teardown hook know before the worktree is removed (JEAN_DELETE_BRANCH)
+func (m *Manager) WillDeleteBranch(branch, baseBranch string, deleteUnpushed bool) bool {
+	deleteBranch, _ := m.decideBranchDeletion(branch, baseBranch, deleteUnpushed)
+	return deleteBranch
+}
 
-	for _, protected := range protectedBranches {
-		if branchName == protected {
-			return true
-		}
+// decideBranchDeletion works out what Remove does with a worktree's branch
+// Protected branches are kept. With the "safe" policy, so are branches with commits that exist nowhere else
+// (or whose commits can't be counted) unless deleteUnpushed is set; those come with a *BranchKeptError.
+func (m *Manager) decideBranchDeletion(branch, baseBranch string, deleteUnpushed bool) (bool, *BranchKeptError) {
+	policy := m.loadBranchPolicy()
+	if branch == "" || policy.IsProtectedBranch(branch) {
+		return false, nil
 	}
-	return false
+	if deleteUnpushed || policy.GetDeletionPolicy() != config.DeletionPolicySafe {
+		return true, nil
+	}
+	atRisk, err := m.CountAtRiskCommits(branch, baseBranch)
+	if atRisk > 0 || err != nil {
+		return false, &BranchKeptError{Branch: branch, AtRisk: atRisk, CheckErr: err}
+	}
+	return true, nil
 }
 
 // MoveWorktree moves a worktree to a new location using git worktree move
@@ -538,15 +428,25 @@ func (m *Manager) MoveWorktree(oldPath, newPath string) error {
 
 // EnsureWorktreeExists checks if the worktree directory exists and recreates it if missing
 // This is useful when a worktree has been deleted externally but git still tracks it
-func (m *Manager) EnsureWorktreeExists(path, branch string) error {
+// Returns whether the worktree was recreated, so the caller can run setup again
+func (m *Manager) EnsureWorktreeExists(path, branch string) (bool, error) {
 	// Check if the directory exists
 	if _, err := os.Stat(path); err == nil {
 		// Directory exists, nothing to do
-		return nil
+		return false, nil
 	}
 
-	// Directory doesn't exist, recreate it
-	args := []string{"-C", m.repoPath, "worktree", "add", path, branch}
+	if err := m.ReAddWorktree(path, branch); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ReAddWorktree checks out a branch again at the path of a worktree whose directory was deleted
+// --force is needed because git still has the missing worktree registered
+// The setup script isn't run here, see StartSetup.
+func (m *Manager) ReAddWorktree(path, branch string) error {
+	args := []string{"-C", m.repoPath, "worktree", "add", "--force", path, branch}
 	cmd := exec.Command("git", args...)
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("failed to recreate worktree: %s", string(output))
@@ -557,18 +457,15 @@ func (m *Manager) EnsureWorktreeExists(path, branch string) error {
 	if err == nil {
 		scriptConfig, err := config.LoadScripts(repoRoot)
 		if err == nil {
-			copyPaths := scriptConfig.GetCopyPaths()
-			if err := m.copyLocalFiles(repoRoot, path, copyPaths); err != nil {
+			if err := m.copyLocalFiles(repoRoot, path, scriptConfig); err != nil {
 				// Log warning but don't fail - worktree is still usable
 				fmt.Fprintf(os.Stderr, "Warning: failed to copy local files: %v\n", err)
 			}
 		}
 	}
 
-	// Execute setup script if configured (non-blocking)
-	if err := m.executeSetupScript(path); err != nil {
-		// Log the error but don't fail - worktree is still usable
-		fmt.Fprintf(os.Stderr, "Warning: setup script failed during worktree recreation: %v\n", err)
+	if _, err := m.RenderEnvTemplates(path, branch); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to render env templates: %v\n", err)
 	}
 
 	return nil
@@ -605,6 +502,48 @@ func (m *Manager) BranchExists(worktreePath, branchName string) (bool, error) {
 	return false, nil
 }
 
+// GetMergedBranches returns the local branches whose tips are reachable from baseBranch
+// Branches without commits of their own (e.g. the branch of a just-created worktree) are reachable
+// too but weren't merged, so they're left out: those at the tip of baseBranch and those whose reflog
+// shows nothing but being created
+func (m *Manager) GetMergedBranches(baseBranch string) (map[string]bool, error) {
+	cmd := exec.Command("git", "-C", m.repoPath, "branch", "--merged", baseBranch, "--format=%(refname:short)%00%(objectname)")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches merged into %s: %w", baseBranch, err)
+	}
+	baseTip, err := exec.Command("git", "-C", m.repoPath, "rev-parse", "--verify", baseBranch+"^{commit}").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", baseBranch, err)
+	}
+
+	merged := make(map[string]bool)
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		branch, tip, ok := strings.Cut(line, "\x00")
+		if !ok || tip == strings.TrimSpace(string(baseTip)) || !m.hasOwnCommits(branch) {
+			continue
+		}
+		merged[branch] = true
+	}
+	return merged, nil
+}
+
+// hasOwnCommits reports whether a branch's reflog shows anything besides it being created or renamed
+// Without a reflog (e.g. expired) that can't be told, so it counts as having commits
+func (m *Manager) hasOwnCommits(branch string) bool {
+	cmd := exec.Command("git", "-C", m.repoPath, "reflog", "show", "--format=%gs", "refs/heads/"+branch, "--")
+	output, err := cmd.Output()
+	if err != nil || strings.TrimSpace(string(output)) == "" {
+		return true
+	}
+	for _, subject := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if !strings.HasPrefix(subject, "branch: Created from") && !strings.HasPrefix(subject, "Branch: renamed") {
+			return true
+		}
+	}
+	return false
+}
+
 // DeleteBranch deletes a local branch by name
 // Uses -D flag for force deletion (deletes even if not fully merged)
 func (m *Manager) DeleteBranch(branchName string) error {
@@ -667,9 +606,10 @@ func (m *Manager) CheckoutBranch(branch string) error {
 	return nil
 }
 
-// ListBranches returns all branches in the repository
+// ListBranches returns all local branches and the branches of every remote (as "<remote>/<branch>")
 func (m *Manager) ListBranches() ([]string, error) {
-	cmd := exec.Command("git", "-C", m.repoPath, "branch", "-a", "--format=%(refname:short)")
+	// Full refnames, since newer git shortens refs/remotes/<remote>/HEAD to just "<remote>"
+	cmd := exec.Command("git", "-C", m.repoPath, "branch", "-a", "--format=%(refname)")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("failed to list branches: %w", err)
@@ -677,12 +617,15 @@ func (m *Manager) ListBranches() ([]string, error) {
 
 	branches := strings.Split(strings.TrimSpace(string(output)), "\n")
 
-	// Filter out current branch marker (origin/HEAD)
+	// Filter out the symbolic HEAD of every remote (e.g. origin/HEAD, upstream/HEAD)
 	var filtered []string
 	for _, b := range branches {
 		b = strings.TrimSpace(b)
-		if b != "" && !strings.HasPrefix(b, "origin/HEAD") {
-			filtered = append(filtered, b)
+		switch {
+		case strings.HasPrefix(b, "refs/heads/"):
+			filtered = append(filtered, strings.TrimPrefix(b, "refs/heads/"))
+		case strings.HasPrefix(b, "refs/remotes/") && !strings.HasSuffix(b, "/HEAD"):
+			filtered = append(filtered, strings.TrimPrefix(b, "refs/remotes/"))
 		}
 	}
 
@@ -699,31 +642,44 @@ func (m *Manager) GetRepoRoot() (string, error) {
 	return strings.TrimSpace(string(output)), nil
 }
 
-// GetDefaultPath returns a default path for a new worktree in .workspaces directory
+// GetDefaultPath returns the path for a new worktree of branch, built from the path template
+// (by default <repo>/.workspaces/<branch>)
 func (m *Manager) GetDefaultPath(branch string) (string, error) {
 	root, err := m.GetRepoRoot()
 	if err != nil {
 		return "", err
 	}
 
-	// Use .workspaces directory inside repo root
-	workspacesDir := filepath.Join(root, ".workspaces")
+	// Strip the remote of remote-tracking branches (e.g. "upstream/next" -> "next")
+	if _, localBranch, ok := m.splitRemoteBranch(branch); ok {
+		branch = localBranch
+	}
 
 	// Sanitize branch name to create safe directory name
 	sanitized := sanitizeBranchForPath(branch)
-	return filepath.Join(workspacesDir, sanitized), nil
+	return ExpandPathTemplate(m.PathTemplate(), root, sanitized)
 }
 
-// GetWorkspacesDir returns the .workspaces directory path
+// GetWorkspacesDir returns the directory new worktrees are created in
+// This is .workspaces in the repo root unless a path template places them elsewhere
 func (m *Manager) GetWorkspacesDir() (string, error) {
 	root, err := m.GetRepoRoot()
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(root, ".workspaces"), nil
+	path, err := ExpandPathTemplate(m.PathTemplate(), root, branchPlaceholder)
+	if err != nil {
+		return "", err
+	}
+	// The part of the path before the branch, e.g. "~/wt/repo" for "~/wt/{repo}/{branch}"
+	prefix, _, _ := strings.Cut(path, branchPlaceholder)
+	if strings.HasSuffix(prefix, string(filepath.Separator)) {
+		return filepath.Clean(prefix), nil
+	}
+	return filepath.Dir(prefix), nil
 }
 
-// EnsureWorkspacesDir creates the .workspaces directory if it doesn't exist
+// EnsureWorkspacesDir creates the directory new worktrees are created in if it doesn't exist
 func (m *Manager) EnsureWorkspacesDir() error {
 	dir, err := m.GetWorkspacesDir()
 	if err != nil {
@@ -731,7 +687,7 @@ func (m *Manager) EnsureWorkspacesDir() error {
 	}
 
 	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create .workspaces directory: %w", err)
+		return fmt.Errorf("failed to create worktree directory %s: %w", dir, err)
 	}
 
 	return nil
@@ -777,11 +733,14 @@ func randomInt(max int) int {
 }
 
 // IsRandomBranchName checks if a branch name matches the random naming pattern (adjective-noun-number)
+// A prefix and ticket ID from the naming rules may come first (e.g. "feat/ABC-1-happy-panda-42")
 func (m *Manager) IsRandomBranchName(branchName string) bool {
+	branchName = branchName[strings.LastIndex(branchName, "/")+1:]
 	parts := strings.Split(branchName, "-")
-	if len(parts) != 3 {
+	if len(parts) < 3 {
 		return false
 	}
+	parts = parts[len(parts)-3:]
 
 	// Check if part 1 is in adjectives list
 	for _, adj := range adjectives {
@@ -840,13 +799,13 @@ func (m *Manager) Push(worktreePath, branch string) error {
 	}
 
 	// First check if remote exists
-	cmd := exec.Command("git", "-C", worktreePath, "remote", "get-url", "origin")
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("no remote 'origin' configured")
+	remote := m.PushRemote()
+	if !m.remoteExists(worktreePath, remote) {
+		return fmt.Errorf("no remote '%s' configured", remote)
 	}
 
 	// Push with --set-upstream to create remote branch if it doesn't exist
-	cmd = exec.Command("git", "-C", worktreePath, "push", "-u", "origin", branch)
+	cmd := exec.Command("git", "-C", worktreePath, "push", "-u", remote, branch)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("failed to push: %s", string(output))
@@ -855,9 +814,9 @@ func (m *Manager) Push(worktreePath, branch string) error {
 	return nil
 }
 
-// RemoteBranchExists checks if a branch exists on the remote
+// RemoteBranchExists checks if a branch exists on the push remote
 func (m *Manager) RemoteBranchExists(worktreePath, branch string) (bool, error) {
-	cmd := exec.Command("git", "-C", worktreePath, "rev-parse", "--verify", fmt.Sprintf("origin/%s", branch))
+	cmd := exec.Command("git", "-C", worktreePath, "rev-parse", "--verify", fmt.Sprintf("refs/remotes/%s/%s", m.PushRemote(), branch))
 	err := cmd.Run()
 	if err != nil {
 		// Check if it's an actual error or just branch not found
@@ -869,9 +828,9 @@ func (m *Manager) RemoteBranchExists(worktreePath, branch string) (bool, error)
 	return true, nil
 }
 
-// DeleteRemoteBranch deletes a branch from the remote repository
+// DeleteRemoteBranch deletes a branch from the push remote
 func (m *Manager) DeleteRemoteBranch(worktreePath, branch string) error {
-	cmd := exec.Command("git", "-C", worktreePath, "push", "origin", "--delete", branch)
+	cmd := exec.Command("git", "-C", worktreePath, "push", m.PushRemote(), "--delete", branch)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("failed to delete remote branch: %s", string(output))
@@ -904,7 +863,7 @@ func (m *Manager) HasUnpushedCommits(worktreePath, branch string) (bool, error)
 	}
 
 	// Remote branch exists, check if we're ahead
-	cmd := exec.Command("git", "-C", worktreePath, "rev-list", "--count", fmt.Sprintf("origin/%s..HEAD", branch))
+	cmd := exec.Command("git", "-C", worktreePath, "rev-list", "--count", fmt.Sprintf("refs/remotes/%s/%s..HEAD", m.PushRemote(), branch))
 	output, err := cmd.Output()
 	if err != nil {
 		return false, fmt.Errorf("failed to check unpushed commits: %w", err)
@@ -914,9 +873,9 @@ func (m *Manager) HasUnpushedCommits(worktreePath, branch string) (bool, error)
 	return commitCount != "0", nil
 }
 
-// GetRemoteURL returns the URL of the origin remote
+// GetRemoteURL returns the URL of the base remote
 func (m *Manager) GetRemoteURL() (string, error) {
-	cmd := exec.Command("git", "-C", m.repoPath, "remote", "get-url", "origin")
+	cmd := exec.Command("git", "-C", m.repoPath, "remote", "get-url", m.BaseRemote())
 	output, err := cmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("failed to get remote URL: %w", err)
@@ -937,36 +896,49 @@ func (m *Manager) IsGitHubRepo() (bool, error) {
 
 // HasUncommittedChanges checks if there are uncommitted changes in a worktree
 func (m *Manager) HasUncommittedChanges(worktreePath string) (bool, error) {
-	// Check for staged and unstaged changes
-	cmd := exec.Command("git", "-C", worktreePath, "status", "--porcelain")
-	output, err := cmd.Output()
-	if err != nil {
-		return false, fmt.Errorf("failed to check git status: %w", err)
-	}
-
-	// If output is not empty, there are uncommitted changes
-	return len(strings.TrimSpace(string(output))) > 0, nil
+	return m.hasUncommittedChangesContext(context.Background(), worktreePath)
 }
 
 // FetchRemote fetches updates from the remote repository without merging
 // Returns nil if remote doesn't exist (graceful skip) or if fetch succeeds
 // Returns error only if remote exists but fetch fails
 func (m *Manager) FetchRemote() error {
-	// Check if 'origin' remote exists
-	checkCmd := exec.Command("git", "-C", m.repoPath, "remote", "get-url", "origin")
-	checkOutput, err := checkCmd.CombinedOutput()
-	checkOutputStr := strings.TrimSpace(string(checkOutput))
+	return m.fetchRemote(context.Background(), false)
+}
 
-	// If remote doesn't exist or check fails, skip fetch gracefully
-	if err != nil || checkOutputStr == "" {
-		return nil // No remote configured, skip fetch
+// FetchRemoteBackground fetches like FetchRemote but never prompts for credentials,
+// so it is safe to run unattended. Cancelling ctx kills the fetch.
+func (m *Manager) FetchRemoteBackground(ctx context.Context) error {
+	return m.fetchRemote(ctx, true)
+}
+
+// fetchRemote fetches from the base and push remotes, optionally disabling interactive credential prompts
+func (m *Manager) fetchRemote(ctx context.Context, nonInteractive bool) error {
+	remotes := []string{m.BaseRemote()}
+	if push := m.PushRemote(); push != remotes[0] {
+		remotes = append(remotes, push)
 	}
 
-	// Remote exists, attempt fetch
-	cmd := exec.Command("git", "-C", m.repoPath, "fetch", "origin")
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("failed to fetch from remote: %s", string(output))
+	for _, remote := range remotes {
+		// If remote doesn't exist or check fails, skip it gracefully
+		checkCmd := exec.CommandContext(ctx, "git", "-C", m.repoPath, "remote", "get-url", remote)
+		checkOutput, err := checkCmd.CombinedOutput()
+		if err != nil || strings.TrimSpace(string(checkOutput)) == "" {
+			continue
+		}
+
+		// Remote exists, attempt fetch
+		cmd := exec.CommandContext(ctx, "git", "-C", m.repoPath, "fetch", remote)
+		if nonInteractive {
+			cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+		}
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("failed to fetch from remote: %w", ctx.Err())
+			}
+			return fmt.Errorf("failed to fetch from %s: %s", remote, string(output))
+		}
 	}
 	return nil
 }
@@ -974,39 +946,86 @@ func (m *Manager) FetchRemote() error {
 // GetBranchStatus returns the ahead and behind counts for a branch compared to the base branch
 // Returns (aheadCount, behindCount, error)
 func (m *Manager) GetBranchStatus(worktreePath, branch, baseBranch string) (int, int, error) {
+	return m.getBranchStatusContext(context.Background(), worktreePath, branch, baseBranch)
+}
+
+// MergeBranch merges the specified base branch into the current branch in the worktree
+func (m *Manager) MergeBranch(worktreePath, baseBranch string) error {
 	if baseBranch == "" {
-		return 0, 0, fmt.Errorf("base branch not specified")
+		return fmt.Errorf("base branch not specified")
 	}
 
 	// Check if base branch exists
 	cmd := exec.Command("git", "-C", worktreePath, "rev-parse", "--verify", baseBranch)
 	if err := cmd.Run(); err != nil {
-		return 0, 0, fmt.Errorf("base branch '%s' does not exist", baseBranch)
+		return fmt.Errorf("base branch '%s' does not exist", baseBranch)
 	}
 
-	// Get ahead count (commits in current branch not in base)
-	cmd = exec.Command("git", "-C", worktreePath, "rev-list", "--count", fmt.Sprintf("%s..%s", baseBranch, branch))
-	output, err := cmd.Output()
+	// Perform the merge
+	cmd = exec.Command("git", "-C", worktreePath, "merge", baseBranch, "--no-edit")
+	output, err := cmd.CombinedOutput()
 	if err != nil {
-		return 0, 0, fmt.Errorf("failed to get ahead count: %w", err)
+		outputStr := string(output)
+		// Check if it's a merge conflict
+		if strings.Contains(outputStr, "CONFLICT") || strings.Contains(outputStr, "Automatic merge failed") {
+			return fmt.Errorf("merge conflict occurred. Use 'git merge --abort' to abort the merge")
+		}
+		return fmt.Errorf("failed to merge: %s", outputStr)
 	}
-	aheadCount := 0
-	fmt.Sscanf(strings.TrimSpace(string(output)), "%d", &aheadCount)
 
-	// Get behind count (commits in base not in current branch)
-	cmd = exec.Command("git", "-C", worktreePath, "rev-list", "--count", fmt.Sprintf("%s..%s", branch, baseBranch))
-	output, err = cmd.Output()
+	return nil
+}
+
+// Update strategies for bringing base branch changes into a worktree branch
+const (
+	UpdateStrategyMerge  = "merge"   // Merge base into the branch (creates a merge commit if diverged)
+	UpdateStrategyRebase = "rebase"  // Replay the branch's commits on top of base
+	UpdateStrategyFFOnly = "ff-only" // Only fast-forward; fail if the branch has diverged
+)
+
+// UpdateFromBase brings changes from baseBranch into the worktree's current branch using the strategy
+// Conflicts are reported as "merge conflict" or "rebase conflict" errors and leave the operation in progress
+func (m *Manager) UpdateFromBase(worktreePath, baseBranch, strategy string) error {
+	switch strategy {
+	case UpdateStrategyRebase:
+		return m.RebaseBranch(worktreePath, baseBranch)
+	case UpdateStrategyFFOnly:
+		return m.FastForwardBranch(worktreePath, baseBranch)
+	default:
+		return m.MergeBranch(worktreePath, baseBranch)
+	}
+}
+
+// RebaseBranch rebases the current branch in the worktree onto the base branch
+func (m *Manager) RebaseBranch(worktreePath, baseBranch string) error {
+	if baseBranch == "" {
+		return fmt.Errorf("base branch not specified")
+	}
+
+	// Check if base branch exists
+	cmd := exec.Command("git", "-C", worktreePath, "rev-parse", "--verify", baseBranch)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("base branch '%s' does not exist", baseBranch)
+	}
+
+	// Perform the rebase
+	cmd = exec.Command("git", "-C", worktreePath, "rebase", baseBranch)
+	output, err := cmd.CombinedOutput()
 	if err != nil {
-		return 0, 0, fmt.Errorf("failed to get behind count: %w", err)
+		outputStr := string(output)
+		// Check if it stopped on a conflict
+		if strings.Contains(outputStr, "CONFLICT") || strings.Contains(outputStr, "could not apply") {
+			return fmt.Errorf("rebase conflict occurred. Use 'git rebase --abort' to abort the rebase")
+		}
+		return fmt.Errorf("failed to rebase: %s", outputStr)
 	}
-	behindCount := 0
-	fmt.Sscanf(strings.TrimSpace(string(output)), "%d", &behindCount)
 
-	return aheadCount, behindCount, nil
+	return nil
 }
 
-// MergeBranch merges the specified base branch into the current branch in the worktree
-func (m *Manager) MergeBranch(worktreePath, baseBranch string) error {
+// FastForwardBranch fast-forwards the current branch in the worktree to the base branch
+// Fails without changing anything if the branch has commits that are not in base
+func (m *Manager) FastForwardBranch(worktreePath, baseBranch string) error {
 	if baseBranch == "" {
 		return fmt.Errorf("base branch not specified")
 	}
@@ -1017,16 +1036,14 @@ func (m *Manager) MergeBranch(worktreePath, baseBranch string) error {
 		return fmt.Errorf("base branch '%s' does not exist", baseBranch)
 	}
 
-	// Perform the merge
-	cmd = exec.Command("git", "-C", worktreePath, "merge", baseBranch, "--no-edit")
+	cmd = exec.Command("git", "-C", worktreePath, "merge", "--ff-only", baseBranch)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		outputStr := string(output)
-		// Check if it's a merge conflict
-		if strings.Contains(outputStr, "CONFLICT") || strings.Contains(outputStr, "Automatic merge failed") {
-			return fmt.Errorf("merge conflict occurred. Use 'git merge --abort' to abort the merge")
+		if strings.Contains(outputStr, "Not possible to fast-forward") || strings.Contains(outputStr, "not possible to fast-forward") {
+			return fmt.Errorf("cannot fast-forward: branch has diverged from %s. Switch the update strategy to merge or rebase", baseBranch)
 		}
-		return fmt.Errorf("failed to merge: %s", outputStr)
+		return fmt.Errorf("failed to fast-forward: %s", outputStr)
 	}
 
 	return nil
@@ -1042,21 +1059,16 @@ func (m *Manager) AbortMerge(worktreePath string) error {
 	return nil
 }
 
-// PullCurrentBranch pulls the current branch from origin
+// PullCurrentBranch pulls the current branch from its remote
 // For repositories without a remote, falls back to no-op
 func (m *Manager) PullCurrentBranch(worktreePath, branch string) error {
-	// Check if 'origin' remote exists
-	checkCmd := exec.Command("git", "-C", worktreePath, "remote", "get-url", "origin")
-	checkOutput, err := checkCmd.CombinedOutput()
-	checkOutputStr := strings.TrimSpace(string(checkOutput))
-	hasRemote := err == nil && checkOutputStr != ""
-
 	// If no remote, skip pull
-	if !hasRemote {
+	remote := m.remoteForBranch(worktreePath, branch)
+	if !m.remoteExists(worktreePath, remote) {
 		return nil
 	}
 
-	cmd := exec.Command("git", "-C", worktreePath, "pull", "origin", branch)
+	cmd := exec.Command("git", "-C", worktreePath, "pull", remote, branch)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		outputStr := string(output)
@@ -1069,19 +1081,15 @@ func (m *Manager) PullCurrentBranch(worktreePath, branch string) error {
 	return nil
 }
 
-// PullBranchInPath pulls a specific branch from origin in the given directory
+// PullBranchInPath pulls a specific branch from its remote in the given directory
 // For repositories without a remote, falls back to local merge
 func (m *Manager) PullBranchInPath(path, branch string) error {
-	// Check if 'origin' remote exists
-	checkCmd := exec.Command("git", "-C", path, "remote", "get-url", "origin")
-	checkOutput, err := checkCmd.CombinedOutput()
-	checkOutputStr := strings.TrimSpace(string(checkOutput))
-	hasRemote := err == nil && checkOutputStr != ""
+	remote := m.remoteForBranch(path, branch)
 
 	var cmd *exec.Cmd
-	if hasRemote {
+	if m.remoteExists(path, remote) {
 		// Remote exists, use git pull
-		cmd = exec.Command("git", "-C", path, "pull", "origin", branch)
+		cmd = exec.Command("git", "-C", path, "pull", remote, branch)
 	} else {
 		// No remote, use local merge instead
 		cmd = exec.Command("git", "-C", path, "merge", branch, "--no-edit")
@@ -1102,46 +1110,36 @@ func (m *Manager) PullBranchInPath(path, branch string) error {
 // PullCurrentBranchWithOutput pulls current branch and returns the git output and error
 // For repositories without a remote, falls back to local merge
 func (m *Manager) PullCurrentBranchWithOutput(worktreePath, branch string) (string, error) {
-	// Check if 'origin' remote exists
-	checkCmd := exec.Command("git", "-C", worktreePath, "remote", "get-url", "origin")
-	checkOutput, err := checkCmd.CombinedOutput()
-	checkOutputStr := strings.TrimSpace(string(checkOutput))
-	hasRemote := err == nil && checkOutputStr != ""
-
-	var cmd *exec.Cmd
-	if hasRemote {
-		// Remote exists, use git pull
-		cmd = exec.Command("git", "-C", worktreePath, "pull", "origin", branch)
-	} else {
-		// No remote, use local merge instead
-		cmd = exec.Command("git", "-C", worktreePath, "merge", branch, "--no-edit")
-	}
-
-	output, err := cmd.CombinedOutput()
-	outputStr := string(output)
-	if err != nil {
-		// Check if it's a merge conflict
-		if strings.Contains(outputStr, "CONFLICT") || strings.Contains(outputStr, "Automatic merge failed") {
-			return outputStr, fmt.Errorf("merge conflict occurred. Use 'git merge --abort' to abort the merge")
-		}
-		return outputStr, fmt.Errorf("failed to pull: %s", outputStr)
-	}
-	return outputStr, nil
+	return m.PullBranchWithStrategy(worktreePath, branch, "")
 }
 
 // PullBranchInPathWithOutput pulls a specific branch and returns the git output and error
 // For repositories without a remote, falls back to local merge
 func (m *Manager) PullBranchInPathWithOutput(path, branch string) (string, error) {
-	// Check if 'origin' remote exists
-	checkCmd := exec.Command("git", "-C", path, "remote", "get-url", "origin")
-	checkOutput, err := checkCmd.CombinedOutput()
-	checkOutputStr := strings.TrimSpace(string(checkOutput))
-	hasRemote := err == nil && checkOutputStr != ""
+	return m.PullBranchWithStrategy(path, branch, "")
+}
+
+// PullBranchWithStrategy pulls a branch from its remote using the given update strategy
+// ("merge", "rebase" or "ff-only"; "" uses git's configured pull behavior)
+// The remote is the branch's upstream remote if it tracks one, otherwise the push remote.
+// Returns the git output and error. For repositories without a remote, falls back to local merge
+func (m *Manager) PullBranchWithStrategy(path, branch, strategy string) (string, error) {
+	remote := m.remoteForBranch(path, branch)
 
 	var cmd *exec.Cmd
-	if hasRemote {
+	if m.remoteExists(path, remote) {
 		// Remote exists, use git pull
-		cmd = exec.Command("git", "-C", path, "pull", "origin", branch)
+		args := []string{"-C", path, "pull"}
+		switch strategy {
+		case UpdateStrategyMerge:
+			args = append(args, "--no-rebase")
+		case UpdateStrategyRebase:
+			args = append(args, "--rebase")
+		case UpdateStrategyFFOnly:
+			args = append(args, "--ff-only")
+		}
+		args = append(args, remote, branch)
+		cmd = exec.Command("git", args...)
 	} else {
 		// No remote, use local merge instead
 		cmd = exec.Command("git", "-C", path, "merge", branch, "--no-edit")
@@ -1150,8 +1148,11 @@ func (m *Manager) PullBranchInPathWithOutput(path, branch string) (string, error
 	output, err := cmd.CombinedOutput()
 	outputStr := string(output)
 	if err != nil {
-		// Check if it's a merge conflict
+		// Check if it's a conflict
 		if strings.Contains(outputStr, "CONFLICT") || strings.Contains(outputStr, "Automatic merge failed") {
+			if strategy == UpdateStrategyRebase {
+				return outputStr, fmt.Errorf("rebase conflict occurred. Use 'git rebase --abort' to abort the rebase")
+			}
 			return outputStr, fmt.Errorf("merge conflict occurred. Use 'git merge --abort' to abort the merge")
 		}
 		return outputStr, fmt.Errorf("failed to pull: %s", outputStr)
@@ -1335,7 +1336,7 @@ func (m *Manager) GetDiffFromBase(worktreePath, baseBranch string) (string, erro
 	}
 
 	// First, ensure the base branch is fetched from remote
-	fetchCmd := exec.Command("git", "-C", worktreePath, "fetch", "origin", baseBranch)
+	fetchCmd := exec.Command("git", "-C", worktreePath, "fetch", m.BaseRemote(), baseBranch)
 	_ = fetchCmd.Run() // Ignore errors, base branch might be local-only
 
 	// Get diff between current branch and base branch
@@ -1380,6 +1381,17 @@ func (m *Manager) GetCurrentBranchForWorktree(worktreePath string) (string, erro
 	return strings.TrimSpace(string(output)), nil
 }
 
+// GetHeadCommit returns the full SHA of HEAD in a worktree
+func (m *Manager) GetHeadCommit(worktreePath string) (string, error) {
+	cmd := exec.Command("git", "-C", worktreePath, "rev-parse", "HEAD")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get HEAD commit: %w", err)
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
 // GetRecentCommits returns the recent commit log (last 10 commits, one line each)
 func (m *Manager) GetRecentCommits(worktreePath string) (string, error) {
 	cmd := exec.Command("git", "-C", worktreePath, "log", "--oneline", "-10")
@@ -0,0 +1,74 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestGetMergedBranches tests that only branches with commits of their own count as merged
+func TestGetMergedBranches(t *testing.T) {
+	repo := newTestRepo(t, nil)
+	paths := map[string]string{}
+	addWorktree := func(branch string) {
+		paths[branch] = filepath.Join(t.TempDir(), branch)
+		runGit(t, repo, "worktree", "add", "-q", "-b", branch, paths[branch])
+	}
+
+	addWorktree("merged")
+	addWorktree("behind") // Never committed to while main moves on
+	runGit(t, paths["merged"], "commit", "-q", "--allow-empty", "-m", "merged work")
+	runGit(t, repo, "merge", "-q", "--no-ff", "--no-edit", "merged")
+	addWorktree("fresh") // At the tip of main
+
+	merged, err := NewManager(repo).GetMergedBranches("main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !merged["merged"] {
+		t.Error("Expected the merged branch to count as merged")
+	}
+	for _, branch := range []string{"fresh", "behind", "main"} {
+		if merged[branch] {
+			t.Errorf("Expected %s without commits of its own not to count as merged", branch)
+		}
+	}
+}
+
+// TestEnsureWorktreeExists tests that a deleted worktree is recreated without running setup
+// The caller starts setup in the background with StartSetup, like for a new worktree
+func TestEnsureWorktreeExists(t *testing.T) {
+	repo := newTestRepo(t, map[string]string{"jean.json": `{"scripts": {"setup": "touch setup-done"}}`})
+	path := filepath.Join(t.TempDir(), "feature")
+	runGit(t, repo, "worktree", "add", "-q", "-b", "feature", path)
+	m := NewManager(repo)
+
+	if recreated, err := m.EnsureWorktreeExists(path, "feature"); err != nil || recreated {
+		t.Fatalf("Expected an existing worktree to be left alone, got %v, %v", recreated, err)
+	}
+
+	if err := os.RemoveAll(path); err != nil {
+		t.Fatal(err)
+	}
+	recreated, err := m.EnsureWorktreeExists(path, "feature")
+	if err != nil || !recreated {
+		t.Fatalf("Expected the worktree to be recreated, got %v, %v", recreated, err)
+	}
+	if branch := runGit(t, path, "branch", "--show-current"); branch != "feature" {
+		t.Errorf("Expected the recreated worktree on feature, got %q", branch)
+	}
+	if _, err := os.Stat(filepath.Join(path, "setup-done")); !os.IsNotExist(err) {
+		t.Error("Expected recreating the worktree not to run setup")
+	}
+
+	run, err := m.StartSetup(path)
+	if err != nil || run == nil {
+		t.Fatalf("Expected setup to start, got %v, %v", run, err)
+	}
+	if err := run.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(path, "setup-done")); err != nil {
+		t.Errorf("Expected setup to run in the recreated worktree: %v", err)
+	}
+}
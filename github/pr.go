@@ -8,7 +8,9 @@ import (
 )
 
 // Manager handles GitHub operations using gh CLI
-type Manager struct{}
+type Manager struct {
+	repo string // "owner/name" passed as --repo ("" lets gh pick the repo from the remotes)
+}
 
 // PRInfo holds information about a pull request
 type PRInfo struct {
@@ -27,6 +29,20 @@ func NewManager() *Manager {
 	return &Manager{}
 }
 
+// SetRepo pins gh to a repository ("owner/name"), e.g. the upstream repo in a fork workflow
+// where gh would otherwise have to guess between several remotes
+func (m *Manager) SetRepo(repo string) {
+	m.repo = repo
+}
+
+// withRepo appends --repo to gh arguments when a repository is pinned
+func (m *Manager) withRepo(args ...string) []string {
+	if m.repo != "" {
+		args = append(args, "--repo", m.repo)
+	}
+	return args
+}
+
 // IsGhInstalled checks if gh CLI is installed
 func (m *Manager) IsGhInstalled() bool {
 	cmd := exec.Command("gh", "--version")
@@ -48,6 +64,7 @@ func (m *Manager) IsAuthenticated() (bool, error) {
 }
 
 // CreatePR creates a pull request (draft or ready for review)
+// branch may be "owner:branch" when the head branch lives on a fork
 func (m *Manager) CreatePR(worktreePath, branch, baseBranch, title, description string, isDraft bool) (string, error) {
 	// Check if gh is installed
 	if !m.IsGhInstalled() {
@@ -77,7 +94,7 @@ func (m *Manager) CreatePR(worktreePath, branch, baseBranch, title, description
 		args = append(args, "--draft")
 	}
 
-	cmd := exec.Command("gh", args...)
+	cmd := exec.Command("gh", m.withRepo(args...)...)
 	cmd.Dir = worktreePath
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -91,6 +108,9 @@ func (m *Manager) CreatePR(worktreePath, branch, baseBranch, title, description
 
 // GetRepoName gets the repository name from gh CLI
 func (m *Manager) GetRepoName(worktreePath string) (string, error) {
+	if m.repo != "" {
+		return m.repo, nil
+	}
 	cmd := exec.Command("gh", "repo", "view", "--json", "nameWithOwner", "--jq", ".nameWithOwner")
 	cmd.Dir = worktreePath
 	output, err := cmd.Output()
@@ -116,11 +136,11 @@ func (m *Manager) GetPRStatus(prURL string) (string, error) {
 // GetPRForBranch gets the PR details for a given branch (if it exists)
 func (m *Manager) GetPRForBranch(worktreePath, branch string) (*PRInfo, error) {
 	// Search for PR on this branch with full details (including closed/merged)
-	cmd := exec.Command("gh", "pr", "list",
+	cmd := exec.Command("gh", m.withRepo("pr", "list",
 		"--head", branch,
 		"--state", "all",
 		"--json", "number,title,headRefName,url,state,author",
-		"--jq", ".[0]")
+		"--jq", ".[0]")...)
 	cmd.Dir = worktreePath
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -153,7 +173,7 @@ func (m *Manager) UpdatePR(worktreePath, prIdentifier, title, description string
 		args = append(args, "--body", description)
 	}
 
-	cmd := exec.Command("gh", args...)
+	cmd := exec.Command("gh", m.withRepo(args...)...)
 	cmd.Dir = worktreePath
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -248,22 +268,20 @@ func (m *Manager) ListPRs(worktreePath string) ([]PRInfo, error) {
 	}
 
 	// List open PRs in JSON format (only 5 latest to avoid cluttering the screen)
-	cmd := exec.Command("gh", "pr", "list",
+	cmd := exec.Command("gh", m.withRepo("pr", "list",
 		"--state", "open",
 		"--json", "number,title,headRefName,url,author",
-		"--limit", "5")
+		"--limit", "5")...)
 	cmd.Dir = worktreePath
 	output, err := cmd.CombinedOutput()
 	if err != nil {
-		errMsg := fmt.Sprintf("failed to list PRs: %s", string(output))
-		return nil, fmt.Errorf(errMsg)
+		return nil, fmt.Errorf("failed to list PRs: %s", string(output))
 	}
 
 	// Parse JSON response
 	var prs []PRInfo
 	if err := json.Unmarshal(output, &prs); err != nil {
-		errMsg := fmt.Sprintf("failed to parse PR list: %v", err)
-		return nil, fmt.Errorf(errMsg)
+		return nil, fmt.Errorf("failed to parse PR list: %w", err)
 	}
 
 	return prs, nil
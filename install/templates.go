@@ -57,6 +57,17 @@ jean() {
 
             # Check if we got valid data (has at least two pipes)
             if [[ "$switch_info" == *"|"*"|"* ]]; then
+                # Run a jean.json script started from the run menu, then reopen jean
+                # (the runner script cds into the worktree and removes itself)
+                if [ -n "$script_command" ]; then
+                    if [ -n "$WEZTERM_PANE" ] && command -v wezterm >/dev/null 2>&1; then
+                        wezterm cli spawn --cwd "$worktree_path" -- sh "$script_command"
+                    else
+                        sh "$script_command"
+                    fi
+                    continue
+                fi
+
                 # Check if inside wezterm and wezterm CLI is available
                 if [ -n "$WEZTERM_PANE" ] && command -v wezterm >/dev/null 2>&1; then
                     if [ "$debug_enabled" = "true" ]; then
@@ -190,11 +201,26 @@ function jean
                 if test (count $parts) -ge 4
                     set target_window $parts[4]
                 end
+                set script_command ""
+                if test (count $parts) -ge 5
+                    set script_command $parts[5]
+                end
                 set is_claude_initialized "false"
                 if test (count $parts) -ge 7
                     set is_claude_initialized $parts[7]
                 end
 
+                # Run a jean.json script started from the run menu, then reopen jean
+                # (the runner script cds into the worktree and removes itself)
+                if test -n "$script_command"
+                    if test -n "$WEZTERM_PANE"; and command -v wezterm &> /dev/null
+                        wezterm cli spawn --cwd "$worktree_path" -- sh "$script_command"
+                    else
+                        sh "$script_command"
+                    end
+                    continue
+                end
+
                 # Check if inside wezterm and wezterm CLI is available
                 if test -n "$WEZTERM_PANE"; and command -v wezterm &> /dev/null
                     if test "$target_window" = "claude"
@@ -53,11 +53,11 @@ func main() {
 	}
 
 	// Auto-initialize shell integration if not already done
-	// Skip this check for init, version, help, and if already attempted (prevent infinite loop)
+	// Skip this check for init, config, version, help, and if already attempted (prevent infinite loop)
 	shouldCheckInit := true
 	if len(os.Args) > 1 {
 		switch os.Args[1] {
-		case "init", "version", "help":
+		case "init", "config", "version", "help":
 			shouldCheckInit = false
 		}
 	}
@@ -78,6 +78,9 @@ func main() {
 		case "update":
 			handleUpdate()
 			return
+		case "config":
+			handleConfig()
+			return
 		case "version":
 			fmt.Printf("jean version %s\n", version.CliVersion)
 			os.Exit(0)
@@ -121,6 +124,9 @@ func main() {
 	p := tea.NewProgram(model, tea.WithAltScreen())
 
 	finalModel, err := p.Run()
+	if m, ok := finalModel.(tui.Model); ok {
+		m.Close()
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error running TUI: %v\n", err)
 		os.Exit(1)
@@ -130,6 +136,10 @@ func main() {
 	if m, ok := finalModel.(tui.Model); ok {
 		switchInfo := m.GetSwitchInfo()
 		if switchInfo.Path != "" {
+			if switchInfo.Warning != "" {
+				fmt.Fprintf(os.Stderr, "Warning: %s\n", switchInfo.Warning)
+			}
+
 			// Format: path|branch|auto-claude|target-window|script-command|session-name|is-claude-initialized
 			autoCl := "false"
 			if switchInfo.AutoClaude {
@@ -334,6 +344,62 @@ func handleInit() {
 	}
 }
 
+func handleConfig() {
+	if len(os.Args) < 3 || os.Args[2] != "migrate" {
+		fmt.Fprintf(os.Stderr, "Usage: jean config migrate [-dry-run]\n")
+		os.Exit(1)
+	}
+
+	migrateCmd := flag.NewFlagSet("config migrate", flag.ExitOnError)
+	dryRunFlag := migrateCmd.Bool("dry-run", false, "Show what would change without writing anything")
+	migrateCmd.Parse(os.Args[3:])
+
+	configPath, err := config.ConfigPath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	migration, err := config.MigrateConfigFile(configPath, *dryRunFlag)
+	if err != nil {
+		if os.IsNotExist(err) {
+			fmt.Printf("No config at %s, nothing to migrate\n", configPath)
+			return
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Config: %s\n", configPath)
+	if len(migration.Steps) == 0 {
+		fmt.Printf("Already at version %d, nothing to migrate\n", migration.ToVersion)
+		return
+	}
+	fmt.Printf("Version %d → %d\n", migration.FromVersion, migration.ToVersion)
+	for _, step := range migration.Steps {
+		fmt.Printf("\n  v%d → v%d: %s\n", step.From, step.To, step.Description)
+		if len(step.Changes) == 0 {
+			fmt.Println("    (nothing to change)")
+		}
+		for _, change := range step.Changes {
+			fmt.Printf("    - %s\n", change)
+		}
+	}
+	fmt.Println()
+
+	if *dryRunFlag {
+		redacted, err := migration.RedactedConfig()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("Dry run, nothing was written. The upgraded config.json would be (secrets masked):")
+		fmt.Println(string(redacted))
+		return
+	}
+	fmt.Printf("Upgraded. The previous file was saved to %s\n", migration.BackupPath)
+}
+
 // GetRCFileForShell is exported from install package wrapper
 func getRCFileForShell(shell install.Shell, homeDir string) string {
 	switch shell {
@@ -358,10 +424,12 @@ integration across multiple branches effortlessly.
 USAGE:
     jean [OPTIONS]
     jean init [FLAGS]
+    jean config migrate [-dry-run]
 
 COMMANDS:
     init            Install or manage jean shell integration
     update          Update jean to the latest version
+    config migrate  Upgrade ~/.config/jean/config.json to the current format
     help            Show this help message
     version         Print version and exit
 
@@ -377,6 +445,9 @@ INIT COMMAND FLAGS:
     -dry-run        Show what would be done without making changes
     -shell <shell>  Specify shell (bash, zsh, fish). Auto-detected if not specified
 
+CONFIG MIGRATE FLAGS:
+    -dry-run        Show what would change without writing anything
+
 KEYBINDINGS:
     Navigation:
         ↑/k         Move up
@@ -0,0 +1,350 @@
+package process
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// stopTimeout is how long Stop waits for a service to exit after SIGTERM before killing it
+const stopTimeout = 5 * time.Second
+
+// Service is a long-running jean.json script (e.g. a dev server) started in a worktree
+type Service struct {
+	Name     string    `json:"name"`     // Script name from jean.json
+	Worktree string    `json:"worktree"` // Worktree the service runs in
+	Branch   string    `json:"branch"`   // Branch of that worktree
+	PID      int       `json:"pid"`      // Process (and process group) ID
+	StartID  string    `json:"startId"`  // When the process started, to tell it from a later one reusing the PID ("" if unknown)
+	LogPath  string    `json:"logPath"`  // File stdout and stderr are written to
+	Started  time.Time `json:"started"`  // When the service was started
+}
+
+// Manager starts and stops services and keeps track of them in a state file,
+// so services keep being tracked when jean is restarted
+type Manager struct {
+	statePath string
+	mu        sync.Mutex // Serializes access within this process; the lock file serializes jean instances
+}
+
+// NewManager creates a process manager with its state in ~/.config/jean/services.json
+func NewManager() (*Manager, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	configDir := filepath.Join(home, ".config", "jean")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	return &Manager{statePath: filepath.Join(configDir, "services.json")}, nil
+}
+
+// lock takes the state file lock, held by one jean instance at a time, and returns its release
+func (m *Manager) lock() (func(), error) {
+	m.mu.Lock()
+	file, err := os.OpenFile(m.statePath+".lock", os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("failed to open services lock: %w", err)
+	}
+	if err := lockFile(file); err != nil {
+		file.Close()
+		m.mu.Unlock()
+		return nil, fmt.Errorf("failed to lock services: %w", err)
+	}
+	return func() {
+		unlockFile(file)
+		file.Close()
+		m.mu.Unlock()
+	}, nil
+}
+
+// load reads the tracked services from the state file
+func (m *Manager) load() ([]Service, error) {
+	data, err := os.ReadFile(m.statePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Service{}, nil
+		}
+		return nil, err
+	}
+
+	var services []Service
+	if err := json.Unmarshal(data, &services); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", m.statePath, err)
+	}
+	return services, nil
+}
+
+// save writes the tracked services to the state file
+// The file is replaced in one step, so a crash never leaves it half written
+func (m *Manager) save(services []Service) error {
+	data, err := json.MarshalIndent(services, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal services: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(m.statePath), "."+filepath.Base(m.statePath)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), m.statePath)
+}
+
+// processStartID identifies a process by when it started
+// On Linux that's the boot ID and the start time in clock ticks since boot, elsewhere the start time from ps
+func processStartID(pid int) (string, error) {
+	if _, err := os.Stat("/proc/self/stat"); err == nil {
+		data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+		if err != nil {
+			return "", err
+		}
+		// The command name is in parentheses and may contain spaces; starttime is the 20th field after it
+		stat := string(data)
+		fields := strings.Fields(stat[strings.LastIndex(stat, ")")+1:])
+		if len(fields) < 20 {
+			return "", fmt.Errorf("unexpected /proc/%d/stat: %q", pid, stat)
+		}
+		bootID, err := os.ReadFile("/proc/sys/kernel/random/boot_id")
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(bootID)) + "/" + fields[19], nil
+	}
+
+	output, err := exec.Command("ps", "-o", "lstart=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return "", err
+	}
+	if started := strings.TrimSpace(string(output)); started != "" {
+		return started, nil
+	}
+	return "", fmt.Errorf("no process %d", pid)
+}
+
+// alive reports whether anything in the service's process group is still running
+// A group leader that started at another time is a later process that reused the PID: the service is gone.
+// Once the leader itself exited, its PID isn't handed out again while the rest of its group runs.
+func alive(svc Service) bool {
+	if svc.StartID != "" {
+		if startID, err := processStartID(svc.PID); err == nil {
+			return startID == svc.StartID
+		}
+	}
+	return groupAlive(svc.PID)
+}
+
+// running returns the tracked services that are still running, dropping the others from the state file
+// The caller holds the lock
+func (m *Manager) running() ([]Service, error) {
+	services, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+
+	running := make([]Service, 0, len(services))
+	for _, svc := range services {
+		if alive(svc) {
+			running = append(running, svc)
+		}
+	}
+	if len(running) != len(services) {
+		if err := m.save(running); err != nil {
+			return nil, err
+		}
+	}
+	return running, nil
+}
+
+// List returns the running services, sorted by worktree and name
+// Services that exited since they were started are dropped from the state file
+func (m *Manager) List() ([]Service, error) {
+	unlock, err := m.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	running, err := m.running()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(running, func(i, j int) bool {
+		if running[i].Worktree != running[j].Worktree {
+			return running[i].Worktree < running[j].Worktree
+		}
+		return running[i].Name < running[j].Name
+	})
+	return running, nil
+}
+
+// Get returns the running service with the given name in a worktree, or nil
+func (m *Manager) Get(worktreePath, name string) (*Service, error) {
+	services, err := m.List()
+	if err != nil {
+		return nil, err
+	}
+	for i := range services {
+		if services[i].Worktree == worktreePath && services[i].Name == name {
+			return &services[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// Start runs cmd as a service, writing its output to logPath (replacing the previous log)
+// The service gets its own session so it outlives jean and its terminal; fails if the
+// service is already running in the worktree
+func (m *Manager) Start(cmd *exec.Cmd, worktreePath, branch, name, logPath string) (*Service, error) {
+	// Hold the lock until the service is recorded, so two jean instances can't both start it
+	unlock, err := m.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	services, err := m.running()
+	if err != nil {
+		return nil, err
+	}
+	for _, existing := range services {
+		if existing.Worktree == worktreePath && existing.Name == name {
+			return nil, fmt.Errorf("'%s' is already running (pid %d)", name, existing.PID)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(logPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	logFile, err := os.Create(logPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create log file: %w", err)
+	}
+
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	detach(cmd)
+	if err := cmd.Start(); err != nil {
+		logFile.Close()
+		return nil, fmt.Errorf("failed to start service '%s': %w", name, err)
+	}
+	// Reap the process while jean runs; after jean exits init takes over
+	go func() {
+		_ = cmd.Wait()
+		logFile.Close()
+	}()
+
+	svc := Service{
+		Name:     name,
+		Worktree: worktreePath,
+		Branch:   branch,
+		PID:      cmd.Process.Pid,
+		LogPath:  logPath,
+		Started:  time.Now(),
+	}
+	// Best effort: without it the service is tracked by PID alone
+	svc.StartID, _ = processStartID(svc.PID)
+
+	if err := m.save(append(services, svc)); err != nil {
+		return nil, err
+	}
+	return &svc, nil
+}
+
+// Stop terminates a service and everything it started, killing it if it ignores SIGTERM
+// Does nothing if the service isn't running
+func (m *Manager) Stop(worktreePath, name string) error {
+	svc, err := m.Get(worktreePath, name)
+	if err != nil || svc == nil {
+		return err
+	}
+	if err := terminate(*svc); err != nil {
+		return fmt.Errorf("failed to stop service '%s': %w", name, err)
+	}
+	return m.forget(func(s Service) bool { return s.Worktree == worktreePath && s.Name == name })
+}
+
+// StopAll stops every service running in a worktree, e.g. before it's deleted
+func (m *Manager) StopAll(worktreePath string) error {
+	services, err := m.List()
+	if err != nil {
+		return err
+	}
+	var firstErr error
+	for _, svc := range services {
+		if svc.Worktree != worktreePath {
+			continue
+		}
+		if err := terminate(svc); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to stop service '%s': %w", svc.Name, err)
+		}
+	}
+	if err := m.forget(func(s Service) bool { return s.Worktree == worktreePath }); err != nil && firstErr == nil {
+		firstErr = err
+	}
+	return firstErr
+}
+
+// forget removes the services matching drop from the state file
+func (m *Manager) forget(drop func(Service) bool) error {
+	unlock, err := m.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	services, err := m.load()
+	if err != nil {
+		return err
+	}
+	kept := make([]Service, 0, len(services))
+	for _, svc := range services {
+		if !drop(svc) {
+			kept = append(kept, svc)
+		}
+	}
+	return m.save(kept)
+}
+
+// terminate sends SIGTERM to a service's process group and SIGKILL if it's still around after stopTimeout
+// Nothing is signalled once the service is gone, even if another process has its PID by now
+func terminate(svc Service) error {
+	if !alive(svc) {
+		return nil
+	}
+	pid := svc.PID
+	if err := signalGroup(pid, false); err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(stopTimeout)
+	for time.Now().Before(deadline) {
+		if !alive(svc) {
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return signalGroup(pid, true)
+}
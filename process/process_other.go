@@ -0,0 +1,46 @@
+//go:build !unix
+
+package process
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+)
+
+// lockFile has no advisory locks to take outside Unix; the Manager's mutex still serializes
+// access within this jean instance
+func lockFile(file *os.File) error {
+	return nil
+}
+
+// unlockFile releases the lock taken by lockFile
+func unlockFile(file *os.File) {}
+
+// detach leaves the command as is: there are no sessions to start it in
+func detach(cmd *exec.Cmd) {}
+
+// groupAlive reports whether the process is still running
+// Without process groups, what the service started isn't tracked
+func groupAlive(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	process.Release()
+	return true
+}
+
+// signalGroup kills the process; there's no SIGTERM to ask it to exit first
+// A process that's already gone isn't an error
+func signalGroup(pid int, force bool) error {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return nil
+	}
+	defer process.Release()
+	if err := process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+//go:build unix
+
+package process
+
+import (
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestServiceWithReusedPIDCountsAsStopped(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	m, err := NewManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktree := t.TempDir()
+	logPath := filepath.Join(t.TempDir(), "dev.log")
+
+	svc, err := m.Start(exec.Command("sleep", "30"), worktree, "feature", "dev", logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer syscall.Kill(-svc.PID, syscall.SIGKILL)
+	if svc.StartID == "" {
+		t.Error("Expected the service's start to be recorded")
+	}
+	if _, err := m.Start(exec.Command("sleep", "30"), worktree, "feature", "dev", logPath); err == nil {
+		t.Error("Expected starting a running service again to fail")
+	}
+
+	// Pretend the service exited and another process got its PID
+	unlock, err := m.lock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	services, err := m.load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	services[0].StartID = "another process"
+	if err := m.save(services); err != nil {
+		t.Fatal(err)
+	}
+	unlock()
+
+	running, err := m.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(running) != 0 {
+		t.Errorf("Expected a PID with another start time not to count as the service, got %v", running)
+	}
+	if err := terminate(services[0]); err != nil {
+		t.Fatal(err)
+	}
+	if err := syscall.Kill(-svc.PID, 0); err != nil {
+		t.Errorf("Expected the process that has the PID now not to be signalled: %v", err)
+	}
+}
@@ -1,7 +1,10 @@
 package tui
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"sort"
@@ -17,6 +20,7 @@ import (
 	"github.com/coollabsio/jean-tui/internal/version"
 	"github.com/coollabsio/jean-tui/claude"
 	"github.com/coollabsio/jean-tui/session"
+	"github.com/coollabsio/jean-tui/process"
 )
 
 // SwitchInfo contains information about the worktree to switch to
@@ -28,6 +32,7 @@ type SwitchInfo struct {
 	ScriptCommand        string // If set, run this script command instead of shell/Claude
 	SessionName          string // Custom name for Claude session (for --session flag)
 	IsClaudeInitialized  bool   // Whether this Claude session has been initialized before
+	Warning              string // Printed after jean exits, e.g. when the post-switch hook failed
 }
 
 type modalType int
@@ -54,9 +59,22 @@ const (
 	postMergeCleanupModal
 	aiPromptsModal
 	prStateSettingsModal
+	autoFetchSettingsModal
+	updateStrategySettingsModal
 	onboardingModal
 	gitInitModal
 	stagingModal
+	conflictModal
+	stashModal
+	diffViewerModal
+	logModal
+	repairModal
+	cleanupModal
+	remoteSettingsModal
+	worktreeLocationModal
+	relocateModal
+	scriptsModal
+	scriptLogModal
 )
 
 // NotificationType defines the type of notification
@@ -111,6 +129,17 @@ type Model struct {
 	lastCreatedBranch string // Last created branch name (for auto-selection after creation)
 	lastRenamedBranch string // Last renamed branch name (for auto-selection after rename)
 
+	// Worktree status streaming
+	watcher       *git.Watcher               // Filesystem watcher for live status updates (nil if unavailable)
+	statusCancel  context.CancelFunc          // Cancels the in-flight worktree status stream (nil if none)
+	statusResults <-chan git.WorktreeStatus   // Channel of the current stream (updates from older streams are dropped)
+
+	// Background fetch scheduling
+	lastFetchTime           time.Time     // When origin was last fetched successfully (background or manual refresh)
+	fetchBackoff            time.Duration // Extra delay added after failed fetches (e.g. while offline)
+	autoFetchInFlight       bool          // Whether a background fetch is currently running
+	autoFetchSettingsCursor int           // Selected index into autoFetchIntervalOptions in the auto-fetch settings modal
+
 	// Activity tracking
 	lastActivityCheck     time.Time
 	activityCheckInterval time.Duration
@@ -123,6 +152,7 @@ type Model struct {
 	searchInput            textinput.Model
 	sessionNameInput       textinput.Model // Session name input for new worktree
 	commitSubjectInput     textinput.Model // Subject line for commit message
+	stashMessageInput      textinput.Model // Message for a new stash
 	prTitleInput           textinput.Model // PR title input
 	prDescriptionInput     textinput.Model // PR description input
 	prModalFocused         int             // Which field in PR modal is focused (0=title, 1=description, 2=create, 3=cancel)
@@ -139,6 +169,9 @@ type Model struct {
 	settingsIndex          int           // Selected setting option index
 	deleteHasUncommitted   bool     // Whether worktree to delete has uncommitted changes
 	deleteConfirmForce     bool     // User acknowledged they want to delete despite uncommitted changes
+	deleteAtRisk           int      // Commits on the branch that aren't on any remote or the base branch
+	deleteAtRiskErr        error    // Set when those commits couldn't be counted
+	deleteBranchProtected  bool     // Branch matches a protected pattern and will be kept
 
 	// AI Settings modal state
 	aiSettingsIndex        int                    // Selected AI setting option index
@@ -224,6 +257,9 @@ type Model struct {
 	prIsDraft    bool // Whether to create PR as draft (based on config setting)
 	prTypeCursor int  // Selected PR type (0=draft, 1=ready for review)
 
+	// Update strategy settings modal state
+	updateStrategySettingsCursor int // Selected index into updateStrategyOptions
+
 	// PR retry state (when PR already exists)
 	prRetryWorktreePath string // Worktree path for PR retry attempt
 	prRetryBranch       string // Branch name for PR retry attempt
@@ -247,6 +283,113 @@ type Model struct {
 	// Staging modal state
 	stagingFiles []git.StagingFile // Files in the staging area
 	stagingIndex int               // Currently selected file index
+
+	// Hunk staging view (per-file diff inside the staging modal)
+	stagingDiffOpen     bool          // Whether the hunk view is shown instead of the file list
+	stagingDiffFile     string        // File shown in the hunk view
+	stagingDiffStaged   bool          // Whether the staged diff (index vs HEAD) is shown instead of the unstaged one
+	stagingDiff         *git.FileDiff // Parsed diff of the file (nil while loading)
+	stagingHunkIndex    int           // Selected hunk
+	stagingLineMode     bool          // Whether individual lines of the hunk are being selected
+	stagingLineIndex    int           // Cursor position in the selected hunk's body (line mode)
+	stagingLineSelected map[int]bool  // Selected body lines of the hunk (line mode)
+
+	// Conflict resolution modal state
+	conflictWorktreePath   string               // Worktree with the in-progress merge or rebase
+	conflictOperation      string               // Operation waiting for resolution ("merge", "rebase" or "cherry-pick")
+	conflictFiles          []string             // Unmerged paths
+	conflictIndex          int                  // Selected file index
+	conflictVersions       *git.ConflictVersions // Base/ours/theirs of the selected file (nil while loading)
+	conflictView           int                  // Which version is shown (0=ours, 1=theirs, 2=base)
+	conflictScroll         int                  // First visible line of the version preview
+	conflictFromLocalMerge bool                 // Whether the conflict came from a local merge (L), to offer cleanup afterwards
+	conflictConfirmAbort   bool                 // Whether the next 'A' confirms aborting the operation
+
+	// Diff viewer state
+	diffViewerPath   string          // Worktree being reviewed
+	diffViewerBranch string          // Branch of that worktree
+	diffViewerMode   string          // git.DiffUnstaged, git.DiffStaged or git.DiffBase
+	diffViewerFiles  []*git.FileDiff // Changed files (nil while loading)
+	diffViewerIndex  int             // Selected file
+	diffViewerScroll int             // First visible line of the selected file's diff
+
+	// Commit log state
+	logWorktreePath string          // Worktree whose history is shown
+	logBranch       string          // Branch of that worktree
+	logEntries      []git.LogEntry  // Loaded log lines, including graph-only lines (nil while loading)
+	logCommits      int             // Number of commits loaded so far (skip for the next page)
+	logHasMore      bool            // Whether another page may be available
+	logLoading      bool            // Whether a page is being loaded
+	logIndex        int             // Selected entry (always a commit line)
+	logSelected     map[string]bool // Commits marked for cherry-pick, by hash
+	logDiffHash     string          // Commit whose full diff is shown ("" shows the log)
+	logDiff         []string        // Lines of that commit's diff (nil while loading)
+	logDiffScroll   int             // First visible line of the commit diff
+	logPickTarget   bool            // Whether the cherry-pick target picker is open
+	logTargetIndex  int             // Selected target worktree in the picker
+
+	// Repair modal state
+	repairItems      []repairItem // Missing worktrees and orphaned directories (nil while scanning)
+	repairIndex      int          // Selected item
+	repairFocusPath  string       // Path to select once the scan finishes
+	repairConfirmDel bool         // Whether delete was pressed once on the selected orphan
+
+	// Remote settings modal state
+	remoteOptions     []string // Configured remotes to choose from
+	remoteField       int      // Focused field (0=push remote, 1=base remote)
+	remotePushIndex   int      // Selected push remote
+	remoteBaseIndex   int      // Selected base remote
+
+	// Run script menu state
+	scriptsWorktreePath string                    // Worktree scripts are run in
+	scriptsBranch       string                    // Branch of that worktree
+	scripts             map[string]string         // Commands of the jean.json scripts, by name
+	scriptNames         []string                  // Script names, sorted
+	scriptIndex         int                       // Selected script
+	scriptRuns          map[string]*git.ScriptRun // Latest background run of each script, by scriptRunKey
+	scriptServices      map[string]bool           // Scripts jean.json marks as services
+
+	// Script log viewer state
+	scriptLogWorktree string   // Worktree whose script log is shown
+	scriptLogBranch   string   // Branch of that worktree
+	scriptLogName     string   // Script whose log is shown
+	scriptLogLines    []string // Lines of the log (nil while loading)
+	scriptLogMissing  bool     // Whether the script hasn't been run in the background yet
+	scriptLogScroll   int      // First visible line
+	scriptLogFollow   bool     // Keep the end of the log in view as output arrives
+	scriptLogTicking  bool     // Whether the refresh tick is scheduled
+	scriptLogBack     modalType // Modal to return to when the viewer is closed
+	scriptLogService  bool      // Whether the script runs as a service
+
+	// Long-running services (dev servers etc.), tracked across jean restarts
+	processManager *process.Manager  // nil if ~/.config/jean isn't usable
+	services       []process.Service // Running services of all worktrees
+
+	// Branch naming rules from jean.json, loaded when the create or rename modal opens
+	namingRules *config.BranchNamingRules
+
+	// Worktree location modal state
+	worktreeLocationGlobal bool // Editing the global default instead of this repository's template
+
+	// Cleanup modal state
+	cleanupCandidates []cleanupCandidate // Worktrees suggested for removal (nil while scanning)
+	cleanupIndex      int                // Selected candidate
+	cleanupSelected   map[string]bool    // Checked candidates, by worktree path
+	cleanupStaleDays  int                // Days without changes before a worktree counts as abandoned
+	cleanupConfirm    bool               // Whether enter was pressed once to confirm removal
+	cleanupRunning    bool               // Whether the batch removal is in progress
+
+	// Stash modal state
+	stashWorktreePath string      // Worktree the stash modal was opened for
+	stashBranch       string      // Branch of that worktree (stashes are filtered by it)
+	stashes           []git.Stash // All stash entries of the repository
+	stashIndex        int         // Selected index into visibleStashes()
+	stashShowAll      bool        // Show stashes from every branch instead of just this worktree's
+	stashPreview      string      // Diff of the selected stash ("" while loading)
+	stashPreviewRef   string      // Stash ref the preview belongs to
+	stashScroll       int         // First visible line of the preview
+	stashInputMode    bool        // Whether the new stash message input is active
+	stashConfirmDrop  bool        // Whether the next 'd' confirms dropping the selected stash
 }
 
 // NewModel creates a new TUI model
@@ -277,6 +420,11 @@ func NewModel(repoPath string, autoClaude bool) Model {
 	commitSubjectInput.CharLimit = 72
 	commitSubjectInput.Width = 70
 
+	stashMessageInput := textinput.New()
+	stashMessageInput.Placeholder = "Stash message (optional)"
+	stashMessageInput.CharLimit = 100
+	stashMessageInput.Width = 60
+
 	prTitleInput := textinput.New()
 	prTitleInput.Placeholder = "PR title (required, max 72 characters)"
 	prTitleInput.CharLimit = 72
@@ -288,7 +436,7 @@ func NewModel(repoPath string, autoClaude bool) Model {
 	prDescriptionInput.Width = 70
 
 	aiAPIKeyInput := textinput.New()
-	aiAPIKeyInput.Placeholder = "sk-or-..."
+	aiAPIKeyInput.Placeholder = "sk-ant-..."
 	aiAPIKeyInput.CharLimit = 256
 	aiAPIKeyInput.Width = 50
 	aiAPIKeyInput.EchoMode = textinput.EchoPassword // Mask API key input
@@ -322,6 +470,21 @@ func NewModel(repoPath string, autoClaude bool) Model {
 
 	// Create git manager and get absolute repo root path
 	gitManager := git.NewManager(repoPath)
+	if configManager != nil {
+		gitManager.SetStatusConcurrency(configManager.GetStatusConcurrency())
+	}
+
+	// Track services in ~/.config/jean (optional, services just can't be started without it)
+	processManager, err := process.NewManager()
+	if err != nil {
+		processManager = nil
+	}
+
+	// Watch worktrees for live status updates (optional, e.g. inotify limits may be exhausted)
+	watcher, err := git.NewWatcher(git.DefaultWatchDebounce)
+	if err != nil {
+		watcher = nil
+	}
 	absoluteRepoPath := repoPath
 	if root, err := gitManager.GetRepoRoot(); err == nil {
 		absoluteRepoPath = root
@@ -347,6 +510,8 @@ func NewModel(repoPath string, autoClaude bool) Model {
 
 	m := Model{
 		gitManager:         gitManager,
+		watcher:            watcher,
+		processManager:     processManager,
 		sessionManager:     session.NewManager(),
 		configManager:      configManager,
 		githubManager:      github.NewManager(),
@@ -355,6 +520,7 @@ func NewModel(repoPath string, autoClaude bool) Model {
 		searchInput:        searchInput,
 		sessionNameInput:   sessionNameInput,
 		commitSubjectInput: commitSubjectInput,
+		stashMessageInput:  stashMessageInput,
 		prTitleInput:       prTitleInput,
 		prDescriptionInput: prDescriptionInput,
 		aiAPIKeyInput:      aiAPIKeyInput,
@@ -369,8 +535,18 @@ func NewModel(repoPath string, autoClaude bool) Model {
 		availableThemes:    GetAvailableThemes(),
 		prStateSettingsCursor: 1, // Default to "Ready for review" (index 1)
 		isInitializing: true,
+		scriptRuns:     make(map[string]*git.ScriptRun),
 	}
 
+	// Push to and fetch base from the configured remotes (e.g. a fork and its upstream)
+	m.applyRemotes()
+
+	// Create new worktrees where the configured path template says
+	m.applyPathTemplate()
+
+	// Reserve port blocks for worktrees in the config (JEAN_PORT, ...)
+	m.applyPortAllocator()
+
 	// Load AI settings from config
 	if configManager != nil {
 		if apiKey := configManager.GetAnthropicAPIKey(); apiKey != "" {
@@ -424,7 +600,10 @@ func (m Model) Init() tea.Cmd {
 	return tea.Batch(
 		m.loadBaseBranch(),
 		m.loadSessions(),
+		m.loadServices(),
 		m.scheduleActivityCheck(),
+		m.scheduleAutoFetch(),
+		m.waitForWorktreeChange(),
 		m.checkForUpdates(),
 		tea.EnterAltScreen,
 	)
@@ -474,15 +653,26 @@ type (
 	}
 
 	worktreeDeletedMsg struct {
-		err error
+		err     error
+		kept    *git.BranchKeptError // Set when the branch was kept because of unpushed commits
+		hookErr error                // teardown hook failure (the worktree is deleted anyway)
 	}
 
 	worktreeStatusUpdatedMsg struct {
 		index    int  // Index of worktree in list
+		path     string // Worktree path (used when the list was re-sorted since loading started)
 		hasUncommitted bool
 		aheadCount int
 		behindCount int
 		err      error
+		results  <-chan git.WorktreeStatus // Stream this update came from (nil for one-off loads)
+		commit   string   // Current HEAD SHA ("" = unchanged/unknown)
+		recentCommits []string // Last commit titles (nil = unchanged/unknown)
+		branchChanged bool // Whether the checked out branch differs from the loaded list (requires reload)
+	}
+
+	worktreeChangedMsg struct {
+		path string // Worktree path reported by the filesystem watcher
 	}
 
 	branchRenamedMsg struct {
@@ -524,8 +714,9 @@ type (
 	}
 
 	branchPulledMsg struct {
-		err         error
-		hadConflict bool
+		err          error
+		hadConflict  bool
+		worktreePath string // Worktree where the merge ran (set on conflict)
 	}
 
 	localMergePreparedMsg struct {
@@ -542,6 +733,7 @@ type (
 		worktreePath string // Worktree path
 		err          error
 		hadConflict  bool   // Whether there was a merge conflict
+		conflictPath string // Where the conflicted merge is waiting (main repo)
 	}
 
 	refreshWithPullMsg struct {
@@ -551,10 +743,19 @@ type (
 		upToDate          bool            // Whether everything was already up to date
 		mergedBaseBranch  bool            // Whether base branch was merged into selected worktree
 		pullErr           error           // Error from pulling the main repo branch (non-blocking)
+		conflictPath      string          // First worktree left with merge conflicts by the pull
+		stashConflicts    []string        // Branches whose auto-stashed changes could not be re-applied cleanly
 	}
 
 	activityTickMsg time.Time
 
+	autoFetchTickMsg time.Time
+
+	autoFetchCompletedMsg struct {
+		statuses map[string]git.BranchStatus // Branch -> ahead/behind counts after the fetch
+		err      error
+	}
+
 	activityCheckedMsg struct {
 		sessions []session.Session
 		err      error
@@ -676,7 +877,14 @@ type (
 	}
 
 	worktreeEnsuredMsg struct {
-		err error
+		err      error
+		hookErr  error // post-switch hook failure (the switch still happens)
+		setupErr error // setup of a recreated worktree couldn't start (the switch still happens)
+	}
+
+	hookFinishedMsg struct {
+		hook string
+		err  error
 	}
 
 	stagingFilesLoadedMsg struct {
@@ -684,9 +892,167 @@ type (
 		err   error
 	}
 
+	diffViewerLoadedMsg struct {
+		path  string
+		mode  string
+		files []*git.FileDiff
+		err   error
+	}
+
+	remotesFetchedMsg struct {
+		err error
+	}
+
+	worktreeRelocatedMsg struct {
+		oldPath string
+		newPath string
+		err     error
+	}
+
+	scriptStartedMsg struct {
+		run         *git.ScriptRun // nil if there was nothing to run
+		afterCreate bool           // Setup started for a newly created or re-added worktree
+		err         error
+	}
+
+	scriptFinishedMsg struct {
+		run *git.ScriptRun
+	}
+
+	scriptRunnerReadyMsg struct {
+		path   string
+		branch string
+		runner string // Runner script the shell wrapper executes
+		err    error
+	}
+
+	scriptLogLoadedMsg struct {
+		path    string // Worktree the log belongs to
+		name    string
+		lines   []string
+		missing bool
+		err     error
+	}
+
+	scriptLogTickMsg struct{}
+
+	servicesLoadedMsg struct {
+		services []process.Service
+		err      error
+	}
+
+	serviceStartedMsg struct {
+		service   *process.Service
+		restarted bool
+		err       error
+	}
+
+	serviceStoppedMsg struct {
+		path   string // Worktree the service ran in
+		branch string
+		name   string
+		err    error
+	}
+
+	cleanupCandidatesMsg struct {
+		candidates []cleanupCandidate
+		err        error
+	}
+
+	cleanupCompletedMsg struct {
+		removed  int
+		kept     []string // Branches kept because of unpushed commits
+		failures []string
+		hookErrs []string // Worktrees whose teardown hook failed
+	}
+
+	repairScanMsg struct {
+		items []repairItem
+		err   error
+	}
+
+	repairActionMsg struct {
+		action  string
+		count   int
+		readded []string // Paths of re-added worktrees, which get their setup run again
+		err     error
+	}
+
+	logLoadedMsg struct {
+		path    string
+		skip    int
+		entries []git.LogEntry
+		err     error
+	}
+
+	commitDiffLoadedMsg struct {
+		hash string
+		diff string
+		err  error
+	}
+
+	cherryPickCompletedMsg struct {
+		targetPath   string
+		targetBranch string
+		count        int
+		skipped      int // Commits dropped because their changes were already on the branch
+		err          error
+	}
+
+	stashesLoadedMsg struct {
+		stashes []git.Stash
+		err     error
+	}
+
+	stashPreviewLoadedMsg struct {
+		ref     string
+		content string
+		err     error
+	}
+
+	stashActionCompletedMsg struct {
+		action string // "push", "apply", "pop" or "drop"
+		ref    string
+		err    error
+	}
+
+	stagingDiffLoadedMsg struct {
+		file   string
+		staged bool
+		diff   *git.FileDiff
+		err    error
+	}
+
+	stagingPatchAppliedMsg struct {
+		err error
+	}
+
 	stagingFileToggledMsg struct {
 		err error
 	}
+
+	conflictFilesLoadedMsg struct {
+		files []string
+		err   error
+	}
+
+	conflictVersionsLoadedMsg struct {
+		file     string
+		versions git.ConflictVersions
+		err      error
+	}
+
+	conflictFileResolvedMsg struct {
+		file   string
+		action string // "ours", "theirs" or "resolved"
+		err    error
+	}
+
+	conflictFinishedMsg struct {
+		aborted         bool // Whether the merge/rebase was aborted instead of completed
+		stillInProgress bool // Whether a rebase stopped again on the next commit's conflicts
+		err             error
+	}
 )
 
 // Commands
@@ -728,18 +1094,129 @@ func (m Model) loadWorktreeStatus(index int, worktree git.Worktree) tea.Cmd {
 		aheadCount := 0
 		behindCount := 0
 		if m.baseBranch != "" && !strings.HasPrefix(worktree.Branch, "(detached") {
-			if ahead, behind, err := m.gitManager.GetBranchStatus(worktree.Path, worktree.Branch, m.baseBranch); err == nil {
-				aheadCount = ahead
-				behindCount = behind
+			statuses, err := m.gitManager.GetBranchStatuses(context.Background(), m.baseBranch, []string{worktree.Branch})
+			if status, ok := statuses[worktree.Branch]; err == nil && ok {
+				aheadCount = status.Ahead
+				behindCount = status.Behind
 			}
 		}
 
+		// Pick up new commits and branch switches made outside jean
+		commit, _ := m.gitManager.GetHeadCommit(worktree.Path)
+		var recentCommits []string
+		if commit != "" && commit != worktree.Commit {
+			recentCommits, _ = m.gitManager.GetRecentCommitTitles(worktree.Path, 5)
+		}
+		branchChanged := false
+		if branch, err := m.gitManager.GetCurrentBranchForWorktree(worktree.Path); err == nil {
+			isDetached := strings.HasPrefix(worktree.Branch, "(detached")
+			branchChanged = (branch == "") != isDetached || (!isDetached && branch != worktree.Branch)
+		}
+
 		return worktreeStatusUpdatedMsg{
 			index:          index,
+			path:           worktree.Path,
 			hasUncommitted: hasUncommitted,
 			aheadCount:     aheadCount,
 			behindCount:    behindCount,
 			err:            nil,
+			commit:         commit,
+			recentCommits:  recentCommits,
+			branchChanged:  branchChanged,
+		}
+	}
+}
+
+// syncWatcher points the filesystem watcher at the current worktree list
+// Runs as a command because resolving gitdirs and tracked directories shells out to git
+func (m Model) syncWatcher() tea.Cmd {
+	if m.watcher == nil {
+		return nil
+	}
+	worktrees := make([]git.Worktree, len(m.worktrees))
+	copy(worktrees, m.worktrees)
+	return func() tea.Msg {
+		m.watcher.SetWorktrees(worktrees)
+		return nil
+	}
+}
+
+// waitForWorktreeChange waits for the next debounced change reported by the filesystem watcher
+func (m Model) waitForWorktreeChange() tea.Cmd {
+	if m.watcher == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		path, ok := <-m.watcher.Events()
+		if !ok {
+			return nil
+		}
+		return worktreeChangedMsg{path: path}
+	}
+}
+
+// Close stops background work (filesystem watcher, status streams) once the program exits
+func (m Model) Close() {
+	if m.statusCancel != nil {
+		m.statusCancel()
+	}
+	if m.watcher != nil {
+		m.watcher.Close()
+	}
+	// Background scripts don't outlive jean, except setup: switching to a new worktree
+	// right away shouldn't cut its npm install short (the output still goes to the log)
+	for _, run := range m.scriptRuns {
+		if run.Name != git.SetupScript {
+			_ = run.Stop()
+		}
+	}
+}
+
+// startWorktreeStatusStream collects status for all worktrees through the git worker pool
+// Any previous stream is cancelled so stale results never land on a freshly loaded list.
+// Each finished worktree arrives as its own worktreeStatusUpdatedMsg.
+func (m *Model) startWorktreeStatusStream() tea.Cmd {
+	m.cancelWorktreeStatusStream()
+	if len(m.worktrees) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.statusCancel = cancel
+
+	// Snapshot the list so the workers never observe later mutations
+	worktrees := make([]git.Worktree, len(m.worktrees))
+	copy(worktrees, m.worktrees)
+
+	m.statusResults = m.gitManager.CollectStatus(ctx, worktrees, m.baseBranch)
+	return waitForWorktreeStatus(m.statusResults)
+}
+
+// cancelWorktreeStatusStream stops the in-flight status stream, if any
+func (m *Model) cancelWorktreeStatusStream() {
+	if m.statusCancel != nil {
+		m.statusCancel()
+		m.statusCancel = nil
+	}
+	m.statusResults = nil
+}
+
+// waitForWorktreeStatus waits for the next result on a status stream
+// Returns nil once the stream is closed, which ends the chain
+func waitForWorktreeStatus(results <-chan git.WorktreeStatus) tea.Cmd {
+	return func() tea.Msg {
+		status, ok := <-results
+		if !ok {
+			return nil
+		}
+		return worktreeStatusUpdatedMsg{
+			index:          status.Index,
+			path:           status.Path,
+			hasUncommitted: status.HasUncommitted,
+			aheadCount:     status.AheadCount,
+			behindCount:    status.BehindCount,
+			err:            status.Err,
+			results:        results,
 		}
 	}
 }
@@ -806,7 +1283,7 @@ func (m Model) createWorktree(path, branch string, newBranch bool) tea.Cmd {
 			baseBranch = m.baseBranch
 		}
 
-		err := m.gitManager.Create(path, branch, newBranch, baseBranch)
+		path, err := m.gitManager.Create(path, branch, newBranch, baseBranch)
 		return worktreeCreatedMsg{err: err, path: path, branch: branch}
 	}
 }
@@ -824,22 +1301,53 @@ func (m Model) createWorktreeWithSession(path, sessionName string, newBranch boo
 			baseBranch = m.baseBranch
 		}
 
-		err := m.gitManager.Create(path, sessionName, newBranch, baseBranch)
+		path, err := m.gitManager.Create(path, sessionName, newBranch, baseBranch)
 		return worktreeCreatedWithSessionMsg{err: err, path: path, branch: sessionName, sessionName: sessionName}
 	}
 }
 
-func (m Model) deleteWorktree(path, branch string, force bool) tea.Cmd {
+// deleteNeedsForce reports whether deleting the selected worktree risks losing work
+// and therefore needs the "Force Delete" confirmation
+func (m Model) deleteNeedsForce() bool {
+	return m.deleteHasUncommitted || m.deleteAtRisk > 0 || m.deleteAtRiskErr != nil
+}
+
+// hookFailureNotice describes a failed hook for a notification, pointing to its log
+// The hook's output is left out: it's in the log, and notifications are a single line
+func hookFailureNotice(err error) string {
+	var hookErr *git.HookError
+	if !errors.As(err, &hookErr) {
+		return err.Error()
+	}
+	notice := fmt.Sprintf("%s hook failed: %v", hookErr.Hook, hookErr.Err)
+	if hookErr.LogPath != "" {
+		notice += ", see " + hookErr.LogPath
+	}
+	return notice
+}
+
+// deleteWorktree removes a worktree and its branch
+// deleteUnpushed confirms deleting a branch with commits that aren't on any remote or the base branch
+func (m Model) deleteWorktree(path, branch string, force, deleteUnpushed bool) tea.Cmd {
+	m.stopScriptRuns(path)
 	return func() tea.Msg {
+		m.stopServices(path)
+
+		// Let the teardown hook stop containers, drop databases etc. while the worktree still exists
+		willDelete := m.gitManager.WillDeleteBranch(branch, m.baseBranch, deleteUnpushed)
+		hookErr := m.gitManager.RunHook(git.HookTeardown, path, map[string]string{"JEAN_DELETE_BRANCH": fmt.Sprint(willDelete)})
+
 		// First remove the worktree
-		err := m.gitManager.Remove(path, force)
-		if err != nil {
-			return worktreeDeletedMsg{err: err}
+		err := m.gitManager.Remove(path, m.baseBranch, force, deleteUnpushed)
+		var kept *git.BranchKeptError
+		if err != nil && !errors.As(err, &kept) {
+			return worktreeDeletedMsg{err: err, hookErr: hookErr}
 		}
 
 		// Clean up branch-specific config data (PRs, Claude initialization, etc.)
 		// This prevents config file bloat and removes stale references
-		if m.configManager != nil {
+		// Kept branches keep their data so it's still there when the branch gets a new worktree
+		if m.configManager != nil && kept == nil {
 			_ = m.configManager.CleanupBranch(m.repoPath, branch) // Ignore error, not critical
 		}
 
@@ -848,7 +1356,7 @@ func (m Model) deleteWorktree(path, branch string, force bool) tea.Cmd {
 		sessionName := m.sessionManager.SanitizeName(repoName, branch)
 		_ = m.sessionManager.Kill(sessionName) // Ignore error if session doesn't exist
 
-		return worktreeDeletedMsg{err: nil}
+		return worktreeDeletedMsg{err: nil, kept: kept, hookErr: hookErr}
 	}
 }
 
@@ -875,7 +1383,7 @@ func (m Model) createWorktreeFromPR(branch string) tea.Cmd {
 
 		// Create worktree from the PR's branch (existing branch, not new)
 		m.debugLog(fmt.Sprintf("createWorktreeFromPR: calling gitManager.Create() with args: path='%s', branch='%s', newBranch=false, baseBranch=''", path, branch))
-		err = m.gitManager.Create(path, branch, false, "")
+		path, err = m.gitManager.Create(path, branch, false, "")
 		if err != nil {
 			m.debugLog("createWorktreeFromPR: gitManager.Create() failed - " + err.Error())
 		} else {
@@ -885,10 +1393,21 @@ func (m Model) createWorktreeFromPR(branch string) tea.Cmd {
 	}
 }
 
-func (m Model) ensureWorktreeExists(worktreePath, branch string) tea.Cmd {
+// ensureWorktreeExists recreates the worktree if needed and runs the post-switch hook before switching
+// target is the window being switched to ("claude" or "terminal")
+func (m Model) ensureWorktreeExists(worktreePath, branch, target string) tea.Cmd {
 	return func() tea.Msg {
-		err := m.gitManager.EnsureWorktreeExists(worktreePath, branch)
-		return worktreeEnsuredMsg{err: err}
+		recreated, err := m.gitManager.EnsureWorktreeExists(worktreePath, branch)
+		if err != nil {
+			return worktreeEnsuredMsg{err: err}
+		}
+		var setupErr error
+		if recreated {
+			// Setup keeps running in the background after jean exits, like for new worktrees
+			_, setupErr = m.gitManager.StartSetup(worktreePath)
+		}
+		hookErr := m.gitManager.RunHook(git.HookPostSwitch, worktreePath, map[string]string{"JEAN_TARGET": target})
+		return worktreeEnsuredMsg{hookErr: hookErr, setupErr: setupErr}
 	}
 }
 
@@ -908,6 +1427,11 @@ func (m Model) renameBranch(oldName, newName, worktreePath string) tea.Cmd {
 			}
 		}
 
+		// The worktree keeps its ports under the new name
+		if m.configManager != nil {
+			_ = m.configManager.RenamePortBlock(m.repoPath, oldName, newName)
+		}
+
 		// Success: branch renamed, directory path unchanged
 		return branchRenamedMsg{
 			oldBranch: oldName,
@@ -1030,7 +1554,7 @@ func (m Model) createPR(worktreePath, branch string, optionalTitle string, optio
 		// Only push if branch doesn't exist remotely or has unpushed commits
 		if !remoteBranchExists {
 			// Push the branch for the first time
-			if err := m.gitManager.Push(worktreePath, branch); err != nil {
+			if err := m.pushWithHook(worktreePath, branch); err != nil {
 				return prCreatedMsg{err: fmt.Errorf("failed to push commits: %w", err), isDraft: m.prIsDraft}
 			}
 		} else {
@@ -1041,7 +1565,7 @@ func (m Model) createPR(worktreePath, branch string, optionalTitle string, optio
 			}
 			if hasUnpushed {
 				// Push new commits
-				if err := m.gitManager.Push(worktreePath, branch); err != nil {
+				if err := m.pushWithHook(worktreePath, branch); err != nil {
 					return prCreatedMsg{err: fmt.Errorf("failed to push commits: %w", err), isDraft: m.prIsDraft}
 				}
 			}
@@ -1060,7 +1584,10 @@ func (m Model) createPR(worktreePath, branch string, optionalTitle string, optio
 		description := optionalDescription
 
 		// Create PR (draft or ready for review based on user selection)
-		prURL, err := m.githubManager.CreatePR(worktreePath, branch, m.baseBranch, title, description, m.prIsDraft)
+		if err := m.runPrePRHook(worktreePath, title); err != nil {
+			return prCreatedMsg{err: err, branch: branch, worktreePath: worktreePath, isDraft: m.prIsDraft}
+		}
+		prURL, err := m.githubManager.CreatePR(worktreePath, m.prHeadRef(branch), m.baseBranch, title, description, m.prIsDraft)
 		if err != nil {
 			return prCreatedMsg{err: err, branch: branch, worktreePath: worktreePath}
 		}
@@ -1108,7 +1635,10 @@ func (m Model) createOrUpdatePR(worktreePath, branch string, title string, descr
 		}
 
 		// PR doesn't exist, create a new one (draft or ready for review based on user selection)
-		prURL, err := m.githubManager.CreatePR(worktreePath, branch, m.baseBranch, title, description, m.prIsDraft)
+		if err := m.runPrePRHook(worktreePath, title); err != nil {
+			return prCreatedMsg{err: err, branch: branch, worktreePath: worktreePath, isDraft: m.prIsDraft}
+		}
+		prURL, err := m.githubManager.CreatePR(worktreePath, m.prHeadRef(branch), m.baseBranch, title, description, m.prIsDraft)
 		if err != nil {
 			return prCreatedMsg{err: err, branch: branch, worktreePath: worktreePath, isDraft: m.prIsDraft}
 		}
@@ -1130,6 +1660,9 @@ func (m Model) createCommit(worktreePath, subject string) tea.Cmd {
 			return commitCreatedMsg{err: fmt.Errorf("commit subject cannot be empty")}
 		}
 
+		if err := m.gitManager.RunHook(git.HookPreCommit, worktreePath, map[string]string{"JEAN_COMMIT_MESSAGE": subject}); err != nil {
+			return commitCreatedMsg{err: err}
+		}
 		commitHash, err := m.gitManager.CreateCommit(worktreePath, subject)
 		return commitCreatedMsg{err: err, commitHash: commitHash, subject: subject}
 	}
@@ -1148,6 +1681,9 @@ func (m Model) autoCommitBeforePR(worktreePath, branch string) tea.Cmd {
 			subject = strings.ToUpper(subject[:1]) + subject[1:]
 		}
 
+		if err := m.gitManager.RunHook(git.HookPreCommit, worktreePath, map[string]string{"JEAN_COMMIT_MESSAGE": subject}); err != nil {
+			return autoCommitBeforePRMsg{worktreePath: worktreePath, branch: branch, err: err}
+		}
 		_, err := m.gitManager.CreateCommit(worktreePath, subject)
 		return autoCommitBeforePRMsg{worktreePath: worktreePath, branch: branch, err: err}
 	}
@@ -1213,8 +1749,33 @@ func (m Model) generateCommitMessageWithAI(worktreePath string) tea.Cmd {
 	}
 }
 
+// loadNamingRules reads the branch naming rules from jean.json (nil if there are none)
+func (m Model) loadNamingRules() *config.BranchNamingRules {
+	scriptConfig, err := config.LoadScripts(m.repoPath)
+	if err != nil {
+		return nil
+	}
+	return scriptConfig.GetBranchNaming()
+}
+
+// newBranchName builds the branch name for the create modal's input
+// An input without a description (empty, or only a prefix and ticket ID) is completed with the
+// random name, using the first allowed prefix if none was picked
+func newBranchName(input string, rules *config.BranchNamingRules, random string) string {
+	name := git.SanitizeBranchName(input)
+	prefix, ticket, rest := rules.SplitName(name)
+	if rest != "" {
+		return name
+	}
+	if prefixes := rules.GetPrefixes(); prefix == "" && len(prefixes) > 0 {
+		prefix = prefixes[0]
+	}
+	return rules.FormatName(prefix, ticket, random, 0)
+}
+
 // generateRenameWithAI generates a branch name suggestion based on git changes
-func (m Model) generateRenameWithAI(worktreePath, baseBranch string) tea.Cmd {
+// A ticket ID in currentName is kept in the suggestion
+func (m Model) generateRenameWithAI(worktreePath, currentName, baseBranch string) tea.Cmd {
 	return func() tea.Msg {
 		// Get uncommitted changes
 		diff := ""
@@ -1235,7 +1796,9 @@ func (m Model) generateRenameWithAI(worktreePath, baseBranch string) tea.Cmd {
 		// Call Claude CLI
 		client := claude.NewClient()
 		customPrompt := m.configManager.GetBranchNamePrompt()
-		name, err := client.GenerateBranchName(diff, customPrompt)
+		rules := m.loadNamingRules()
+		_, ticket, _ := rules.SplitName(currentName)
+		name, err := client.GenerateBranchName(diff, customPrompt, rules, ticket)
 		if err != nil {
 			return renameGeneratedMsg{err: fmt.Errorf("failed to generate branch name: %w", err)}
 		}
@@ -1269,7 +1832,9 @@ func (m Model) generateBranchNameForPR(worktreePath, oldBranch, baseBranch strin
 		// Call Claude CLI
 		client := claude.NewClient()
 		customPrompt := m.configManager.GetBranchNamePrompt()
-		newName, err := client.GenerateBranchName(diff, customPrompt)
+		rules := m.loadNamingRules()
+		_, ticket, _ := rules.SplitName(oldBranch)
+		newName, err := client.GenerateBranchName(diff, customPrompt, rules, ticket)
 
 		return prBranchNameGeneratedMsg{
 			oldBranchName: oldBranch,
@@ -1307,16 +1872,18 @@ func (m Model) renameBranchForPR(oldName, newName, worktreePath string) tea.Cmd
 			}
 		}
 
+		// The worktree keeps its ports under the new name
+		if m.configManager != nil {
+			_ = m.configManager.RenamePortBlock(m.repoPath, oldName, newName)
+		}
+
 		// Step 2: Rename directory if it's a workspace worktree
 		workspacesDir, err := m.gitManager.GetWorkspacesDir()
-		if err == nil && strings.HasPrefix(worktreePath, workspacesDir) {
-			// Sanitize the new branch name for use as directory name
-			sanitizedDirName := git.SanitizeBranchName(newName)
-			sanitizedDirName = strings.ReplaceAll(sanitizedDirName, "/", "-")
-			newPath := filepath.Join(workspacesDir, sanitizedDirName)
-
-			// Move the worktree directory (non-critical if it fails)
-			_ = m.gitManager.MoveWorktree(worktreePath, newPath)
+		if err == nil && strings.HasPrefix(worktreePath, workspacesDir+string(filepath.Separator)) {
+			// Build the new directory from the path template (non-critical if it fails)
+			if newPath, err := m.gitManager.GetDefaultPath(git.SanitizeBranchName(newName)); err == nil {
+				_ = m.gitManager.MoveWorktree(worktreePath, newPath)
+			}
 		}
 
 		return prBranchRenamedMsg{
@@ -1494,7 +2061,9 @@ func (m Model) generateBranchNameForPush(worktreePath, oldBranch, baseBranch str
 		// Call Claude CLI
 		client := claude.NewClient()
 		customPrompt := m.configManager.GetBranchNamePrompt()
-		newName, err := client.GenerateBranchName(diff, customPrompt)
+		rules := m.loadNamingRules()
+		_, ticket, _ := rules.SplitName(oldBranch)
+		newName, err := client.GenerateBranchName(diff, customPrompt, rules, ticket)
 
 		return pushBranchNameGeneratedMsg{
 			oldBranchName: oldBranch,
@@ -1532,18 +2101,21 @@ func (m Model) renameBranchForPush(oldName, newName, worktreePath string) tea.Cm
 			}
 		}
 
+		// The worktree keeps its ports under the new name
+		if m.configManager != nil {
+			_ = m.configManager.RenamePortBlock(m.repoPath, oldName, newName)
+		}
+
 		// Step 2: Rename directory if it's a workspace worktree
 		newWorktreePath := worktreePath
 		workspacesDir, err := m.gitManager.GetWorkspacesDir()
-		if err == nil && strings.HasPrefix(worktreePath, workspacesDir) {
-			// Sanitize the new branch name for use as directory name
-			sanitizedDirName := git.SanitizeBranchName(newName)
-			sanitizedDirName = strings.ReplaceAll(sanitizedDirName, "/", "-")
-			newPath := filepath.Join(workspacesDir, sanitizedDirName)
-
-			// Move the worktree directory (non-critical if it fails)
-			if moveErr := m.gitManager.MoveWorktree(worktreePath, newPath); moveErr == nil {
-				newWorktreePath = newPath
+		if err == nil && strings.HasPrefix(worktreePath, workspacesDir+string(filepath.Separator)) {
+			// Build the new directory from the path template
+			if newPath, err := m.gitManager.GetDefaultPath(git.SanitizeBranchName(newName)); err == nil {
+				// Move the worktree directory (non-critical if it fails)
+				if moveErr := m.gitManager.MoveWorktree(worktreePath, newPath); moveErr == nil {
+					newWorktreePath = newPath
+				}
 			}
 		}
 
@@ -1569,7 +2141,7 @@ func (m Model) pushBranch(worktreePath, branch string) tea.Cmd {
 		}
 
 		// Push the branch
-		if err := m.gitManager.Push(worktreePath, branch); err != nil {
+		if err := m.pushWithHook(worktreePath, branch); err != nil {
 			return pushCompletedMsg{branch: branch, err: fmt.Errorf("failed to push: %w", err)}
 		}
 
@@ -1577,6 +2149,36 @@ func (m Model) pushBranch(worktreePath, branch string) tea.Cmd {
 	}
 }
 
+// pushWithHook runs the pre-push hook and pushes the branch if it passes
+func (m Model) pushWithHook(worktreePath, branch string) error {
+	vars := map[string]string{"JEAN_REMOTE": m.gitManager.PushRemote()}
+	if err := m.gitManager.RunHook(git.HookPrePush, worktreePath, vars); err != nil {
+		return err
+	}
+	return m.gitManager.Push(worktreePath, branch)
+}
+
+// runPrePRHook runs the pre-pr hook before a PR is opened against the base branch
+func (m Model) runPrePRHook(worktreePath, title string) error {
+	return m.gitManager.RunHook(git.HookPrePR, worktreePath, map[string]string{
+		"JEAN_BASE_BRANCH": m.baseBranch,
+		"JEAN_PR_TITLE":    title,
+	})
+}
+
+// runPostMergeHook runs the post-merge hook in the main repository after branch was merged into baseBranch
+func (m Model) runPostMergeHook(worktreePath, branch, baseBranch string) tea.Cmd {
+	repoPath := m.repoPath
+	return func() tea.Msg {
+		err := m.gitManager.RunHook(git.HookPostMerge, repoPath, map[string]string{
+			"JEAN_MERGED_BRANCH":   branch,
+			"JEAN_MERGED_WORKTREE": worktreePath,
+			"JEAN_BASE_BRANCH":     baseBranch,
+		})
+		return hookFinishedMsg{hook: git.HookPostMerge, err: err}
+	}
+}
+
 // Helper methods
 func (m Model) selectedWorktree() *git.Worktree {
 	if m.selectedIndex < 0 || m.selectedIndex >= len(m.worktrees) {
@@ -1660,18 +2262,19 @@ type sessionsLoadedMsg struct {
 
 // pullFromBaseBranch pulls changes from the base branch into the worktree
 func (m Model) pullFromBaseBranch(worktreePath, baseBranch string) tea.Cmd {
+	strategy := m.getUpdateStrategy()
 	return func() tea.Msg {
 		// Fetch first to ensure we have latest changes
 		if err := m.gitManager.FetchRemote(); err != nil {
 			return branchPulledMsg{err: fmt.Errorf("failed to fetch: %w", err)}
 		}
 
-		// Merge base branch into current branch
-		err := m.gitManager.MergeBranch(worktreePath, baseBranch)
+		// Bring base branch changes into current branch using the configured strategy
+		err := m.gitManager.UpdateFromBase(worktreePath, baseBranch, strategy)
 		if err != nil {
-			// Check if it's a merge conflict
-			if strings.Contains(err.Error(), "merge conflict") {
-				return branchPulledMsg{err: err, hadConflict: true}
+			// Check if it stopped on conflicts
+			if isConflictError(err) {
+				return branchPulledMsg{err: err, hadConflict: true, worktreePath: worktreePath}
 			}
 			return branchPulledMsg{err: err, hadConflict: false}
 		}
@@ -1683,6 +2286,7 @@ func (m Model) pullFromBaseBranch(worktreePath, baseBranch string) tea.Cmd {
 // checkAndPullFromBase fetches first, then checks if behind, then pulls if needed
 // This ensures we check against actual remote state, not stale cached data
 func (m Model) checkAndPullFromBase(worktreePath, baseBranch string) tea.Cmd {
+	strategy := m.getUpdateStrategy()
 	return func() tea.Msg {
 		// First: Fetch to get latest remote refs
 		if err := m.gitManager.FetchRemote(); err != nil {
@@ -1701,12 +2305,12 @@ func (m Model) checkAndPullFromBase(worktreePath, baseBranch string) tea.Cmd {
 			return branchPulledMsg{err: fmt.Errorf("worktree is already up-to-date with base branch"), hadConflict: false}
 		}
 
-		// Fourth: Pull if behind
-		err = m.gitManager.MergeBranch(worktreePath, baseBranch)
+		// Fourth: Pull if behind, using the configured update strategy
+		err = m.gitManager.UpdateFromBase(worktreePath, baseBranch, strategy)
 		if err != nil {
-			// Check if it's a merge conflict
-			if strings.Contains(err.Error(), "merge conflict") {
-				return branchPulledMsg{err: err, hadConflict: true}
+			// Check if it stopped on conflicts
+			if isConflictError(err) {
+				return branchPulledMsg{err: err, hadConflict: true, worktreePath: worktreePath}
 			}
 			return branchPulledMsg{err: err, hadConflict: false}
 		}
@@ -1776,6 +2380,7 @@ func (m Model) executeLocalMerge(worktreePath, branch, baseBranch string) tea.Cm
 					worktreePath: worktreePath,
 					err:          err,
 					hadConflict:  true,
+					conflictPath: repoRoot,
 				}
 			}
 			return localMergeCompletedMsg{
@@ -1797,8 +2402,10 @@ func (m Model) executeLocalMerge(worktreePath, branch, baseBranch string) tea.Cm
 
 // refreshWithPull fetches latest commits from remote and pulls all worktrees
 // Automatically pulls changes into ALL worktrees (main repo + workspace branches)
-// Skips worktrees with uncommitted changes to prevent merge conflicts
+// Skips worktrees with uncommitted changes to prevent merge conflicts, unless auto-stash is enabled
 func (m Model) refreshWithPull() tea.Cmd {
+	strategy := m.getUpdateStrategy()
+	autoStash := m.configManager != nil && m.configManager.GetAutoStash(m.repoPath)
 	return func() tea.Msg {
 		msg := refreshWithPullMsg{
 			updatedBranches: make(map[string]int),
@@ -1818,8 +2425,25 @@ func (m Model) refreshWithPull() tea.Cmd {
 
 			// Check if this worktree has uncommitted changes
 			hasUncommitted, _ := m.gitManager.HasUncommittedChanges(wt.Path)
+			stashCommit := ""
 			if hasUncommitted {
-				continue // Skip pulling if there are uncommitted changes
+				// Skip pulling unless auto-stash is enabled (never stash over an unfinished merge/rebase)
+				if !autoStash || m.gitManager.GetInProgressOperation(wt.Path) != "" {
+					continue
+				}
+				if err := m.gitManager.PushStash(wt.Path, autoStashMessage); err != nil {
+					if msg.pullErr == nil {
+						msg.pullErr = fmt.Errorf("failed to stash %s: %w", wt.Branch, err)
+					}
+					continue
+				}
+				// Remember the stash by commit: stash@{0} may be a different entry by the time it's re-applied
+				commit, err := m.gitManager.StashCommit(wt.Path, "stash@{0}")
+				if err != nil {
+					msg.stashConflicts = append(msg.stashConflicts, wt.Branch)
+					continue
+				}
+				stashCommit = commit
 			}
 
 			// Pull this worktree's current branch
@@ -1827,11 +2451,22 @@ func (m Model) refreshWithPull() tea.Cmd {
 			var err error
 
 			if wt.IsCurrent {
-				// For main repo, use PullCurrentBranchWithOutput
-				output, err = m.gitManager.PullCurrentBranchWithOutput(m.repoPath, wt.Branch)
+				// For main repo, pull in the repository root
+				output, err = m.gitManager.PullBranchWithStrategy(m.repoPath, wt.Branch, strategy)
 			} else {
-				// For workspace branches, use PullBranchInPathWithOutput
-				output, err = m.gitManager.PullBranchInPathWithOutput(wt.Path, wt.Branch)
+				// For workspace branches, pull in the worktree
+				output, err = m.gitManager.PullBranchWithStrategy(wt.Path, wt.Branch, strategy)
+			}
+
+			// Re-apply auto-stashed changes (unless the pull itself stopped on conflicts)
+			if stashCommit != "" {
+				if isConflictError(err) {
+					msg.stashConflicts = append(msg.stashConflicts, wt.Branch)
+				} else if applyErr := m.gitManager.ApplyStash(wt.Path, stashCommit); applyErr != nil {
+					msg.stashConflicts = append(msg.stashConflicts, wt.Branch)
+				} else if dropErr := m.gitManager.DropStashCommit(wt.Path, stashCommit); dropErr != nil && msg.pullErr == nil {
+					msg.pullErr = fmt.Errorf("re-applied the auto-stash in %s but couldn't drop it: %w", wt.Branch, dropErr)
+				}
 			}
 
 			if err != nil {
@@ -1840,6 +2475,10 @@ func (m Model) refreshWithPull() tea.Cmd {
 				if msg.pullErr == nil {
 					msg.pullErr = fmt.Errorf("failed to pull %s: %w", wt.Branch, err)
 				}
+				// Remember the first conflicted worktree so it can be resolved right away
+				if msg.conflictPath == "" && isConflictError(err) {
+					msg.conflictPath = wt.Path
+				}
 				continue
 			}
 
@@ -1864,6 +2503,136 @@ func (m Model) scheduleActivityCheck() tea.Cmd {
 	})
 }
 
+// autoFetchIntervalOptions are the intervals (in seconds) offered in the auto-fetch settings modal
+// A negative value disables background fetching
+var autoFetchIntervalOptions = []struct {
+	name     string
+	interval int
+}{
+	{"Off", -1},
+	{"10 seconds (default)", 10},
+	{"30 seconds", 30},
+	{"1 minute", 60},
+	{"5 minutes", 300},
+	{"15 minutes", 900},
+}
+
+// updateStrategyOptions are the update strategies offered in the update strategy settings modal
+var updateStrategyOptions = []struct {
+	name        string
+	strategy    string
+	description string
+}{
+	{"Merge (default)", git.UpdateStrategyMerge, "Merge base into the branch"},
+	{"Rebase", git.UpdateStrategyRebase, "Replay branch commits on top of base"},
+	{"Fast-forward only", git.UpdateStrategyFFOnly, "Only update branches that haven't diverged"},
+}
+
+// getUpdateStrategy returns the configured update strategy for the repository
+func (m Model) getUpdateStrategy() string {
+	if m.configManager == nil {
+		return git.UpdateStrategyMerge
+	}
+	return m.configManager.GetUpdateStrategy(m.repoPath)
+}
+
+// isConflictError reports whether an operation stopped on conflicts (merge, rebase or cherry-pick) that need resolving
+func isConflictError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "merge conflict") || strings.Contains(msg, "rebase conflict") || strings.Contains(msg, "cherry-pick conflict")
+}
+
+// operationLabel returns the capitalized name of an in-progress operation for notifications
+func operationLabel(operation string) string {
+	switch operation {
+	case git.OperationRebase:
+		return "Rebase"
+	case git.OperationCherryPick:
+		return "Cherry-pick"
+	}
+	return "Merge"
+}
+
+// autoStashMessage is the stash message used when refresh stashes changes around a pull
+const autoStashMessage = "jean: auto-stash before refresh"
+
+// maxFetchBackoff caps the extra delay added after repeated fetch failures
+const maxFetchBackoff = 5 * time.Minute
+
+// autoFetchDisabledPollInterval is how often the scheduler re-checks the setting while auto-fetch is off
+const autoFetchDisabledPollInterval = 30 * time.Second
+
+// getAutoFetchInterval returns the configured background fetch interval (0 = disabled)
+func (m Model) getAutoFetchInterval() time.Duration {
+	if m.configManager == nil {
+		return 0
+	}
+	return time.Duration(m.configManager.GetAutoFetchInterval(m.repoPath)) * time.Second
+}
+
+// scheduleAutoFetch schedules the next background fetch tick
+// The delay is the configured interval plus any backoff from previous failures
+func (m Model) scheduleAutoFetch() tea.Cmd {
+	delay := m.getAutoFetchInterval()
+	if delay <= 0 {
+		delay = autoFetchDisabledPollInterval
+	}
+	delay += m.fetchBackoff
+	return tea.Tick(delay, func(t time.Time) tea.Msg {
+		return autoFetchTickMsg(t)
+	})
+}
+
+// shouldAutoFetch reports whether a background fetch should start now
+// Fetching pauses while a modal is open or another git operation is running
+func (m Model) shouldAutoFetch() bool {
+	interval := m.getAutoFetchInterval()
+	if interval <= 0 || m.autoFetchInFlight || m.isInitializing {
+		return false
+	}
+	if m.modal != noModal || m.ensuringWorktree || m.generatingCommit || m.generatingRename || m.generatingPRContent {
+		return false
+	}
+	// Skip if a fetch (background or manual refresh) happened recently
+	return time.Since(m.lastFetchTime) >= interval
+}
+
+// autoFetch fetches from origin in the background and recomputes ahead/behind counts
+// Runs without notifications; failures only increase the backoff
+func (m Model) autoFetch() tea.Cmd {
+	worktrees := m.worktrees
+	baseBranch := m.baseBranch
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		if err := m.gitManager.FetchRemoteBackground(ctx); err != nil {
+			return autoFetchCompletedMsg{err: err}
+		}
+
+		if baseBranch == "" {
+			return autoFetchCompletedMsg{}
+		}
+
+		branches := make([]string, 0, len(worktrees))
+		for _, wt := range worktrees {
+			if wt.Branch != "" && !strings.HasPrefix(wt.Branch, "(detached") {
+				branches = append(branches, wt.Branch)
+			}
+		}
+		statuses, err := m.gitManager.GetBranchStatuses(ctx, baseBranch, branches)
+		if err != nil {
+			// Fetch itself succeeded; keep the existing counts
+			m.debugLog("autoFetch: failed to refresh branch statuses: " + err.Error())
+			return autoFetchCompletedMsg{}
+		}
+		return autoFetchCompletedMsg{statuses: statuses}
+	}
+}
+
 // animateSpinner sends a spinner tick message with 100ms interval
 // Continues animating as long as generatingCommit is true
 func (m Model) animateSpinner() tea.Cmd {
@@ -2136,6 +2905,981 @@ func (m Model) loadStagingFiles() tea.Cmd {
 	}
 }
 
+// openConflictModal shows the conflict resolution modal for a worktree with an in-progress merge, rebase or cherry-pick
+func (m *Model) openConflictModal(worktreePath string) tea.Cmd {
+	m.modal = conflictModal
+	m.conflictWorktreePath = worktreePath
+	m.conflictConfirmAbort = false
+	m.conflictOperation = m.gitManager.GetInProgressOperation(worktreePath)
+	if m.conflictOperation == "" {
+		m.conflictOperation = git.OperationMerge
+	}
+	m.conflictFiles = nil
+	m.conflictIndex = 0
+	m.conflictVersions = nil
+	m.conflictView = 0
+	m.conflictScroll = 0
+	return m.loadConflictFiles()
+}
+
+// loadConflictFiles lists the unmerged paths of the worktree in the conflict modal
+func (m Model) loadConflictFiles() tea.Cmd {
+	worktreePath := m.conflictWorktreePath
+	return func() tea.Msg {
+		files, err := m.gitManager.GetConflictedFiles(worktreePath)
+		return conflictFilesLoadedMsg{files: files, err: err}
+	}
+}
+
+// loadConflictVersions loads base/ours/theirs for the selected conflicted file
+func (m Model) loadConflictVersions() tea.Cmd {
+	if m.conflictIndex < 0 || m.conflictIndex >= len(m.conflictFiles) {
+		return nil
+	}
+	worktreePath := m.conflictWorktreePath
+	file := m.conflictFiles[m.conflictIndex]
+	return func() tea.Msg {
+		versions, err := m.gitManager.GetConflictVersions(worktreePath, file)
+		return conflictVersionsLoadedMsg{file: file, versions: versions, err: err}
+	}
+}
+
+// resolveConflictFile takes one side of a conflict ("ours"/"theirs") or marks an edited file as resolved
+func (m Model) resolveConflictFile(file, action string) tea.Cmd {
+	worktreePath := m.conflictWorktreePath
+	return func() tea.Msg {
+		var err error
+		switch action {
+		case "ours":
+			err = m.gitManager.ResolveWithOurs(worktreePath, file)
+		case "theirs":
+			err = m.gitManager.ResolveWithTheirs(worktreePath, file)
+		default:
+			err = m.gitManager.MarkResolved(worktreePath, file)
+		}
+		return conflictFileResolvedMsg{file: file, action: action, err: err}
+	}
+}
+
+// finishConflict either commits the resolved merge or aborts it
+// For a stopped rebase or cherry-pick it continues (or aborts) that operation instead
+func (m Model) finishConflict(abort bool) tea.Cmd {
+	worktreePath := m.conflictWorktreePath
+	switch m.conflictOperation {
+	case git.OperationRebase:
+		return func() tea.Msg {
+			if abort {
+				return conflictFinishedMsg{aborted: true, err: m.gitManager.AbortRebase(worktreePath)}
+			}
+			return sequencerStepResult(m.gitManager.ContinueRebase(worktreePath))
+		}
+	case git.OperationCherryPick:
+		return func() tea.Msg {
+			if abort {
+				return conflictFinishedMsg{aborted: true, err: m.gitManager.AbortCherryPick(worktreePath)}
+			}
+			return sequencerStepResult(m.gitManager.ContinueCherryPick(worktreePath))
+		}
+	}
+	return func() tea.Msg {
+		if abort {
+			return conflictFinishedMsg{aborted: true, err: m.gitManager.AbortMerge(worktreePath)}
+		}
+		return conflictFinishedMsg{err: m.gitManager.CommitMerge(worktreePath)}
+	}
+}
+
+// skipConflictCommit drops the commit a rebase or cherry-pick stopped on and moves on to the next one
+func (m Model) skipConflictCommit() tea.Cmd {
+	worktreePath := m.conflictWorktreePath
+	operation := m.conflictOperation
+	return func() tea.Msg {
+		if operation == git.OperationCherryPick {
+			return sequencerStepResult(m.gitManager.SkipCherryPick(worktreePath))
+		}
+		return sequencerStepResult(m.gitManager.SkipRebase(worktreePath))
+	}
+}
+
+// sequencerStepResult turns the outcome of a rebase/cherry-pick continue or skip into a conflictFinishedMsg
+// An operation that stops on the next commit's conflicts keeps the conflict modal open
+func sequencerStepResult(err error) conflictFinishedMsg {
+	if isConflictError(err) {
+		return conflictFinishedMsg{stillInProgress: true}
+	}
+	return conflictFinishedMsg{err: err}
+}
+
+// openDiffViewer shows the full-screen diff viewer for a worktree
+// Starts with the changes versus base when a base branch is set, otherwise the unstaged changes
+func (m *Model) openDiffViewer(wt *git.Worktree) tea.Cmd {
+	m.modal = diffViewerModal
+	m.diffViewerPath = wt.Path
+	m.diffViewerBranch = wt.Branch
+	m.diffViewerMode = git.DiffUnstaged
+	if m.baseBranch != "" {
+		m.diffViewerMode = git.DiffBase
+	}
+	m.diffViewerFiles = nil
+	m.diffViewerIndex = 0
+	m.diffViewerScroll = 0
+	return m.loadDiffViewer()
+}
+
+// loadDiffViewer loads the changed files of the diff viewer's worktree for the current mode
+func (m Model) loadDiffViewer() tea.Cmd {
+	path := m.diffViewerPath
+	mode := m.diffViewerMode
+	baseBranch := m.baseBranch
+	return func() tea.Msg {
+		files, err := m.gitManager.GetFileDiffs(path, mode, baseBranch)
+		return diffViewerLoadedMsg{path: path, mode: mode, files: files, err: err}
+	}
+}
+
+// selectedDiffFile returns the file selected in the diff viewer
+func (m Model) selectedDiffFile() *git.FileDiff {
+	if m.diffViewerIndex < 0 || m.diffViewerIndex >= len(m.diffViewerFiles) {
+		return nil
+	}
+	return m.diffViewerFiles[m.diffViewerIndex]
+}
+
+// diffViewerVisibleLines returns how many diff lines fit in the diff viewer's right panel
+func (m Model) diffViewerVisibleLines() int {
+	visible := m.height - 10
+	if visible < 5 {
+		visible = 5
+	}
+	return visible
+}
+
+// fileDiffLines flattens a file diff into display lines (hunk headers and bodies)
+// and returns the line offsets where each hunk starts
+func fileDiffLines(diff *git.FileDiff) ([]string, []int) {
+	var lines []string
+	var hunkStarts []int
+	for _, hunk := range diff.Hunks {
+		hunkStarts = append(hunkStarts, len(lines))
+		lines = append(lines, fmt.Sprintf("@@ -%d,%d +%d,%d @@%s", hunk.OldStart, hunk.OldLines, hunk.NewStart, hunk.NewLines, hunk.Section))
+		lines = append(lines, hunk.Lines...)
+	}
+	return lines, hunkStarts
+}
+
+// applyRemotes configures git and gh with the repository's push and base remotes
+func (m Model) applyRemotes() {
+	if m.configManager == nil {
+		return
+	}
+	push := m.configManager.GetPushRemote(m.repoPath)
+	base := m.configManager.GetBaseRemote(m.repoPath)
+	m.gitManager.SetRemotes(push, base)
+
+	// When pushing to a fork, pin gh to the upstream repo so PRs target it
+	repo := ""
+	if push != base {
+		repo, _ = m.gitManager.GetGitHubRepo(base)
+	}
+	m.githubManager.SetRepo(repo)
+}
+
+// applyPathTemplate configures git with the repository's worktree path template
+func (m Model) applyPathTemplate() {
+	if m.configManager == nil {
+		return
+	}
+	m.gitManager.SetPathTemplate(m.configManager.GetWorktreePathTemplate(m.repoPath))
+}
+
+// applyPortAllocator lets git reserve port blocks for worktrees, recorded in the config
+func (m Model) applyPortAllocator() {
+	if m.configManager == nil {
+		return
+	}
+	configManager := m.configManager
+	repoPath := m.repoPath
+	m.gitManager.SetPortAllocator(func(branch string) (int, error) {
+		return configManager.AllocatePortBlock(repoPath, branch, git.PortBlockFree)
+	})
+}
+
+// relocateWorktree moves a worktree directory to newPath
+func (m Model) relocateWorktree(oldPath, newPath string) tea.Cmd {
+	return func() tea.Msg {
+		resolved, err := m.gitManager.RelocateWorktree(oldPath, newPath)
+		return worktreeRelocatedMsg{oldPath: oldPath, newPath: resolved, err: err}
+	}
+}
+
+// indexOf returns the index of value in values, or 0 if it isn't there
+func indexOf(values []string, value string) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+	return 0
+}
+
+// prHeadRef returns the head of a new PR: "owner:branch" when branches are pushed to a fork, otherwise the branch
+func (m Model) prHeadRef(branch string) string {
+	push := m.gitManager.PushRemote()
+	if push == m.gitManager.BaseRemote() {
+		return branch
+	}
+	repo, err := m.gitManager.GetGitHubRepo(push)
+	if err != nil {
+		return branch
+	}
+	owner, _, _ := strings.Cut(repo, "/")
+	return owner + ":" + branch
+}
+
+// fetchAllRemotes fetches every remote in the background so the branch picker can list their branches
+func (m Model) fetchAllRemotes() tea.Cmd {
+	return func() tea.Msg {
+		return remotesFetchedMsg{err: m.gitManager.FetchAllRemotes()}
+	}
+}
+
+// scriptRunKey identifies the background runs of a script in a worktree
+func scriptRunKey(worktreePath, name string) string {
+	return worktreePath + "\x00" + name
+}
+
+// scriptRunStatus describes the state of a background run: "running", "succeeded", "failed" or "stopped"
+func scriptRunStatus(run *git.ScriptRun) string {
+	switch {
+	case run == nil:
+		return ""
+	case run.Running():
+		return "running"
+	case run.Stopped():
+		return "stopped"
+	case run.Err() != nil:
+		return "failed"
+	default:
+		return "succeeded"
+	}
+}
+
+// openScriptsModal shows the run menu with the jean.json scripts for a worktree
+func (m *Model) openScriptsModal(wt *git.Worktree) tea.Cmd {
+	m.modal = scriptsModal
+	m.scriptsWorktreePath = wt.Path
+	m.scriptsBranch = wt.Branch
+	m.scriptIndex = 0
+	m.scripts = nil
+	m.scriptNames = nil
+
+	scriptConfig, err := config.LoadScripts(m.repoPath)
+	if err != nil {
+		return m.showErrorNotification("Failed to load jean.json: "+err.Error(), 4*time.Second)
+	}
+	m.scripts = scriptConfig.Scripts
+	m.scriptNames = scriptConfig.GetScriptNames()
+	m.scriptServices = make(map[string]bool)
+	for _, name := range m.scriptNames {
+		m.scriptServices[name] = scriptConfig.IsService(name)
+	}
+	return m.loadServices()
+}
+
+// selectedScript returns the name of the script selected in the run menu
+func (m Model) selectedScript() string {
+	if m.scriptIndex < 0 || m.scriptIndex >= len(m.scriptNames) {
+		return ""
+	}
+	return m.scriptNames[m.scriptIndex]
+}
+
+// startScript runs a jean.json script in the background
+func (m Model) startScript(worktreePath, branch, name string) tea.Cmd {
+	return func() tea.Msg {
+		run, err := m.gitManager.StartScript(worktreePath, branch, name)
+		return scriptStartedMsg{run: run, err: err}
+	}
+}
+
+// startSetup runs the jean.json setup script in a new or re-added worktree in the background
+func (m Model) startSetup(worktreePath string) tea.Cmd {
+	return func() tea.Msg {
+		run, err := m.gitManager.StartSetup(worktreePath)
+		return scriptStartedMsg{run: run, afterCreate: true, err: err}
+	}
+}
+
+// stopScriptRuns stops the background scripts running in a worktree, e.g. before it's deleted
+func (m Model) stopScriptRuns(worktreePath string) {
+	for _, run := range m.scriptRuns {
+		if run.Path == worktreePath {
+			_ = run.Stop()
+		}
+	}
+}
+
+// waitForScript reports when a background script exits
+func waitForScript(run *git.ScriptRun) tea.Cmd {
+	return func() tea.Msg {
+		_ = run.Wait()
+		return scriptFinishedMsg{run: run}
+	}
+}
+
+// prepareScriptRunner writes the runner script the shell wrapper executes for a foreground run
+func (m Model) prepareScriptRunner(worktreePath, branch, name string) tea.Cmd {
+	return func() tea.Msg {
+		runner, err := m.gitManager.WriteScriptRunner(worktreePath, branch, name)
+		return scriptRunnerReadyMsg{path: worktreePath, branch: branch, runner: runner, err: err}
+	}
+}
+
+// openScriptLog shows the output of a script's latest background run in a worktree
+func (m *Model) openScriptLog(worktreePath, branch, name string) tea.Cmd {
+	m.scriptLogBack = m.modal
+	m.modal = scriptLogModal
+	m.scriptLogWorktree = worktreePath
+	m.scriptLogBranch = branch
+	m.scriptLogName = name
+	m.scriptLogLines = nil
+	m.scriptLogMissing = false
+	m.scriptLogScroll = 0
+	m.scriptLogFollow = true
+	m.scriptLogService = false
+	if scriptConfig, err := config.LoadScripts(m.repoPath); err == nil {
+		m.scriptLogService = scriptConfig.IsService(name)
+	}
+
+	cmds := []tea.Cmd{m.loadScriptLog()}
+	if !m.scriptLogTicking {
+		m.scriptLogTicking = true
+		cmds = append(cmds, scriptLogTick())
+	}
+	return tea.Batch(cmds...)
+}
+
+// scriptLogRun returns the background run whose log is shown, if it was started in this session
+func (m Model) scriptLogRun() *git.ScriptRun {
+	return m.scriptRuns[scriptRunKey(m.scriptLogWorktree, m.scriptLogName)]
+}
+
+// scriptLogActive reports whether the output shown in the script log viewer may still grow
+func (m Model) scriptLogActive() bool {
+	if run := m.scriptLogRun(); run != nil && run.Running() {
+		return true
+	}
+	return m.serviceFor(m.scriptLogWorktree, m.scriptLogName) != nil
+}
+
+// loadScriptLog reads the log shown in the script log viewer
+func (m Model) loadScriptLog() tea.Cmd {
+	worktreePath := m.scriptLogWorktree
+	name := m.scriptLogName
+	run := m.scriptLogRun()
+	service := m.serviceFor(worktreePath, name)
+	return func() tea.Msg {
+		var logPath string
+		if service != nil {
+			logPath = service.LogPath
+		} else if run != nil {
+			logPath = run.LogPath
+		} else {
+			var err error
+			logPath, err = m.gitManager.ScriptLogPath(worktreePath, name)
+			if err != nil {
+				return scriptLogLoadedMsg{path: worktreePath, name: name, err: err}
+			}
+		}
+		lines, err := git.ReadLog(logPath)
+		if os.IsNotExist(err) {
+			return scriptLogLoadedMsg{path: worktreePath, name: name, lines: []string{}, missing: true}
+		}
+		return scriptLogLoadedMsg{path: worktreePath, name: name, lines: lines, err: err}
+	}
+}
+
+// scriptLogTick schedules the next refresh of the script log viewer
+func scriptLogTick() tea.Cmd {
+	return tea.Tick(500*time.Millisecond, func(t time.Time) tea.Msg {
+		return scriptLogTickMsg{}
+	})
+}
+
+// scriptLogVisibleLines returns how many log lines fit on screen
+func (m Model) scriptLogVisibleLines() int {
+	if lines := m.height - 6; lines > 5 {
+		return lines
+	}
+	return 5
+}
+
+// clampScriptLogScroll keeps the log scroll position in range, pinning it to the end while following
+func (m *Model) clampScriptLogScroll() {
+	maxScroll := len(m.scriptLogLines) - m.scriptLogVisibleLines()
+	if maxScroll < 0 {
+		maxScroll = 0
+	}
+	if m.scriptLogFollow || m.scriptLogScroll > maxScroll {
+		m.scriptLogScroll = maxScroll
+	}
+	if m.scriptLogScroll < 0 {
+		m.scriptLogScroll = 0
+	}
+}
+
+// loadServices reads the running services from the process manager's state file
+func (m Model) loadServices() tea.Cmd {
+	if m.processManager == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		services, err := m.processManager.List()
+		return servicesLoadedMsg{services: services, err: err}
+	}
+}
+
+// serviceFor returns the running service with the given name in a worktree, or nil
+func (m Model) serviceFor(worktreePath, name string) *process.Service {
+	for i := range m.services {
+		if m.services[i].Worktree == worktreePath && m.services[i].Name == name {
+			return &m.services[i]
+		}
+	}
+	return nil
+}
+
+// dropService removes a service from the list until the next refresh, e.g. once it's stopped
+func (m *Model) dropService(worktreePath, name string) {
+	remaining := make([]process.Service, 0, len(m.services))
+	for _, svc := range m.services {
+		if svc.Worktree != worktreePath || svc.Name != name {
+			remaining = append(remaining, svc)
+		}
+	}
+	m.services = remaining
+}
+
+// servicesIn returns the names of the services running in a worktree
+func (m Model) servicesIn(worktreePath string) []string {
+	var names []string
+	for _, svc := range m.services {
+		if svc.Worktree == worktreePath {
+			names = append(names, svc.Name)
+		}
+	}
+	return names
+}
+
+// launchService starts a jean.json script as a service with its output going to the script's log
+func (m Model) launchService(worktreePath, branch, name string) (*process.Service, error) {
+	if m.processManager == nil {
+		return nil, fmt.Errorf("services are unavailable: ~/.config/jean couldn't be created")
+	}
+	cmd, err := m.gitManager.ScriptCommand(worktreePath, branch, name)
+	if err != nil {
+		return nil, err
+	}
+	logPath, err := m.gitManager.ScriptLogPath(worktreePath, name)
+	if err != nil {
+		return nil, err
+	}
+	return m.processManager.Start(cmd, worktreePath, branch, name, logPath)
+}
+
+// startService starts a jean.json script as a service
+func (m Model) startService(worktreePath, branch, name string) tea.Cmd {
+	return func() tea.Msg {
+		service, err := m.launchService(worktreePath, branch, name)
+		return serviceStartedMsg{service: service, err: err}
+	}
+}
+
+// stopService stops a service
+func (m Model) stopService(worktreePath, branch, name string) tea.Cmd {
+	return func() tea.Msg {
+		err := m.processManager.Stop(worktreePath, name)
+		return serviceStoppedMsg{path: worktreePath, branch: branch, name: name, err: err}
+	}
+}
+
+// restartService stops a service (if it's running) and starts it again
+func (m Model) restartService(worktreePath, branch, name string) tea.Cmd {
+	return func() tea.Msg {
+		if m.processManager != nil {
+			if err := m.processManager.Stop(worktreePath, name); err != nil {
+				return serviceStartedMsg{restarted: true, err: err}
+			}
+		}
+		service, err := m.launchService(worktreePath, branch, name)
+		return serviceStartedMsg{service: service, restarted: true, err: err}
+	}
+}
+
+// stopServices stops the services running in a worktree, e.g. before it's deleted (blocking)
+func (m Model) stopServices(worktreePath string) {
+	if m.processManager != nil {
+		_ = m.processManager.StopAll(worktreePath)
+	}
+}
+
+// cleanupCandidate is a worktree suggested for removal in the cleanup modal
+type cleanupCandidate struct {
+	worktree git.Worktree
+	reasons  []string // Why it's suggested (merged, PR closed, untouched)
+}
+
+// openCleanupModal shows the bulk cleanup screen
+func (m *Model) openCleanupModal() tea.Cmd {
+	m.modal = cleanupModal
+	m.cleanupCandidates = nil
+	m.cleanupIndex = 0
+	m.cleanupSelected = make(map[string]bool)
+	m.cleanupConfirm = false
+	m.cleanupRunning = false
+	m.cleanupStaleDays = config.DefaultCleanupStaleDays
+	if m.configManager != nil {
+		m.cleanupStaleDays = m.configManager.GetCleanupStaleDays(m.repoPath)
+	}
+	return m.loadCleanupCandidates()
+}
+
+// loadCleanupCandidates finds worktrees that are merged, have a merged/closed PR or haven't been touched in a while
+func (m Model) loadCleanupCandidates() tea.Cmd {
+	worktrees := m.worktrees
+	baseBranch := m.baseBranch
+	staleDays := m.cleanupStaleDays
+	return func() tea.Msg {
+		var merged map[string]bool
+		if baseBranch != "" {
+			var err error
+			merged, err = m.gitManager.GetMergedBranches(baseBranch)
+			if err != nil {
+				return cleanupCandidatesMsg{err: err}
+			}
+		}
+		return cleanupCandidatesMsg{candidates: findCleanupCandidates(worktrees, baseBranch, merged, staleDays, time.Now())}
+	}
+}
+
+// findCleanupCandidates picks the workspace worktrees worth removing and explains why
+func findCleanupCandidates(worktrees []git.Worktree, baseBranch string, merged map[string]bool, staleDays int, now time.Time) []cleanupCandidate {
+	candidates := []cleanupCandidate{}
+	for _, wt := range worktrees {
+		// Only workspace worktrees can be removed; missing ones belong to the repair screen
+		if wt.IsMain || wt.IsPrunable || wt.Branch == baseBranch {
+			continue
+		}
+
+		var reasons []string
+		if merged[wt.Branch] {
+			reasons = append(reasons, "merged into "+baseBranch)
+		}
+		if prs, ok := wt.PRs.([]config.PRInfo); ok && len(prs) > 0 {
+			// The most recent PR decides
+			pr := prs[len(prs)-1]
+			if pr.Status == "merged" || pr.Status == "closed" {
+				label := "PR"
+				if pr.PRNumber > 0 {
+					label = fmt.Sprintf("PR #%d", pr.PRNumber)
+				}
+				reasons = append(reasons, label+" "+pr.Status)
+			}
+		}
+		if !wt.LastModified.IsZero() && staleDays > 0 {
+			if days := int(now.Sub(wt.LastModified).Hours() / 24); days >= staleDays {
+				reasons = append(reasons, fmt.Sprintf("untouched for %d days", days))
+			}
+		}
+
+		if len(reasons) > 0 {
+			candidates = append(candidates, cleanupCandidate{worktree: wt, reasons: reasons})
+		}
+	}
+	return candidates
+}
+
+// removeCleanupWorktrees removes the given worktrees (and their branches) in one batch
+// Worktrees with uncommitted changes are force-removed since the user checked them despite the warning,
+// but branches with unpushed commits are kept as the deletion policy requires
+func (m Model) removeCleanupWorktrees(worktrees []git.Worktree) tea.Cmd {
+	repoPath := m.repoPath
+	baseBranch := m.baseBranch
+	for _, wt := range worktrees {
+		m.stopScriptRuns(wt.Path)
+	}
+	return func() tea.Msg {
+		removed := 0
+		var kept, failures, hookErrs []string
+		for _, wt := range worktrees {
+			m.stopServices(wt.Path)
+			willDelete := m.gitManager.WillDeleteBranch(wt.Branch, baseBranch, false)
+			if err := m.gitManager.RunHook(git.HookTeardown, wt.Path, map[string]string{"JEAN_DELETE_BRANCH": fmt.Sprint(willDelete)}); err != nil {
+				hookErrs = append(hookErrs, wt.Branch)
+			}
+			err := m.gitManager.Remove(wt.Path, baseBranch, wt.HasUncommitted, false)
+			var keptErr *git.BranchKeptError
+			if errors.As(err, &keptErr) {
+				kept = append(kept, wt.Branch)
+			} else if err != nil {
+				failures = append(failures, fmt.Sprintf("%s: %v", wt.Branch, err))
+				continue
+			}
+			removed++
+
+			// Same cleanup as deleting a single worktree
+			if m.configManager != nil && keptErr == nil {
+				_ = m.configManager.CleanupBranch(repoPath, wt.Branch)
+			}
+			sessionName := m.sessionManager.SanitizeName(filepath.Base(repoPath), wt.Branch)
+			_ = m.sessionManager.Kill(sessionName)
+		}
+		return cleanupCompletedMsg{removed: removed, kept: kept, failures: failures, hookErrs: hookErrs}
+	}
+}
+
+// selectedCleanupWorktrees returns the checked candidates in list order
+func (m Model) selectedCleanupWorktrees() []git.Worktree {
+	var worktrees []git.Worktree
+	for _, candidate := range m.cleanupCandidates {
+		if m.cleanupSelected[candidate.worktree.Path] {
+			worktrees = append(worktrees, candidate.worktree)
+		}
+	}
+	return worktrees
+}
+
+// repairItem is a problem listed in the repair modal
+type repairItem struct {
+	orphan bool   // Directory under .workspaces that git doesn't track (otherwise a missing worktree)
+	path   string // Worktree or directory path
+	branch string // Branch of a missing worktree
+	reason string // Why the item needs repair
+	locked bool   // Missing worktree is locked
+}
+
+// openRepairModal shows the repair screen, selecting focusPath once the scan finishes
+func (m *Model) openRepairModal(focusPath string) tea.Cmd {
+	m.modal = repairModal
+	m.repairItems = nil
+	m.repairIndex = 0
+	m.repairFocusPath = focusPath
+	m.repairConfirmDel = false
+	return m.scanRepairItems()
+}
+
+// scanRepairItems looks for worktrees whose directory is gone and orphaned directories under .workspaces
+func (m Model) scanRepairItems() tea.Cmd {
+	return func() tea.Msg {
+		worktrees, err := m.gitManager.ListLightweight()
+		if err != nil {
+			return repairScanMsg{err: err}
+		}
+
+		items := []repairItem{}
+		for _, wt := range worktrees {
+			if !wt.IsMissing() {
+				continue
+			}
+			reason := wt.PruneReason
+			if reason == "" {
+				reason = "worktree directory is missing"
+			}
+			items = append(items, repairItem{path: wt.Path, branch: wt.Branch, reason: reason, locked: wt.IsLocked})
+		}
+
+		orphans, err := m.gitManager.FindOrphanDirs(worktrees)
+		if err != nil {
+			return repairScanMsg{items: items, err: err}
+		}
+		for _, path := range orphans {
+			items = append(items, repairItem{orphan: true, path: path, reason: "not a registered worktree"})
+		}
+		return repairScanMsg{items: items}
+	}
+}
+
+// runRepairAction prunes or re-adds missing worktrees, or deletes an orphaned directory
+// Pruning also clears the branch's config entries (PRs, Claude state) like deleting a worktree does
+func (m Model) runRepairAction(action string, items []repairItem) tea.Cmd {
+	repoPath := m.repoPath
+	return func() tea.Msg {
+		count := 0
+		var readded []string
+		for _, item := range items {
+			var err error
+			switch action {
+			case "prune":
+				err = m.gitManager.PruneWorktree(item.path)
+				if err == nil && m.configManager != nil && item.branch != "" {
+					_ = m.configManager.CleanupBranch(repoPath, item.branch)
+				}
+			case "re-add":
+				err = m.gitManager.ReAddWorktree(item.path, item.branch)
+				if err == nil {
+					readded = append(readded, item.path)
+				}
+			case "delete":
+				var worktrees []git.Worktree
+				worktrees, err = m.gitManager.ListLightweight()
+				if err == nil {
+					err = m.gitManager.DeleteOrphanDir(item.path, worktrees)
+				}
+			}
+			if err != nil {
+				return repairActionMsg{action: action, count: count, readded: readded, err: err}
+			}
+			count++
+		}
+		return repairActionMsg{action: action, count: count, readded: readded}
+	}
+}
+
+// selectedRepairItem returns the item selected in the repair modal
+func (m Model) selectedRepairItem() *repairItem {
+	if m.repairIndex < 0 || m.repairIndex >= len(m.repairItems) {
+		return nil
+	}
+	return &m.repairItems[m.repairIndex]
+}
+
+// logPageSize is the number of commits loaded per page of the commit log
+const logPageSize = 100
+
+// openLogModal shows the commit log of a worktree
+func (m *Model) openLogModal(wt *git.Worktree) tea.Cmd {
+	m.modal = logModal
+	m.logWorktreePath = wt.Path
+	m.logBranch = wt.Branch
+	m.logEntries = nil
+	m.logCommits = 0
+	m.logHasMore = true
+	m.logIndex = 0
+	m.logSelected = make(map[string]bool)
+	m.logDiffHash = ""
+	m.logDiff = nil
+	m.logDiffScroll = 0
+	m.logPickTarget = false
+	return m.loadLogPage()
+}
+
+// loadLogPage loads the next page of the commit log
+func (m *Model) loadLogPage() tea.Cmd {
+	if m.logLoading || !m.logHasMore {
+		return nil
+	}
+	m.logLoading = true
+	path := m.logWorktreePath
+	skip := m.logCommits
+	return func() tea.Msg {
+		entries, err := m.gitManager.GetLog(path, skip, logPageSize)
+		return logLoadedMsg{path: path, skip: skip, entries: entries, err: err}
+	}
+}
+
+// selectedLogEntry returns the commit selected in the log
+func (m Model) selectedLogEntry() *git.LogEntry {
+	if m.logIndex < 0 || m.logIndex >= len(m.logEntries) || m.logEntries[m.logIndex].Hash == "" {
+		return nil
+	}
+	return &m.logEntries[m.logIndex]
+}
+
+// moveLogCursor moves the log selection by delta commits, skipping graph-only lines
+func (m *Model) moveLogCursor(delta int) {
+	step := 1
+	if delta < 0 {
+		step = -1
+		delta = -delta
+	}
+	for ; delta > 0; delta-- {
+		next := m.logIndex + step
+		for next >= 0 && next < len(m.logEntries) && m.logEntries[next].Hash == "" {
+			next += step
+		}
+		if next < 0 || next >= len(m.logEntries) {
+			return
+		}
+		m.logIndex = next
+	}
+}
+
+// loadCommitDiff loads the full diff of a commit in the log's worktree
+func (m Model) loadCommitDiff(hash string) tea.Cmd {
+	path := m.logWorktreePath
+	return func() tea.Msg {
+		diff, err := m.gitManager.GetCommitDiff(path, hash)
+		return commitDiffLoadedMsg{hash: hash, diff: diff, err: err}
+	}
+}
+
+// logDiffVisibleLines returns how many commit diff lines fit on screen
+func (m Model) logDiffVisibleLines() int {
+	visible := m.height - 6
+	if visible < 5 {
+		visible = 5
+	}
+	return visible
+}
+
+// cherryPickCommits returns the commits to cherry-pick in the order they were made (oldest first)
+// Falls back to the selected commit when none are marked
+func (m Model) cherryPickCommits() []string {
+	var hashes []string
+	for i := len(m.logEntries) - 1; i >= 0; i-- {
+		if hash := m.logEntries[i].Hash; hash != "" && m.logSelected[hash] {
+			hashes = append(hashes, hash)
+		}
+	}
+	if len(hashes) == 0 {
+		if entry := m.selectedLogEntry(); entry != nil {
+			hashes = append(hashes, entry.Hash)
+		}
+	}
+	return hashes
+}
+
+// cherryPickTargets returns the worktrees commits from the log can be cherry-picked onto
+func (m Model) cherryPickTargets() []git.Worktree {
+	var targets []git.Worktree
+	for _, wt := range m.worktrees {
+		if wt.Path == m.logWorktreePath || wt.Branch == "" || strings.HasPrefix(wt.Branch, "(detached") {
+			continue
+		}
+		targets = append(targets, wt)
+	}
+	return targets
+}
+
+// cherryPickOnto applies commits onto the branch checked out in the target worktree
+func (m Model) cherryPickOnto(target git.Worktree, hashes []string) tea.Cmd {
+	return func() tea.Msg {
+		skipped, err := m.gitManager.CherryPick(target.Path, hashes)
+		return cherryPickCompletedMsg{targetPath: target.Path, targetBranch: target.Branch, count: len(hashes), skipped: skipped, err: err}
+	}
+}
+
+// openStashModal shows the stash manager for a worktree
+func (m *Model) openStashModal(wt *git.Worktree) tea.Cmd {
+	m.modal = stashModal
+	m.stashWorktreePath = wt.Path
+	m.stashBranch = wt.Branch
+	m.stashes = nil
+	m.stashIndex = 0
+	m.stashShowAll = false
+	m.stashPreview = ""
+	m.stashPreviewRef = ""
+	m.stashScroll = 0
+	m.stashInputMode = false
+	m.stashConfirmDrop = false
+	return m.loadStashes()
+}
+
+// visibleStashes returns the stashes shown in the stash modal
+// Only stashes taken on the worktree's branch are shown unless stashShowAll is set
+func (m Model) visibleStashes() []git.Stash {
+	if m.stashShowAll {
+		return m.stashes
+	}
+	var stashes []git.Stash
+	for _, stash := range m.stashes {
+		if stash.Branch == m.stashBranch {
+			stashes = append(stashes, stash)
+		}
+	}
+	return stashes
+}
+
+// selectedStash returns the stash under the cursor in the stash modal
+func (m Model) selectedStash() *git.Stash {
+	stashes := m.visibleStashes()
+	if m.stashIndex < 0 || m.stashIndex >= len(stashes) {
+		return nil
+	}
+	return &stashes[m.stashIndex]
+}
+
+// loadStashes lists the repository's stashes for the stash modal
+func (m Model) loadStashes() tea.Cmd {
+	worktreePath := m.stashWorktreePath
+	return func() tea.Msg {
+		stashes, err := m.gitManager.ListStashes(worktreePath)
+		return stashesLoadedMsg{stashes: stashes, err: err}
+	}
+}
+
+// loadStashPreview loads the diff of the selected stash
+func (m Model) loadStashPreview() tea.Cmd {
+	stash := m.selectedStash()
+	if stash == nil {
+		return nil
+	}
+	worktreePath := m.stashWorktreePath
+	ref := stash.Ref
+	return func() tea.Msg {
+		content, err := m.gitManager.ShowStash(worktreePath, ref)
+		return stashPreviewLoadedMsg{ref: ref, content: content, err: err}
+	}
+}
+
+// runStashAction pushes a new stash or applies/pops/drops an existing one in the stash modal's worktree
+func (m Model) runStashAction(action, arg string) tea.Cmd {
+	worktreePath := m.stashWorktreePath
+	return func() tea.Msg {
+		var err error
+		switch action {
+		case "push":
+			err = m.gitManager.PushStash(worktreePath, arg)
+		case "apply":
+			err = m.gitManager.ApplyStash(worktreePath, arg)
+		case "pop":
+			err = m.gitManager.PopStash(worktreePath, arg)
+		case "drop":
+			err = m.gitManager.DropStash(worktreePath, arg)
+		}
+		return stashActionCompletedMsg{action: action, ref: arg, err: err}
+	}
+}
+
+// openStagingDiff shows the hunk view for a file of the staging modal
+// The unstaged diff is shown first unless the file only has staged changes
+func (m *Model) openStagingDiff(file git.StagingFile) tea.Cmd {
+	m.stagingDiffOpen = true
+	m.stagingDiffFile = file.Path
+	m.stagingDiffStaged = file.IsStaged && file.WorkingStatus == " "
+	m.stagingDiff = nil
+	m.stagingHunkIndex = 0
+	m.stagingLineMode = false
+	m.stagingLineSelected = nil
+	return m.loadStagingDiff()
+}
+
+// loadStagingDiff loads the diff of the file shown in the hunk view
+func (m Model) loadStagingDiff() tea.Cmd {
+	file := m.stagingDiffFile
+	staged := m.stagingDiffStaged
+	return func() tea.Msg {
+		wt := m.selectedWorktree()
+		if wt == nil {
+			return stagingDiffLoadedMsg{file: file, staged: staged, err: fmt.Errorf("no worktree selected")}
+		}
+		diff, err := m.gitManager.GetFileDiff(wt.Path, file, staged)
+		return stagingDiffLoadedMsg{file: file, staged: staged, diff: diff, err: err}
+	}
+}
+
+// applyStagingPatch stages (or unstages, for the staged diff) a patch built from the hunk view
+func (m Model) applyStagingPatch(patch string) tea.Cmd {
+	reverse := m.stagingDiffStaged
+	return func() tea.Msg {
+		wt := m.selectedWorktree()
+		if wt == nil {
+			return stagingPatchAppliedMsg{err: fmt.Errorf("no worktree selected")}
+		}
+		return stagingPatchAppliedMsg{err: m.gitManager.ApplyPatchToIndex(wt.Path, patch, reverse)}
+	}
+}
+
 // stageFile stages a single file
 func (m Model) stageFile(filePath string) tea.Cmd {
 	return func() tea.Msg {
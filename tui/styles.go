@@ -57,6 +57,13 @@ var (
 	selectedDeleteButtonStyle  lipgloss.Style
 	disabledButtonStyle        lipgloss.Style
 
+	// Diff styles
+	diffAddStyle     lipgloss.Style
+	diffRemoveStyle  lipgloss.Style
+	diffHunkStyle    lipgloss.Style
+	diffHeaderStyle  lipgloss.Style
+	diffContextStyle lipgloss.Style
+
 	// Notification styles
 	successNotifStyle lipgloss.Style
 	errorNotifStyle   lipgloss.Style
@@ -293,6 +293,23 @@ func rebuildStyles(colors ThemeColors) {
 		Padding(0, 3).
 		MarginRight(2)
 
+	// Diff styles
+	diffAddStyle = lipgloss.NewStyle().
+		Foreground(colors.Success)
+
+	diffRemoveStyle = lipgloss.NewStyle().
+		Foreground(colors.Error)
+
+	diffHunkStyle = lipgloss.NewStyle().
+		Foreground(colors.Accent)
+
+	diffHeaderStyle = lipgloss.NewStyle().
+		Foreground(colors.Primary).
+		Bold(true)
+
+	diffContextStyle = lipgloss.NewStyle().
+		Foreground(colors.Foreground)
+
 	// Notification styles
 	successNotifStyle = lipgloss.NewStyle().
 		Foreground(colors.Background).
@@ -128,31 +128,74 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 
-			// Launch background status loaders for each worktree (non-blocking)
-			// This enables progressive status updates as each worktree's data loads
-			statusLoaders := make([]tea.Cmd, 0, len(m.worktrees))
-			for i := range m.worktrees {
-				statusLoaders = append(statusLoaders, m.loadWorktreeStatus(i, m.worktrees[i]))
+			// Keep the cursor in range when worktrees were removed (e.g. by bulk cleanup)
+			if m.selectedIndex >= len(m.worktrees) {
+				m.selectedIndex = len(m.worktrees) - 1
 			}
-			if len(statusLoaders) > 0 {
-				cmd = tea.Batch(statusLoaders...)
-			} else {
-				cmd = nil
+			if m.selectedIndex < 0 {
+				m.selectedIndex = 0
 			}
+
+			// Stream status for all worktrees through a bounded worker pool (non-blocking)
+			// This enables progressive status updates as each worktree's data loads
+			cmd = tea.Batch(m.startWorktreeStatusStream(), m.syncWatcher())
 		}
 		// After first successful worktree load, check if we need to show onboarding
 		return m, tea.Batch(cmd, m.checkOnboardingStatus())
 
 	case worktreeStatusUpdatedMsg:
+		// Drop results from a stream that was superseded by a newer load
+		if msg.results != nil && msg.results != m.statusResults {
+			return m, nil
+		}
+
 		// Update individual worktree with loaded status data (no blocking, progressive update)
-		if msg.index >= 0 && msg.index < len(m.worktrees) {
-			m.worktrees[msg.index].HasUncommitted = msg.hasUncommitted
-			m.worktrees[msg.index].AheadCount = msg.aheadCount
-			m.worktrees[msg.index].BehindCount = msg.behindCount
-			m.worktrees[msg.index].IsOutdated = msg.behindCount > 0
+		// Indexes refer to the list at load time, so fall back to a path lookup if the list moved
+		index := msg.index
+		if msg.path != "" && (index < 0 || index >= len(m.worktrees) || m.worktrees[index].Path != msg.path) {
+			index = -1
+			for i := range m.worktrees {
+				if m.worktrees[i].Path == msg.path {
+					index = i
+					break
+				}
+			}
+		}
+		if index >= 0 && index < len(m.worktrees) {
+			m.worktrees[index].HasUncommitted = msg.hasUncommitted
+			m.worktrees[index].AheadCount = msg.aheadCount
+			m.worktrees[index].BehindCount = msg.behindCount
+			m.worktrees[index].IsOutdated = msg.behindCount > 0
+			if msg.commit != "" {
+				m.worktrees[index].Commit = msg.commit
+			}
+			if msg.recentCommits != nil {
+				m.worktrees[index].RecentCommits = msg.recentCommits
+			}
+		}
+
+		// A branch was switched outside jean: reload so names, PRs and sessions line up
+		if msg.branchChanged {
+			return m, m.loadWorktreesLightweight()
+		}
+
+		// Keep reading from the stream until the worker pool is done
+		if msg.results != nil {
+			return m, waitForWorktreeStatus(msg.results)
 		}
 		return m, nil
 
+	case worktreeChangedMsg:
+		// Filesystem watcher saw changes: reload status for just that worktree
+		cmds := []tea.Cmd{m.waitForWorktreeChange()}
+		for i, wt := range m.worktrees {
+			if wt.Path == msg.path {
+				cmds = append(cmds, m.loadWorktreeStatus(i, wt))
+				break
+			}
+		}
+		return m, tea.Batch(cmds...)
+
 	case onboardingStatusMsg:
 		// If user needs onboarding and we haven't shown it yet, show the modal
 		if msg.needsOnboarding {
@@ -214,42 +257,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case worktreeCreatedMsg:
 		if msg.err != nil {
-			// Check if this is a setup script error (warning) or a git error (error)
-			errMsg := msg.err.Error()
-			if strings.Contains(errMsg, "setup script failed") {
-				// Setup script failed - show warning but worktree was created
-				// Extract just the relevant error message (skip "setup script failed: " prefix)
-				warningMsg := strings.TrimPrefix(errMsg, "setup script failed: ")
-				cmd = m.showWarningNotification(fmt.Sprintf("Worktree created but setup script failed:\n%s", warningMsg))
-				m.modal = noModal
-				m.lastCreatedBranch = msg.branch
-
-				// OPTIMISTIC UI UPDATE: Add worktree immediately even though setup failed
-				repoName := filepath.Base(m.repoPath)
-				tempWorktree := git.Worktree{
-					Path:              msg.path,
-					Branch:            msg.branch,
-					LastModified:      time.Now(),
-					ClaudeSessionName: m.sessionManager.SanitizeName(repoName, msg.branch),
-				}
-				m.worktrees = append(m.worktrees, tempWorktree)
-				m.sortWorktrees()
-
-				// Select the newly created worktree
-				for i, wt := range m.worktrees {
-					if wt.Branch == msg.branch {
-						m.selectedIndex = i
-						break
-					}
-				}
-
-				// Still refresh worktrees since the worktree was created successfully
-				return m, tea.Batch(cmd, m.loadWorktrees())
-			} else {
-				// Git worktree creation failed - show error
-				cmd = m.showErrorNotification("Failed to create worktree: "+msg.err.Error(), 5*time.Second)
-				return m, cmd
-			}
+			cmd = m.showErrorNotification("Failed to create worktree: "+msg.err.Error(), 5*time.Second)
+			return m, cmd
 		} else {
 			cmd = m.showSuccessNotification("Worktree created successfully", 3*time.Second)
 			m.modal = noModal
@@ -292,56 +301,19 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 			// Background refresh to update with accurate status
 			// Also load PR details asynchronously for the newly created branch
+			// and prepare the worktree with the setup script while it's already usable
 			return m, tea.Batch(
 				cmd,
 				m.loadWorktrees(),
 				m.loadPRDetailsForBranch(msg.path, msg.branch),
+				m.startSetup(msg.path),
 			)
 		}
 
 	case worktreeCreatedWithSessionMsg:
 		if msg.err != nil {
-			// Check if this is a setup script error (warning) or a git error (error)
-			errMsg := msg.err.Error()
-			if strings.Contains(errMsg, "setup script failed") {
-				// Setup script failed - show warning but worktree was created
-				warningMsg := strings.TrimPrefix(errMsg, "setup script failed: ")
-				cmd = m.showWarningNotification(fmt.Sprintf("Worktree created but setup script failed:\n%s", warningMsg))
-				m.modal = noModal
-				m.lastCreatedBranch = msg.branch
-				// Store session name for switch
-				m.switchInfo = SwitchInfo{
-					Path:       msg.path,
-					Branch:     msg.branch,
-					SessionName: msg.sessionName,
-					AutoClaude: m.autoClaude,
-				}
-
-				// OPTIMISTIC UI UPDATE: Add worktree immediately even though setup failed
-				repoName := filepath.Base(m.repoPath)
-				tempWorktree := git.Worktree{
-					Path:              msg.path,
-					Branch:            msg.branch,
-					LastModified:      time.Now(),
-					ClaudeSessionName: m.sessionManager.SanitizeName(repoName, msg.branch),
-				}
-				m.worktrees = append(m.worktrees, tempWorktree)
-				m.sortWorktrees()
-
-				// Select the newly created worktree
-				for i, wt := range m.worktrees {
-					if wt.Branch == msg.branch {
-						m.selectedIndex = i
-						break
-					}
-				}
-
-				return m, tea.Batch(cmd, m.loadWorktrees())
-			} else {
-				// Git worktree creation failed - show error
-				cmd = m.showErrorNotification("Failed to create worktree: "+msg.err.Error(), 5*time.Second)
-				return m, cmd
-			}
+			cmd = m.showErrorNotification("Failed to create worktree: "+msg.err.Error(), 5*time.Second)
+			return m, cmd
 		} else {
 			cmd = m.showSuccessNotification("Worktree created successfully", 3*time.Second)
 			m.modal = noModal
@@ -380,10 +352,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 			// Background refresh to update with accurate status
 			// Also load PR details asynchronously for the newly created branch
+			// and prepare the worktree with the setup script while it's already usable
 			return m, tea.Batch(
 				cmd,
 				m.loadWorktrees(),
 				m.loadPRDetailsForBranch(msg.path, msg.branch),
+				m.startSetup(msg.path),
 			)
 		}
 
@@ -392,7 +366,20 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			cmd = m.showErrorNotification("Failed to delete worktree: "+msg.err.Error(), 5*time.Second)
 			return m, cmd
 		} else {
-			cmd = m.showSuccessNotification("Worktree and branch deleted successfully", 3*time.Second)
+			if msg.kept != nil {
+				notice := fmt.Sprintf("Worktree deleted, branch '%s' kept: %d commit(s) not pushed", msg.kept.Branch, msg.kept.AtRisk)
+				if msg.kept.CheckErr != nil {
+					notice = fmt.Sprintf("Worktree deleted, branch '%s' kept: couldn't check it for unpushed commits", msg.kept.Branch)
+				}
+				if msg.hookErr != nil {
+					notice += ". Also, the " + hookFailureNotice(msg.hookErr)
+				}
+				cmd = m.showWarningNotification(notice)
+			} else if msg.hookErr != nil {
+				cmd = m.showWarningNotification("Worktree and branch deleted, but the " + hookFailureNotice(msg.hookErr))
+			} else {
+				cmd = m.showSuccessNotification("Worktree and branch deleted successfully", 3*time.Second)
+			}
 			m.modal = noModal
 			if m.selectedIndex >= len(m.worktrees)-1 {
 				m.selectedIndex = len(m.worktrees) - 2
@@ -493,444 +480,629 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case stagingFileToggledMsg:
+	case conflictFilesLoadedMsg:
 		if msg.err != nil {
-			cmd = m.showErrorNotification("Failed to toggle file: "+msg.err.Error(), 4*time.Second)
+			cmd = m.showErrorNotification("Failed to load conflicted files: "+msg.err.Error(), 4*time.Second)
 			return m, cmd
 		}
-		// Refresh the staging files list
-		return m, m.loadStagingFiles()
+		m.conflictFiles = msg.files
+		// Keep index in bounds
+		if m.conflictIndex >= len(m.conflictFiles) {
+			m.conflictIndex = len(m.conflictFiles) - 1
+		}
+		if m.conflictIndex < 0 {
+			m.conflictIndex = 0
+		}
+		m.conflictVersions = nil
+		m.conflictScroll = 0
+		return m, m.loadConflictVersions()
 
-	case editorOpenedMsg:
+	case conflictVersionsLoadedMsg:
+		// Ignore stale results if the selection moved on
+		if m.conflictIndex >= len(m.conflictFiles) || m.conflictFiles[m.conflictIndex] != msg.file {
+			return m, nil
+		}
 		if msg.err != nil {
-			cmd = m.showErrorNotification("Failed to open editor: " + msg.err.Error(), 4*time.Second)
-			return m, cmd
-		} else {
-			cmd = m.showSuccessNotification("Opened in editor", 3*time.Second)
-			return m, cmd
+			m.debugLog("Failed to load conflict versions: " + msg.err.Error())
+			return m, nil
 		}
+		versions := msg.versions
+		m.conflictVersions = &versions
+		return m, nil
 
-	case prCreatedMsg:
+	case conflictFileResolvedMsg:
 		if msg.err != nil {
-			m.debugLog(fmt.Sprintf("PR creation failed: %v", msg.err))
-			errMsg := msg.err.Error()
-
-			// Check if the error is "PR already exists"
-			if strings.Contains(errMsg, "already exists") {
-				// Only retry once - if we're already retrying, don't try again
-				if !m.prRetryInProgress {
-					// Check if AI is configured
-					hasAPIKey := m.configManager != nil && m.configManager.GetAnthropicAPIKey() != ""
-					aiContentEnabled := m.configManager != nil && m.configManager.GetAICommitEnabled()
-
-					if hasAPIKey && aiContentEnabled && msg.worktreePath != "" && msg.branch != "" {
-						// Mark that we're in a retry attempt
-						m.prRetryInProgress = true
-						// Store the worktree and branch for retry
-						m.prRetryWorktreePath = msg.worktreePath
-						m.prRetryBranch = msg.branch
-
-						// Trigger PR content regeneration
-						cmd = m.showWarningNotification("PR already exists. Regenerating title and description...")
-						return m, tea.Batch(cmd, m.generatePRContent(msg.worktreePath, msg.branch, m.baseBranch))
-					}
-				}
-			}
-
-			// Clear retry state before showing error
-			m.prRetryInProgress = false
-			m.prRetryWorktreePath = ""
-			m.prRetryBranch = ""
-			m.prRetryTitle = ""
-			m.prRetryDescription = ""
-
-			cmd = m.showErrorNotification("Failed to create PR: " + errMsg, 4*time.Second)
+			cmd = m.showErrorNotification(msg.err.Error(), 4*time.Second)
 			return m, cmd
-		} else {
-			// Clear retry state on successful creation
-			m.prRetryInProgress = false
-			m.prRetryWorktreePath = ""
-			m.prRetryBranch = ""
-			m.prRetryTitle = ""
-			m.prRetryDescription = ""
-
-			m.debugLog(fmt.Sprintf("PR created successfully: %s", msg.prURL))
-			// Use the branch from the message (the one we actually created the PR for)
-			// This prevents race conditions where the user navigates to a different worktree
-			// while the PR is being created
-			prBranch := msg.branch
+		}
+		switch msg.action {
+		case "ours":
+			cmd = m.showSuccessNotification("Took our version of "+msg.file, 2*time.Second)
+		case "theirs":
+			cmd = m.showSuccessNotification("Took their version of "+msg.file, 2*time.Second)
+		default:
+			cmd = m.showSuccessNotification("Marked "+msg.file+" as resolved", 2*time.Second)
+		}
+		return m, tea.Batch(cmd, m.loadConflictFiles())
 
-			m.debugLog(fmt.Sprintf("Saving PR for branch: %s, title: %s", prBranch, msg.prTitle))
-			// Save PR to config
-			if prBranch != "" {
-				// Extract PR number from URL (e.g., github.com/owner/repo/pull/123)
-				prNumber := 0
-				if parts := strings.Split(msg.prURL, "/pull/"); len(parts) == 2 {
-					fmt.Sscanf(parts[1], "%d", &prNumber)
-				}
-				m.debugLog(fmt.Sprintf("Extracted PR number: %d from URL: %s", prNumber, msg.prURL))
-				_ = m.configManager.AddPR(m.repoPath, prBranch, msg.prURL, prNumber, msg.prTitle, msg.author)
+	case conflictFinishedMsg:
+		if msg.err != nil {
+			cmd = m.showErrorNotification(msg.err.Error(), 5*time.Second)
+			return m, cmd
+		}
+		if msg.stillInProgress {
+			// The rebase/cherry-pick moved on and stopped on the next commit's conflicts
+			m.conflictIndex = 0
+			cmd = m.showWarningNotification(operationLabel(m.conflictOperation) + " stopped on more conflicts. Resolve them, then continue.")
+			return m, tea.Batch(cmd, m.loadConflictFiles())
+		}
+		m.modal = noModal
+		m.conflictFiles = nil
+		m.conflictVersions = nil
+		if m.conflictOperation != git.OperationMerge {
+			if msg.aborted {
+				cmd = m.showInfoNotification(operationLabel(m.conflictOperation) + " aborted")
+			} else {
+				cmd = m.showSuccessNotification(operationLabel(m.conflictOperation)+" completed", 3*time.Second)
 			}
+			return m, tea.Batch(cmd, m.loadWorktrees())
+		}
+		if msg.aborted {
+			m.conflictFromLocalMerge = false
+			cmd = m.showInfoNotification("Merge aborted")
+			return m, tea.Batch(cmd, m.loadWorktrees())
+		}
 
-			m.debugLog("Triggering worktree refresh after PR creation")
+		// Local merge finished: offer to clean up the merged worktree like a clean merge would
+		cmd = m.showSuccessNotification("Merge committed", 3*time.Second)
+		if m.conflictFromLocalMerge {
+			m.conflictFromLocalMerge = false
+			m.postMergeDeleteIndex = 0
+			m.modal = postMergeCleanupModal
+			return m, tea.Batch(cmd, m.loadWorktrees(), m.runPostMergeHook(m.localMergeWorktree, m.localMergeBranch, m.localMergeTarget))
+		}
+		return m, tea.Batch(cmd, m.loadWorktrees())
 
-			// Create status message based on draft status
-			statusMsg := "PR created / updated"
-			if msg.isDraft {
-				statusMsg = "Draft PR created / updated"
-			}
-			cmd = m.showSuccessNotification(statusMsg + ": " + msg.prURL, 5*time.Second)
-			return m, tea.Batch(
-				cmd,
-				m.loadWorktrees(),
-			)
+	case hookFinishedMsg:
+		if msg.err != nil {
+			return m, m.showWarningNotification(msg.err.Error())
 		}
+		return m, nil
 
-	case prBranchNameGeneratedMsg:
-		// AI branch name generated for PR
+	case diffViewerLoadedMsg:
+		// Ignore stale results if the viewer moved on to another worktree or mode
+		if m.modal != diffViewerModal || msg.path != m.diffViewerPath || msg.mode != m.diffViewerMode {
+			return m, nil
+		}
 		if msg.err != nil {
-			// AI generation failed - fall back to current name (graceful degradation)
-			cmd = m.showWarningNotification("Using current branch name for PR...")
-			// Still try to generate PR content with AI
-			hasAPIKey := m.configManager != nil && m.configManager.GetAnthropicAPIKey() != ""
-			aiContentEnabled := m.configManager != nil && m.configManager.GetAICommitEnabled()
-			if hasAPIKey && aiContentEnabled {
-				return m, tea.Batch(cmd, m.generatePRContent(msg.worktreePath, msg.oldBranchName, m.baseBranch))
+			m.diffViewerFiles = []*git.FileDiff{}
+			cmd = m.showErrorNotification("Failed to load diff: "+msg.err.Error(), 4*time.Second)
+			return m, cmd
+		}
+		// Stay on the same file across reloads if it still has changes
+		selected := ""
+		if file := m.selectedDiffFile(); file != nil {
+			selected = file.Path
+		}
+		m.diffViewerFiles = msg.files
+		m.diffViewerIndex = 0
+		for i, file := range m.diffViewerFiles {
+			if file.Path == selected {
+				m.diffViewerIndex = i
+				break
 			}
-			return m, tea.Batch(cmd, m.createPR(msg.worktreePath, msg.oldBranchName, "", ""))
 		}
+		m.clampDiffViewerScroll()
+		return m, nil
 
-		// Check if target branch already exists locally
-		targetExists, _ := m.gitManager.BranchExists(msg.worktreePath, msg.newBranchName)
-		if targetExists {
-			// Target branch already exists - skip rename and use current name for PR
-			cmd = m.showWarningNotification("Branch name already exists, using current name...")
-			// Still try to generate PR content with AI
-			hasAPIKey := m.configManager != nil && m.configManager.GetAnthropicAPIKey() != ""
-			aiContentEnabled := m.configManager != nil && m.configManager.GetAICommitEnabled()
-			if hasAPIKey && aiContentEnabled {
-				return m, tea.Batch(cmd, m.generatePRContent(msg.worktreePath, msg.oldBranchName, m.baseBranch))
+	case worktreeRelocatedMsg:
+		if msg.err != nil {
+			cmd = m.showErrorNotification("Failed to move worktree: "+msg.err.Error(), 5*time.Second)
+			if msg.newPath == "" {
+				return m, cmd
 			}
-			return m, tea.Batch(cmd, m.createPR(msg.worktreePath, msg.oldBranchName, "", ""))
+		} else {
+			cmd = m.showSuccessNotification("Moved worktree to "+msg.newPath, 3*time.Second)
 		}
+		return m, tea.Batch(cmd, m.loadWorktrees())
 
-		// Store pending rename state
-		m.pendingPRNewName = msg.newBranchName
-		m.pendingPROldName = msg.oldBranchName
-		m.pendingPRWorktree = msg.worktreePath
+	case scriptStartedMsg:
+		if msg.err != nil {
+			if msg.afterCreate {
+				return m, m.showWarningNotification("Worktree is ready but setup couldn't start: " + msg.err.Error())
+			}
+			return m, m.showErrorNotification("Failed to run script: "+msg.err.Error(), 5*time.Second)
+		}
+		if msg.run == nil {
+			// No setup script configured
+			return m, nil
+		}
+		if m.scriptRuns == nil {
+			m.scriptRuns = make(map[string]*git.ScriptRun)
+		}
+		m.scriptRuns[scriptRunKey(msg.run.Path, msg.run.Name)] = msg.run
+		cmds := []tea.Cmd{waitForScript(msg.run)}
+		if m.modal == scriptLogModal && m.scriptLogWorktree == msg.run.Path && m.scriptLogName == msg.run.Name {
+			// Restarted from the log viewer: show the new run's output
+			m.scriptLogFollow = true
+			cmds = append(cmds, m.loadScriptLog())
+		} else if msg.afterCreate {
+			cmds = append(cmds, m.showInfoNotification(fmt.Sprintf("Running setup on %s in the background (S shows output)", msg.run.Branch)))
+		} else {
+			cmds = append(cmds, m.showInfoNotification(fmt.Sprintf("Running '%s' in the background on %s", msg.run.Name, msg.run.Branch)))
+		}
+		return m, tea.Batch(cmds...)
 
-		// Check if remote branch exists
-		remoteExists, _ := m.gitManager.RemoteBranchExists(msg.worktreePath, msg.oldBranchName)
+	case scriptFinishedMsg:
+		// Ignore runs that were replaced by a newer one
+		if m.scriptRuns[scriptRunKey(msg.run.Path, msg.run.Name)] != msg.run {
+			return m, nil
+		}
+		logHint := "x → l shows the log"
+		if msg.run.Name == git.SetupScript {
+			logHint = "S shows the log, r there re-runs it"
+		}
+		switch scriptRunStatus(msg.run) {
+		case "stopped":
+			cmd = m.showInfoNotification(fmt.Sprintf("Stopped '%s' on %s", msg.run.Name, msg.run.Branch))
+		case "failed":
+			cmd = m.showErrorNotification(fmt.Sprintf("'%s' failed on %s: %v (%s)", msg.run.Name, msg.run.Branch, msg.run.Err(), logHint), 5*time.Second)
+		default:
+			cmd = m.showSuccessNotification(fmt.Sprintf("'%s' finished on %s", msg.run.Name, msg.run.Branch), 3*time.Second)
+		}
+		if m.modal == scriptLogModal && m.scriptLogWorktree == msg.run.Path && m.scriptLogName == msg.run.Name {
+			return m, tea.Batch(cmd, m.loadScriptLog())
+		}
+		return m, cmd
 
-		if remoteExists {
-			// Delete remote branch first
-			cmd = m.showInfoNotification("Deleting old remote branch...")
-			return m, tea.Batch(cmd, m.deleteRemoteBranchForPR(msg.worktreePath, msg.oldBranchName, msg.newBranchName))
-		} else {
-			// No remote, go straight to rename
-			cmd = m.showInfoNotification("Renaming to: " + msg.newBranchName)
-			return m, tea.Batch(cmd, m.renameBranchForPR(msg.oldBranchName, msg.newBranchName, msg.worktreePath))
+	case scriptRunnerReadyMsg:
+		if msg.err != nil {
+			return m, m.showErrorNotification("Failed to run script: "+msg.err.Error(), 5*time.Second)
 		}
+		// Hand the runner over to the shell wrapper, which executes it in the worktree and reopens jean
+		m.switchInfo = SwitchInfo{
+			Path:          msg.path,
+			Branch:        msg.branch,
+			TargetWindow:  "terminal",
+			ScriptCommand: msg.runner,
+		}
+		return m, tea.Quit
 
-	case prRemoteBranchDeletedMsg:
-		// Remote branch deleted, now rename local branch
+	case scriptLogLoadedMsg:
+		if m.modal != scriptLogModal || msg.path != m.scriptLogWorktree || msg.name != m.scriptLogName {
+			return m, nil
+		}
 		if msg.err != nil {
-			// Deletion failed but continue anyway
-			cmd = m.showWarningNotification("Couldn't delete old remote, continuing...")
+			m.scriptLogLines = []string{}
+			return m, m.showErrorNotification("Failed to read log: "+msg.err.Error(), 4*time.Second)
 		}
+		m.scriptLogLines = msg.lines
+		m.scriptLogMissing = msg.missing
+		m.clampScriptLogScroll()
+		return m, nil
 
-		// Check if target branch already exists locally
-		targetExists, _ := m.gitManager.BranchExists(msg.worktreePath, msg.newBranchName)
-		if targetExists {
-			// Target branch already exists - skip rename and use current name for PR
-			cmd = m.showWarningNotification("Branch name already exists, using current name...")
-			// Still try to generate PR content with AI
-			hasAPIKey := m.configManager != nil && m.configManager.GetAnthropicAPIKey() != ""
-			aiContentEnabled := m.configManager != nil && m.configManager.GetAICommitEnabled()
-			if hasAPIKey && aiContentEnabled {
-				return m, tea.Batch(cmd, m.generatePRContent(msg.worktreePath, msg.oldBranchName, m.baseBranch))
-			}
-			return m, tea.Batch(cmd, m.createPR(msg.worktreePath, msg.oldBranchName, "", ""))
+	case scriptLogTickMsg:
+		if m.modal != scriptLogModal {
+			m.scriptLogTicking = false
+			return m, nil
 		}
+		if m.scriptLogActive() {
+			return m, tea.Batch(m.loadScriptLog(), scriptLogTick())
+		}
+		return m, scriptLogTick()
 
-		cmd = m.showInfoNotification("Renaming branch locally...")
-		return m, tea.Batch(cmd, m.renameBranchForPR(msg.oldBranchName, msg.newBranchName, msg.worktreePath))
+	case servicesLoadedMsg:
+		if msg.err == nil {
+			m.services = msg.services
+		}
+		return m, nil
 
-	case prBranchRenamedMsg:
-		// Branch renamed, now create PR with new name
+	case serviceStartedMsg:
 		if msg.err != nil {
-			cmd = m.showErrorNotification("Failed to rename branch: " + msg.err.Error(), 4*time.Second)
-			return m, cmd
+			return m, tea.Batch(m.showErrorNotification("Failed to start service: "+msg.err.Error(), 5*time.Second), m.loadServices())
 		}
+		m.dropService(msg.service.Worktree, msg.service.Name)
+		m.services = append(m.services, *msg.service)
+		verb := "Started"
+		if msg.restarted {
+			verb = "Restarted"
+		}
+		cmds := []tea.Cmd{
+			m.showSuccessNotification(fmt.Sprintf("%s '%s' on %s (pid %d)", verb, msg.service.Name, msg.service.Branch, msg.service.PID), 3*time.Second),
+			m.loadServices(),
+		}
+		if m.modal == scriptLogModal && m.scriptLogWorktree == msg.service.Worktree && m.scriptLogName == msg.service.Name {
+			m.scriptLogFollow = true
+			cmds = append(cmds, m.loadScriptLog())
+		}
+		return m, tea.Batch(cmds...)
 
-		// Rename succeeded, check if we should generate AI PR content
-		hasAPIKey := m.configManager != nil && m.configManager.GetAnthropicAPIKey() != ""
-		aiEnabled := m.configManager != nil && m.configManager.GetAIBranchNameEnabled()
-
-		if hasAPIKey && aiEnabled {
-			// Generate AI PR content before creating PR
-			cmd = m.showInfoNotification("🤖 Generating PR title and description...")
-			return m, tea.Batch(
-				cmd,
-				m.renameSessionsForBranch(msg.oldBranchName, msg.newBranchName),
-				m.generatePRContent(msg.worktreePath, msg.newBranchName, m.baseBranch),
-			)
-		} else {
-			// No AI - open PR content modal for manual entry
-			m.modal = prContentModal
-			m.prModalFocused = 0
-			m.prModalWorktreePath = msg.worktreePath
-			m.prModalBranch = msg.newBranchName
-
-			// Default title to new branch name
-			defaultTitle := strings.ReplaceAll(msg.newBranchName, "-", " ")
-			defaultTitle = strings.ReplaceAll(defaultTitle, "_", " ")
-			defaultTitle = strings.Title(defaultTitle)
-			m.prTitleInput.SetValue(defaultTitle)
-			m.prTitleInput.Focus()
-			m.prDescriptionInput.SetValue("")
+	case serviceStoppedMsg:
+		if msg.err != nil {
+			return m, tea.Batch(m.showErrorNotification(msg.err.Error(), 5*time.Second), m.loadServices())
+		}
+		m.dropService(msg.path, msg.name)
+		cmds := []tea.Cmd{
+			m.showInfoNotification(fmt.Sprintf("Stopped '%s' on %s", msg.name, msg.branch)),
+			m.loadServices(),
+		}
+		if m.modal == scriptLogModal && m.scriptLogWorktree == msg.path && m.scriptLogName == msg.name {
+			cmds = append(cmds, m.loadScriptLog())
+		}
+		return m, tea.Batch(cmds...)
 
-			// Rename sessions
-			cmd = m.renameSessionsForBranch(msg.oldBranchName, msg.newBranchName)
-			return m, cmd
+	case remotesFetchedMsg:
+		// Fetching is best effort; the picker already lists the cached remote branches
+		if msg.err != nil || m.modal != branchSelectModal {
+			return m, nil
 		}
+		return m, m.loadBranches
 
-	case commitCreatedMsg:
+	case cleanupCandidatesMsg:
+		if m.modal != cleanupModal {
+			return m, nil
+		}
+		m.cleanupCandidates = msg.candidates
+		if m.cleanupCandidates == nil {
+			m.cleanupCandidates = []cleanupCandidate{}
+		}
+		if m.cleanupIndex >= len(m.cleanupCandidates) {
+			m.cleanupIndex = 0
+		}
+		// Drop checks of worktrees that are no longer candidates
+		for path := range m.cleanupSelected {
+			found := false
+			for _, candidate := range m.cleanupCandidates {
+				if candidate.worktree.Path == path {
+					found = true
+					break
+				}
+			}
+			if !found {
+				delete(m.cleanupSelected, path)
+			}
+		}
 		if msg.err != nil {
-			m.debugLog(fmt.Sprintf("Commit creation failed: %v", msg.err))
-			cmd = m.showErrorNotification("Failed to create commit: " + msg.err.Error(), 4*time.Second)
+			cmd = m.showErrorNotification("Failed to find cleanup candidates: "+msg.err.Error(), 4*time.Second)
 			return m, cmd
-		} else {
-			m.debugLog(fmt.Sprintf("Commit created successfully with hash: %s", msg.commitHash))
-			// Save the commit message for use as PR title
-			m.lastCommitMessage = msg.subject
-
-			// Clear commit modal inputs for next use
-			m.commitSubjectInput.SetValue("")
-			m.modalFocused = 0
+		}
+		return m, nil
 
-			// Show success message with commit hash
-			if msg.commitHash != "" {
-				hashDisplay := msg.commitHash
-				if len(msg.commitHash) > 8 {
-					hashDisplay = msg.commitHash[:8]
-				}
-				cmd = m.showSuccessNotification("Commit created: " + hashDisplay, 3*time.Second)
-			} else {
-				cmd = m.showSuccessNotification("Commit created successfully", 3*time.Second)
+	case cleanupCompletedMsg:
+		m.cleanupRunning = false
+		m.modal = noModal
+		m.cleanupCandidates = nil
+		m.cleanupSelected = nil
+		if len(msg.failures) > 0 {
+			cmd = m.showErrorNotification(fmt.Sprintf("Removed %d worktree(s), %d failed: %s", msg.removed, len(msg.failures), strings.Join(msg.failures, "; ")), 6*time.Second)
+		} else if len(msg.kept) > 0 || len(msg.hookErrs) > 0 {
+			var notices []string
+			if len(msg.kept) > 0 {
+				notices = append(notices, "kept branches with unpushed commits: "+strings.Join(msg.kept, ", "))
 			}
-
-			// Check if we're committing before PR creation
-			if m.commitBeforePR && m.prCreationPending != "" {
-				// Get the branch name from the pending PR worktree
-				var branch string
-				for _, wt := range m.worktrees {
-					if wt.Path == m.prCreationPending {
-						branch = wt.Branch
-						break
-					}
-				}
-
-				// Reset commit before PR flag
-				m.commitBeforePR = false
-
-				// Check if we should do AI renaming
-				hasAPIKey := m.configManager != nil && m.configManager.GetAnthropicAPIKey() != ""
-				aiEnabled := m.configManager != nil && m.configManager.GetAIBranchNameEnabled()
-				isRandomName := m.gitManager.IsRandomBranchName(branch)
-				shouldAIRename := hasAPIKey && aiEnabled && isRandomName
-
-				if shouldAIRename {
-					// Start AI rename flow before PR creation
-					cmd = m.showInfoNotification("🤖 Generating semantic branch name...")
-					return m, tea.Batch(cmd, m.generateBranchNameForPR(m.prCreationPending, branch, m.baseBranch))
-				} else {
-					// No AI rename needed - use default PR state from config
-					prState := m.configManager.GetPRDefaultState(m.repoPath)
-					m.prIsDraft = (prState == "draft")
-					m.prModalWorktreePath = m.prCreationPending
-					m.prModalBranch = branch
-					m.commitBeforePR = false
-					m.prCreationPending = ""
-
-					// Check if AI is enabled for PR content generation
-					aiEnabled := m.configManager != nil &&
-						m.configManager.GetAnthropicAPIKey() != "" &&
-						m.aiCommitEnabled
-
-					if aiEnabled {
-						// Generate PR content with AI
-						cmd := m.showSuccessNotification("Committed successfully. Generating PR content...", 2*time.Second)
-						return m, tea.Batch(cmd, m.generatePRContent(m.prModalWorktreePath, m.prModalBranch, m.baseBranch))
-					}
-
-					// No AI - open PR content modal for manual input
-					m.modal = prContentModal
-					m.prModalFocused = 0
-					m.prTitleInput.Focus()
-					m.prDescriptionInput.Blur()
-					return m, m.showSuccessNotification("Committed successfully. Enter PR details:", 2*time.Second)
-				}
+			if len(msg.hookErrs) > 0 {
+				notices = append(notices, "teardown hook failed for: "+strings.Join(msg.hookErrs, ", ")+" (logs in .git/jean/logs)")
 			}
+			cmd = m.showWarningNotification(fmt.Sprintf("Removed %d worktree(s), %s", msg.removed, strings.Join(notices, "; ")))
+		} else {
+			cmd = m.showSuccessNotification(fmt.Sprintf("Removed %d worktree(s)", msg.removed), 3*time.Second)
+		}
+		return m, tea.Batch(cmd, m.loadWorktrees())
 
-			// Check if we're committing before push-only (no PR)
-			if m.commitBeforePR && m.prCreationPending == "" {
-				// Get current worktree
-				wt := m.selectedWorktree()
-				if wt == nil {
-					return m, cmd
-				}
-
-				// Reset commit before PR flag
-				m.commitBeforePR = false
-
-				// Check if we should do AI renaming
-				hasAPIKey := m.configManager != nil && m.configManager.GetAnthropicAPIKey() != ""
-				aiEnabled := m.configManager != nil && m.configManager.GetAIBranchNameEnabled()
-				isRandomName := m.gitManager.IsRandomBranchName(wt.Branch)
-				shouldAIRename := hasAPIKey && aiEnabled && isRandomName
-
-				if shouldAIRename {
-					// Start AI rename flow before push
-					notifyCmd := m.showInfoNotification("🤖 Generating semantic branch name...")
-					return m, tea.Batch(notifyCmd, m.generateBranchNameForPush(wt.Path, wt.Branch, m.baseBranch))
-				} else {
-					// No AI rename needed, go straight to push
-					notifyCmd := m.showInfoNotification("Pushing to remote...")
-					return m, tea.Batch(notifyCmd, m.pushBranch(wt.Path, wt.Branch))
+	case repairScanMsg:
+		if m.modal != repairModal {
+			return m, nil
+		}
+		m.repairItems = msg.items
+		if m.repairItems == nil {
+			m.repairItems = []repairItem{}
+		}
+		m.repairConfirmDel = false
+		if m.repairIndex >= len(m.repairItems) {
+			m.repairIndex = len(m.repairItems) - 1
+		}
+		if m.repairIndex < 0 {
+			m.repairIndex = 0
+		}
+		if m.repairFocusPath != "" {
+			for i, item := range m.repairItems {
+				if item.path == m.repairFocusPath {
+					m.repairIndex = i
+					break
 				}
 			}
-
-			// Check if we committed from staging modal - refresh staging files
-			if m.commitFromStagingModal {
-				m.commitFromStagingModal = false
-				m.debugLog("Triggering worktree and staging files refresh after commit from staging modal")
-				return m, tea.Batch(cmd, m.loadWorktrees(), m.loadStagingFiles())
-			}
-
-			// Normal commit (not before PR)
-			m.debugLog("Triggering worktree refresh after commit")
-			return m, tea.Batch(
-				cmd,
-				m.loadWorktrees(),
-			)
+			m.repairFocusPath = ""
 		}
-
-	case autoCommitBeforePRMsg:
-		// Auto-commit succeeded, now proceed with PR creation
 		if msg.err != nil {
-			cmd = m.showErrorNotification("Failed to commit changes: " + msg.err.Error(), 4*time.Second)
+			cmd = m.showErrorNotification("Failed to scan worktrees: "+msg.err.Error(), 4*time.Second)
 			return m, cmd
 		}
+		return m, nil
 
-		// Commit succeeded, now proceed with PR creation
-		// Check if we should do AI renaming first
-		hasAPIKey := m.configManager != nil && m.configManager.GetAnthropicAPIKey() != ""
-		aiEnabled := m.configManager != nil && m.configManager.GetAIBranchNameEnabled()
-		isRandomName := m.gitManager.IsRandomBranchName(msg.branch)
-
-		shouldAIRename := hasAPIKey && aiEnabled && isRandomName
-
-		if shouldAIRename {
-			// Start AI rename flow before PR creation
-			cmd = m.showInfoNotification("🤖 Generating semantic branch name...")
-			return m, tea.Batch(cmd, m.generateBranchNameForPR(msg.worktreePath, msg.branch, m.baseBranch))
+	case repairActionMsg:
+		if msg.err != nil {
+			cmd = m.showErrorNotification(msg.err.Error(), 5*time.Second)
 		} else {
-			// No AI rename needed - use default PR state from config
-			prState := m.configManager.GetPRDefaultState(m.repoPath)
-			m.prIsDraft = (prState == "draft")
-			m.prModalWorktreePath = msg.worktreePath
-			m.prModalBranch = msg.branch
-
-			// Check if AI is enabled for PR content generation
-			aiEnabled := m.configManager != nil &&
-				m.configManager.GetAnthropicAPIKey() != "" &&
-				m.aiCommitEnabled
-
-			if aiEnabled {
-				// Generate PR content with AI
-				return m, m.generatePRContent(m.prModalWorktreePath, m.prModalBranch, m.baseBranch)
+			switch msg.action {
+			case "prune":
+				cmd = m.showSuccessNotification(fmt.Sprintf("Pruned %d worktree(s)", msg.count), 3*time.Second)
+			case "re-add":
+				cmd = m.showSuccessNotification("Worktree re-created", 3*time.Second)
+			case "delete":
+				cmd = m.showSuccessNotification("Orphaned directory deleted", 3*time.Second)
 			}
+		}
+		cmds := []tea.Cmd{cmd, m.loadWorktrees()}
+		for _, path := range msg.readded {
+			cmds = append(cmds, m.startSetup(path))
+		}
+		if m.modal == repairModal {
+			cmds = append(cmds, m.scanRepairItems())
+		}
+		return m, tea.Batch(cmds...)
 
-			// No AI - open PR content modal for manual input
-			m.modal = prContentModal
-			m.prModalFocused = 0
-			m.prTitleInput.Focus()
-			m.prDescriptionInput.Blur()
+	case logLoadedMsg:
+		// Ignore pages of a log that was closed or reopened for another worktree
+		if m.modal != logModal || msg.path != m.logWorktreePath || msg.skip != m.logCommits {
 			return m, nil
 		}
-
-	case prContentGeneratedMsg:
-		// AI PR content generated (title and description)
-
-		// Stop spinner animation if we're generating in PR modal
-		if m.modal == prContentModal {
-			m.generatingPRContent = false
+		m.logLoading = false
+		if m.logEntries == nil {
+			m.logEntries = []git.LogEntry{}
 		}
-
 		if msg.err != nil {
-			// AI generation failed
-			// Check if this was a retry attempt
-			if m.prRetryWorktreePath != "" {
-				m.prRetryInProgress = false
-				m.prRetryWorktreePath = ""
-				m.prRetryBranch = ""
-				m.prRetryTitle = ""
-				m.prRetryDescription = ""
-				cmd = m.showErrorNotification("Failed to regenerate PR content: " + msg.err.Error(), 4*time.Second)
-				return m, cmd
-			}
-			// AI generation failed - show error and keep modal open for manual entry
-			cmd = m.showWarningNotification("Failed to generate PR content: " + msg.err.Error())
+			m.logHasMore = false
+			cmd = m.showErrorNotification("Failed to load log: "+msg.err.Error(), 4*time.Second)
 			return m, cmd
 		}
-
-		// Check if this is a PR retry attempt (PR already exists)
-		if m.prRetryWorktreePath != "" {
-			// Store the generated content and worktree/branch info before clearing
-			retryWorktreePath := m.prRetryWorktreePath
-			retryBranch := m.prRetryBranch
-			// Clear retry state before returning
-			m.prRetryInProgress = false
-			m.prRetryWorktreePath = ""
-			m.prRetryBranch = ""
-			m.prRetryTitle = ""
-			m.prRetryDescription = ""
-			// Retry creating PR with new content (skip push since branch already exists)
-			cmd = m.showInfoNotification("Retrying PR creation with new content...")
-			return m, tea.Batch(cmd, m.createPRRetry(retryWorktreePath, retryBranch, msg.title, msg.description))
+		commits := 0
+		for _, entry := range msg.entries {
+			if entry.Hash != "" {
+				commits++
+			}
+		}
+		m.logEntries = append(m.logEntries, msg.entries...)
+		m.logCommits += commits
+		m.logHasMore = commits == logPageSize
+		// Start on the first commit rather than a graph-only line
+		if m.selectedLogEntry() == nil {
+			m.moveLogCursor(1)
 		}
+		return m, nil
 
-		// Check if we're in PR content modal
-		if m.modal == prContentModal {
-			// Fill in the generated content but don't create PR yet - let user confirm
-			m.prTitleInput.SetValue(msg.title)
-			m.prDescriptionInput.SetValue(msg.description)
-			cmd = m.showSuccessNotification("PR content generated! Review and press Enter to create", 3*time.Second)
+	case commitDiffLoadedMsg:
+		if m.modal != logModal || msg.hash != m.logDiffHash {
+			return m, nil
+		}
+		if msg.err != nil {
+			m.logDiffHash = ""
+			cmd = m.showErrorNotification(msg.err.Error(), 4*time.Second)
 			return m, cmd
 		}
+		m.logDiff = strings.Split(strings.TrimRight(msg.diff, "\n"), "\n")
+		m.logDiffScroll = 0
+		return m, nil
 
-		// Not in modal - auto-create or update PR with generated content (for auto-generation flow)
-		cmd = m.showInfoNotification("Creating or updating draft PR...")
-		return m, tea.Batch(cmd, m.createOrUpdatePR(msg.worktreePath, msg.branch, msg.title, msg.description))
+	case cherryPickCompletedMsg:
+		if msg.err != nil {
+			if isConflictError(msg.err) {
+				cmd = m.showWarningNotification("Cherry-pick onto " + msg.targetBranch + " stopped on conflicts. Resolve them to continue.")
+				return m, tea.Batch(cmd, m.openConflictModal(msg.targetPath))
+			}
+			cmd = m.showErrorNotification(msg.err.Error(), 5*time.Second)
+			return m, cmd
+		}
+		m.logSelected = make(map[string]bool)
+		if msg.skipped > 0 {
+			cmd = m.showInfoNotification(fmt.Sprintf("Cherry-picked %d commit(s) onto %s, skipped %d already on the branch", msg.count-msg.skipped, msg.targetBranch, msg.skipped))
+			return m, tea.Batch(cmd, m.loadWorktrees())
+		}
+		cmd = m.showSuccessNotification(fmt.Sprintf("Cherry-picked %d commit(s) onto %s", msg.count, msg.targetBranch), 3*time.Second)
+		return m, tea.Batch(cmd, m.loadWorktrees())
 
-	case pushBranchNameGeneratedMsg:
-		// AI branch name generated for push
+	case stashesLoadedMsg:
+		if msg.err != nil {
+			cmd = m.showErrorNotification("Failed to load stashes: "+msg.err.Error(), 4*time.Second)
+			return m, cmd
+		}
+		m.stashes = msg.stashes
+		// Keep index in bounds
+		if visible := len(m.visibleStashes()); m.stashIndex >= visible {
+			m.stashIndex = visible - 1
+		}
+		if m.stashIndex < 0 {
+			m.stashIndex = 0
+		}
+		m.stashPreview = ""
+		m.stashPreviewRef = ""
+		m.stashScroll = 0
+		return m, m.loadStashPreview()
+
+	case stashPreviewLoadedMsg:
+		// Ignore stale previews if the selection moved on
+		if stash := m.selectedStash(); stash == nil || stash.Ref != msg.ref {
+			return m, nil
+		}
+		if msg.err != nil {
+			m.debugLog("Failed to load stash preview: " + msg.err.Error())
+			return m, nil
+		}
+		m.stashPreview = msg.content
+		m.stashPreviewRef = msg.ref
+		return m, nil
+
+	case stashActionCompletedMsg:
+		if msg.err != nil {
+			if strings.Contains(msg.err.Error(), "stash conflict") {
+				cmd = m.showWarningNotification(msg.err.Error())
+				return m, tea.Batch(cmd, m.loadStashes(), m.loadWorktrees())
+			}
+			cmd = m.showErrorNotification(msg.err.Error(), 4*time.Second)
+			return m, cmd
+		}
+		switch msg.action {
+		case "push":
+			cmd = m.showSuccessNotification("Changes stashed", 2*time.Second)
+		case "apply":
+			cmd = m.showSuccessNotification("Applied "+msg.ref, 2*time.Second)
+		case "pop":
+			cmd = m.showSuccessNotification("Popped "+msg.ref, 2*time.Second)
+		case "drop":
+			cmd = m.showSuccessNotification("Dropped "+msg.ref, 2*time.Second)
+		}
+		return m, tea.Batch(cmd, m.loadStashes(), m.loadWorktrees())
+
+	case stagingDiffLoadedMsg:
+		// Ignore stale results if the view moved on to another file or side
+		if !m.stagingDiffOpen || msg.file != m.stagingDiffFile || msg.staged != m.stagingDiffStaged {
+			return m, nil
+		}
+		if msg.err != nil {
+			cmd = m.showErrorNotification("Failed to load diff: "+msg.err.Error(), 4*time.Second)
+			return m, cmd
+		}
+		m.stagingDiff = msg.diff
+		// Keep hunk index in bounds
+		if m.stagingHunkIndex >= len(m.stagingDiff.Hunks) {
+			m.stagingHunkIndex = len(m.stagingDiff.Hunks) - 1
+		}
+		if m.stagingHunkIndex < 0 {
+			m.stagingHunkIndex = 0
+		}
+		return m, nil
+
+	case stagingPatchAppliedMsg:
+		if msg.err != nil {
+			cmd = m.showErrorNotification(msg.err.Error(), 4*time.Second)
+			return m, cmd
+		}
+		// Reload the diff (the applied changes moved to the other side) and the file list behind it
+		m.stagingLineMode = false
+		m.stagingLineSelected = nil
+		return m, tea.Batch(m.loadStagingDiff(), m.loadStagingFiles())
+
+	case stagingFileToggledMsg:
+		if msg.err != nil {
+			cmd = m.showErrorNotification("Failed to toggle file: "+msg.err.Error(), 4*time.Second)
+			return m, cmd
+		}
+		// Refresh the staging files list
+		return m, m.loadStagingFiles()
+
+	case editorOpenedMsg:
+		if msg.err != nil {
+			cmd = m.showErrorNotification("Failed to open editor: " + msg.err.Error(), 4*time.Second)
+			return m, cmd
+		} else {
+			cmd = m.showSuccessNotification("Opened in editor", 3*time.Second)
+			return m, cmd
+		}
+
+	case prCreatedMsg:
+		if msg.err != nil {
+			m.debugLog(fmt.Sprintf("PR creation failed: %v", msg.err))
+			errMsg := msg.err.Error()
+
+			// Check if the error is "PR already exists"
+			if strings.Contains(errMsg, "already exists") {
+				// Only retry once - if we're already retrying, don't try again
+				if !m.prRetryInProgress {
+					// Check if AI is configured
+					hasAPIKey := m.configManager != nil && m.configManager.GetAnthropicAPIKey() != ""
+					aiContentEnabled := m.configManager != nil && m.configManager.GetAICommitEnabled()
+
+					if hasAPIKey && aiContentEnabled && msg.worktreePath != "" && msg.branch != "" {
+						// Mark that we're in a retry attempt
+						m.prRetryInProgress = true
+						// Store the worktree and branch for retry
+						m.prRetryWorktreePath = msg.worktreePath
+						m.prRetryBranch = msg.branch
+
+						// Trigger PR content regeneration
+						cmd = m.showWarningNotification("PR already exists. Regenerating title and description...")
+						return m, tea.Batch(cmd, m.generatePRContent(msg.worktreePath, msg.branch, m.baseBranch))
+					}
+				}
+			}
+
+			// Clear retry state before showing error
+			m.prRetryInProgress = false
+			m.prRetryWorktreePath = ""
+			m.prRetryBranch = ""
+			m.prRetryTitle = ""
+			m.prRetryDescription = ""
+
+			cmd = m.showErrorNotification("Failed to create PR: " + errMsg, 4*time.Second)
+			return m, cmd
+		} else {
+			// Clear retry state on successful creation
+			m.prRetryInProgress = false
+			m.prRetryWorktreePath = ""
+			m.prRetryBranch = ""
+			m.prRetryTitle = ""
+			m.prRetryDescription = ""
+
+			m.debugLog(fmt.Sprintf("PR created successfully: %s", msg.prURL))
+			// Use the branch from the message (the one we actually created the PR for)
+			// This prevents race conditions where the user navigates to a different worktree
+			// while the PR is being created
+			prBranch := msg.branch
+
+			m.debugLog(fmt.Sprintf("Saving PR for branch: %s, title: %s", prBranch, msg.prTitle))
+			// Save PR to config
+			if prBranch != "" {
+				// Extract PR number from URL (e.g., github.com/owner/repo/pull/123)
+				prNumber := 0
+				if parts := strings.Split(msg.prURL, "/pull/"); len(parts) == 2 {
+					fmt.Sscanf(parts[1], "%d", &prNumber)
+				}
+				m.debugLog(fmt.Sprintf("Extracted PR number: %d from URL: %s", prNumber, msg.prURL))
+				_ = m.configManager.AddPR(m.repoPath, prBranch, msg.prURL, prNumber, msg.prTitle, msg.author)
+			}
+
+			m.debugLog("Triggering worktree refresh after PR creation")
+
+			// Create status message based on draft status
+			statusMsg := "PR created / updated"
+			if msg.isDraft {
+				statusMsg = "Draft PR created / updated"
+			}
+			cmd = m.showSuccessNotification(statusMsg + ": " + msg.prURL, 5*time.Second)
+			return m, tea.Batch(
+				cmd,
+				m.loadWorktrees(),
+			)
+		}
+
+	case prBranchNameGeneratedMsg:
+		// AI branch name generated for PR
 		if msg.err != nil {
 			// AI generation failed - fall back to current name (graceful degradation)
-			cmd = m.showWarningNotification("Using current branch name...")
-			return m, tea.Batch(cmd, m.pushBranch(msg.worktreePath, msg.oldBranchName))
+			cmd = m.showWarningNotification("Using current branch name for PR...")
+			// Still try to generate PR content with AI
+			hasAPIKey := m.configManager != nil && m.configManager.GetAnthropicAPIKey() != ""
+			aiContentEnabled := m.configManager != nil && m.configManager.GetAICommitEnabled()
+			if hasAPIKey && aiContentEnabled {
+				return m, tea.Batch(cmd, m.generatePRContent(msg.worktreePath, msg.oldBranchName, m.baseBranch))
+			}
+			return m, tea.Batch(cmd, m.createPR(msg.worktreePath, msg.oldBranchName, "", ""))
 		}
 
 		// Check if target branch already exists locally
 		targetExists, _ := m.gitManager.BranchExists(msg.worktreePath, msg.newBranchName)
 		if targetExists {
-			// Target branch already exists - skip rename and use current name for push
+			// Target branch already exists - skip rename and use current name for PR
 			cmd = m.showWarningNotification("Branch name already exists, using current name...")
-			return m, tea.Batch(cmd, m.pushBranch(msg.worktreePath, msg.oldBranchName))
+			// Still try to generate PR content with AI
+			hasAPIKey := m.configManager != nil && m.configManager.GetAnthropicAPIKey() != ""
+			aiContentEnabled := m.configManager != nil && m.configManager.GetAICommitEnabled()
+			if hasAPIKey && aiContentEnabled {
+				return m, tea.Batch(cmd, m.generatePRContent(msg.worktreePath, msg.oldBranchName, m.baseBranch))
+			}
+			return m, tea.Batch(cmd, m.createPR(msg.worktreePath, msg.oldBranchName, "", ""))
 		}
 
-		// Store pending rename state (reuse PR state variables)
+		// Store pending rename state
 		m.pendingPRNewName = msg.newBranchName
 		m.pendingPROldName = msg.oldBranchName
 		m.pendingPRWorktree = msg.worktreePath
@@ -941,14 +1113,14 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if remoteExists {
 			// Delete remote branch first
 			cmd = m.showInfoNotification("Deleting old remote branch...")
-			return m, tea.Batch(cmd, m.deleteRemoteBranchForPush(msg.worktreePath, msg.oldBranchName, msg.newBranchName))
+			return m, tea.Batch(cmd, m.deleteRemoteBranchForPR(msg.worktreePath, msg.oldBranchName, msg.newBranchName))
 		} else {
 			// No remote, go straight to rename
 			cmd = m.showInfoNotification("Renaming to: " + msg.newBranchName)
-			return m, tea.Batch(cmd, m.renameBranchForPush(msg.oldBranchName, msg.newBranchName, msg.worktreePath))
+			return m, tea.Batch(cmd, m.renameBranchForPR(msg.oldBranchName, msg.newBranchName, msg.worktreePath))
 		}
 
-	case pushRemoteBranchDeletedMsg:
+	case prRemoteBranchDeletedMsg:
 		// Remote branch deleted, now rename local branch
 		if msg.err != nil {
 			// Deletion failed but continue anyway
@@ -958,690 +1130,671 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Check if target branch already exists locally
 		targetExists, _ := m.gitManager.BranchExists(msg.worktreePath, msg.newBranchName)
 		if targetExists {
-			// Target branch already exists - skip rename and use current name for push
+			// Target branch already exists - skip rename and use current name for PR
 			cmd = m.showWarningNotification("Branch name already exists, using current name...")
-			return m, tea.Batch(cmd, m.pushBranch(msg.worktreePath, msg.oldBranchName))
+			// Still try to generate PR content with AI
+			hasAPIKey := m.configManager != nil && m.configManager.GetAnthropicAPIKey() != ""
+			aiContentEnabled := m.configManager != nil && m.configManager.GetAICommitEnabled()
+			if hasAPIKey && aiContentEnabled {
+				return m, tea.Batch(cmd, m.generatePRContent(msg.worktreePath, msg.oldBranchName, m.baseBranch))
+			}
+			return m, tea.Batch(cmd, m.createPR(msg.worktreePath, msg.oldBranchName, "", ""))
 		}
 
 		cmd = m.showInfoNotification("Renaming branch locally...")
-		return m, tea.Batch(cmd, m.renameBranchForPush(msg.oldBranchName, msg.newBranchName, msg.worktreePath))
+		return m, tea.Batch(cmd, m.renameBranchForPR(msg.oldBranchName, msg.newBranchName, msg.worktreePath))
 
-	case pushBranchRenamedMsg:
-		// Branch renamed, now push with new name
+	case prBranchRenamedMsg:
+		// Branch renamed, now create PR with new name
 		if msg.err != nil {
 			cmd = m.showErrorNotification("Failed to rename branch: " + msg.err.Error(), 4*time.Second)
 			return m, cmd
 		}
 
-		// Rename succeeded, now push
-		cmd = m.showInfoNotification("Pushing to remote...")
-		return m, tea.Batch(
-			cmd,
-			m.renameSessionsForBranch(msg.oldBranchName, msg.newBranchName),
-			m.pushBranch(msg.worktreePath, msg.newBranchName),
-		)
+		// Rename succeeded, check if we should generate AI PR content
+		hasAPIKey := m.configManager != nil && m.configManager.GetAnthropicAPIKey() != ""
+		aiEnabled := m.configManager != nil && m.configManager.GetAIBranchNameEnabled()
 
-	case pushCompletedMsg:
-		// Push completed
-		if msg.err != nil {
-			cmd = m.showErrorNotification("Failed to push: " + msg.err.Error(), 4*time.Second)
+		if hasAPIKey && aiEnabled {
+			// Generate AI PR content before creating PR
+			cmd = m.showInfoNotification("🤖 Generating PR title and description...")
 			return m, tea.Batch(
 				cmd,
-				m.loadWorktrees(),
+				m.renameSessionsForBranch(msg.oldBranchName, msg.newBranchName),
+				m.generatePRContent(msg.worktreePath, msg.newBranchName, m.baseBranch),
 			)
-		}
+		} else {
+			// No AI - open PR content modal for manual entry
+			m.modal = prContentModal
+			m.prModalFocused = 0
+			m.prModalWorktreePath = msg.worktreePath
+			m.prModalBranch = msg.newBranchName
 
-		// Push succeeded
-		cmd = m.showSuccessNotification("Pushed to origin/"+msg.branch, 3*time.Second)
-		return m, tea.Batch(
-			cmd,
-			m.loadWorktrees(),
-		)
+			// Default title to new branch name
+			defaultTitle := strings.ReplaceAll(msg.newBranchName, "-", " ")
+			defaultTitle = strings.ReplaceAll(defaultTitle, "_", " ")
+			defaultTitle = strings.Title(defaultTitle)
+			m.prTitleInput.SetValue(defaultTitle)
+			m.prTitleInput.Focus()
+			m.prDescriptionInput.SetValue("")
 
-	case themeChangedMsg:
-		if msg.err != nil {
-			cmd = m.showErrorNotification("Failed to change theme: " + msg.err.Error(), 3*time.Second)
-			return m, cmd
-		} else {
-			cmd = m.showSuccessNotification("Theme changed to: " + msg.theme, 2*time.Second)
+			// Rename sessions
+			cmd = m.renameSessionsForBranch(msg.oldBranchName, msg.newBranchName)
 			return m, cmd
 		}
 
-	case branchPulledMsg:
+	case commitCreatedMsg:
 		if msg.err != nil {
-			if msg.hadConflict {
-				// Show error with abort option
-				cmd = m.showWarningNotification("Merge conflict! Run 'git merge --abort' in the worktree to abort.")
-				return m, cmd
-			} else if strings.Contains(msg.err.Error(), "already up-to-date") {
-				// User tried to pull but worktree is already up-to-date (after checking fresh refs)
-				cmd = m.showInfoNotification("Worktree is already up-to-date with base branch")
-				return m, cmd
-			} else {
-				cmd = m.showErrorNotification("Failed to pull from base branch: " + msg.err.Error(), 5*time.Second)
-				return m, cmd
-			}
+			m.debugLog(fmt.Sprintf("Commit creation failed: %v", msg.err))
+			cmd = m.showErrorNotification("Failed to create commit: " + msg.err.Error(), 4*time.Second)
+			return m, cmd
 		} else {
-			cmd = m.showSuccessNotification("Successfully pulled changes from base branch", 3*time.Second)
-			return m, tea.Batch(
-				cmd,
-				m.loadWorktrees(),
-			)
-		}
+			m.debugLog(fmt.Sprintf("Commit created successfully with hash: %s", msg.commitHash))
+			// Save the commit message for use as PR title
+			m.lastCommitMessage = msg.subject
 
-	case localMergePreparedMsg:
-		if msg.err != nil {
-			cmd = m.showErrorNotification("Failed to prepare merge: " + msg.err.Error(), 5*time.Second)
-			return m, cmd
-		}
-
-		// Store merge info and show confirmation modal
-		m.localMergeBranch = msg.branch
-		m.localMergeTarget = msg.target
-		m.localMergeWorktree = msg.worktreePath
-		m.localMergeAhead = msg.ahead
-		m.localMergeBehind = msg.behind
-		m.localMergeFocused = 0 // Default to confirm button
-		m.modal = localMergeConfirmModal
-		m.debugLog(fmt.Sprintf("Local merge prepared: %s -> %s (ahead: %d, behind: %d)", msg.branch, msg.target, msg.ahead, msg.behind))
-		return m, nil
+			// Clear commit modal inputs for next use
+			m.commitSubjectInput.SetValue("")
+			m.modalFocused = 0
 
-	case localMergeCompletedMsg:
-		if msg.err != nil {
-			if msg.hadConflict {
-				// Show error with abort option
-				cmd = m.showWarningNotification("Merge conflict! Resolve conflicts and commit, or run 'git merge --abort' in main repo.")
-				return m, tea.Batch(
-					cmd,
-					m.loadWorktrees(), // Refresh to show updated state
-				)
+			// Show success message with commit hash
+			if msg.commitHash != "" {
+				hashDisplay := msg.commitHash
+				if len(msg.commitHash) > 8 {
+					hashDisplay = msg.commitHash[:8]
+				}
+				cmd = m.showSuccessNotification("Commit created: " + hashDisplay, 3*time.Second)
 			} else {
-				cmd = m.showErrorNotification("Failed to merge: " + msg.err.Error(), 5*time.Second)
-				return m, tea.Batch(
-					cmd,
-					m.loadWorktrees(),
-				)
+				cmd = m.showSuccessNotification("Commit created successfully", 3*time.Second)
 			}
-		}
 
-		// Merge successful - show post-merge cleanup modal
-		m.debugLog(fmt.Sprintf("Local merge completed successfully: %s merged into %s", msg.branch, m.localMergeTarget))
-		m.postMergeDeleteIndex = 0 // Default to delete option
-		m.modal = postMergeCleanupModal
+			// Check if we're committing before PR creation
+			if m.commitBeforePR && m.prCreationPending != "" {
+				// Get the branch name from the pending PR worktree
+				var branch string
+				for _, wt := range m.worktrees {
+					if wt.Path == m.prCreationPending {
+						branch = wt.Branch
+						break
+					}
+				}
 
-		// Update worktree list to show we're now on base branch
-		return m, m.loadWorktrees()
+				// Reset commit before PR flag
+				m.commitBeforePR = false
 
-	case refreshWithPullMsg:
-		if msg.err != nil {
-			cmd = m.showErrorNotification("Failed to refresh: " + msg.err.Error(), 5*time.Second)
-			return m, cmd
-		} else {
-			// Build detailed status message based on what was pulled
-			statusMsg := buildRefreshStatusMessage(msg)
+				// Check if we should do AI renaming
+				hasAPIKey := m.configManager != nil && m.configManager.GetAnthropicAPIKey() != ""
+				aiEnabled := m.configManager != nil && m.configManager.GetAIBranchNameEnabled()
+				isRandomName := m.gitManager.IsRandomBranchName(branch)
+				shouldAIRename := hasAPIKey && aiEnabled && isRandomName
 
-			// If there was an error pulling the main repo branch, append it to the message
-			if msg.pullErr != nil {
-				statusMsg += " (pull error: " + msg.pullErr.Error() + ")"
-				cmd = m.showWarningNotification(statusMsg)
-			} else {
-				cmd = m.showSuccessNotification(statusMsg, 3*time.Second)
+				if shouldAIRename {
+					// Start AI rename flow before PR creation
+					cmd = m.showInfoNotification("🤖 Generating semantic branch name...")
+					return m, tea.Batch(cmd, m.generateBranchNameForPR(m.prCreationPending, branch, m.baseBranch))
+				} else {
+					// No AI rename needed - use default PR state from config
+					prState := m.configManager.GetPRDefaultState(m.repoPath)
+					m.prIsDraft = (prState == "draft")
+					m.prModalWorktreePath = m.prCreationPending
+					m.prModalBranch = branch
+					m.commitBeforePR = false
+					m.prCreationPending = ""
+
+					// Check if AI is enabled for PR content generation
+					aiEnabled := m.configManager != nil &&
+						m.configManager.GetAnthropicAPIKey() != "" &&
+						m.aiCommitEnabled
+
+					if aiEnabled {
+						// Generate PR content with AI
+						cmd := m.showSuccessNotification("Committed successfully. Generating PR content...", 2*time.Second)
+						return m, tea.Batch(cmd, m.generatePRContent(m.prModalWorktreePath, m.prModalBranch, m.baseBranch))
+					}
+
+					// No AI - open PR content modal for manual input
+					m.modal = prContentModal
+					m.prModalFocused = 0
+					m.prTitleInput.Focus()
+					m.prDescriptionInput.Blur()
+					return m, m.showSuccessNotification("Committed successfully. Enter PR details:", 2*time.Second)
+				}
 			}
 
-			// Only show notification if not initializing (suppress during startup)
-			if !m.isInitializing {
-				cmd = m.showSuccessNotification(buildRefreshStatusMessage(msg), 3*time.Second)
+			// Check if we're committing before push-only (no PR)
+			if m.commitBeforePR && m.prCreationPending == "" {
+				// Get current worktree
+				wt := m.selectedWorktree()
+				if wt == nil {
+					return m, cmd
+				}
+
+				// Reset commit before PR flag
+				m.commitBeforePR = false
+
+				// Check if we should do AI renaming
+				hasAPIKey := m.configManager != nil && m.configManager.GetAnthropicAPIKey() != ""
+				aiEnabled := m.configManager != nil && m.configManager.GetAIBranchNameEnabled()
+				isRandomName := m.gitManager.IsRandomBranchName(wt.Branch)
+				shouldAIRename := hasAPIKey && aiEnabled && isRandomName
+
+				if shouldAIRename {
+					// Start AI rename flow before push
+					notifyCmd := m.showInfoNotification("🤖 Generating semantic branch name...")
+					return m, tea.Batch(notifyCmd, m.generateBranchNameForPush(wt.Path, wt.Branch, m.baseBranch))
+				} else {
+					// No AI rename needed, go straight to push
+					notifyCmd := m.showInfoNotification("Pushing to remote...")
+					return m, tea.Batch(notifyCmd, m.pushBranch(wt.Path, wt.Branch))
+				}
 			}
-			// Reload worktree list to show updated status
+
+			// Check if we committed from staging modal - refresh staging files
+			if m.commitFromStagingModal {
+				m.commitFromStagingModal = false
+				m.debugLog("Triggering worktree and staging files refresh after commit from staging modal")
+				return m, tea.Batch(cmd, m.loadWorktrees(), m.loadStagingFiles())
+			}
+
+			// Normal commit (not before PR)
+			m.debugLog("Triggering worktree refresh after commit")
 			return m, tea.Batch(
 				cmd,
 				m.loadWorktrees(),
 			)
 		}
 
-	case prStatusesRefreshedMsg:
+	case autoCommitBeforePRMsg:
+		// Auto-commit succeeded, now proceed with PR creation
 		if msg.err != nil {
-			// Silently handle PR status refresh errors
-			return m, m.loadWorktrees()
-		}
-		// Reload worktrees to show updated PR statuses
-		return m, m.loadWorktrees()
-
-	case activityTickMsg:
-		// Check if enough time has passed since last activity check
-		if time.Since(m.lastActivityCheck) >= m.activityCheckInterval {
-			m.lastActivityCheck = time.Now()
-			cmd = m.checkSessionActivity()
+			cmd = m.showErrorNotification("Failed to commit changes: " + msg.err.Error(), 4*time.Second)
 			return m, cmd
 		}
-		return m, m.scheduleActivityCheck()
 
-	case activityCheckedMsg:
-		if msg.err == nil {
-			// Update sessions with activity information
-			m.sessions = msg.sessions
-		}
-		// Continue scheduling activity checks
-		cmd = m.scheduleActivityCheck()
-		return m, cmd
+		// Commit succeeded, now proceed with PR creation
+		// Check if we should do AI renaming first
+		hasAPIKey := m.configManager != nil && m.configManager.GetAnthropicAPIKey() != ""
+		aiEnabled := m.configManager != nil && m.configManager.GetAIBranchNameEnabled()
+		isRandomName := m.gitManager.IsRandomBranchName(msg.branch)
 
-	case versionCheckMsg:
-		// Silently handle errors (don't show error notification for version check failures)
-		if msg.err != nil {
+		shouldAIRename := hasAPIKey && aiEnabled && isRandomName
+
+		if shouldAIRename {
+			// Start AI rename flow before PR creation
+			cmd = m.showInfoNotification("🤖 Generating semantic branch name...")
+			return m, tea.Batch(cmd, m.generateBranchNameForPR(msg.worktreePath, msg.branch, m.baseBranch))
+		} else {
+			// No AI rename needed - use default PR state from config
+			prState := m.configManager.GetPRDefaultState(m.repoPath)
+			m.prIsDraft = (prState == "draft")
+			m.prModalWorktreePath = msg.worktreePath
+			m.prModalBranch = msg.branch
+
+			// Check if AI is enabled for PR content generation
+			aiEnabled := m.configManager != nil &&
+				m.configManager.GetAnthropicAPIKey() != "" &&
+				m.aiCommitEnabled
+
+			if aiEnabled {
+				// Generate PR content with AI
+				return m, m.generatePRContent(m.prModalWorktreePath, m.prModalBranch, m.baseBranch)
+			}
+
+			// No AI - open PR content modal for manual input
+			m.modal = prContentModal
+			m.prModalFocused = 0
+			m.prTitleInput.Focus()
+			m.prDescriptionInput.Blur()
 			return m, nil
 		}
 
-		// Show notification only if update is available
-		if msg.updateAvailable {
-			notifMsg := fmt.Sprintf("Update available: %s → %s. Run: jean update", msg.currentVersion, msg.latestVersion)
-			cmd = m.showInfoNotification(notifMsg)
-			return m, cmd
+	case prContentGeneratedMsg:
+		// AI PR content generated (title and description)
+
+		// Stop spinner animation if we're generating in PR modal
+		if m.modal == prContentModal {
+			m.generatingPRContent = false
 		}
-		return m, nil
 
-	case commitMessageGeneratedMsg:
-		m.generatingCommit = false // Stop spinner animation
 		if msg.err != nil {
-			// If auto-committing with AI, show error and abort
-			if m.autoCommitWithAI {
-				m.autoCommitWithAI = false
-				cmd := m.showErrorNotification("🤖 Failed to generate commit message: " + msg.err.Error(), 4*time.Second)
-				return m, cmd
-			}
-			// If in PR creation flow, show error and abort
-			if m.commitBeforePR {
-				m.commitBeforePR = false
-				cmd := m.showErrorNotification("🤖 Failed to generate commit message: " + msg.err.Error(), 4*time.Second)
+			// AI generation failed
+			// Check if this was a retry attempt
+			if m.prRetryWorktreePath != "" {
+				m.prRetryInProgress = false
+				m.prRetryWorktreePath = ""
+				m.prRetryBranch = ""
+				m.prRetryTitle = ""
+				m.prRetryDescription = ""
+				cmd = m.showErrorNotification("Failed to regenerate PR content: " + msg.err.Error(), 4*time.Second)
 				return m, cmd
 			}
-			// Set status message in commit modal
-			m.commitModalStatus = "❌ Error: " + msg.err.Error()
-			m.commitModalStatusTime = time.Now()
-			return m, nil
-		} else {
-			// If auto-committing with AI, commit immediately without PR flow
-			if m.autoCommitWithAI {
-				m.autoCommitWithAI = false
-				if wt := m.selectedWorktree(); wt != nil {
-					return m, m.createCommit(wt.Path, msg.subject)
-				}
-				return m, nil
-			}
-			// If in PR creation flow, auto-commit with generated message
-			if m.commitBeforePR {
-				cmd := m.showInfoNotification("🤖 Committing with AI-generated message...")
-				return m, tea.Batch(cmd, m.createCommit(m.prCreationPending, msg.subject))
-			}
-			// Otherwise populate the commit message fields with AI-generated content for user review
-			m.commitSubjectInput.SetValue(msg.subject)
-			// Set success status message
-			m.commitModalStatus = "✅ Message generated successfully - review and edit if needed"
-			m.commitModalStatusTime = time.Now()
-			// Move focus to subject input so user can review/edit
-			m.modalFocused = 0
-			m.commitSubjectInput.Focus()
-			return m, nil
+			// AI generation failed - show error and keep modal open for manual entry
+			cmd = m.showWarningNotification("Failed to generate PR content: " + msg.err.Error())
+			return m, cmd
 		}
 
-	case apiKeyTestedMsg:
-		if msg.err != nil {
-			// Set status message in AI settings modal
-			m.aiModalStatus = "❌ Test failed: " + msg.err.Error()
-			m.aiModalStatusTime = time.Now()
-			return m, nil
-		} else {
-			// Set success status message
-			m.aiModalStatus = "✅ API key is valid and working!"
-			m.aiModalStatusTime = time.Now()
-			return m, nil
+		// Check if this is a PR retry attempt (PR already exists)
+		if m.prRetryWorktreePath != "" {
+			// Store the generated content and worktree/branch info before clearing
+			retryWorktreePath := m.prRetryWorktreePath
+			retryBranch := m.prRetryBranch
+			// Clear retry state before returning
+			m.prRetryInProgress = false
+			m.prRetryWorktreePath = ""
+			m.prRetryBranch = ""
+			m.prRetryTitle = ""
+			m.prRetryDescription = ""
+			// Retry creating PR with new content (skip push since branch already exists)
+			cmd = m.showInfoNotification("Retrying PR creation with new content...")
+			return m, tea.Batch(cmd, m.createPRRetry(retryWorktreePath, retryBranch, msg.title, msg.description))
 		}
 
-	case aiPromptsLoadedMsg:
-		// Load prompts into the modal inputs
-		if msg.err != nil {
-			m.aiPromptsStatus = "Error loading prompts: " + msg.err.Error()
-			return m, nil
+		// Check if we're in PR content modal
+		if m.modal == prContentModal {
+			// Fill in the generated content but don't create PR yet - let user confirm
+			m.prTitleInput.SetValue(msg.title)
+			m.prDescriptionInput.SetValue(msg.description)
+			cmd = m.showSuccessNotification("PR content generated! Review and press Enter to create", 3*time.Second)
+			return m, cmd
 		}
-		m.aiPromptCommitInput.SetValue(msg.commitPrompt)
-		m.aiPromptBranchInput.SetValue(msg.branchPrompt)
-		m.aiPromptPRInput.SetValue(msg.prPrompt)
-		m.aiPromptsStatus = ""
-		return m, nil
 
-	case aiPromptsSavedMsg:
-		if msg.err != nil {
-			m.aiPromptsStatus = "❌ Failed to save: " + msg.err.Error()
-			m.aiPromptsStatusTime = time.Now()
-			return m, nil
-		}
-		// Success - close modal and return to AI settings
-		cmd := m.showSuccessNotification("AI prompts saved successfully", 2*time.Second)
-		m.modal = aiSettingsModal
-		m.aiModalFocusedField = 0
-		m.aiPromptCommitInput.Blur()
-		m.aiPromptBranchInput.Blur()
-		m.aiPromptPRInput.Blur()
-		return m, cmd
+		// Not in modal - auto-create or update PR with generated content (for auto-generation flow)
+		cmd = m.showInfoNotification("Creating or updating draft PR...")
+		return m, tea.Batch(cmd, m.createOrUpdatePR(msg.worktreePath, msg.branch, msg.title, msg.description))
 
-	case aiPromptsResetMsg:
+	case pushBranchNameGeneratedMsg:
+		// AI branch name generated for push
 		if msg.err != nil {
-			m.aiPromptsStatus = "❌ Failed to reset: " + msg.err.Error()
-			m.aiPromptsStatusTime = time.Now()
-			return m, nil
+			// AI generation failed - fall back to current name (graceful degradation)
+			cmd = m.showWarningNotification("Using current branch name...")
+			return m, tea.Batch(cmd, m.pushBranch(msg.worktreePath, msg.oldBranchName))
 		}
-		// Reload the default prompts to display them
-		cmd := m.loadAIPrompts()
-		m.aiPromptsStatus = "✅ Prompts reset to defaults"
-		m.aiPromptsStatusTime = time.Now()
-		return m, cmd
 
-	case prFetchedForCreationMsg:
-		// Handle fetch completion before PR creation
-		m.prFetchingForCreation = false
-		if msg.err != nil {
-			return m, m.showErrorNotification("Failed to fetch from remote: "+msg.err.Error(), 3*time.Second)
+		// Check if target branch already exists locally
+		targetExists, _ := m.gitManager.BranchExists(msg.worktreePath, msg.newBranchName)
+		if targetExists {
+			// Target branch already exists - skip rename and use current name for push
+			cmd = m.showWarningNotification("Branch name already exists, using current name...")
+			return m, tea.Batch(cmd, m.pushBranch(msg.worktreePath, msg.oldBranchName))
 		}
 
-		// Fetch successful, now proceed with PR creation flow
-		if wt := m.selectedWorktree(); wt != nil {
-			// Check if there are uncommitted changes
-			hasUncommitted, err := m.gitManager.HasUncommittedChanges(wt.Path)
-			if err != nil {
-				return m, m.showErrorNotification("Failed to check for uncommitted changes: "+err.Error(), 3*time.Second)
-			}
-
-			// Check AI configuration
-			hasAPIKey := m.configManager != nil && m.configManager.GetAnthropicAPIKey() != ""
-			aiEnabled := m.configManager != nil && m.configManager.GetAIBranchNameEnabled()
-			aiContentEnabled := m.configManager != nil && m.configManager.GetAICommitEnabled()
-			hasAI := hasAPIKey && (aiEnabled || aiContentEnabled)
-
-			// If there are uncommitted changes, decide how to handle them
-			if hasUncommitted {
-				if hasAI && aiContentEnabled {
-					// AI is enabled for commit messages - generate commit message with AI first
-					cmd := m.showInfoNotification("🤖 Generating conventional commit message...")
-					m.commitBeforePR = true
-					m.prCreationPending = wt.Path // Set to trigger PR creation after commit
-					return m, tea.Batch(cmd, m.generateCommitMessageWithAI(wt.Path))
-				} else if hasAI {
-					// AI is enabled for branch but not commit - auto-commit with simple message and proceed
-					cmd := m.showInfoNotification("Committing changes...")
-					m.commitBeforePR = true
-					m.prCreationPending = wt.Path // Set to trigger PR creation after commit
-					return m, tea.Batch(cmd, m.autoCommitBeforePR(wt.Path, wt.Branch))
-				} else {
-					// No AI - show commit modal for user to write proper commit message
-					m.modal = commitModal
-					m.modalFocused = 0
-					m.commitSubjectInput.SetValue("")
-					m.commitSubjectInput.Focus()
-							m.commitBeforePR = true
-					m.prCreationPending = wt.Path // Set to trigger PR creation after commit
-					return m, nil
-				}
-			}
-
-			// No uncommitted changes - proceed to PR creation
-			// Check if we should do AI renaming first
-			isRandomName := m.gitManager.IsRandomBranchName(wt.Branch)
-			shouldAIRename := hasAPIKey && aiEnabled && isRandomName
-
-			if shouldAIRename {
-				// Start AI rename flow before PR creation
-				cmd := m.showInfoNotification("🤖 Generating semantic branch name...")
-				m.prCreationPending = wt.Path // Set to trigger PR creation after rename
-				return m, tea.Batch(cmd, m.generateBranchNameForPush(wt.Path, wt.Branch, m.baseBranch))
-			} else {
-				// No AI rename needed - use default PR state from config
-				prState := m.configManager.GetPRDefaultState(m.repoPath)
-				m.prIsDraft = (prState == "draft")
-				m.prModalWorktreePath = wt.Path
-				m.prModalBranch = wt.Branch
-
-				// Check if AI is enabled for PR content generation
-				aiEnabled := m.configManager != nil &&
-					m.configManager.GetAnthropicAPIKey() != "" &&
-					m.aiCommitEnabled
-
-				if aiEnabled {
-					// Generate PR content with AI
-					return m, m.generatePRContent(m.prModalWorktreePath, m.prModalBranch, m.baseBranch)
-				}
+		// Store pending rename state (reuse PR state variables)
+		m.pendingPRNewName = msg.newBranchName
+		m.pendingPROldName = msg.oldBranchName
+		m.pendingPRWorktree = msg.worktreePath
 
-				// No AI - open PR content modal for manual input
-				m.modal = prContentModal
-				m.prModalFocused = 0
-				m.prTitleInput.Focus()
-				m.prDescriptionInput.Blur()
-				return m, nil
-			}
-		}
+		// Check if remote branch exists
+		remoteExists, _ := m.gitManager.RemoteBranchExists(msg.worktreePath, msg.oldBranchName)
 
-	case spinnerTickMsg:
-		// Update spinner animation frame and schedule next tick if still generating
-		if m.generatingCommit {
-			m.spinnerFrame = (m.spinnerFrame + 1) % 10
-			return m, m.animateSpinner()
-		}
-		if m.generatingPRContent {
-			m.prSpinnerFrame = (m.prSpinnerFrame + 1) % 10
-			return m, m.animateSpinner()
+		if remoteExists {
+			// Delete remote branch first
+			cmd = m.showInfoNotification("Deleting old remote branch...")
+			return m, tea.Batch(cmd, m.deleteRemoteBranchForPush(msg.worktreePath, msg.oldBranchName, msg.newBranchName))
+		} else {
+			// No remote, go straight to rename
+			cmd = m.showInfoNotification("Renaming to: " + msg.newBranchName)
+			return m, tea.Batch(cmd, m.renameBranchForPush(msg.oldBranchName, msg.newBranchName, msg.worktreePath))
 		}
-		// No more spinners, return no command
-		return m, nil
 
-	case renameGeneratedMsg:
-		// Stop spinner and handle rename generation result
-		m.generatingRename = false
+	case pushRemoteBranchDeletedMsg:
+		// Remote branch deleted, now rename local branch
 		if msg.err != nil {
-			// Set error status message
-			m.renameModalStatus = "❌ Error: " + msg.err.Error()
-			m.renameModalStatusTime = time.Now()
-		} else {
-			// Populate the name input with AI-generated branch name
-			m.nameInput.SetValue(msg.name)
-			m.nameInput.CursorEnd()
-			m.renameModalStatus = "✅ Generated from changes"
-			m.renameModalStatusTime = time.Now()
+			// Deletion failed but continue anyway
+			cmd = m.showWarningNotification("Couldn't delete old remote, continuing...")
 		}
-		return m, nil
 
-	case renameSpinnerTickMsg:
-		// Update spinner animation frame and schedule next tick if still generating
-		if m.generatingRename {
-			m.renameSpinnerFrame = (m.renameSpinnerFrame + 1) % 10
-			return m, m.animateRenameSpinner()
+		// Check if target branch already exists locally
+		targetExists, _ := m.gitManager.BranchExists(msg.worktreePath, msg.newBranchName)
+		if targetExists {
+			// Target branch already exists - skip rename and use current name for push
+			cmd = m.showWarningNotification("Branch name already exists, using current name...")
+			return m, tea.Batch(cmd, m.pushBranch(msg.worktreePath, msg.oldBranchName))
 		}
-		return m, nil
 
-	case worktreeEnsuredMsg:
-		m.ensuringWorktree = false
+		cmd = m.showInfoNotification("Renaming branch locally...")
+		return m, tea.Batch(cmd, m.renameBranchForPush(msg.oldBranchName, msg.newBranchName, msg.worktreePath))
+
+	case pushBranchRenamedMsg:
+		// Branch renamed, now push with new name
 		if msg.err != nil {
-			cmd = m.showErrorNotification(fmt.Sprintf("Failed to ensure worktree exists: %v", msg.err), 4*time.Second)
-			m.pendingSwitchInfo = nil
+			cmd = m.showErrorNotification("Failed to rename branch: " + msg.err.Error(), 4*time.Second)
 			return m, cmd
 		}
-		// Worktree is now ensured to exist, proceed with switch
-		if m.pendingSwitchInfo != nil {
-			m.switchInfo = *m.pendingSwitchInfo
-			m.pendingSwitchInfo = nil
-			return m, tea.Quit
-		}
 
-	case prMarkedReadyMsg:
-		// PR has been marked as ready for review
+		// Rename succeeded, now push
+		cmd = m.showInfoNotification("Pushing to remote...")
+		return m, tea.Batch(
+			cmd,
+			m.renameSessionsForBranch(msg.oldBranchName, msg.newBranchName),
+			m.pushBranch(msg.worktreePath, msg.newBranchName),
+		)
+
+	case pushCompletedMsg:
+		// Push completed
 		if msg.err != nil {
-			cmd = m.showErrorNotification("Failed to mark PR as ready: "+msg.err.Error(), 4*time.Second)
-			return m, cmd
+			cmd = m.showErrorNotification("Failed to push: " + msg.err.Error(), 4*time.Second)
+			return m, tea.Batch(
+				cmd,
+				m.loadWorktrees(),
+			)
 		}
 
-		// Now proceed with merge - get the stored merge method from merge strategy modal
-		wt := m.selectedWorktree()
-		if wt == nil {
-			return m, m.showErrorNotification("No worktree selected", 3*time.Second)
-		}
+		// Push succeeded
+		cmd = m.showSuccessNotification("Pushed to "+m.gitManager.PushRemote()+"/"+msg.branch, 3*time.Second)
+		return m, tea.Batch(
+			cmd,
+			m.loadWorktrees(),
+		)
 
-		// Get the PR info to find merge method (we need to reopen the modal to get this info)
-		// For now, show success and reload
-		prs, ok := wt.PRs.([]config.PRInfo)
-		if !ok || len(prs) == 0 {
-			cmd = m.showSuccessNotification("PR marked as ready for review", 2*time.Second)
-			return m, tea.Batch(cmd, m.loadWorktrees())
+	case themeChangedMsg:
+		if msg.err != nil {
+			cmd = m.showErrorNotification("Failed to change theme: " + msg.err.Error(), 3*time.Second)
+			return m, cmd
+		} else {
+			cmd = m.showSuccessNotification("Theme changed to: " + msg.theme, 2*time.Second)
+			return m, cmd
 		}
 
-		// Find the PR that was just marked ready
-		for _, pr := range prs {
-			if pr.URL == msg.prURL {
-				// PR found - now we need to merge it
-				// For now, show a notification and reload
-				cmd = m.showSuccessNotification("PR marked as ready. Please press M again to merge.", 3*time.Second)
-				return m, tea.Batch(cmd, m.loadWorktrees())
+	case branchPulledMsg:
+		if msg.err != nil {
+			if msg.hadConflict {
+				// Open the conflict resolution modal
+				cmd = m.showWarningNotification("Conflicts! Resolve the conflicted files or abort the update.")
+				m.conflictFromLocalMerge = false
+				return m, tea.Batch(cmd, m.openConflictModal(msg.worktreePath))
+			} else if strings.Contains(msg.err.Error(), "already up-to-date") {
+				// User tried to pull but worktree is already up-to-date (after checking fresh refs)
+				cmd = m.showInfoNotification("Worktree is already up-to-date with base branch")
+				return m, cmd
+			} else {
+				cmd = m.showErrorNotification("Failed to pull from base branch: " + msg.err.Error(), 5*time.Second)
+				return m, cmd
 			}
+		} else {
+			cmd = m.showSuccessNotification("Successfully pulled changes from base branch", 3*time.Second)
+			return m, tea.Batch(
+				cmd,
+				m.loadWorktrees(),
+			)
 		}
 
-		cmd = m.showSuccessNotification("PR marked as ready for review", 2*time.Second)
-		return m, tea.Batch(cmd, m.loadWorktrees())
-
-	case prMergedMsg:
-		// PR has been merged
+	case localMergePreparedMsg:
 		if msg.err != nil {
-			cmd = m.showErrorNotification("Failed to merge PR: "+msg.err.Error(), 4*time.Second)
+			cmd = m.showErrorNotification("Failed to prepare merge: " + msg.err.Error(), 5*time.Second)
 			return m, cmd
 		}
 
-		// Mark PR as merged in config
-		if m.configManager != nil && msg.branch != "" {
-			_ = m.configManager.UpdatePRStatus(m.repoPath, msg.branch, msg.prURL, "merged")
+		// Store merge info and show confirmation modal
+		m.localMergeBranch = msg.branch
+		m.localMergeTarget = msg.target
+		m.localMergeWorktree = msg.worktreePath
+		m.localMergeAhead = msg.ahead
+		m.localMergeBehind = msg.behind
+		m.localMergeFocused = 0 // Default to confirm button
+		m.modal = localMergeConfirmModal
+		m.debugLog(fmt.Sprintf("Local merge prepared: %s -> %s (ahead: %d, behind: %d)", msg.branch, msg.target, msg.ahead, msg.behind))
+		return m, nil
+
+	case localMergeCompletedMsg:
+		if msg.err != nil {
+			if msg.hadConflict {
+				// Open the conflict resolution modal on the main repo
+				cmd = m.showWarningNotification("Merge conflict! Resolve the conflicted files or abort the merge.")
+				m.conflictFromLocalMerge = true
+				return m, tea.Batch(
+					cmd,
+					m.openConflictModal(msg.conflictPath),
+					m.loadWorktrees(), // Refresh to show updated state
+				)
+			} else {
+				cmd = m.showErrorNotification("Failed to merge: " + msg.err.Error(), 5*time.Second)
+				return m, tea.Batch(
+					cmd,
+					m.loadWorktrees(),
+				)
+			}
 		}
 
-		// Show success and reload worktrees
-		cmd = m.showSuccessNotification("PR merged successfully!", 3*time.Second)
-		return m, tea.Batch(cmd, m.loadWorktrees())
-	}
+		// Merge successful - show post-merge cleanup modal
+		m.debugLog(fmt.Sprintf("Local merge completed successfully: %s merged into %s", msg.branch, m.localMergeTarget))
+		m.postMergeDeleteIndex = 0 // Default to delete option
+		m.modal = postMergeCleanupModal
 
-	return m, cmd
-}
+		// Update worktree list to show we're now on base branch
+		return m, tea.Batch(m.loadWorktrees(), m.runPostMergeHook(msg.worktreePath, msg.branch, m.localMergeTarget))
 
-func (m Model) handleMainInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	var cmd tea.Cmd
-	switch msg.String() {
-	case "q", "ctrl+c":
-		// Clear switch info to prevent shell wrapper from switching directories
-		m.switchInfo = SwitchInfo{}
-		return m, tea.Quit
+	case refreshWithPullMsg:
+		if msg.err != nil {
+			cmd = m.showErrorNotification("Failed to refresh: " + msg.err.Error(), 5*time.Second)
+			return m, cmd
+		} else {
+			// A manual refresh counts as a fetch for the background scheduler
+			m.lastFetchTime = time.Now()
+			m.fetchBackoff = 0
 
-	case "up":
-		if m.selectedIndex > 0 {
-			m.selectedIndex--
-			// Save the last selected branch
-			if wt := m.selectedWorktree(); wt != nil && m.configManager != nil {
-				_ = m.configManager.SetLastSelectedBranch(m.repoPath, wt.Branch)
+			// Build detailed status message based on what was pulled
+			statusMsg := buildRefreshStatusMessage(msg)
+
+			// If there was an error pulling the main repo branch, append it to the message
+			if msg.pullErr != nil {
+				statusMsg += " (pull error: " + msg.pullErr.Error() + ")"
+				cmd = m.showWarningNotification(statusMsg)
+			} else {
+				cmd = m.showSuccessNotification(statusMsg, 3*time.Second)
 			}
-		}
 
-	case "down":
-		if m.selectedIndex < len(m.worktrees)-1 {
-			m.selectedIndex++
-			// Save the last selected branch
-			if wt := m.selectedWorktree(); wt != nil && m.configManager != nil {
-				_ = m.configManager.SetLastSelectedBranch(m.repoPath, wt.Branch)
+			// Only show notification if not initializing (suppress during startup)
+			if !m.isInitializing {
+				cmd = m.showSuccessNotification(buildRefreshStatusMessage(msg), 3*time.Second)
 			}
-		}
 
-	case "r":
-		// Refresh: pull latest commits, refresh PR statuses, and load PR details for all worktrees
-		cmd = m.showInfoNotification("Pulling latest commits and refreshing...")
-		return m, tea.Batch(cmd, m.refreshWithPull(), m.refreshPRStatuses(), m.loadPRDetailsForAllWorktrees(), m.checkSessionActivity())
+			// Auto-stashed changes couldn't be re-applied cleanly in some worktrees
+			if len(msg.stashConflicts) > 0 {
+				statusMsg += fmt.Sprintf(" (auto-stash conflicted in %s; changes kept in stash, press 'z' to manage)", strings.Join(msg.stashConflicts, ", "))
+				cmd = m.showWarningNotification(statusMsg)
+			}
 
-	case "n":
-		// Open create with custom name modal
-		m.modal = createWithNameModal
-		m.sessionNameInput.SetValue("")  // Start with empty input
-		m.sessionNameInput.Focus()       // Focus the input field
-		m.modalFocused = 0               // Focus on input field
-		return m, nil
+			// A pull left a worktree with conflicts: let the user resolve them right away
+			if msg.conflictPath != "" && m.modal == noModal {
+				cmd = m.showWarningNotification(statusMsg)
+				m.conflictFromLocalMerge = false
+				return m, tea.Batch(
+					cmd,
+					m.openConflictModal(msg.conflictPath),
+					m.loadWorktrees(),
+				)
+			}
 
-	case "b":
-		// Open change base branch modal (b for base branch)
-		m.modal = changeBaseBranchModal
-		m.modalFocused = 0
-		m.branchIndex = 0
-		m.searchInput.SetValue("")
-		m.searchInput.Focus()
-		m.filteredBranches = nil
-		// Try to find current base branch in the list
-		return m, m.loadBranches
+			// Reload worktree list to show updated status
+			return m, tea.Batch(
+				cmd,
+				m.loadWorktrees(),
+			)
+		}
 
-	case "a":
-		// Open create from existing branch modal
-		m.modal = branchSelectModal
-		m.modalFocused = 0
-		m.createNewBranch = false
-		m.branchIndex = 0
-		m.searchInput.SetValue("")
-		m.searchInput.Focus()
-		m.filteredBranches = nil
-		return m, m.loadBranches
+	case prStatusesRefreshedMsg:
+		if msg.err != nil {
+			// Silently handle PR status refresh errors
+			return m, m.loadWorktrees()
+		}
+		// Reload worktrees to show updated PR statuses
+		return m, m.loadWorktrees()
 
-	case "d":
-		// Open delete modal
-		if wt := m.selectedWorktree(); wt != nil && !wt.IsCurrent {
-			// Check for uncommitted changes
-			hasUncommitted, err := m.gitManager.HasUncommittedChanges(wt.Path)
-			if err != nil {
-				cmd = m.showWarningNotification("Failed to check for uncommitted changes")
-				return m, cmd
-			}
-			m.deleteHasUncommitted = hasUncommitted
-			m.deleteConfirmForce = false
-			m.modal = deleteModal
-			m.modalFocused = 0
-			return m, nil
-		} else if wt != nil && wt.IsCurrent {
-			return m, m.showWarningNotification("Cannot delete current worktree")
+	case autoFetchTickMsg:
+		if !m.shouldAutoFetch() {
+			return m, m.scheduleAutoFetch()
 		}
+		m.autoFetchInFlight = true
+		m.debugLog("autoFetch: starting background fetch")
+		return m, m.autoFetch()
 
-	case "enter":
-		// Switch to selected worktree with Claude
-		if wt := m.selectedWorktree(); wt != nil {
-			// Save the last selected branch before switching
-			if m.configManager != nil {
-				_ = m.configManager.SetLastSelectedBranch(m.repoPath, wt.Branch)
-			}
-			// Check if this Claude session has been initialized before
-			isInitialized := false
-			if m.configManager != nil {
-				isInitialized = m.configManager.IsClaudeInitialized(m.repoPath, wt.Branch)
-				// Mark this branch as initialized for next time
-				// (so next run will use --continue instead of plain claude)
-				if m.autoClaude && !isInitialized {
-					_ = m.configManager.SetClaudeInitialized(m.repoPath, wt.Branch)
-				}
+	case autoFetchCompletedMsg:
+		m.autoFetchInFlight = false
+		if msg.err != nil {
+			// Probably offline or the remote is unreachable: back off exponentially
+			if m.fetchBackoff == 0 {
+				m.fetchBackoff = m.getAutoFetchInterval()
+			} else {
+				m.fetchBackoff *= 2
 			}
-			// Store pending switch info and ensure worktree exists
-			// SessionName includes repo basename for uniqueness across repositories (e.g., jean-reponame-branch)
-			m.pendingSwitchInfo = &SwitchInfo{
-				Path:                 wt.Path,
-				Branch:               wt.Branch,
-				SessionName:          wt.ClaudeSessionName, // Pre-sanitized session name with repo basename
-				AutoClaude:           m.autoClaude,
-				TargetWindow:         "claude", // Attach to Claude window
-				IsClaudeInitialized:  isInitialized,
+			if m.fetchBackoff > maxFetchBackoff {
+				m.fetchBackoff = maxFetchBackoff
 			}
-			m.ensuringWorktree = true
-			cmd = m.showInfoNotification("Preparing workspace...")
-			return m, tea.Batch(cmd, m.ensureWorktreeExists(wt.Path, wt.Branch))
+			m.debugLog(fmt.Sprintf("autoFetch: failed (next attempt delayed by %s): %v", m.fetchBackoff, msg.err))
+			return m, m.scheduleAutoFetch()
 		}
 
-	case "B":
-		// Rename current branch (Shift+B)
-		if wt := m.selectedWorktree(); wt != nil {
-			// Check if this is a workspace worktree (in .workspaces directory)
-			if !strings.Contains(wt.Path, ".workspaces") {
-				return m, m.showWarningNotification("Cannot rename main branch. Only workspace branches can be renamed.")
-			}
+		m.lastFetchTime = time.Now()
+		m.fetchBackoff = 0
 
-			// Check if this branch has PRs
-			if m.configManager != nil && m.configManager.HasPRs(m.repoPath, wt.Branch) {
-				return m, m.showErrorNotification("Cannot rename branch with existing PRs. Delete PRs first or close them manually.", 5*time.Second)
+		// Quietly refresh ahead/behind counts
+		for i := range m.worktrees {
+			if status, ok := msg.statuses[m.worktrees[i].Branch]; ok {
+				m.worktrees[i].AheadCount = status.Ahead
+				m.worktrees[i].BehindCount = status.Behind
+				m.worktrees[i].IsOutdated = status.Behind > 0
 			}
+		}
+		return m, m.scheduleAutoFetch()
 
-			m.modal = renameModal
-			m.modalFocused = 0
-			m.nameInput.SetValue(wt.Branch)
-			m.nameInput.Focus()
-			m.nameInput.CursorEnd()
-			return m, nil
+	case activityTickMsg:
+		// Check if enough time has passed since last activity check
+		if time.Since(m.lastActivityCheck) >= m.activityCheckInterval {
+			m.lastActivityCheck = time.Now()
+			// Services are refreshed along with the sessions to notice ones that exited
+			return m, tea.Batch(m.checkSessionActivity(), m.loadServices())
 		}
+		return m, m.scheduleActivityCheck()
 
-	case "K":
-		// Checkout/switch branch in main repository (Shift+K for checkout)
-		m.modal = checkoutBranchModal
-		m.modalFocused = 0
-		m.branchIndex = 0
-		m.searchInput.SetValue("")
-		m.searchInput.Focus()
-		m.filteredBranches = nil
-		return m, m.loadBranches
-
-	case "t":
-		// Open terminal in the terminal window of the session
-		m.debugLog("DEBUG: 't' keybinding pressed, TargetWindow=terminal")
-		if wt := m.selectedWorktree(); wt != nil {
-			// Save the last selected branch before switching
-			if m.configManager != nil {
-				_ = m.configManager.SetLastSelectedBranch(m.repoPath, wt.Branch)
-			}
-			// Store pending switch info and ensure worktree exists
-			m.pendingSwitchInfo = &SwitchInfo{
-				Path:         wt.Path,
-				Branch:       wt.Branch,
-				SessionName:  wt.ClaudeSessionName,     // Pre-sanitized session name with repo basename
-				AutoClaude:   false,                    // Never auto-start Claude for terminal window
-				TargetWindow: "terminal",               // Attach to terminal window
-			}
-			m.ensuringWorktree = true
-			m.debugLog("DEBUG: ensuring worktree exists before opening terminal")
-			cmd = m.showInfoNotification("Preparing workspace...")
-			return m, tea.Batch(cmd, m.ensureWorktreeExists(wt.Path, wt.Branch))
+	case activityCheckedMsg:
+		if msg.err == nil {
+			// Update sessions with activity information
+			m.sessions = msg.sessions
 		}
-		m.debugLog("DEBUG: 't' pressed but no worktree selected")
+		// Continue scheduling activity checks
+		cmd = m.scheduleActivityCheck()
+		return m, cmd
 
-	case "o":
-		// Open worktree in default IDE
-		if wt := m.selectedWorktree(); wt != nil {
-			cmd = m.showInfoNotification("Opening in editor...")
-			return m, tea.Batch(cmd, m.openInEditor(wt.Path))
+	case versionCheckMsg:
+		// Silently handle errors (don't show error notification for version check failures)
+		if msg.err != nil {
+			return m, nil
 		}
 
-	case "e":
-		// Open editor selection modal
-		m.modal = editorSelectModal
-		m.modalFocused = 0
-		m.editorIndex = 0
+		// Show notification only if update is available
+		if msg.updateAvailable {
+			notifMsg := fmt.Sprintf("Update available: %s → %s. Run: jean update", msg.currentVersion, msg.latestVersion)
+			cmd = m.showInfoNotification(notifMsg)
+			return m, cmd
+		}
+		return m, nil
 
-		// Find current editor in the list
-		if m.configManager != nil {
-			currentEditor := m.configManager.GetEditor(m.repoPath)
-			for i, editor := range m.editors {
-				if editor == currentEditor {
-					m.editorIndex = i
-					break
+	case commitMessageGeneratedMsg:
+		m.generatingCommit = false // Stop spinner animation
+		if msg.err != nil {
+			// If auto-committing with AI, show error and abort
+			if m.autoCommitWithAI {
+				m.autoCommitWithAI = false
+				cmd := m.showErrorNotification("🤖 Failed to generate commit message: " + msg.err.Error(), 4*time.Second)
+				return m, cmd
+			}
+			// If in PR creation flow, show error and abort
+			if m.commitBeforePR {
+				m.commitBeforePR = false
+				cmd := m.showErrorNotification("🤖 Failed to generate commit message: " + msg.err.Error(), 4*time.Second)
+				return m, cmd
+			}
+			// Set status message in commit modal
+			m.commitModalStatus = "❌ Error: " + msg.err.Error()
+			m.commitModalStatusTime = time.Now()
+			return m, nil
+		} else {
+			// If auto-committing with AI, commit immediately without PR flow
+			if m.autoCommitWithAI {
+				m.autoCommitWithAI = false
+				if wt := m.selectedWorktree(); wt != nil {
+					return m, m.createCommit(wt.Path, msg.subject)
 				}
+				return m, nil
+			}
+			// If in PR creation flow, auto-commit with generated message
+			if m.commitBeforePR {
+				cmd := m.showInfoNotification("🤖 Committing with AI-generated message...")
+				return m, tea.Batch(cmd, m.createCommit(m.prCreationPending, msg.subject))
 			}
+			// Otherwise populate the commit message fields with AI-generated content for user review
+			m.commitSubjectInput.SetValue(msg.subject)
+			// Set success status message
+			m.commitModalStatus = "✅ Message generated successfully - review and edit if needed"
+			m.commitModalStatusTime = time.Now()
+			// Move focus to subject input so user can review/edit
+			m.modalFocused = 0
+			m.commitSubjectInput.Focus()
+			return m, nil
 		}
-		return m, nil
 
-	case "s":
-		// Open settings modal
-		m.modal = settingsModal
-		m.modalFocused = 0
-		m.settingsIndex = 0
+	case apiKeyTestedMsg:
+		if msg.err != nil {
+			// Set status message in AI settings modal
+			m.aiModalStatus = "❌ Test failed: " + msg.err.Error()
+			m.aiModalStatusTime = time.Now()
+			return m, nil
+		} else {
+			// Set success status message
+			m.aiModalStatus = "✅ API key is valid and working!"
+			m.aiModalStatusTime = time.Now()
+			return m, nil
+		}
+
+	case aiPromptsLoadedMsg:
+		// Load prompts into the modal inputs
+		if msg.err != nil {
+			m.aiPromptsStatus = "Error loading prompts: " + msg.err.Error()
+			return m, nil
+		}
+		m.aiPromptCommitInput.SetValue(msg.commitPrompt)
+		m.aiPromptBranchInput.SetValue(msg.branchPrompt)
+		m.aiPromptPRInput.SetValue(msg.prPrompt)
+		m.aiPromptsStatus = ""
 		return m, nil
 
-	case "u":
-		// Update from base branch (pull/merge base branch changes)
-		if wt := m.selectedWorktree(); wt != nil {
-			// Check if base branch is set
-			if m.baseBranch == "" {
-				return m, m.showWarningNotification("Base branch not set. Press 'b' to set base branch")
-			}
+	case aiPromptsSavedMsg:
+		if msg.err != nil {
+			m.aiPromptsStatus = "❌ Failed to save: " + msg.err.Error()
+			m.aiPromptsStatusTime = time.Now()
+			return m, nil
+		}
+		// Success - close modal and return to AI settings
+		cmd := m.showSuccessNotification("AI prompts saved successfully", 2*time.Second)
+		m.modal = aiSettingsModal
+		m.aiModalFocusedField = 0
+		m.aiPromptCommitInput.Blur()
+		m.aiPromptBranchInput.Blur()
+		m.aiPromptPRInput.Blur()
+		return m, cmd
 
-			// Don't allow pull on main worktree
-			if !strings.Contains(wt.Path, ".workspaces") {
-				return m, m.showWarningNotification("Cannot pull on main worktree. Use 'git pull' manually.")
-			}
+	case aiPromptsResetMsg:
+		if msg.err != nil {
+			m.aiPromptsStatus = "❌ Failed to reset: " + msg.err.Error()
+			m.aiPromptsStatusTime = time.Now()
+			return m, nil
+		}
+		// Reload the default prompts to display them
+		cmd := m.loadAIPrompts()
+		m.aiPromptsStatus = "✅ Prompts reset to defaults"
+		m.aiPromptsStatusTime = time.Now()
+		return m, cmd
 
-			// Fetch and check for updates (don't rely on cached status)
-			cmd = m.showInfoNotification("Checking for updates...")
-			return m, tea.Batch(cmd, m.checkAndPullFromBase(wt.Path, m.baseBranch))
+	case prFetchedForCreationMsg:
+		// Handle fetch completion before PR creation
+		m.prFetchingForCreation = false
+		if msg.err != nil {
+			return m, m.showErrorNotification("Failed to fetch from remote: "+msg.err.Error(), 3*time.Second)
 		}
 
-	case "p":
-		// Push branch to remote (with AI branch naming) - lowercase p
+		// Fetch successful, now proceed with PR creation flow
 		if wt := m.selectedWorktree(); wt != nil {
-			// First check if there are uncommitted changes
+			// Check if there are uncommitted changes
 			hasUncommitted, err := m.gitManager.HasUncommittedChanges(wt.Path)
 			if err != nil {
 				return m, m.showErrorNotification("Failed to check for uncommitted changes: "+err.Error(), 3*time.Second)
@@ -1657,15 +1810,15 @@ func (m Model) handleMainInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			if hasUncommitted {
 				if hasAI && aiContentEnabled {
 					// AI is enabled for commit messages - generate commit message with AI first
-					cmd = m.showInfoNotification("🤖 Generating commit message...")
-					m.commitBeforePR = true // Reuse this flag to track commit-before-push
-					m.prCreationPending = "" // Empty means push-only (no PR)
+					cmd := m.showInfoNotification("🤖 Generating conventional commit message...")
+					m.commitBeforePR = true
+					m.prCreationPending = wt.Path // Set to trigger PR creation after commit
 					return m, tea.Batch(cmd, m.generateCommitMessageWithAI(wt.Path))
 				} else if hasAI {
 					// AI is enabled for branch but not commit - auto-commit with simple message and proceed
-					cmd = m.showInfoNotification("Committing changes...")
+					cmd := m.showInfoNotification("Committing changes...")
 					m.commitBeforePR = true
-					m.prCreationPending = "" // Empty means push-only
+					m.prCreationPending = wt.Path // Set to trigger PR creation after commit
 					return m, tea.Batch(cmd, m.autoCommitBeforePR(wt.Path, wt.Branch))
 				} else {
 					// No AI - show commit modal for user to write proper commit message
@@ -1674,2027 +1827,3874 @@ func (m Model) handleMainInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 					m.commitSubjectInput.SetValue("")
 					m.commitSubjectInput.Focus()
 							m.commitBeforePR = true
-					m.prCreationPending = "" // Empty means push-only
+					m.prCreationPending = wt.Path // Set to trigger PR creation after commit
 					return m, nil
 				}
 			}
 
-			// No uncommitted changes - proceed to push
+			// No uncommitted changes - proceed to PR creation
 			// Check if we should do AI renaming first
 			isRandomName := m.gitManager.IsRandomBranchName(wt.Branch)
 			shouldAIRename := hasAPIKey && aiEnabled && isRandomName
 
 			if shouldAIRename {
-				// Start AI rename flow before push
-				cmd = m.showInfoNotification("🤖 Generating semantic branch name...")
+				// Start AI rename flow before PR creation
+				cmd := m.showInfoNotification("🤖 Generating semantic branch name...")
+				m.prCreationPending = wt.Path // Set to trigger PR creation after rename
 				return m, tea.Batch(cmd, m.generateBranchNameForPush(wt.Path, wt.Branch, m.baseBranch))
 			} else {
-				// Normal push (no AI)
-				cmd = m.showInfoNotification("Pushing to remote...")
-				return m, tea.Batch(cmd, m.pushBranch(wt.Path, wt.Branch))
-			}
-		}
+				// No AI rename needed - use default PR state from config
+				prState := m.configManager.GetPRDefaultState(m.repoPath)
+				m.prIsDraft = (prState == "draft")
+				m.prModalWorktreePath = wt.Path
+				m.prModalBranch = wt.Branch
 
-	case "P":
-		// Create new PR on GitHub (Shift+P)
-		if wt := m.selectedWorktree(); wt != nil {
-			// Check if a PR already exists for this branch
-			if m.configManager != nil {
-				existingPR := m.configManager.GetLatestPR(m.repoPath, wt.Branch)
-				if existingPR != nil && existingPR.Status == "open" {
-					m.debugLog(fmt.Sprintf("P keybinding: found existing PR #%d for branch %s, opening in browser", existingPR.PRNumber, wt.Branch))
-					// Open the existing PR in the browser
-					cmd := exec.Command("gh", "pr", "view", existingPR.URL, "--web")
-					err := cmd.Start()
-					if err != nil {
-						return m, m.showErrorNotification("Failed to open PR in browser: "+err.Error(), 3*time.Second)
-					}
-					notifyMsg := fmt.Sprintf("PR #%d already exists for this branch. Opening in browser...", existingPR.PRNumber)
-					return m, m.showInfoNotification(notifyMsg)
+				// Check if AI is enabled for PR content generation
+				aiEnabled := m.configManager != nil &&
+					m.configManager.GetAnthropicAPIKey() != "" &&
+					m.aiCommitEnabled
+
+				if aiEnabled {
+					// Generate PR content with AI
+					return m, m.generatePRContent(m.prModalWorktreePath, m.prModalBranch, m.baseBranch)
 				}
+
+				// No AI - open PR content modal for manual input
+				m.modal = prContentModal
+				m.prModalFocused = 0
+				m.prTitleInput.Focus()
+				m.prDescriptionInput.Blur()
+				return m, nil
 			}
+		}
 
-			// No existing PR - create new one
-			// First fetch from remote to get latest changes
-			cmd = m.showInfoNotification("Fetching latest changes...")
-			m.prFetchingForCreation = true // Flag to indicate fetch is for PR creation
-			return m, tea.Batch(cmd, m.fetchRemoteForPR(wt.Path))
+	case spinnerTickMsg:
+		// Update spinner animation frame and schedule next tick if still generating
+		if m.generatingCommit {
+			m.spinnerFrame = (m.spinnerFrame + 1) % 10
+			return m, m.animateSpinner()
+		}
+		if m.generatingPRContent {
+			m.prSpinnerFrame = (m.prSpinnerFrame + 1) % 10
+			return m, m.animateSpinner()
 		}
+		// No more spinners, return no command
+		return m, nil
 
-	case "N":
-		// Create worktree from existing PR on GitHub (Shift+N)
-		m.debugLog("N keybinding pressed - opening PR list modal to create worktree from PR")
-		m.modal = prListModal
-		m.prListIndex = 0
-		m.prListCreationMode = true // Set creation mode flag
-		m.prSearchInput.SetValue("")
-		m.prSearchInput.Focus()
-		m.filteredPRs = nil
-		m.prLoadingError = ""
-		m.debugLog("PR list modal state: prListCreationMode=true, repoPath=" + m.repoPath)
-		return m, m.loadPRs()
+	case renameGeneratedMsg:
+		// Stop spinner and handle rename generation result
+		m.generatingRename = false
+		if msg.err != nil {
+			// Set error status message
+			m.renameModalStatus = "❌ Error: " + msg.err.Error()
+			m.renameModalStatusTime = time.Now()
+		} else {
+			// Populate the name input with AI-generated branch name
+			m.nameInput.SetValue(msg.name)
+			m.nameInput.CursorEnd()
+			m.renameModalStatus = "✅ Generated from changes"
+			m.renameModalStatusTime = time.Now()
+		}
+		return m, nil
 
-	case "L":
-		// Local merge: merge worktree branch into base branch locally (Shift+L)
+	case renameSpinnerTickMsg:
+		// Update spinner animation frame and schedule next tick if still generating
+		if m.generatingRename {
+			m.renameSpinnerFrame = (m.renameSpinnerFrame + 1) % 10
+			return m, m.animateRenameSpinner()
+		}
+		return m, nil
+
+	case worktreeEnsuredMsg:
+		m.ensuringWorktree = false
+		if msg.err != nil {
+			cmd = m.showErrorNotification(fmt.Sprintf("Failed to ensure worktree exists: %v", msg.err), 4*time.Second)
+			m.pendingSwitchInfo = nil
+			return m, cmd
+		}
+		// Worktree is now ensured to exist, proceed with switch
+		if m.pendingSwitchInfo != nil {
+			m.switchInfo = *m.pendingSwitchInfo
+			if msg.hookErr != nil {
+				// Post hooks don't block the switch; jean prints the failure on exit
+				m.switchInfo.Warning = msg.hookErr.Error()
+			} else if msg.setupErr != nil {
+				m.switchInfo.Warning = "worktree re-created but setup couldn't start: " + msg.setupErr.Error()
+			}
+			m.pendingSwitchInfo = nil
+			return m, tea.Quit
+		}
+
+	case prMarkedReadyMsg:
+		// PR has been marked as ready for review
+		if msg.err != nil {
+			cmd = m.showErrorNotification("Failed to mark PR as ready: "+msg.err.Error(), 4*time.Second)
+			return m, cmd
+		}
+
+		// Now proceed with merge - get the stored merge method from merge strategy modal
+		wt := m.selectedWorktree()
+		if wt == nil {
+			return m, m.showErrorNotification("No worktree selected", 3*time.Second)
+		}
+
+		// Get the PR info to find merge method (we need to reopen the modal to get this info)
+		// For now, show success and reload
+		prs, ok := wt.PRs.([]config.PRInfo)
+		if !ok || len(prs) == 0 {
+			cmd = m.showSuccessNotification("PR marked as ready for review", 2*time.Second)
+			return m, tea.Batch(cmd, m.loadWorktrees())
+		}
+
+		// Find the PR that was just marked ready
+		for _, pr := range prs {
+			if pr.URL == msg.prURL {
+				// PR found - now we need to merge it
+				// For now, show a notification and reload
+				cmd = m.showSuccessNotification("PR marked as ready. Please press M again to merge.", 3*time.Second)
+				return m, tea.Batch(cmd, m.loadWorktrees())
+			}
+		}
+
+		cmd = m.showSuccessNotification("PR marked as ready for review", 2*time.Second)
+		return m, tea.Batch(cmd, m.loadWorktrees())
+
+	case prMergedMsg:
+		// PR has been merged
+		if msg.err != nil {
+			cmd = m.showErrorNotification("Failed to merge PR: "+msg.err.Error(), 4*time.Second)
+			return m, cmd
+		}
+
+		// Mark PR as merged in config
+		if m.configManager != nil && msg.branch != "" {
+			_ = m.configManager.UpdatePRStatus(m.repoPath, msg.branch, msg.prURL, "merged")
+		}
+
+		// Show success and reload worktrees
+		cmd = m.showSuccessNotification("PR merged successfully!", 3*time.Second)
+		return m, tea.Batch(cmd, m.loadWorktrees())
+	}
+
+	return m, cmd
+}
+
+func (m Model) handleMainInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg.String() {
+	case "q", "ctrl+c":
+		// Clear switch info to prevent shell wrapper from switching directories
+		m.switchInfo = SwitchInfo{}
+		return m, tea.Quit
+
+	case "up":
+		if m.selectedIndex > 0 {
+			m.selectedIndex--
+			// Save the last selected branch
+			if wt := m.selectedWorktree(); wt != nil && m.configManager != nil {
+				_ = m.configManager.SetLastSelectedBranch(m.repoPath, wt.Branch)
+			}
+		}
+
+	case "down":
+		if m.selectedIndex < len(m.worktrees)-1 {
+			m.selectedIndex++
+			// Save the last selected branch
+			if wt := m.selectedWorktree(); wt != nil && m.configManager != nil {
+				_ = m.configManager.SetLastSelectedBranch(m.repoPath, wt.Branch)
+			}
+		}
+
+	case "r":
+		// Refresh: pull latest commits, refresh PR statuses, and load PR details for all worktrees
+		cmd = m.showInfoNotification("Pulling latest commits and refreshing...")
+		return m, tea.Batch(cmd, m.refreshWithPull(), m.refreshPRStatuses(), m.loadPRDetailsForAllWorktrees(), m.checkSessionActivity())
+
+	case "n":
+		// Open create with custom name modal
+		m.modal = createWithNameModal
+		m.namingRules = m.loadNamingRules()
+		m.sessionNameInput.SetValue("")  // Start with empty input
+		m.sessionNameInput.Focus()       // Focus the input field
+		m.modalFocused = 0               // Focus on input field
+		return m, nil
+
+	case "b":
+		// Open change base branch modal (b for base branch)
+		m.modal = changeBaseBranchModal
+		m.modalFocused = 0
+		m.branchIndex = 0
+		m.searchInput.SetValue("")
+		m.searchInput.Focus()
+		m.filteredBranches = nil
+		// Try to find current base branch in the list
+		return m, m.loadBranches
+
+	case "a":
+		// Open create from existing branch modal
+		m.modal = branchSelectModal
+		m.modalFocused = 0
+		m.createNewBranch = false
+		m.branchIndex = 0
+		m.searchInput.SetValue("")
+		m.searchInput.Focus()
+		m.filteredBranches = nil
+		// List cached branches right away and refresh once all remotes (e.g. upstream) are fetched
+		return m, tea.Batch(m.loadBranches, m.fetchAllRemotes())
+
+	case "d":
+		// Open delete modal
+		if wt := m.selectedWorktree(); wt != nil && !wt.IsCurrent {
+			// Check for uncommitted changes
+			hasUncommitted, err := m.gitManager.HasUncommittedChanges(wt.Path)
+			if err != nil {
+				cmd = m.showWarningNotification("Failed to check for uncommitted changes")
+				return m, cmd
+			}
+			m.deleteHasUncommitted = hasUncommitted
+			m.deleteConfirmForce = false
+
+			// Count commits that would be lost with the branch (protected branches are never deleted)
+			m.deleteAtRisk = 0
+			m.deleteAtRiskErr = nil
+			m.deleteBranchProtected = m.gitManager.IsProtectedBranch(wt.Branch)
+			if !m.deleteBranchProtected && m.gitManager.DeletionPolicy() == config.DeletionPolicySafe {
+				m.deleteAtRisk, m.deleteAtRiskErr = m.gitManager.CountAtRiskCommits(wt.Branch, m.baseBranch)
+			}
+			m.modal = deleteModal
+			m.modalFocused = 0
+			return m, nil
+		} else if wt != nil && wt.IsCurrent {
+			return m, m.showWarningNotification("Cannot delete current worktree")
+		}
+
+	case "enter":
+		// Switch to selected worktree with Claude
 		if wt := m.selectedWorktree(); wt != nil {
-			// Safety check: base branch must be set
-			if m.baseBranch == "" {
-				return m, m.showWarningNotification("Base branch not set. Press 'b' to set base branch")
+			// Don't silently re-create a worktree whose directory was deleted
+			if wt.IsMissing() {
+				return m, tea.Batch(m.showWarningNotification("Worktree directory is missing. Re-add or prune it."), m.openRepairModal(wt.Path))
+			}
+			// Save the last selected branch before switching
+			if m.configManager != nil {
+				_ = m.configManager.SetLastSelectedBranch(m.repoPath, wt.Branch)
+			}
+			// Check if this Claude session has been initialized before
+			isInitialized := false
+			if m.configManager != nil {
+				isInitialized = m.configManager.IsClaudeInitialized(m.repoPath, wt.Branch)
+				// Mark this branch as initialized for next time
+				// (so next run will use --continue instead of plain claude)
+				if m.autoClaude && !isInitialized {
+					_ = m.configManager.SetClaudeInitialized(m.repoPath, wt.Branch)
+				}
+			}
+			// Store pending switch info and ensure worktree exists
+			// SessionName includes repo basename for uniqueness across repositories (e.g., jean-reponame-branch)
+			m.pendingSwitchInfo = &SwitchInfo{
+				Path:                 wt.Path,
+				Branch:               wt.Branch,
+				SessionName:          wt.ClaudeSessionName, // Pre-sanitized session name with repo basename
+				AutoClaude:           m.autoClaude,
+				TargetWindow:         "claude", // Attach to Claude window
+				IsClaudeInitialized:  isInitialized,
 			}
+			m.ensuringWorktree = true
+			cmd = m.showInfoNotification("Preparing workspace...")
+			return m, tea.Batch(cmd, m.ensureWorktreeExists(wt.Path, wt.Branch, m.pendingSwitchInfo.TargetWindow))
+		}
 
-			// Safety check: only allow merge from workspace worktrees (not main repo)
-			if !strings.Contains(wt.Path, ".workspaces") {
-				return m, m.showWarningNotification("Can only merge workspace worktrees. Use 'git merge' manually in main repo.")
+	case "m":
+		// Move (relocate) the selected worktree to another directory
+		if wt := m.selectedWorktree(); wt != nil {
+			if wt.IsMain {
+				return m, m.showWarningNotification("Cannot move the main repository")
+			}
+			if wt.IsCurrent {
+				return m, m.showWarningNotification("Cannot move the worktree jean is running in")
+			}
+			if wt.IsMissing() {
+				return m, m.openRepairModal(wt.Path)
+			}
+			if wt.IsLocked {
+				return m, m.showWarningNotification("Worktree is locked. Unlock it with 'git worktree unlock' first.")
+			}
+
+			// Suggest the location from the path template
+			suggested := wt.Path
+			if path, err := m.gitManager.GetDefaultPath(wt.Branch); err == nil && path != wt.Path {
+				suggested = path
+			}
+			m.modal = relocateModal
+			m.pathInput.SetValue(suggested)
+			m.pathInput.CursorEnd()
+			m.pathInput.Focus()
+			return m, nil
+		}
+
+	case "B":
+		// Rename current branch (Shift+B)
+		if wt := m.selectedWorktree(); wt != nil {
+			// Only worktrees created by jean can be renamed, not the main repository
+			if wt.IsMain {
+				return m, m.showWarningNotification("Cannot rename main branch. Only workspace branches can be renamed.")
+			}
+
+			// Check if this branch has PRs
+			if m.configManager != nil && m.configManager.HasPRs(m.repoPath, wt.Branch) {
+				return m, m.showErrorNotification("Cannot rename branch with existing PRs. Delete PRs first or close them manually.", 5*time.Second)
 			}
 
-			// Safety check: cannot merge base branch into itself
-			if wt.Branch == m.baseBranch {
-				return m, m.showWarningNotification("Cannot merge base branch into itself")
-			}
+			m.modal = renameModal
+			m.namingRules = m.loadNamingRules()
+			m.modalFocused = 0
+			m.nameInput.SetValue(wt.Branch)
+			m.nameInput.Focus()
+			m.nameInput.CursorEnd()
+			return m, nil
+		}
+
+	case "K":
+		// Checkout/switch branch in main repository (Shift+K for checkout)
+		m.modal = checkoutBranchModal
+		m.modalFocused = 0
+		m.branchIndex = 0
+		m.searchInput.SetValue("")
+		m.searchInput.Focus()
+		m.filteredBranches = nil
+		return m, m.loadBranches
+
+	case "t":
+		// Open terminal in the terminal window of the session
+		m.debugLog("DEBUG: 't' keybinding pressed, TargetWindow=terminal")
+		if wt := m.selectedWorktree(); wt != nil {
+			if wt.IsMissing() {
+				return m, tea.Batch(m.showWarningNotification("Worktree directory is missing. Re-add or prune it."), m.openRepairModal(wt.Path))
+			}
+			// Save the last selected branch before switching
+			if m.configManager != nil {
+				_ = m.configManager.SetLastSelectedBranch(m.repoPath, wt.Branch)
+			}
+			// Store pending switch info and ensure worktree exists
+			m.pendingSwitchInfo = &SwitchInfo{
+				Path:         wt.Path,
+				Branch:       wt.Branch,
+				SessionName:  wt.ClaudeSessionName,     // Pre-sanitized session name with repo basename
+				AutoClaude:   false,                    // Never auto-start Claude for terminal window
+				TargetWindow: "terminal",               // Attach to terminal window
+			}
+			m.ensuringWorktree = true
+			m.debugLog("DEBUG: ensuring worktree exists before opening terminal")
+			cmd = m.showInfoNotification("Preparing workspace...")
+			return m, tea.Batch(cmd, m.ensureWorktreeExists(wt.Path, wt.Branch, m.pendingSwitchInfo.TargetWindow))
+		}
+		m.debugLog("DEBUG: 't' pressed but no worktree selected")
+
+	case "o":
+		// Open worktree in default IDE
+		if wt := m.selectedWorktree(); wt != nil {
+			cmd = m.showInfoNotification("Opening in editor...")
+			return m, tea.Batch(cmd, m.openInEditor(wt.Path))
+		}
+
+	case "e":
+		// Open editor selection modal
+		m.modal = editorSelectModal
+		m.modalFocused = 0
+		m.editorIndex = 0
+
+		// Find current editor in the list
+		if m.configManager != nil {
+			currentEditor := m.configManager.GetEditor(m.repoPath)
+			for i, editor := range m.editors {
+				if editor == currentEditor {
+					m.editorIndex = i
+					break
+				}
+			}
+		}
+		return m, nil
+
+	case "s":
+		// Open settings modal
+		m.modal = settingsModal
+		m.modalFocused = 0
+		m.settingsIndex = 0
+		return m, nil
+
+	case "u":
+		// Update from base branch (pull/merge base branch changes)
+		if wt := m.selectedWorktree(); wt != nil {
+			// Check if base branch is set
+			if m.baseBranch == "" {
+				return m, m.showWarningNotification("Base branch not set. Press 'b' to set base branch")
+			}
+
+			// A previous update stopped on conflicts: resume resolving them
+			if m.gitManager.GetInProgressOperation(wt.Path) != "" {
+				m.conflictFromLocalMerge = false
+				return m, m.openConflictModal(wt.Path)
+			}
+
+			// Don't allow pull on main worktree
+			if wt.IsMain {
+				return m, m.showWarningNotification("Cannot pull on main worktree. Use 'git pull' manually.")
+			}
+
+			// Fetch and check for updates (don't rely on cached status)
+			cmd = m.showInfoNotification("Checking for updates...")
+			return m, tea.Batch(cmd, m.checkAndPullFromBase(wt.Path, m.baseBranch))
+		}
+
+	case "p":
+		// Push branch to remote (with AI branch naming) - lowercase p
+		if wt := m.selectedWorktree(); wt != nil {
+			// First check if there are uncommitted changes
+			hasUncommitted, err := m.gitManager.HasUncommittedChanges(wt.Path)
+			if err != nil {
+				return m, m.showErrorNotification("Failed to check for uncommitted changes: "+err.Error(), 3*time.Second)
+			}
+
+			// Check AI configuration
+			hasAPIKey := m.configManager != nil && m.configManager.GetAnthropicAPIKey() != ""
+			aiEnabled := m.configManager != nil && m.configManager.GetAIBranchNameEnabled()
+			aiContentEnabled := m.configManager != nil && m.configManager.GetAICommitEnabled()
+			hasAI := hasAPIKey && (aiEnabled || aiContentEnabled)
+
+			// If there are uncommitted changes, decide how to handle them
+			if hasUncommitted {
+				if hasAI && aiContentEnabled {
+					// AI is enabled for commit messages - generate commit message with AI first
+					cmd = m.showInfoNotification("🤖 Generating commit message...")
+					m.commitBeforePR = true // Reuse this flag to track commit-before-push
+					m.prCreationPending = "" // Empty means push-only (no PR)
+					return m, tea.Batch(cmd, m.generateCommitMessageWithAI(wt.Path))
+				} else if hasAI {
+					// AI is enabled for branch but not commit - auto-commit with simple message and proceed
+					cmd = m.showInfoNotification("Committing changes...")
+					m.commitBeforePR = true
+					m.prCreationPending = "" // Empty means push-only
+					return m, tea.Batch(cmd, m.autoCommitBeforePR(wt.Path, wt.Branch))
+				} else {
+					// No AI - show commit modal for user to write proper commit message
+					m.modal = commitModal
+					m.modalFocused = 0
+					m.commitSubjectInput.SetValue("")
+					m.commitSubjectInput.Focus()
+							m.commitBeforePR = true
+					m.prCreationPending = "" // Empty means push-only
+					return m, nil
+				}
+			}
+
+			// No uncommitted changes - proceed to push
+			// Check if we should do AI renaming first
+			isRandomName := m.gitManager.IsRandomBranchName(wt.Branch)
+			shouldAIRename := hasAPIKey && aiEnabled && isRandomName
+
+			if shouldAIRename {
+				// Start AI rename flow before push
+				cmd = m.showInfoNotification("🤖 Generating semantic branch name...")
+				return m, tea.Batch(cmd, m.generateBranchNameForPush(wt.Path, wt.Branch, m.baseBranch))
+			} else {
+				// Normal push (no AI)
+				cmd = m.showInfoNotification("Pushing to remote...")
+				return m, tea.Batch(cmd, m.pushBranch(wt.Path, wt.Branch))
+			}
+		}
+
+	case "P":
+		// Create new PR on GitHub (Shift+P)
+		if wt := m.selectedWorktree(); wt != nil {
+			// Check if a PR already exists for this branch
+			if m.configManager != nil {
+				existingPR := m.configManager.GetLatestPR(m.repoPath, wt.Branch)
+				if existingPR != nil && existingPR.Status == "open" {
+					m.debugLog(fmt.Sprintf("P keybinding: found existing PR #%d for branch %s, opening in browser", existingPR.PRNumber, wt.Branch))
+					// Open the existing PR in the browser
+					cmd := exec.Command("gh", "pr", "view", existingPR.URL, "--web")
+					err := cmd.Start()
+					if err != nil {
+						return m, m.showErrorNotification("Failed to open PR in browser: "+err.Error(), 3*time.Second)
+					}
+					notifyMsg := fmt.Sprintf("PR #%d already exists for this branch. Opening in browser...", existingPR.PRNumber)
+					return m, m.showInfoNotification(notifyMsg)
+				}
+			}
+
+			// No existing PR - create new one
+			// First fetch from remote to get latest changes
+			cmd = m.showInfoNotification("Fetching latest changes...")
+			m.prFetchingForCreation = true // Flag to indicate fetch is for PR creation
+			return m, tea.Batch(cmd, m.fetchRemoteForPR(wt.Path))
+		}
+
+	case "N":
+		// Create worktree from existing PR on GitHub (Shift+N)
+		m.debugLog("N keybinding pressed - opening PR list modal to create worktree from PR")
+		m.modal = prListModal
+		m.prListIndex = 0
+		m.prListCreationMode = true // Set creation mode flag
+		m.prSearchInput.SetValue("")
+		m.prSearchInput.Focus()
+		m.filteredPRs = nil
+		m.prLoadingError = ""
+		m.debugLog("PR list modal state: prListCreationMode=true, repoPath=" + m.repoPath)
+		return m, m.loadPRs()
+
+	case "L":
+		// Local merge: merge worktree branch into base branch locally (Shift+L)
+		if wt := m.selectedWorktree(); wt != nil {
+			// Safety check: base branch must be set
+			if m.baseBranch == "" {
+				return m, m.showWarningNotification("Base branch not set. Press 'b' to set base branch")
+			}
+
+			// A previous local merge stopped on conflicts in the main repo: resume resolving them
+			if repoRoot, err := m.gitManager.GetRepoRoot(); err == nil && m.gitManager.IsMergeInProgress(repoRoot) {
+				return m.resumeLocalMerge(wt, repoRoot, m.gitManager.MergingBranch(repoRoot))
+			}
+
+			// Safety check: only allow merge from workspace worktrees (not main repo)
+			if wt.IsMain {
+				return m, m.showWarningNotification("Can only merge workspace worktrees. Use 'git merge' manually in main repo.")
+			}
+
+			// Safety check: cannot merge base branch into itself
+			if wt.Branch == m.baseBranch {
+				return m, m.showWarningNotification("Cannot merge base branch into itself")
+			}
+
+			// Safety check: ensure no uncommitted changes
+			hasUncommitted, err := m.gitManager.HasUncommittedChanges(wt.Path)
+			if err != nil {
+				return m, m.showErrorNotification("Failed to check for uncommitted changes: "+err.Error(), 3*time.Second)
+			}
+			if hasUncommitted {
+				return m, m.showWarningNotification("Commit or stash changes before merging. Press 'c' to commit.")
+			}
+
+			// All checks passed - prepare merge (fetch and get status)
+			m.debugLog(fmt.Sprintf("L keybinding: preparing local merge of %s into %s", wt.Branch, m.baseBranch))
+			cmd = m.showInfoNotification("Preparing merge...")
+			return m, tea.Batch(cmd, m.prepareLocalMerge(wt.Path, wt.Branch, m.baseBranch))
+		}
+
+	case "c":
+		// Commit changes
+		if wt := m.selectedWorktree(); wt != nil {
+			// Check if worktree has uncommitted changes
+			hasUncommitted, err := m.gitManager.HasUncommittedChanges(wt.Path)
+			if err != nil {
+				return m, m.showErrorNotification("Failed to check for uncommitted changes: " + err.Error(), 3*time.Second)
+			}
+			if !hasUncommitted {
+				cmd = m.showInfoNotification("Nothing to commit - no uncommitted changes in " + wt.Branch)
+				return m, cmd
+			}
+
+			// Check if AI commit generation is enabled and API key is set
+			aiEnabled := m.configManager.GetAICommitEnabled()
+			apiKey := m.configManager.GetAnthropicAPIKey()
+
+			if aiEnabled && apiKey != "" {
+				// Auto-generate and auto-commit with AI (no modal shown)
+				m.generatingCommit = true
+				m.spinnerFrame = 0
+				m.autoCommitWithAI = true    // Flag for standalone auto-commit
+				notifyCmd := m.showInfoNotification("🤖 Generating commit message...")
+				return m, tea.Batch(
+					notifyCmd,
+					m.animateSpinner(),
+					m.generateCommitMessageWithAI(wt.Path),
+				)
+			} else {
+				// Manual commit mode - open modal for user to type message
+				m.modal = commitModal
+				m.modalFocused = 0
+				m.commitSubjectInput.SetValue("")
+				m.commitSubjectInput.Focus()
+					m.commitModalStatus = "" // Clear any previous status
+				return m, nil
+			}
+		}
+
+	case "v":
+		// Open PR in browser - if multiple PRs exist, show selection modal
+		if wt := m.selectedWorktree(); wt != nil {
+			if prs, ok := wt.PRs.([]config.PRInfo); ok && len(prs) > 0 {
+				if len(prs) == 1 {
+					// Only one PR - open it directly
+					m.debugLog(fmt.Sprintf("v keybinding: opening single PR %s", prs[0].URL))
+					cmd := exec.Command("gh", "pr", "view", prs[0].URL, "--web")
+					err := cmd.Start()
+					if err != nil {
+						return m, m.showErrorNotification("Failed to open PR in browser: "+err.Error(), 3*time.Second)
+					}
+					return m, m.showSuccessNotification("Opening PR in browser...", 2*time.Second)
+				} else {
+					// Multiple PRs - show selection modal for viewing
+					m.debugLog(fmt.Sprintf("v keybinding: opening PR selection modal with %d PRs", len(prs)))
+					m.modal = prListModal
+					m.prListCreationMode = false  // Ensure NOT creation mode
+					m.prListMergeMode = false     // Ensure NOT merge mode
+					m.prListViewMode = true       // SET view mode
+					m.prListIndex = len(prs) - 1  // Default to most recent
+					m.prSearchInput.SetValue("")
+					m.prSearchInput.Focus()
+					// Convert config.PRInfo to github.PRInfo for display
+					m.prs = make([]github.PRInfo, len(prs))
+					for i, pr := range prs {
+						m.debugLog(fmt.Sprintf("v keybinding: PR[%d] #%d %s - %s", i, pr.PRNumber, pr.Title, pr.URL))
+						// Convert config.PRInfo to github.PRInfo for modal display
+						m.prs[i] = github.PRInfo{
+							Number:      pr.PRNumber,
+							Title:       pr.Title, // Now we have the title from config
+							URL:         pr.URL,
+							HeadRefName: wt.Branch,
+							Status:      pr.Status,
+						}
+						// Set the author
+						m.prs[i].Author.Login = pr.Author
+					}
+					m.filteredPRs = m.prs // Populate filtered PRs with worktree PRs
+					m.prLoadingError = ""
+					return m, nil
+				}
+			} else {
+				return m, m.showInfoNotification("No PRs found for this worktree")
+			}
+		}
+
+	case "M":
+		// Merge PR (Shift+M)
+		if wt := m.selectedWorktree(); wt != nil {
+			if prs, ok := wt.PRs.([]config.PRInfo); ok && len(prs) > 0 {
+				if len(prs) == 1 {
+					// Only one PR - proceed to merge strategy selection
+					m.selectedPRForMerge = prs[0].URL
+					m.mergeStrategyCursor = 0 // Default to squash
+					m.modal = mergeStrategyModal
+					return m, nil
+				} else {
+					// Multiple PRs - show selection modal in merge mode
+					m.modal = prListModal
+					m.prListMergeMode = true
+					m.prListIndex = len(prs) - 1 // Default to most recent
+					return m, nil
+				}
+			} else {
+				return m, m.showErrorNotification("No PRs found for this worktree", 3*time.Second)
+			}
+		}
+
+	case "h":
+		// Open help modal
+		m.modal = helperModal
+		return m, nil
+
+	case "g":
+		// Open staging modal
+		if wt := m.selectedWorktree(); wt != nil {
+			m.modal = stagingModal
+			m.stagingIndex = 0
+			m.stagingFiles = nil
+			m.stagingDiffOpen = false
+			return m, m.loadStagingFiles()
+		}
+
+	case "z":
+		// Open stash manager
+		if wt := m.selectedWorktree(); wt != nil {
+			return m, m.openStashModal(wt)
+		}
+
+	case "D":
+		// Open diff viewer
+		if wt := m.selectedWorktree(); wt != nil {
+			return m, m.openDiffViewer(wt)
+		}
+
+	case "l":
+		// Open commit log
+		if wt := m.selectedWorktree(); wt != nil {
+			return m, m.openLogModal(wt)
+		}
+
+	case "R":
+		// Open repair screen for missing worktrees and orphaned directories
+		return m, m.openRepairModal("")
+
+	case "C":
+		// Open bulk cleanup of merged and abandoned worktrees
+		return m, m.openCleanupModal()
+
+	case "S":
+		// Show the output of the setup script, which runs in the background after creation
+		if wt := m.selectedWorktree(); wt != nil {
+			scriptConfig, err := config.LoadScripts(m.repoPath)
+			if err != nil {
+				return m, m.showErrorNotification("Failed to load jean.json: "+err.Error(), 4*time.Second)
+			}
+			if scriptConfig.GetScript(git.SetupScript) == "" {
+				return m, m.showWarningNotification("No setup script in jean.json")
+			}
+			return m, m.openScriptLog(wt.Path, wt.Branch, git.SetupScript)
+		}
+
+	case "x":
+		// Open the run menu with the jean.json scripts
+		if wt := m.selectedWorktree(); wt != nil {
+			if wt.IsMissing() {
+				return m, tea.Batch(m.showWarningNotification("Worktree directory is missing. Re-add or prune it."), m.openRepairModal(wt.Path))
+			}
+			return m, m.openScriptsModal(wt)
+		}
+	}
+
+	return m, nil
+}
+
+func (m Model) handleModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch m.modal {
+	case createModal:
+		return m.handleCreateModalInput(msg)
+
+	case createWithNameModal:
+		return m.handleCreateWithNameModalInput(msg)
+
+	case deleteModal:
+		return m.handleDeleteModalInput(msg)
+
+	case branchSelectModal:
+		return m.handleBranchSelectModalInput(msg)
+
+	case checkoutBranchModal:
+		return m.handleCheckoutBranchModalInput(msg)
+
+	case renameModal:
+		return m.handleRenameModalInput(msg)
+
+	case changeBaseBranchModal:
+		return m.handleChangeBaseBranchModalInput(msg)
+
+	case editorSelectModal:
+		return m.handleEditorSelectModalInput(msg)
+
+	case themeSelectModal:
+		return m.handleThemeSelectModalInput(msg)
+
+	case settingsModal:
+		return m.handleSettingsModalInput(msg)
+
+	case aiSettingsModal:
+		return m.handleAISettingsModalInput(msg)
+
+	case commitModal:
+		return m.handleCommitModalInput(msg)
+
+	case prContentModal:
+		return m.handlePRContentModalInput(msg)
+
+	case prListModal:
+		return m.handlePRListModalInput(msg)
+
+
+	case mergeStrategyModal:
+		return m.handleMergeStrategyModalInput(msg)
+
+	case localMergeConfirmModal:
+		return m.handleLocalMergeConfirmModalInput(msg)
+
+	case postMergeCleanupModal:
+		return m.handlePostMergeCleanupModalInput(msg)
+
+	case aiPromptsModal:
+		return m.handleAIPromptsModalInput(msg)
+
+	case prStateSettingsModal:
+		return m.handlePRStateSettingsModalInput(msg)
+
+	case autoFetchSettingsModal:
+		return m.handleAutoFetchSettingsModalInput(msg)
+
+	case updateStrategySettingsModal:
+		return m.handleUpdateStrategySettingsModalInput(msg)
+
+	case remoteSettingsModal:
+		return m.handleRemoteSettingsModalInput(msg)
+
+	case worktreeLocationModal:
+		return m.handleWorktreeLocationModalInput(msg)
+
+	case relocateModal:
+		return m.handleRelocateModalInput(msg)
+
+	case scriptsModal:
+		return m.handleScriptsModalInput(msg)
+
+	case scriptLogModal:
+		return m.handleScriptLogModalInput(msg)
+
+	case stashModal:
+		return m.handleStashModalInput(msg)
+
+	case diffViewerModal:
+		return m.handleDiffViewerInput(msg)
+
+	case logModal:
+		return m.handleLogModalInput(msg)
+
+	case repairModal:
+		return m.handleRepairModalInput(msg)
+
+	case cleanupModal:
+		return m.handleCleanupModalInput(msg)
+
+	case onboardingModal:
+		return m.handleOnboardingModalInput(msg)
+
+	case gitInitModal:
+		return m.handleGitInitModalInput(msg)
+
+	case helperModal:
+		return m.handleHelperModalInput(msg)
+
+	case stagingModal:
+		return m.handleStagingModalInput(msg)
+
+	case conflictModal:
+		return m.handleConflictModalInput(msg)
+	}
+
+	return m, cmd
+}
+
+// handleSearchBasedModalInput is a shared handler for modals with search/filter functionality
+// Used by: branchSelectModal, checkoutBranchModal, changeBaseBranchModal
+func (m Model) handleSearchBasedModalInput(msg tea.KeyMsg, config searchModalConfig) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg.String() {
+	case "esc":
+		m.searchInput.Blur()
+		// Use custom cancel handler if provided, otherwise default to main view
+		if config.onCancel != nil {
+			return config.onCancel(m)
+		}
+		m.modal = noModal
+		return m, nil
+
+	case "up":
+		if m.modalFocused == 0 {
+			// In search input, move focus to list
+			m.modalFocused = 1
+			m.searchInput.Blur()
+		} else if m.modalFocused == 1 && m.branchIndex > 0 {
+			// In list, move selection up
+			m.branchIndex--
+		}
+		return m, nil
+
+	case "down":
+		if m.modalFocused == 0 {
+			// In search input, move focus to list
+			m.modalFocused = 1
+			m.searchInput.Blur()
+		} else if m.modalFocused == 1 {
+			// In list, move selection down
+			branches := m.filteredBranches
+			if len(branches) == 0 {
+				branches = m.branches
+			}
+			if m.branchIndex < len(branches)-1 {
+				m.branchIndex++
+			}
+		}
+		return m, nil
+
+	case "tab":
+		// Cycle: search -> list -> action button -> cancel button -> search
+		m.modalFocused = (m.modalFocused + 1) % 4
+		if m.modalFocused == 0 {
+			m.searchInput.Focus()
+		} else {
+			m.searchInput.Blur()
+		}
+		return m, nil
+
+	case "enter":
+		if m.modalFocused == 2 {
+			// Action button: Execute the configured action
+			branch := m.selectedBranch()
+			if branch == "" {
+				return m, nil
+			}
+			m.modal = noModal
+			m.searchInput.Blur()
+			return config.onConfirm(m, branch)
+		} else if m.modalFocused == 3 {
+			// Cancel button
+			m.modal = noModal
+			m.searchInput.Blur()
+			return m, nil
+		} else if m.modalFocused == 0 || m.modalFocused == 1 {
+			// In search input or list: move focus to action button
+			m.modalFocused = 2
+			m.searchInput.Blur()
+			return m, nil
+		}
+	}
+
+	// Handle search input typing
+	// Pass all non-navigation keys to search input when in search or list mode
+	if m.modalFocused == 0 || m.modalFocused == 1 {
+		// Check if this is a navigation key that's already been handled
+		key := msg.String()
+		isNavigationKey := key == "up" || key == "down" ||
+			key == "tab" || key == "enter" || key == "esc"
+
+		if !isNavigationKey {
+			// Pass to search input for typing
+			m.searchInput.Focus()
+			m.searchInput, cmd = m.searchInput.Update(msg)
+			// Filter branches based on search
+			m.filteredBranches = m.filterBranches(m.searchInput.Value())
+			// Reset branch index when filter changes
+			m.branchIndex = 0
+			m.modalFocused = 0 // Ensure we're tracking search as focused
+		}
+	}
+
+	return m, cmd
+}
+
+// handleListSelectionModalInput is a shared handler for modals with simple list selection
+// Used by: editorSelectModal
+func (m Model) handleListSelectionModalInput(msg tea.KeyMsg, config listSelectionConfig) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		// Use custom cancel handler if provided, otherwise default to main view
+		if config.onCancel != nil {
+			return config.onCancel(m)
+		}
+		m.modal = noModal
+		return m, nil
+
+	case "up":
+		if config.getCurrentIndex() > 0 {
+			config.decrementIndex(&m)
+		}
+		return m, nil
+
+	case "down":
+		if config.getCurrentIndex() < config.getItemCount(m)-1 {
+			config.incrementIndex(&m)
+		}
+		return m, nil
+
+	case "enter":
+		return config.onConfirm(m)
+
+	default:
+		// Allow custom key handling (e.g., "d" for delete in session list)
+		if config.onCustomKey != nil {
+			return config.onCustomKey(m, msg.String())
+		}
+	}
+
+	return m, nil
+}
+
+// searchModalConfig contains configuration for search-based modals
+type searchModalConfig struct {
+	onConfirm func(m Model, selectedBranch string) (tea.Model, tea.Cmd)
+	onCancel  func(m Model) (tea.Model, tea.Cmd) // Optional: specify where to return on Esc
+}
+
+// listSelectionConfig contains configuration for list selection modals
+type listSelectionConfig struct {
+	getCurrentIndex func() int
+	getItemCount    func(m Model) int
+	incrementIndex  func(m *Model)
+	decrementIndex  func(m *Model)
+	onConfirm       func(m Model) (tea.Model, tea.Cmd)
+	onCancel        func(m Model) (tea.Model, tea.Cmd) // Optional: specify where to return on Esc
+	onCustomKey     func(m Model, key string) (tea.Model, tea.Cmd) // Optional
+}
+
+func (m Model) handleCreateModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.modal = noModal
+		m.nameInput.Blur()
+		return m, nil
+
+	case "tab", "shift+tab":
+		// Cycle through: nameInput -> create button -> cancel button (if new branch)
+		// or just: create button -> cancel button (if existing branch)
+		if m.createNewBranch {
+			m.modalFocused = (m.modalFocused + 1) % 3
+			if m.modalFocused == 0 {
+				m.nameInput.Focus()
+			} else {
+				m.nameInput.Blur()
+			}
+		} else {
+			// For existing branch, just toggle between buttons
+			if m.modalFocused == 0 {
+				m.modalFocused = 1
+			} else if m.modalFocused == 1 {
+				m.modalFocused = 2
+			} else {
+				m.modalFocused = 1
+			}
+		}
+		return m, nil
+
+	case "enter":
+		if m.modalFocused <= 1 {
+			// Create button or enter in input
+			name := m.nameInput.Value()
+
+			if name == "" {
+				m.status = "Branch name is required"
+				return m, nil
+			}
+
+			// Always generate random path
+			path, err := m.gitManager.GetDefaultPath(name)
+			if err != nil {
+				m.status = "Failed to generate workspace path"
+				return m, nil
+			}
+
+			return m, m.createWorktree(path, name, m.createNewBranch)
+		} else if m.modalFocused == 2 {
+			// Cancel button
+			m.modal = noModal
+			m.nameInput.Blur()
+			return m, nil
+		}
+	}
+
+	// Handle text input for branch name
+	var cmd tea.Cmd
+	if m.modalFocused == 0 && m.createNewBranch {
+		m.nameInput, cmd = m.nameInput.Update(msg)
+	}
+
+	return m, cmd
+}
+
+func (m Model) handleCreateWithNameModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.modal = noModal
+		m.sessionNameInput.Blur()
+		return m, nil
+
+	case "tab", "shift+tab":
+		// Cycle through: sessionNameInput -> create button -> cancel button
+		m.modalFocused = (m.modalFocused + 1) % 3
+		if m.modalFocused == 0 {
+			m.sessionNameInput.Focus()
+		} else {
+			m.sessionNameInput.Blur()
+		}
+		return m, nil
+
+	case "enter":
+		if m.modalFocused == 0 {
+			// In input, move to create button
+			m.modalFocused = 1
+			m.sessionNameInput.Blur()
+			return m, nil
+		} else if m.modalFocused == 1 {
+			// Create button
+			randomName, err := m.gitManager.GenerateRandomName()
+			if err != nil {
+				cmd := m.showWarningNotification("Failed to generate random name")
+				return m, cmd
+			}
+
+			// Sanitize the session name to ensure it's a valid branch name (random if there's no name)
+			sanitizedName := newBranchName(m.sessionNameInput.Value(), m.namingRules, randomName)
+			if sanitizedName == "" {
+				cmd := m.showWarningNotification("Session name contains no valid characters")
+				return m, cmd
+			}
+			if err := m.namingRules.Validate(sanitizedName); err != nil {
+				cmd := m.showWarningNotification(fmt.Sprintf("Branch '%s' %v", sanitizedName, err))
+				return m, cmd
+			}
+
+			// Generate path from sanitized session name
+			path, err := m.gitManager.GetDefaultPath(sanitizedName)
+			if err != nil {
+				cmd := m.showWarningNotification("Failed to generate workspace path")
+				return m, cmd
+			}
+
+			m.modal = noModal
+			m.sessionNameInput.Blur()
+			notificationMsg := fmt.Sprintf("Creating worktree: %s\n  Path: %s\n  Claude will automatically continue previous conversations", sanitizedName, path)
+			cmd := m.showInfoNotification(notificationMsg)
+			return m, tea.Batch(cmd, m.createWorktreeWithSession(path, sanitizedName, true))
+		} else {
+			// Cancel button (modalFocused == 2)
+			m.modal = noModal
+			m.sessionNameInput.Blur()
+			return m, nil
+		}
+
+	case "up", "down":
+		// Prefix picker
+		if m.modalFocused == 0 {
+			step := 1
+			if msg.String() == "up" {
+				step = -1
+			}
+			m.sessionNameInput.SetValue(m.namingRules.CyclePrefix(m.sessionNameInput.Value(), step))
+			m.sessionNameInput.CursorEnd()
+		}
+		return m, nil
+	}
+
+	// Handle text input
+	var cmd tea.Cmd
+	if m.modalFocused == 0 {
+		m.sessionNameInput, cmd = m.sessionNameInput.Update(msg)
+	}
+
+	return m, cmd
+}
+
+func (m Model) handleDeleteModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "n":
+		m.modal = noModal
+		return m, nil
+
+	case "tab", "left", "right":
+		// With uncommitted changes or unpushed commits we have 3 buttons (Yes/No/Force), otherwise 2 (Yes/No)
+		if m.deleteNeedsForce() {
+			m.modalFocused = (m.modalFocused + 1) % 3
+		} else {
+			m.modalFocused = (m.modalFocused + 1) % 2
+		}
+
+	case "enter", "y":
+		// If there are uncommitted changes or unpushed commits and user hasn't confirmed force
+		if m.deleteNeedsForce() && !m.deleteConfirmForce {
+			// modalFocused: 0 = Yes (blocked with uncommitted changes, keeps the branch otherwise), 1 = No, 2 = Force Delete
+			if m.modalFocused == 2 || msg.String() == "f" {
+				// User clicked "Force Delete" - set confirmation flag
+				m.deleteConfirmForce = true
+				return m, m.showWarningNotification("Press 'y' or Enter to confirm force delete")
+			} else if m.modalFocused == 1 || msg.String() == "n" {
+				// User clicked "No" - cancel
+				m.modal = noModal
+				return m, nil
+			} else if m.modalFocused == 0 && m.deleteHasUncommitted {
+				// User tried to click "Yes" but it's blocked
+				return m, m.showWarningNotification("Cannot delete: uncommitted changes. Use 'Force Delete' to proceed.")
+			} else if m.modalFocused == 0 {
+				// Only unpushed commits: delete the worktree but keep the branch
+				if wt := m.selectedWorktree(); wt != nil {
+					m.modal = noModal
+					return m, m.deleteWorktree(wt.Path, wt.Branch, false, false)
+				}
+			}
+		} else if m.deleteNeedsForce() && m.deleteConfirmForce {
+			// User already confirmed, now execute force delete (including the branch's unpushed commits)
+			if m.modalFocused == 0 || msg.String() == "y" {
+				if wt := m.selectedWorktree(); wt != nil {
+					m.modal = noModal
+					return m, m.deleteWorktree(wt.Path, wt.Branch, m.deleteHasUncommitted, true)
+				}
+			}
+			m.modal = noModal
+			return m, nil
+		} else {
+			// No uncommitted changes, normal delete
+			if m.modalFocused == 0 || msg.String() == "y" {
+				if wt := m.selectedWorktree(); wt != nil {
+					m.modal = noModal
+					return m, m.deleteWorktree(wt.Path, wt.Branch, false, false)
+				}
+			}
+			m.modal = noModal
+			return m, nil
+		}
+
+	case "f":
+		// Shortcut for "Force Delete"
+		if m.deleteNeedsForce() && !m.deleteConfirmForce {
+			m.deleteConfirmForce = true
+			return m, m.showWarningNotification("Press 'y' or Enter to confirm force delete")
+		}
+	}
+
+	return m, nil
+}
+
+func (m Model) handleBranchSelectModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	config := searchModalConfig{
+		onConfirm: func(m Model, branch string) (tea.Model, tea.Cmd) {
+			// Generate random path
+			path, err := m.gitManager.GetDefaultPath(branch)
+			if err != nil {
+				cmd := m.showWarningNotification("Failed to generate workspace path")
+				return m, cmd
+			}
+			return m, m.createWorktree(path, branch, false)
+		},
+	}
+	return m.handleSearchBasedModalInput(msg, config)
+}
+
+func (m Model) handleCheckoutBranchModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	config := searchModalConfig{
+		onConfirm: func(m Model, branch string) (tea.Model, tea.Cmd) {
+			cmd := m.showInfoNotification("Checking out branch: " + branch)
+			return m, tea.Batch(cmd, m.checkoutBranch(branch))
+		},
+	}
+	return m.handleSearchBasedModalInput(msg, config)
+}
+
+func (m Model) handleRenameModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.modal = noModal
+		m.nameInput.Blur()
+		// Clear rename generation state when closing modal
+		m.generatingRename = false
+		m.renameSpinnerFrame = 0
+		m.renameModalStatus = ""
+		return m, nil
+
+	case "g":
+		// AI-generate branch name (only when focused on buttons, not input field)
+		if m.modalFocused > 0 && m.configManager != nil && m.configManager.GetAnthropicAPIKey() != "" {
+			if wt := m.selectedWorktree(); wt != nil {
+				m.generatingRename = true
+				m.renameSpinnerFrame = 0
+				m.renameModalStatus = ""
+				return m, tea.Batch(
+					m.animateRenameSpinner(),
+					m.generateRenameWithAI(wt.Path, m.nameInput.Value(), m.baseBranch),
+				)
+			}
+		}
+		// If in input field (modalFocused == 0), fall through to handle text input
+
+	case "tab", "shift+tab":
+		// Toggle between input and buttons
+		m.modalFocused = (m.modalFocused + 1) % 3
+		if m.modalFocused == 0 {
+			m.nameInput.Focus()
+		} else {
+			m.nameInput.Blur()
+		}
+		return m, nil
+
+	case "up", "down":
+		// Prefix picker
+		if m.modalFocused == 0 {
+			step := 1
+			if msg.String() == "up" {
+				step = -1
+			}
+			m.nameInput.SetValue(m.namingRules.CyclePrefix(m.nameInput.Value(), step))
+			m.nameInput.CursorEnd()
+		}
+		return m, nil
+
+	case "enter":
+		if m.modalFocused <= 1 {
+			// Rename button or enter in input
+			newName := m.nameInput.Value()
+			if newName == "" {
+				cmd := m.showWarningNotification("Branch name cannot be empty")
+				return m, cmd
+			}
+
+			// Sanitize branch name
+			newName = git.SanitizeBranchName(newName)
+			if newName == "" {
+				cmd := m.showWarningNotification("Branch name cannot be empty after sanitization")
+				return m, cmd
+			}
+			if err := m.namingRules.Validate(newName); err != nil {
+				cmd := m.showWarningNotification(fmt.Sprintf("Branch '%s' %v", newName, err))
+				return m, cmd
+			}
+
+			if wt := m.selectedWorktree(); wt != nil {
+				if newName == wt.Branch {
+					cmd := m.showInfoNotification("Branch name unchanged")
+					m.modal = noModal
+					m.nameInput.Blur()
+					return m, cmd
+				}
+
+				cmd := m.showInfoNotification(fmt.Sprintf("Renaming branch to '%s'...", newName))
+				m.modal = noModal
+				m.nameInput.Blur()
+				return m, tea.Batch(cmd, m.renameBranch(wt.Branch, newName, wt.Path))
+			}
+		} else if m.modalFocused == 2 {
+			// Cancel button
+			m.modal = noModal
+			m.nameInput.Blur()
+			return m, nil
+		}
+	}
+
+	// Handle text input
+	var cmd tea.Cmd
+	if m.modalFocused == 0 {
+		m.nameInput, cmd = m.nameInput.Update(msg)
+	}
+
+	return m, cmd
+}
+
+func (m Model) handleChangeBaseBranchModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	config := searchModalConfig{
+		onConfirm: func(m Model, branch string) (tea.Model, tea.Cmd) {
+			m.baseBranch = branch
+			var cmd tea.Cmd
+
+			// Save to config
+			if m.configManager != nil {
+				if err := m.configManager.SetBaseBranch(m.repoPath, branch); err != nil {
+					cmd = m.showWarningNotification("Base branch set to: " + branch + " (warning: failed to save)")
+				} else {
+					cmd = m.showSuccessNotification("Base branch set to: " + branch, 3*time.Second)
+				}
+			} else {
+				cmd = m.showInfoNotification("Base branch set to: " + branch)
+			}
+			m.modal = settingsModal
+			m.settingsIndex = 2
+			return m, cmd
+		},
+		onCancel: func(m Model) (tea.Model, tea.Cmd) {
+			// Return to settings modal
+			m.modal = settingsModal
+			m.settingsIndex = 2
+			return m, nil
+		},
+	}
+	return m.handleSearchBasedModalInput(msg, config)
+}
+
+func (m Model) handleCommitModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.modal = noModal
+		m.commitSubjectInput.Blur()
+		return m, nil
 
-			// Safety check: ensure no uncommitted changes
-			hasUncommitted, err := m.gitManager.HasUncommittedChanges(wt.Path)
-			if err != nil {
-				return m, m.showErrorNotification("Failed to check for uncommitted changes: "+err.Error(), 3*time.Second)
-			}
-			if hasUncommitted {
-				return m, m.showWarningNotification("Commit or stash changes before merging. Press 'c' to commit.")
-			}
+	case "tab", "shift+tab":
+		// Cycle through: subject input -> commit button -> cancel button
+		m.modalFocused = (m.modalFocused + 1) % 3
 
-			// All checks passed - prepare merge (fetch and get status)
-			m.debugLog(fmt.Sprintf("L keybinding: preparing local merge of %s into %s", wt.Branch, m.baseBranch))
-			cmd = m.showInfoNotification("Preparing merge...")
-			return m, tea.Batch(cmd, m.prepareLocalMerge(wt.Path, wt.Branch, m.baseBranch))
+		// Update focus state
+		if m.modalFocused == 0 {
+			m.commitSubjectInput.Focus()
+		} else {
+			m.commitSubjectInput.Blur()
 		}
+		return m, nil
 
-	case "c":
-		// Commit changes
-		if wt := m.selectedWorktree(); wt != nil {
-			// Check if worktree has uncommitted changes
-			hasUncommitted, err := m.gitManager.HasUncommittedChanges(wt.Path)
-			if err != nil {
-				return m, m.showErrorNotification("Failed to check for uncommitted changes: " + err.Error(), 3*time.Second)
-			}
-			if !hasUncommitted {
-				cmd = m.showInfoNotification("Nothing to commit - no uncommitted changes in " + wt.Branch)
-				return m, cmd
-			}
-
-			// Check if AI commit generation is enabled and API key is set
-			aiEnabled := m.configManager.GetAICommitEnabled()
-			apiKey := m.configManager.GetAnthropicAPIKey()
-
-			if aiEnabled && apiKey != "" {
-				// Auto-generate and auto-commit with AI (no modal shown)
+	case "g":
+		// Generate AI commit message (only if not focused on input field and API key is configured)
+		if m.modalFocused > 0 && m.configManager != nil && m.configManager.GetAnthropicAPIKey() != "" {
+			if wt := m.selectedWorktree(); wt != nil {
 				m.generatingCommit = true
 				m.spinnerFrame = 0
-				m.autoCommitWithAI = true    // Flag for standalone auto-commit
-				notifyCmd := m.showInfoNotification("🤖 Generating commit message...")
+				m.commitModalStatus = ""
 				return m, tea.Batch(
-					notifyCmd,
 					m.animateSpinner(),
 					m.generateCommitMessageWithAI(wt.Path),
 				)
-			} else {
-				// Manual commit mode - open modal for user to type message
-				m.modal = commitModal
-				m.modalFocused = 0
-				m.commitSubjectInput.SetValue("")
-				m.commitSubjectInput.Focus()
-					m.commitModalStatus = "" // Clear any previous status
-				return m, nil
 			}
 		}
+		// If in input field (modalFocused 0), fall through to handle text input
 
-	case "v":
-		// Open PR in browser - if multiple PRs exist, show selection modal
-		if wt := m.selectedWorktree(); wt != nil {
-			if prs, ok := wt.PRs.([]config.PRInfo); ok && len(prs) > 0 {
-				if len(prs) == 1 {
-					// Only one PR - open it directly
-					m.debugLog(fmt.Sprintf("v keybinding: opening single PR %s", prs[0].URL))
-					cmd := exec.Command("gh", "pr", "view", prs[0].URL, "--web")
-					err := cmd.Start()
-					if err != nil {
-						return m, m.showErrorNotification("Failed to open PR in browser: "+err.Error(), 3*time.Second)
+	case "enter":
+		if m.modalFocused == 0 {
+			// In subject input, move to commit button
+			m.modalFocused = 1
+			m.commitSubjectInput.Blur()
+			return m, nil
+		} else if m.modalFocused == 1 {
+			// Commit button
+			subject := m.commitSubjectInput.Value()
+			if subject == "" {
+				// If AI commit is enabled and API key is configured, try auto-generate
+				if m.configManager != nil && m.configManager.GetAICommitEnabled() && m.configManager.GetAnthropicAPIKey() != "" {
+					if wt := m.selectedWorktree(); wt != nil {
+						m.generatingCommit = true
+						m.spinnerFrame = 0
+						m.commitModalStatus = ""
+						return m, tea.Batch(
+							m.animateSpinner(),
+							m.generateCommitMessageWithAI(wt.Path),
+						)
 					}
-					return m, m.showSuccessNotification("Opening PR in browser...", 2*time.Second)
 				} else {
-					// Multiple PRs - show selection modal for viewing
-					m.debugLog(fmt.Sprintf("v keybinding: opening PR selection modal with %d PRs", len(prs)))
-					m.modal = prListModal
-					m.prListCreationMode = false  // Ensure NOT creation mode
-					m.prListMergeMode = false     // Ensure NOT merge mode
-					m.prListViewMode = true       // SET view mode
-					m.prListIndex = len(prs) - 1  // Default to most recent
-					m.prSearchInput.SetValue("")
-					m.prSearchInput.Focus()
-					// Convert config.PRInfo to github.PRInfo for display
-					m.prs = make([]github.PRInfo, len(prs))
-					for i, pr := range prs {
-						m.debugLog(fmt.Sprintf("v keybinding: PR[%d] #%d %s - %s", i, pr.PRNumber, pr.Title, pr.URL))
-						// Convert config.PRInfo to github.PRInfo for modal display
-						m.prs[i] = github.PRInfo{
-							Number:      pr.PRNumber,
-							Title:       pr.Title, // Now we have the title from config
-							URL:         pr.URL,
-							HeadRefName: wt.Branch,
-							Status:      pr.Status,
-						}
-						// Set the author
-						m.prs[i].Author.Login = pr.Author
-					}
-					m.filteredPRs = m.prs // Populate filtered PRs with worktree PRs
-					m.prLoadingError = ""
-					return m, nil
+					// No AI generation, show error
+					cmd := m.showWarningNotification("Commit subject cannot be empty")
+					return m, cmd
 				}
-			} else {
-				return m, m.showInfoNotification("No PRs found for this worktree")
 			}
+
+			if wt := m.selectedWorktree(); wt != nil {
+				cmd := m.showInfoNotification("Creating commit...")
+				m.modal = noModal
+				m.commitSubjectInput.Blur()
+				return m, tea.Batch(cmd, m.createCommit(wt.Path, subject))
+			}
+		} else {
+			// Cancel button (modalFocused == 2)
+			m.modal = noModal
+			m.commitSubjectInput.Blur()
+			return m, nil
 		}
+	}
 
-	case "M":
-		// Merge PR (Shift+M)
-		if wt := m.selectedWorktree(); wt != nil {
-			if prs, ok := wt.PRs.([]config.PRInfo); ok && len(prs) > 0 {
-				if len(prs) == 1 {
-					// Only one PR - proceed to merge strategy selection
-					m.selectedPRForMerge = prs[0].URL
-					m.mergeStrategyCursor = 0 // Default to squash
-					m.modal = mergeStrategyModal
-					return m, nil
-				} else {
-					// Multiple PRs - show selection modal in merge mode
-					m.modal = prListModal
-					m.prListMergeMode = true
-					m.prListIndex = len(prs) - 1 // Default to most recent
-					return m, nil
-				}
-			} else {
-				return m, m.showErrorNotification("No PRs found for this worktree", 3*time.Second)
+	// Handle text input
+	var cmd tea.Cmd
+	if m.modalFocused == 0 {
+		m.commitSubjectInput, cmd = m.commitSubjectInput.Update(msg)
+	}
+
+	return m, cmd
+}
+
+func (m Model) handlePRContentModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.modal = noModal
+		m.prTitleInput.Blur()
+		m.prDescriptionInput.Blur()
+		return m, nil
+
+	case "tab", "shift+tab":
+		// Cycle through: title input -> description input -> create button -> cancel button
+		m.prModalFocused = (m.prModalFocused + 1) % 4
+
+		// Update focus state
+		if m.prModalFocused == 0 {
+			m.prTitleInput.Focus()
+			m.prDescriptionInput.Blur()
+		} else if m.prModalFocused == 1 {
+			m.prTitleInput.Blur()
+			m.prDescriptionInput.Focus()
+		} else {
+			m.prTitleInput.Blur()
+			m.prDescriptionInput.Blur()
+		}
+		return m, nil
+
+	case "g":
+		// Generate AI PR content (only if not focused on input fields and API key is configured)
+		if m.prModalFocused > 1 && m.configManager != nil && m.configManager.GetAnthropicAPIKey() != "" {
+			m.generatingPRContent = true
+			m.prSpinnerFrame = 0
+			return m, tea.Batch(
+				m.animateSpinner(),
+				m.generatePRContent(m.prModalWorktreePath, m.prModalBranch, m.baseBranch),
+			)
+		}
+		// If in input field (prModalFocused 0 or 1), fall through to handle text input
+
+	case "enter":
+		if m.prModalFocused == 0 {
+			// In title input, move to description
+			m.prModalFocused = 1
+			m.prTitleInput.Blur()
+			m.prDescriptionInput.Focus()
+			return m, nil
+		} else if m.prModalFocused == 1 {
+			// In description input, move to create button
+			m.prModalFocused = 2
+			m.prDescriptionInput.Blur()
+			return m, nil
+		} else if m.prModalFocused == 2 {
+			// Create button
+			title := m.prTitleInput.Value()
+			description := m.prDescriptionInput.Value()
+
+			// Validate title
+			if title == "" {
+				cmd := m.showWarningNotification("PR title cannot be empty")
+				return m, cmd
 			}
+
+			// Create the PR
+			cmd := m.showInfoNotification("Creating draft PR...")
+			m.modal = noModal
+			m.prTitleInput.Blur()
+			m.prDescriptionInput.Blur()
+			return m, tea.Batch(
+				cmd,
+				m.createPR(m.prModalWorktreePath, m.prModalBranch, title, description),
+			)
+		} else {
+			// Cancel button (prModalFocused == 3)
+			m.modal = noModal
+			m.prTitleInput.Blur()
+			m.prDescriptionInput.Blur()
+			return m, nil
 		}
+	}
+
+	// Handle text input
+	var cmd tea.Cmd
+	if m.prModalFocused == 0 {
+		m.prTitleInput, cmd = m.prTitleInput.Update(msg)
+	} else if m.prModalFocused == 1 {
+		m.prDescriptionInput, cmd = m.prDescriptionInput.Update(msg)
+	}
+
+	return m, cmd
+}
 
-	case "h":
-		// Open help modal
-		m.modal = helperModal
+func (m Model) handlePRListModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.debugLog("handlePRListModalInput: ESC pressed - closing PR list modal")
+		m.modal = noModal
+		m.prListMergeMode = false
+		m.prListCreationMode = false
+		m.prListViewMode = false
+		m.prListIndex = 0
+		m.prSearchInput.Blur()
 		return m, nil
 
-	case "g":
-		// Open staging modal
-		if wt := m.selectedWorktree(); wt != nil {
-			m.modal = stagingModal
-			m.stagingIndex = 0
-			m.stagingFiles = nil
-			return m, m.loadStagingFiles()
+	case "up":
+		if m.prListIndex > 0 {
+			m.prListIndex--
 		}
-	}
+		m.debugLog(fmt.Sprintf("handlePRListModalInput: UP pressed - prListIndex now %d", m.prListIndex))
+		return m, nil
 
-	return m, nil
-}
+	case "down":
+		// Get the filtered PR list based on current search
+		filteredList := m.filterPRs(m.prSearchInput.Value())
+		if m.prListIndex < len(filteredList)-1 {
+			m.prListIndex++
+		}
+		m.debugLog(fmt.Sprintf("handlePRListModalInput: DOWN pressed - prListIndex now %d (max %d)", m.prListIndex, len(filteredList)-1))
+		return m, nil
 
-func (m Model) handleModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	var cmd tea.Cmd
+	case "tab":
+		// Cycle focus: search -> list -> OK button -> Cancel button -> search
+		m.modalFocused = (m.modalFocused + 1) % 4
+		if m.modalFocused == 0 {
+			m.prSearchInput.Focus()
+		} else {
+			m.prSearchInput.Blur()
+		}
+		m.debugLog(fmt.Sprintf("handlePRListModalInput: TAB pressed - modalFocused now %d", m.modalFocused))
+		return m, nil
 
-	switch m.modal {
-	case createModal:
-		return m.handleCreateModalInput(msg)
+	case "enter":
+		// Get filtered PRs for selection
+		filteredList := m.filterPRs(m.prSearchInput.Value())
+		m.debugLog(fmt.Sprintf("handlePRListModalInput: ENTER pressed - filtered list has %d PRs, selected index=%d", len(filteredList), m.prListIndex))
 
-	case createWithNameModal:
-		return m.handleCreateWithNameModalInput(msg)
+		if m.prListIndex >= len(filteredList) {
+			m.debugLog("handlePRListModalInput: ENTER - index out of bounds, aborting")
+			return m, nil
+		}
 
-	case deleteModal:
-		return m.handleDeleteModalInput(msg)
+		if len(filteredList) == 0 {
+			m.debugLog("handlePRListModalInput: ENTER - no filtered PRs, aborting")
+			return m, nil
+		}
 
-	case branchSelectModal:
-		return m.handleBranchSelectModalInput(msg)
+		selectedPR := filteredList[m.prListIndex]
+		m.debugLog(fmt.Sprintf("handlePRListModalInput: ENTER - selected PR #%d - %s (branch: %s)", selectedPR.Number, selectedPR.Title, selectedPR.HeadRefName))
 
-	case checkoutBranchModal:
-		return m.handleCheckoutBranchModalInput(msg)
+		// Handle creation mode: create worktree from PR branch
+		if m.prListCreationMode {
+			m.debugLog(fmt.Sprintf("handlePRListModalInput: CREATION MODE - creating worktree from PR branch: %s", selectedPR.HeadRefName))
 
-	case renameModal:
-		return m.handleRenameModalInput(msg)
+			// Store PR info temporarily (will be saved after worktree is created)
+			prCopy := selectedPR // Make a copy to avoid pointer issues
+			m.pendingPRInfo = &prCopy
+			m.debugLog(fmt.Sprintf("handlePRListModalInput: stored pendingPRInfo - PR #%d: %s (URL: %s)", selectedPR.Number, selectedPR.Title, selectedPR.URL))
 
-	case changeBaseBranchModal:
-		return m.handleChangeBaseBranchModalInput(msg)
+			m.modal = noModal
+			m.prListCreationMode = false
+			m.prListIndex = 0
+			m.prSearchInput.SetValue("")
+			m.prSearchInput.Blur()
+			cmd := m.showInfoNotification("Creating worktree from PR...")
+			return m, tea.Batch(cmd, m.createWorktreeFromPR(selectedPR.HeadRefName))
+		} else if m.prListViewMode {
+			// Handle view mode: user pressed 'v' and is selecting a PR to view
+			m.debugLog(fmt.Sprintf("handlePRListModalInput: VIEW MODE - opening selected PR in browser: %s", selectedPR.URL))
+			m.modal = noModal
+			m.prListViewMode = false
+			cmd := exec.Command("gh", "pr", "view", selectedPR.URL, "--web")
+			err := cmd.Start()
+			if err != nil {
+				return m, m.showErrorNotification("Failed to open PR in browser: "+err.Error(), 3*time.Second)
+			}
+			return m, m.showSuccessNotification("Opening PR in browser...", 2*time.Second)
+		} else {
+			// Handle merge or default mode (for worktree PRs)
+			wt := m.selectedWorktree()
+			if wt == nil {
+				m.modal = noModal
+				m.prListMergeMode = false
+				return m, nil
+			}
 
-	case editorSelectModal:
-		return m.handleEditorSelectModalInput(msg)
+			// Get PRs from worktree
+			prList, ok := wt.PRs.([]config.PRInfo)
+			if !ok || len(prList) == 0 {
+				m.modal = noModal
+				m.prListMergeMode = false
+				return m, nil
+			}
 
-	case themeSelectModal:
-		return m.handleThemeSelectModalInput(msg)
+			// Find matching PR by branch name
+			var selectedConfigPR config.PRInfo
+			for _, pr := range prList {
+				if pr.Branch == selectedPR.HeadRefName {
+					selectedConfigPR = pr
+					break
+				}
+			}
 
-	case settingsModal:
-		return m.handleSettingsModalInput(msg)
+			if selectedConfigPR.URL == "" {
+				m.modal = noModal
+				return m, m.showErrorNotification("PR not found in worktree", 3*time.Second)
+			}
 
-	case aiSettingsModal:
-		return m.handleAISettingsModalInput(msg)
+			if m.prListMergeMode {
+				// User is merging a PR - proceed to merge strategy selection
+				m.selectedPRForMerge = selectedConfigPR.URL
+				m.mergeStrategyCursor = 0 // Default to squash
+				m.modal = mergeStrategyModal
+				m.prListMergeMode = false
+				return m, nil
+			} else {
+				// This should not happen, but handle it gracefully
+				m.modal = noModal
+				return m, m.showWarningNotification("Unknown PR modal mode")
+			}
+		}
 
-	case commitModal:
-		return m.handleCommitModalInput(msg)
+	default:
+		// Pass all other input to the search input when it's focused
+		if m.modalFocused == 0 {
+			oldValue := m.prSearchInput.Value()
+			m.prSearchInput, _ = m.prSearchInput.Update(msg)
+			newValue := m.prSearchInput.Value()
 
-	case prContentModal:
-		return m.handlePRContentModalInput(msg)
+			// If search value changed, reset list index
+			if oldValue != newValue {
+				m.prListIndex = 0
+			}
 
-	case prListModal:
-		return m.handlePRListModalInput(msg)
+			return m, nil
+		}
+		return m, nil
+	}
+}
 
+func (m Model) handleMergeStrategyModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.modal = noModal
+		m.selectedPRForMerge = ""
+		return m, nil
 
-	case mergeStrategyModal:
-		return m.handleMergeStrategyModalInput(msg)
+	case "up":
+		if m.mergeStrategyCursor > 0 {
+			m.mergeStrategyCursor--
+		}
+		return m, nil
 
-	case localMergeConfirmModal:
-		return m.handleLocalMergeConfirmModalInput(msg)
+	case "down":
+		if m.mergeStrategyCursor < 2 {
+			m.mergeStrategyCursor++
+		}
+		return m, nil
 
-	case postMergeCleanupModal:
-		return m.handlePostMergeCleanupModalInput(msg)
+	case "enter":
+		// User confirmed merge strategy selection
+		if m.selectedPRForMerge == "" {
+			m.modal = noModal
+			return m, m.showErrorNotification("No PR selected for merge", 3*time.Second)
+		}
 
-	case aiPromptsModal:
-		return m.handleAIPromptsModalInput(msg)
+		// Get the selected merge method
+		mergeStrategies := []string{"squash", "merge", "rebase"}
+		mergeMethod := mergeStrategies[m.mergeStrategyCursor]
 
-	case prStateSettingsModal:
-		return m.handlePRStateSettingsModalInput(msg)
+		// Get the selected worktree to determine if PR is draft
+		wt := m.selectedWorktree()
+		if wt == nil {
+			m.modal = noModal
+			return m, m.showErrorNotification("No worktree selected", 3*time.Second)
+		}
 
-	case onboardingModal:
-		return m.handleOnboardingModalInput(msg)
+		// Get the PR info to check its status
+		prs, ok := wt.PRs.([]config.PRInfo)
+		if !ok || len(prs) == 0 {
+			m.modal = noModal
+			return m, m.showErrorNotification("No PR found", 3*time.Second)
+		}
 
-	case gitInitModal:
-		return m.handleGitInitModalInput(msg)
+		// Find the selected PR
+		selectedPR := config.PRInfo{}
+		for _, pr := range prs {
+			if pr.URL == m.selectedPRForMerge {
+				selectedPR = pr
+				break
+			}
+		}
 
-	case helperModal:
-		return m.handleHelperModalInput(msg)
+		if selectedPR.URL == "" {
+			m.modal = noModal
+			return m, m.showErrorNotification("PR not found", 3*time.Second)
+		}
 
-	case stagingModal:
-		return m.handleStagingModalInput(msg)
+		// Check PR status - if draft, mark as ready first
+		if selectedPR.Status == "draft" {
+			m.modal = noModal
+			m.selectedPRForMerge = ""
+			notifyCmd := m.showInfoNotification("⏳ Marking PR as ready...")
+			return m, tea.Batch(
+				notifyCmd,
+				m.markPRReady(wt.Path, selectedPR.URL),
+			)
+		}
+
+		// PR is already ready/open - proceed directly to merge
+		m.modal = noModal
+		m.selectedPRForMerge = ""
+		notifyCmd := m.showInfoNotification("⏳ Merging PR with " + mergeMethod + " strategy...")
+		return m, tea.Batch(
+			notifyCmd,
+			m.mergePR(wt.Path, selectedPR.URL, mergeMethod),
+		)
 	}
 
-	return m, cmd
+	return m, nil
 }
 
-// handleSearchBasedModalInput is a shared handler for modals with search/filter functionality
-// Used by: branchSelectModal, checkoutBranchModal, changeBaseBranchModal
-func (m Model) handleSearchBasedModalInput(msg tea.KeyMsg, config searchModalConfig) (tea.Model, tea.Cmd) {
-	var cmd tea.Cmd
-
+func (m Model) handleLocalMergeConfirmModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "esc":
-		m.searchInput.Blur()
-		// Use custom cancel handler if provided, otherwise default to main view
-		if config.onCancel != nil {
-			return config.onCancel(m)
-		}
+		// Cancel merge and close modal
 		m.modal = noModal
+		m.localMergeBranch = ""
+		m.localMergeTarget = ""
+		m.localMergeWorktree = ""
 		return m, nil
 
-	case "up":
-		if m.modalFocused == 0 {
-			// In search input, move focus to list
-			m.modalFocused = 1
-			m.searchInput.Blur()
-		} else if m.modalFocused == 1 && m.branchIndex > 0 {
-			// In list, move selection up
-			m.branchIndex--
-		}
+	case "tab":
+		// Cycle between confirm (0) and cancel (1) buttons
+		m.localMergeFocused = (m.localMergeFocused + 1) % 2
 		return m, nil
 
-	case "down":
-		if m.modalFocused == 0 {
-			// In search input, move focus to list
-			m.modalFocused = 1
-			m.searchInput.Blur()
-		} else if m.modalFocused == 1 {
-			// In list, move selection down
-			branches := m.filteredBranches
-			if len(branches) == 0 {
-				branches = m.branches
-			}
-			if m.branchIndex < len(branches)-1 {
-				m.branchIndex++
-			}
+	case "left", "shift+tab":
+		// Move focus left (backward)
+		if m.localMergeFocused > 0 {
+			m.localMergeFocused--
+		} else {
+			m.localMergeFocused = 1 // Wrap around to cancel
 		}
 		return m, nil
 
-	case "tab":
-		// Cycle: search -> list -> action button -> cancel button -> search
-		m.modalFocused = (m.modalFocused + 1) % 4
-		if m.modalFocused == 0 {
-			m.searchInput.Focus()
+	case "right":
+		// Move focus right (forward)
+		if m.localMergeFocused < 1 {
+			m.localMergeFocused++
 		} else {
-			m.searchInput.Blur()
+			m.localMergeFocused = 0 // Wrap around to confirm
 		}
 		return m, nil
 
 	case "enter":
-		if m.modalFocused == 2 {
-			// Action button: Execute the configured action
-			branch := m.selectedBranch()
-			if branch == "" {
-				return m, nil
-			}
+		// Execute based on focused button
+		if m.localMergeFocused == 0 {
+			// Confirm button - execute merge
+			m.debugLog(fmt.Sprintf("Local merge confirmed: %s -> %s", m.localMergeBranch, m.localMergeTarget))
+
+			branch := m.localMergeBranch
+			target := m.localMergeTarget
+			worktree := m.localMergeWorktree
+
+			// Close modal
 			m.modal = noModal
-			m.searchInput.Blur()
-			return config.onConfirm(m, branch)
-		} else if m.modalFocused == 3 {
-			// Cancel button
+
+			// Show notification and execute merge
+			notifyCmd := m.showInfoNotification(fmt.Sprintf("Merging %s into %s...", branch, target))
+			return m, tea.Batch(
+				notifyCmd,
+				m.executeLocalMerge(worktree, branch, target),
+			)
+		} else {
+			// Cancel button - just close modal
 			m.modal = noModal
-			m.searchInput.Blur()
-			return m, nil
-		} else if m.modalFocused == 0 || m.modalFocused == 1 {
-			// In search input or list: move focus to action button
-			m.modalFocused = 2
-			m.searchInput.Blur()
+			m.localMergeBranch = ""
+			m.localMergeTarget = ""
+			m.localMergeWorktree = ""
 			return m, nil
 		}
 	}
 
-	// Handle search input typing
-	// Pass all non-navigation keys to search input when in search or list mode
-	if m.modalFocused == 0 || m.modalFocused == 1 {
-		// Check if this is a navigation key that's already been handled
-		key := msg.String()
-		isNavigationKey := key == "up" || key == "down" ||
-			key == "tab" || key == "enter" || key == "esc"
-
-		if !isNavigationKey {
-			// Pass to search input for typing
-			m.searchInput.Focus()
-			m.searchInput, cmd = m.searchInput.Update(msg)
-			// Filter branches based on search
-			m.filteredBranches = m.filterBranches(m.searchInput.Value())
-			// Reset branch index when filter changes
-			m.branchIndex = 0
-			m.modalFocused = 0 // Ensure we're tracking search as focused
-		}
-	}
-
-	return m, cmd
+	return m, nil
 }
 
-// handleListSelectionModalInput is a shared handler for modals with simple list selection
-// Used by: editorSelectModal
-func (m Model) handleListSelectionModalInput(msg tea.KeyMsg, config listSelectionConfig) (tea.Model, tea.Cmd) {
+func (m Model) handlePostMergeCleanupModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
-	case "esc", "q":
-		// Use custom cancel handler if provided, otherwise default to main view
-		if config.onCancel != nil {
-			return config.onCancel(m)
-		}
+	case "esc":
+		// Close modal without action
 		m.modal = noModal
 		return m, nil
 
 	case "up":
-		if config.getCurrentIndex() > 0 {
-			config.decrementIndex(&m)
+		if m.postMergeDeleteIndex > 0 {
+			m.postMergeDeleteIndex--
 		}
 		return m, nil
 
 	case "down":
-		if config.getCurrentIndex() < config.getItemCount(m)-1 {
-			config.incrementIndex(&m)
+		if m.postMergeDeleteIndex < 1 {
+			m.postMergeDeleteIndex++
 		}
 		return m, nil
 
 	case "enter":
-		return config.onConfirm(m)
+		branch := m.localMergeBranch
+		worktree := m.localMergeWorktree
 
-	default:
-		// Allow custom key handling (e.g., "d" for delete in session list)
-		if config.onCustomKey != nil {
-			return config.onCustomKey(m, msg.String())
+		if m.postMergeDeleteIndex == 0 {
+			// User chose to delete worktree
+			m.debugLog(fmt.Sprintf("Post-merge cleanup: deleting worktree %s (branch: %s)", worktree, branch))
+			m.modal = noModal
+
+			// Delete the worktree (force=false since we know it's clean)
+			notifyCmd := m.showInfoNotification("Deleting merged worktree...")
+			return m, tea.Batch(
+				notifyCmd,
+				m.deleteWorktree(worktree, branch, false, false),
+			)
+		} else {
+			// User chose to keep worktree
+			m.debugLog(fmt.Sprintf("Post-merge cleanup: keeping worktree %s", worktree))
+			m.modal = noModal
+			return m, m.showSuccessNotification("Merge complete. Worktree kept for reference.", 3*time.Second)
 		}
 	}
 
 	return m, nil
 }
 
-// searchModalConfig contains configuration for search-based modals
-type searchModalConfig struct {
-	onConfirm func(m Model, selectedBranch string) (tea.Model, tea.Cmd)
-	onCancel  func(m Model) (tea.Model, tea.Cmd) // Optional: specify where to return on Esc
-}
-
-// listSelectionConfig contains configuration for list selection modals
-type listSelectionConfig struct {
-	getCurrentIndex func() int
-	getItemCount    func(m Model) int
-	incrementIndex  func(m *Model)
-	decrementIndex  func(m *Model)
-	onConfirm       func(m Model) (tea.Model, tea.Cmd)
-	onCancel        func(m Model) (tea.Model, tea.Cmd) // Optional: specify where to return on Esc
-	onCustomKey     func(m Model, key string) (tea.Model, tea.Cmd) // Optional
-}
-
-func (m Model) handleCreateModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+func (m Model) handlePRTypeModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "esc":
 		m.modal = noModal
-		m.nameInput.Blur()
 		return m, nil
 
-	case "tab", "shift+tab":
-		// Cycle through: nameInput -> create button -> cancel button (if new branch)
-		// or just: create button -> cancel button (if existing branch)
-		if m.createNewBranch {
-			m.modalFocused = (m.modalFocused + 1) % 3
-			if m.modalFocused == 0 {
-				m.nameInput.Focus()
-			} else {
-				m.nameInput.Blur()
+	case "up":
+		if m.prTypeCursor > 0 {
+			m.prTypeCursor--
+		}
+		return m, nil
+
+	case "down":
+		if m.prTypeCursor < 1 {
+			m.prTypeCursor++
+		}
+		return m, nil
+
+	case "enter":
+		// User confirmed PR type selection
+		// Set isDraft based on selection (0=draft, 1=ready for review)
+		m.prIsDraft = (m.prTypeCursor == 0)
+
+		// Close modal and create PR with commit message as title
+		m.modal = noModal
+
+		// Use the last commit message as PR title
+		title := m.lastCommitMessage
+		if title == "" {
+			// Fallback if no commit message available (shouldn't happen in normal flow)
+			title = strings.ReplaceAll(m.prModalBranch, "-", " ")
+			title = strings.ReplaceAll(title, "_", " ")
+			title = strings.TrimSpace(title)
+			if len(title) > 0 {
+				title = strings.ToUpper(title[:1]) + title[1:]
 			}
-		} else {
-			// For existing branch, just toggle between buttons
-			if m.modalFocused == 0 {
-				m.modalFocused = 1
-			} else if m.modalFocused == 1 {
-				m.modalFocused = 2
-			} else {
-				m.modalFocused = 1
+		}
+
+		// Create PR with commit message as title and empty description
+		cmd := m.showInfoNotification("Creating draft PR...")
+		return m, tea.Batch(cmd, m.createOrUpdatePR(m.prModalWorktreePath, m.prModalBranch, title, ""))
+	}
+
+	return m, nil
+}
+
+func (m Model) handleEditorSelectModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	config := listSelectionConfig{
+		getCurrentIndex: func() int { return m.editorIndex },
+		getItemCount:    func(m Model) int { return len(m.editors) },
+		incrementIndex:  func(m *Model) { m.editorIndex++ },
+		decrementIndex:  func(m *Model) { m.editorIndex-- },
+		onConfirm: func(m Model) (tea.Model, tea.Cmd) {
+			if m.editorIndex >= 0 && m.editorIndex < len(m.editors) {
+				selectedEditor := m.editors[m.editorIndex]
+				if m.configManager != nil {
+					if err := m.configManager.SetEditor(m.repoPath, selectedEditor); err != nil {
+						m.showErrorNotification("Failed to save editor preference", 3*time.Second)
+					} else {
+						m.showSuccessNotification("Editor set to: " + selectedEditor, 3*time.Second)
+					}
+				}
 			}
+			m.modal = settingsModal
+			m.settingsIndex = 0
+			return m, nil
+		},
+		onCancel: func(m Model) (tea.Model, tea.Cmd) {
+			// Return to settings modal
+			m.modal = settingsModal
+			m.settingsIndex = 0
+			return m, nil
+		},
+	}
+	return m.handleListSelectionModalInput(msg, config)
+}
+
+func (m Model) handleThemeSelectModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		// Revert to original theme when cancelling
+		if m.originalTheme != "" {
+			ApplyTheme(m.originalTheme)
 		}
+		m.modal = settingsModal
+		m.settingsIndex = 1
 		return m, nil
 
-	case "enter":
-		if m.modalFocused <= 1 {
-			// Create button or enter in input
-			name := m.nameInput.Value()
-
-			if name == "" {
-				m.status = "Branch name is required"
-				return m, nil
+	case "up":
+		if m.themeIndex > 0 {
+			m.themeIndex--
+			// Apply theme preview immediately
+			selectedTheme := m.availableThemes[m.themeIndex]
+			themeName := strings.ToLower(selectedTheme.Name)
+			if err := ApplyTheme(themeName); err == nil {
+				// Theme applied successfully, UI will refresh with new colors
 			}
+		}
+		return m, nil
 
-			// Always generate random path
-			path, err := m.gitManager.GetDefaultPath(name)
-			if err != nil {
-				m.status = "Failed to generate workspace path"
-				return m, nil
+	case "down":
+		if m.themeIndex < len(m.availableThemes)-1 {
+			m.themeIndex++
+			// Apply theme preview immediately
+			selectedTheme := m.availableThemes[m.themeIndex]
+			themeName := strings.ToLower(selectedTheme.Name)
+			if err := ApplyTheme(themeName); err == nil {
+				// Theme applied successfully, UI will refresh with new colors
 			}
-
-			return m, m.createWorktree(path, name, m.createNewBranch)
-		} else if m.modalFocused == 2 {
-			// Cancel button
-			m.modal = noModal
-			m.nameInput.Blur()
-			return m, nil
 		}
-	}
+		return m, nil
 
-	// Handle text input for branch name
-	var cmd tea.Cmd
-	if m.modalFocused == 0 && m.createNewBranch {
-		m.nameInput, cmd = m.nameInput.Update(msg)
+	case "enter":
+		// Save the selected theme
+		if m.themeIndex >= 0 && m.themeIndex < len(m.availableThemes) {
+			selectedTheme := m.availableThemes[m.themeIndex]
+			themeName := strings.ToLower(selectedTheme.Name)
+			cmd := m.changeTheme(themeName)
+			m.modal = settingsModal
+			m.settingsIndex = 1
+			return m, cmd
+		}
+		m.modal = settingsModal
+		m.settingsIndex = 1
+		return m, nil
 	}
 
-	return m, cmd
+	return m, nil
 }
 
-func (m Model) handleCreateWithNameModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+func (m Model) handleSettingsModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
-	case "esc":
+	case "esc", "q":
 		m.modal = noModal
-		m.sessionNameInput.Blur()
 		return m, nil
 
-	case "tab", "shift+tab":
-		// Cycle through: sessionNameInput -> create button -> cancel button
-		m.modalFocused = (m.modalFocused + 1) % 3
-		if m.modalFocused == 0 {
-			m.sessionNameInput.Focus()
-		} else {
-			m.sessionNameInput.Blur()
+	case "up":
+		if m.settingsIndex > 0 {
+			m.settingsIndex--
 		}
-		return m, nil
 
-	case "enter":
-		if m.modalFocused == 0 {
-			// In input, move to create button
-			m.modalFocused = 1
-			m.sessionNameInput.Blur()
-			return m, nil
-		} else if m.modalFocused == 1 {
-			// Create button
-			sessionName := m.sessionNameInput.Value()
+	case "down":
+		if m.settingsIndex < 10 { // 11 settings (editor, theme, base branch, AI integration, debug logs, PR default state, auto fetch, update strategy, auto stash, remotes, worktree location)
+			m.settingsIndex++
+		}
 
-			// If empty, generate a random name
-			if sessionName == "" {
-				randomName, err := m.gitManager.GenerateRandomName()
-				if err != nil {
-					cmd := m.showWarningNotification("Failed to generate random name")
-					return m, cmd
-				}
-				sessionName = randomName
-			}
+	case "e":
+		// Quick key for Editor
+		m.settingsIndex = 0
+		msg = tea.KeyMsg{Type: tea.KeyEnter}
+		return m.handleSettingsModalInput(msg)
 
-			// Sanitize the session name to ensure it's a valid branch name
-			sanitizedName := m.sessionManager.SanitizeBranchName(sessionName)
-			if sanitizedName == "" {
-				cmd := m.showWarningNotification("Session name contains no valid characters")
-				return m, cmd
-			}
+	case "h":
+		// Quick key for Theme
+		m.settingsIndex = 1
+		msg = tea.KeyMsg{Type: tea.KeyEnter}
+		return m.handleSettingsModalInput(msg)
 
-			// Generate path from sanitized session name
-			path, err := m.gitManager.GetDefaultPath(sanitizedName)
-			if err != nil {
-				cmd := m.showWarningNotification("Failed to generate workspace path")
-				return m, cmd
-			}
+	case "c":
+		// Quick key for Base Branch
+		m.settingsIndex = 2
+		msg = tea.KeyMsg{Type: tea.KeyEnter}
+		return m.handleSettingsModalInput(msg)
 
-			m.modal = noModal
-			m.sessionNameInput.Blur()
-			notificationMsg := fmt.Sprintf("Creating worktree: %s\n  Path: %s\n  Claude will automatically continue previous conversations", sanitizedName, path)
-			cmd := m.showInfoNotification(notificationMsg)
-			return m, tea.Batch(cmd, m.createWorktreeWithSession(path, sanitizedName, true))
-		} else {
-			// Cancel button (modalFocused == 2)
-			m.modal = noModal
-			m.sessionNameInput.Blur()
-			return m, nil
-		}
-	}
+	case "a":
+		// Quick key for AI Integration
+		m.settingsIndex = 3
+		msg = tea.KeyMsg{Type: tea.KeyEnter}
+		return m.handleSettingsModalInput(msg)
 
-	// Handle text input
-	var cmd tea.Cmd
-	if m.modalFocused == 0 {
-		m.sessionNameInput, cmd = m.sessionNameInput.Update(msg)
-	}
+	case "d":
+		// Quick key for Debug Logs
+		m.settingsIndex = 4
+		msg = tea.KeyMsg{Type: tea.KeyEnter}
+		return m.handleSettingsModalInput(msg)
 
-	return m, cmd
-}
+	case "p":
+		// Quick key for PR Default State
+		m.settingsIndex = 5
+		msg = tea.KeyMsg{Type: tea.KeyEnter}
+		return m.handleSettingsModalInput(msg)
 
-func (m Model) handleDeleteModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "esc", "n":
-		m.modal = noModal
-		return m, nil
+	case "f":
+		// Quick key for Auto Fetch
+		m.settingsIndex = 6
+		msg = tea.KeyMsg{Type: tea.KeyEnter}
+		return m.handleSettingsModalInput(msg)
 
-	case "tab", "left", "right":
-		// If uncommitted changes, we have 3 buttons (Yes/No/Force), otherwise 2 (Yes/No)
-		if m.deleteHasUncommitted {
-			m.modalFocused = (m.modalFocused + 1) % 3
-		} else {
-			m.modalFocused = (m.modalFocused + 1) % 2
-		}
+	case "u":
+		// Quick key for Update Strategy
+		m.settingsIndex = 7
+		msg = tea.KeyMsg{Type: tea.KeyEnter}
+		return m.handleSettingsModalInput(msg)
 
-	case "enter", "y":
-		// If there are uncommitted changes and user hasn't confirmed force
-		if m.deleteHasUncommitted && !m.deleteConfirmForce {
-			// modalFocused: 0 = Yes (blocked), 1 = No, 2 = Force Delete
-			if m.modalFocused == 2 || msg.String() == "f" {
-				// User clicked "Force Delete" - set confirmation flag
-				m.deleteConfirmForce = true
-				return m, m.showWarningNotification("Press 'y' or Enter to confirm force delete")
-			} else if m.modalFocused == 1 || msg.String() == "n" {
-				// User clicked "No" - cancel
-				m.modal = noModal
-				return m, nil
-			} else if m.modalFocused == 0 {
-				// User tried to click "Yes" but it's blocked
-				return m, m.showWarningNotification("Cannot delete: uncommitted changes. Use 'Force Delete' to proceed.")
-			}
-		} else if m.deleteHasUncommitted && m.deleteConfirmForce {
-			// User already confirmed, now execute force delete
-			if m.modalFocused == 0 || msg.String() == "y" {
-				if wt := m.selectedWorktree(); wt != nil {
-					m.modal = noModal
-					return m, m.deleteWorktree(wt.Path, wt.Branch, true) // force = true
+	case "z":
+		// Quick key for Auto Stash
+		m.settingsIndex = 8
+		msg = tea.KeyMsg{Type: tea.KeyEnter}
+		return m.handleSettingsModalInput(msg)
+
+	case "o":
+		// Quick key for Remotes
+		m.settingsIndex = 9
+		msg = tea.KeyMsg{Type: tea.KeyEnter}
+		return m.handleSettingsModalInput(msg)
+
+	case "w":
+		// Quick key for Worktree Location
+		m.settingsIndex = 10
+		msg = tea.KeyMsg{Type: tea.KeyEnter}
+		return m.handleSettingsModalInput(msg)
+
+	case "enter":
+		// Open the selected setting's modal
+		switch m.settingsIndex {
+		case 0:
+			// Editor setting - open editor select modal
+			m.modal = editorSelectModal
+			m.modalFocused = 0
+			m.editorIndex = 0
+
+			// Find current editor in the list
+			if m.configManager != nil {
+				currentEditor := m.configManager.GetEditor(m.repoPath)
+				for i, editor := range m.editors {
+					if editor == currentEditor {
+						m.editorIndex = i
+						break
+					}
 				}
 			}
-			m.modal = noModal
 			return m, nil
-		} else {
-			// No uncommitted changes, normal delete
-			if m.modalFocused == 0 || msg.String() == "y" {
-				if wt := m.selectedWorktree(); wt != nil {
-					m.modal = noModal
-					return m, m.deleteWorktree(wt.Path, wt.Branch, false)
+
+		case 1:
+			// Theme setting - open theme select modal
+			m.modal = themeSelectModal
+			m.modalFocused = 0
+			m.themeIndex = 0
+
+			// Store original theme for preview revert
+			if m.configManager != nil {
+				m.originalTheme = m.configManager.GetTheme(m.repoPath)
+			}
+
+			// Find current theme in the list
+			if m.configManager != nil {
+				currentTheme := m.configManager.GetTheme(m.repoPath)
+				for i, theme := range m.availableThemes {
+					if theme.Name == strings.ToTitle(currentTheme) || theme.Name == strings.Title(currentTheme) {
+						m.themeIndex = i
+						break
+					}
 				}
 			}
-			m.modal = noModal
 			return m, nil
-		}
 
-	case "f":
-		// Shortcut for "Force Delete"
-		if m.deleteHasUncommitted && !m.deleteConfirmForce {
-			m.deleteConfirmForce = true
-			return m, m.showWarningNotification("Press 'y' or Enter to confirm force delete")
-		}
-	}
+		case 2:
+			// Base branch setting - open change base branch modal
+			m.modal = changeBaseBranchModal
+			m.modalFocused = 0
+			m.branchIndex = 0
+			m.searchInput.SetValue("")
+			m.searchInput.Focus()
+			m.filteredBranches = nil
+			return m, m.loadBranches
 
-	return m, nil
-}
+		case 3:
+			// AI Integration setting - open AI settings modal
+			m.modal = aiSettingsModal
+			m.modalFocused = 0
+			m.aiSettingsIndex = 0
+			m.aiAPIKeyInput.Focus()
+			m.aiModalStatus = "" // Clear any previous status
+			return m, nil
 
-func (m Model) handleBranchSelectModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	config := searchModalConfig{
-		onConfirm: func(m Model, branch string) (tea.Model, tea.Cmd) {
-			// Generate random path
-			path, err := m.gitManager.GetDefaultPath(branch)
-			if err != nil {
-				cmd := m.showWarningNotification("Failed to generate workspace path")
-				return m, cmd
+		case 4:
+			// Debug Logs setting - toggle debug logging
+			if m.configManager != nil {
+				enabled := m.configManager.GetDebugLoggingEnabled()
+				if err := m.configManager.SetDebugLoggingEnabled(!enabled); err != nil {
+					cmd := m.showErrorNotification("Failed to save debug logs setting: "+err.Error(), 3*time.Second)
+					return m, cmd
+				}
+				if !enabled {
+					cmd := m.showSuccessNotification("Debug logging enabled", 2*time.Second)
+					return m, cmd
+				} else {
+					cmd := m.showSuccessNotification("Debug logging disabled", 2*time.Second)
+					return m, cmd
+				}
 			}
-			return m, m.createWorktree(path, branch, false)
-		},
-	}
-	return m.handleSearchBasedModalInput(msg, config)
-}
-
-func (m Model) handleCheckoutBranchModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	config := searchModalConfig{
-		onConfirm: func(m Model, branch string) (tea.Model, tea.Cmd) {
-			cmd := m.showInfoNotification("Checking out branch: " + branch)
-			return m, tea.Batch(cmd, m.checkoutBranch(branch))
-		},
-	}
-	return m.handleSearchBasedModalInput(msg, config)
-}
+			return m, nil
 
-func (m Model) handleRenameModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "esc":
-		m.modal = noModal
-		m.nameInput.Blur()
-		// Clear rename generation state when closing modal
-		m.generatingRename = false
-		m.renameSpinnerFrame = 0
-		m.renameModalStatus = ""
-		return m, nil
+		case 5:
+			// PR Default State setting - open PR state settings modal
+			m.modal = prStateSettingsModal
+			m.prStateSettingsCursor = 0
 
-	case "g":
-		// AI-generate branch name (only when focused on buttons, not input field)
-		if m.modalFocused > 0 && m.configManager != nil && m.configManager.GetAnthropicAPIKey() != "" {
-			if wt := m.selectedWorktree(); wt != nil {
-				m.generatingRename = true
-				m.renameSpinnerFrame = 0
-				m.renameModalStatus = ""
-				return m, tea.Batch(
-					m.animateRenameSpinner(),
-					m.generateRenameWithAI(wt.Path, m.baseBranch),
-				)
+			// Set cursor to current setting
+			if m.configManager != nil {
+				prState := m.configManager.GetPRDefaultState(m.repoPath)
+				if prState == "draft" {
+					m.prStateSettingsCursor = 0
+				} else {
+					m.prStateSettingsCursor = 1
+				}
 			}
-		}
-		// If in input field (modalFocused == 0), fall through to handle text input
+			return m, nil
 
-	case "tab", "shift+tab":
-		// Toggle between input and buttons
-		m.modalFocused = (m.modalFocused + 1) % 3
-		if m.modalFocused == 0 {
-			m.nameInput.Focus()
-		} else {
-			m.nameInput.Blur()
-		}
-		return m, nil
+		case 6:
+			// Auto Fetch setting - open auto-fetch interval modal
+			m.modal = autoFetchSettingsModal
+			m.autoFetchSettingsCursor = 1 // Default interval
 
-	case "enter":
-		if m.modalFocused <= 1 {
-			// Rename button or enter in input
-			newName := m.nameInput.Value()
-			if newName == "" {
-				cmd := m.showWarningNotification("Branch name cannot be empty")
-				return m, cmd
+			// Set cursor to current setting
+			if m.configManager != nil {
+				current := m.configManager.GetAutoFetchInterval(m.repoPath)
+				if current == 0 {
+					current = -1 // Disabled
+				}
+				for i, option := range autoFetchIntervalOptions {
+					if option.interval == current {
+						m.autoFetchSettingsCursor = i
+						break
+					}
+				}
 			}
+			return m, nil
 
-			// Sanitize branch name
-			newName = git.SanitizeBranchName(newName)
-			if newName == "" {
-				cmd := m.showWarningNotification("Branch name cannot be empty after sanitization")
-				return m, cmd
+		case 7:
+			// Update Strategy setting - open update strategy modal
+			m.modal = updateStrategySettingsModal
+			m.updateStrategySettingsCursor = 0
+
+			// Set cursor to current setting
+			current := m.getUpdateStrategy()
+			for i, option := range updateStrategyOptions {
+				if option.strategy == current {
+					m.updateStrategySettingsCursor = i
+					break
+				}
 			}
+			return m, nil
 
-			if wt := m.selectedWorktree(); wt != nil {
-				if newName == wt.Branch {
-					cmd := m.showInfoNotification("Branch name unchanged")
-					m.modal = noModal
-					m.nameInput.Blur()
+		case 8:
+			// Auto Stash setting - toggle stashing dirty worktrees during refresh
+			if m.configManager != nil {
+				enabled := m.configManager.GetAutoStash(m.repoPath)
+				if err := m.configManager.SetAutoStash(m.repoPath, !enabled); err != nil {
+					cmd := m.showErrorNotification("Failed to save auto-stash setting: "+err.Error(), 3*time.Second)
 					return m, cmd
 				}
-
-				cmd := m.showInfoNotification(fmt.Sprintf("Renaming branch to '%s'...", newName))
-				m.modal = noModal
-				m.nameInput.Blur()
-				return m, tea.Batch(cmd, m.renameBranch(wt.Branch, newName, wt.Path))
+				if !enabled {
+					cmd := m.showSuccessNotification("Auto-stash enabled: refresh will stash, pull and re-apply changes", 3*time.Second)
+					return m, cmd
+				}
+				cmd := m.showSuccessNotification("Auto-stash disabled: refresh skips worktrees with changes", 3*time.Second)
+				return m, cmd
 			}
-		} else if m.modalFocused == 2 {
-			// Cancel button
-			m.modal = noModal
-			m.nameInput.Blur()
 			return m, nil
-		}
-	}
-
-	// Handle text input
-	var cmd tea.Cmd
-	if m.modalFocused == 0 {
-		m.nameInput, cmd = m.nameInput.Update(msg)
-	}
-
-	return m, cmd
-}
 
-func (m Model) handleChangeBaseBranchModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	config := searchModalConfig{
-		onConfirm: func(m Model, branch string) (tea.Model, tea.Cmd) {
-			m.baseBranch = branch
-			var cmd tea.Cmd
+		case 9:
+			// Remotes setting - open remote picker
+			remotes, err := m.gitManager.Remotes()
+			if err != nil || len(remotes) == 0 {
+				cmd := m.showWarningNotification("No remotes configured for this repository")
+				return m, cmd
+			}
+			m.modal = remoteSettingsModal
+			m.remoteOptions = remotes
+			m.remoteField = 0
+			m.remotePushIndex = indexOf(remotes, m.gitManager.PushRemote())
+			m.remoteBaseIndex = indexOf(remotes, m.gitManager.BaseRemote())
+			return m, nil
 
-			// Save to config
+		case 10:
+			// Worktree Location setting - edit this repository's path template
+			m.modal = worktreeLocationModal
+			m.worktreeLocationGlobal = false
+			m.pathInput.SetValue("")
 			if m.configManager != nil {
-				if err := m.configManager.SetBaseBranch(m.repoPath, branch); err != nil {
-					cmd = m.showWarningNotification("Base branch set to: " + branch + " (warning: failed to save)")
-				} else {
-					cmd = m.showSuccessNotification("Base branch set to: " + branch, 3*time.Second)
-				}
-			} else {
-				cmd = m.showInfoNotification("Base branch set to: " + branch)
+				m.pathInput.SetValue(m.configManager.GetWorktreePathTemplate(m.repoPath))
 			}
-			m.modal = settingsModal
-			m.settingsIndex = 2
-			return m, cmd
-		},
-		onCancel: func(m Model) (tea.Model, tea.Cmd) {
-			// Return to settings modal
-			m.modal = settingsModal
-			m.settingsIndex = 2
+			m.pathInput.CursorEnd()
+			m.pathInput.Focus()
 			return m, nil
-		},
+		}
 	}
-	return m.handleSearchBasedModalInput(msg, config)
+
+	return m, nil
 }
 
-func (m Model) handleCommitModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+func (m Model) handleAutoFetchSettingsModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "esc":
-		m.modal = noModal
-		m.commitSubjectInput.Blur()
+		// Close auto-fetch settings modal and return to settings
+		m.modal = settingsModal
+		m.settingsIndex = 6 // Go back to Auto Fetch option in settings
 		return m, nil
 
-	case "tab", "shift+tab":
-		// Cycle through: subject input -> commit button -> cancel button
-		m.modalFocused = (m.modalFocused + 1) % 3
-
-		// Update focus state
-		if m.modalFocused == 0 {
-			m.commitSubjectInput.Focus()
-		} else {
-			m.commitSubjectInput.Blur()
+	case "up":
+		if m.autoFetchSettingsCursor > 0 {
+			m.autoFetchSettingsCursor--
 		}
 		return m, nil
 
-	case "g":
-		// Generate AI commit message (only if not focused on input field and API key is configured)
-		if m.modalFocused > 0 && m.configManager != nil && m.configManager.GetAnthropicAPIKey() != "" {
-			if wt := m.selectedWorktree(); wt != nil {
-				m.generatingCommit = true
-				m.spinnerFrame = 0
-				m.commitModalStatus = ""
-				return m, tea.Batch(
-					m.animateSpinner(),
-					m.generateCommitMessageWithAI(wt.Path),
-				)
-			}
+	case "down":
+		if m.autoFetchSettingsCursor < len(autoFetchIntervalOptions)-1 {
+			m.autoFetchSettingsCursor++
 		}
-		// If in input field (modalFocused 0), fall through to handle text input
+		return m, nil
 
 	case "enter":
-		if m.modalFocused == 0 {
-			// In subject input, move to commit button
-			m.modalFocused = 1
-			m.commitSubjectInput.Blur()
-			return m, nil
-		} else if m.modalFocused == 1 {
-			// Commit button
-			subject := m.commitSubjectInput.Value()
-			if subject == "" {
-				// If AI commit is enabled and API key is configured, try auto-generate
-				if m.configManager != nil && m.configManager.GetAICommitEnabled() && m.configManager.GetAnthropicAPIKey() != "" {
-					if wt := m.selectedWorktree(); wt != nil {
-						m.generatingCommit = true
-						m.spinnerFrame = 0
-						m.commitModalStatus = ""
-						return m, tea.Batch(
-							m.animateSpinner(),
-							m.generateCommitMessageWithAI(wt.Path),
-						)
-					}
-				} else {
-					// No AI generation, show error
-					cmd := m.showWarningNotification("Commit subject cannot be empty")
-					return m, cmd
-				}
-			}
+		option := autoFetchIntervalOptions[m.autoFetchSettingsCursor]
 
-			if wt := m.selectedWorktree(); wt != nil {
-				cmd := m.showInfoNotification("Creating commit...")
-				m.modal = noModal
-				m.commitSubjectInput.Blur()
-				return m, tea.Batch(cmd, m.createCommit(wt.Path, subject))
+		// Save to config
+		if m.configManager != nil {
+			if err := m.configManager.SetAutoFetchInterval(m.repoPath, option.interval); err != nil {
+				cmd := m.showErrorNotification("Failed to save auto-fetch interval: "+err.Error(), 3*time.Second)
+				return m, cmd
 			}
-		} else {
-			// Cancel button (modalFocused == 2)
-			m.modal = noModal
-			m.commitSubjectInput.Blur()
-			return m, nil
 		}
-	}
 
-	// Handle text input
-	var cmd tea.Cmd
-	if m.modalFocused == 0 {
-		m.commitSubjectInput, cmd = m.commitSubjectInput.Update(msg)
+		// Reset backoff so the new interval takes effect from the next tick
+		m.fetchBackoff = 0
+
+		// Close modal and return to settings
+		m.modal = settingsModal
+		m.settingsIndex = 6
+
+		if option.interval < 0 {
+			return m, m.showSuccessNotification("Background fetch disabled", 2*time.Second)
+		}
+		return m, m.showSuccessNotification("Background fetch interval set to "+option.name, 2*time.Second)
 	}
 
-	return m, cmd
+	return m, nil
 }
 
-func (m Model) handlePRContentModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+func (m Model) handleUpdateStrategySettingsModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "esc":
-		m.modal = noModal
-		m.prTitleInput.Blur()
-		m.prDescriptionInput.Blur()
+		// Close update strategy modal and return to settings
+		m.modal = settingsModal
+		m.settingsIndex = 7 // Go back to Update Strategy option in settings
 		return m, nil
 
-	case "tab", "shift+tab":
-		// Cycle through: title input -> description input -> create button -> cancel button
-		m.prModalFocused = (m.prModalFocused + 1) % 4
-
-		// Update focus state
-		if m.prModalFocused == 0 {
-			m.prTitleInput.Focus()
-			m.prDescriptionInput.Blur()
-		} else if m.prModalFocused == 1 {
-			m.prTitleInput.Blur()
-			m.prDescriptionInput.Focus()
-		} else {
-			m.prTitleInput.Blur()
-			m.prDescriptionInput.Blur()
+	case "up":
+		if m.updateStrategySettingsCursor > 0 {
+			m.updateStrategySettingsCursor--
 		}
 		return m, nil
 
-	case "g":
-		// Generate AI PR content (only if not focused on input fields and API key is configured)
-		if m.prModalFocused > 1 && m.configManager != nil && m.configManager.GetAnthropicAPIKey() != "" {
-			m.generatingPRContent = true
-			m.prSpinnerFrame = 0
-			return m, tea.Batch(
-				m.animateSpinner(),
-				m.generatePRContent(m.prModalWorktreePath, m.prModalBranch, m.baseBranch),
-			)
+	case "down":
+		if m.updateStrategySettingsCursor < len(updateStrategyOptions)-1 {
+			m.updateStrategySettingsCursor++
 		}
-		// If in input field (prModalFocused 0 or 1), fall through to handle text input
+		return m, nil
 
 	case "enter":
-		if m.prModalFocused == 0 {
-			// In title input, move to description
-			m.prModalFocused = 1
-			m.prTitleInput.Blur()
-			m.prDescriptionInput.Focus()
-			return m, nil
-		} else if m.prModalFocused == 1 {
-			// In description input, move to create button
-			m.prModalFocused = 2
-			m.prDescriptionInput.Blur()
-			return m, nil
-		} else if m.prModalFocused == 2 {
-			// Create button
-			title := m.prTitleInput.Value()
-			description := m.prDescriptionInput.Value()
+		option := updateStrategyOptions[m.updateStrategySettingsCursor]
 
-			// Validate title
-			if title == "" {
-				cmd := m.showWarningNotification("PR title cannot be empty")
+		// Save to config
+		if m.configManager != nil {
+			if err := m.configManager.SetUpdateStrategy(m.repoPath, option.strategy); err != nil {
+				cmd := m.showErrorNotification("Failed to save update strategy: "+err.Error(), 3*time.Second)
 				return m, cmd
 			}
-
-			// Create the PR
-			cmd := m.showInfoNotification("Creating draft PR...")
-			m.modal = noModal
-			m.prTitleInput.Blur()
-			m.prDescriptionInput.Blur()
-			return m, tea.Batch(
-				cmd,
-				m.createPR(m.prModalWorktreePath, m.prModalBranch, title, description),
-			)
-		} else {
-			// Cancel button (prModalFocused == 3)
-			m.modal = noModal
-			m.prTitleInput.Blur()
-			m.prDescriptionInput.Blur()
-			return m, nil
 		}
-	}
 
-	// Handle text input
-	var cmd tea.Cmd
-	if m.prModalFocused == 0 {
-		m.prTitleInput, cmd = m.prTitleInput.Update(msg)
-	} else if m.prModalFocused == 1 {
-		m.prDescriptionInput, cmd = m.prDescriptionInput.Update(msg)
+		// Close modal and return to settings
+		m.modal = settingsModal
+		m.settingsIndex = 7
+		return m, m.showSuccessNotification("Update strategy set to "+option.name, 2*time.Second)
 	}
 
-	return m, cmd
+	return m, nil
 }
 
-func (m Model) handlePRListModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+func (m Model) handleRemoteSettingsModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "esc":
-		m.debugLog("handlePRListModalInput: ESC pressed - closing PR list modal")
-		m.modal = noModal
-		m.prListMergeMode = false
-		m.prListCreationMode = false
-		m.prListViewMode = false
-		m.prListIndex = 0
-		m.prSearchInput.Blur()
+		// Close remotes modal and return to settings
+		m.modal = settingsModal
+		m.settingsIndex = 9 // Go back to Remotes option in settings
 		return m, nil
 
-	case "up":
-		if m.prListIndex > 0 {
-			m.prListIndex--
+	case "up", "down", "tab":
+		// Switch between push and base remote
+		m.remoteField = 1 - m.remoteField
+		return m, nil
+
+	case "left", "right", " ":
+		// Cycle through the remotes for the focused field
+		step := 1
+		if msg.String() == "left" {
+			step = len(m.remoteOptions) - 1
+		}
+		if m.remoteField == 0 {
+			m.remotePushIndex = (m.remotePushIndex + step) % len(m.remoteOptions)
+		} else {
+			m.remoteBaseIndex = (m.remoteBaseIndex + step) % len(m.remoteOptions)
 		}
-		m.debugLog(fmt.Sprintf("handlePRListModalInput: UP pressed - prListIndex now %d", m.prListIndex))
 		return m, nil
 
-	case "down":
-		// Get the filtered PR list based on current search
-		filteredList := m.filterPRs(m.prSearchInput.Value())
-		if m.prListIndex < len(filteredList)-1 {
-			m.prListIndex++
+	case "enter":
+		push := m.remoteOptions[m.remotePushIndex]
+		base := m.remoteOptions[m.remoteBaseIndex]
+
+		// Save to config
+		if m.configManager != nil {
+			if err := m.configManager.SetRemotes(m.repoPath, push, base); err != nil {
+				cmd := m.showErrorNotification("Failed to save remotes: "+err.Error(), 3*time.Second)
+				return m, cmd
+			}
 		}
-		m.debugLog(fmt.Sprintf("handlePRListModalInput: DOWN pressed - prListIndex now %d (max %d)", m.prListIndex, len(filteredList)-1))
+		m.applyRemotes()
+
+		// Close modal and return to settings
+		m.modal = settingsModal
+		m.settingsIndex = 9
+		if push == base {
+			return m, m.showSuccessNotification("Pushing to and basing on "+push, 2*time.Second)
+		}
+		return m, m.showSuccessNotification("Pushing to "+push+", PRs target "+base, 2*time.Second)
+	}
+
+	return m, nil
+}
+
+func (m Model) handleWorktreeLocationModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		// Close worktree location modal and return to settings
+		m.modal = settingsModal
+		m.settingsIndex = 10 // Go back to Worktree Location option in settings
+		m.pathInput.Blur()
 		return m, nil
 
 	case "tab":
-		// Cycle focus: search -> list -> OK button -> Cancel button -> search
-		m.modalFocused = (m.modalFocused + 1) % 4
-		if m.modalFocused == 0 {
-			m.prSearchInput.Focus()
-		} else {
-			m.prSearchInput.Blur()
+		// Switch between this repository's template and the global default
+		m.worktreeLocationGlobal = !m.worktreeLocationGlobal
+		if m.configManager != nil {
+			if m.worktreeLocationGlobal {
+				m.pathInput.SetValue(m.configManager.GetGlobalWorktreePathTemplate())
+			} else {
+				m.pathInput.SetValue(m.configManager.GetWorktreePathTemplate(m.repoPath))
+			}
 		}
-		m.debugLog(fmt.Sprintf("handlePRListModalInput: TAB pressed - modalFocused now %d", m.modalFocused))
+		m.pathInput.CursorEnd()
 		return m, nil
 
 	case "enter":
-		// Get filtered PRs for selection
-		filteredList := m.filterPRs(m.prSearchInput.Value())
-		m.debugLog(fmt.Sprintf("handlePRListModalInput: ENTER pressed - filtered list has %d PRs, selected index=%d", len(filteredList), m.prListIndex))
-
-		if m.prListIndex >= len(filteredList) {
-			m.debugLog("handlePRListModalInput: ENTER - index out of bounds, aborting")
-			return m, nil
+		template := strings.TrimSpace(m.pathInput.Value())
+		if err := git.ValidatePathTemplate(template); err != nil {
+			return m, m.showWarningNotification(err.Error())
 		}
 
-		if len(filteredList) == 0 {
-			m.debugLog("handlePRListModalInput: ENTER - no filtered PRs, aborting")
-			return m, nil
+		// Save to config
+		if m.configManager != nil {
+			var err error
+			if m.worktreeLocationGlobal {
+				err = m.configManager.SetGlobalWorktreePathTemplate(template)
+			} else {
+				err = m.configManager.SetWorktreePathTemplate(m.repoPath, template)
+			}
+			if err != nil {
+				cmd := m.showErrorNotification("Failed to save worktree location: "+err.Error(), 3*time.Second)
+				return m, cmd
+			}
 		}
+		m.applyPathTemplate()
 
-		selectedPR := filteredList[m.prListIndex]
-		m.debugLog(fmt.Sprintf("handlePRListModalInput: ENTER - selected PR #%d - %s (branch: %s)", selectedPR.Number, selectedPR.Title, selectedPR.HeadRefName))
+		// Close modal and return to settings
+		m.modal = settingsModal
+		m.settingsIndex = 10
+		m.pathInput.Blur()
+		return m, m.showSuccessNotification("New worktrees will be created at "+m.gitManager.PathTemplate(), 3*time.Second)
+	}
 
-		// Handle creation mode: create worktree from PR branch
-		if m.prListCreationMode {
-			m.debugLog(fmt.Sprintf("handlePRListModalInput: CREATION MODE - creating worktree from PR branch: %s", selectedPR.HeadRefName))
+	var cmd tea.Cmd
+	m.pathInput, cmd = m.pathInput.Update(msg)
+	return m, cmd
+}
 
-			// Store PR info temporarily (will be saved after worktree is created)
-			prCopy := selectedPR // Make a copy to avoid pointer issues
-			m.pendingPRInfo = &prCopy
-			m.debugLog(fmt.Sprintf("handlePRListModalInput: stored pendingPRInfo - PR #%d: %s (URL: %s)", selectedPR.Number, selectedPR.Title, selectedPR.URL))
+func (m Model) handleRelocateModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.modal = noModal
+		m.pathInput.Blur()
+		return m, nil
 
+	case "enter":
+		newPath := strings.TrimSpace(m.pathInput.Value())
+		if newPath == "" {
+			return m, m.showWarningNotification("Path cannot be empty")
+		}
+		if wt := m.selectedWorktree(); wt != nil {
 			m.modal = noModal
-			m.prListCreationMode = false
-			m.prListIndex = 0
-			m.prSearchInput.SetValue("")
-			m.prSearchInput.Blur()
-			cmd := m.showInfoNotification("Creating worktree from PR...")
-			return m, tea.Batch(cmd, m.createWorktreeFromPR(selectedPR.HeadRefName))
-		} else if m.prListViewMode {
-			// Handle view mode: user pressed 'v' and is selecting a PR to view
-			m.debugLog(fmt.Sprintf("handlePRListModalInput: VIEW MODE - opening selected PR in browser: %s", selectedPR.URL))
-			m.modal = noModal
-			m.prListViewMode = false
-			cmd := exec.Command("gh", "pr", "view", selectedPR.URL, "--web")
-			err := cmd.Start()
-			if err != nil {
-				return m, m.showErrorNotification("Failed to open PR in browser: "+err.Error(), 3*time.Second)
-			}
-			return m, m.showSuccessNotification("Opening PR in browser...", 2*time.Second)
-		} else {
-			// Handle merge or default mode (for worktree PRs)
-			wt := m.selectedWorktree()
-			if wt == nil {
-				m.modal = noModal
-				m.prListMergeMode = false
-				return m, nil
-			}
+			m.pathInput.Blur()
+			cmd := m.showInfoNotification("Moving worktree...")
+			return m, tea.Batch(cmd, m.relocateWorktree(wt.Path, newPath))
+		}
+		return m, nil
+	}
 
-			// Get PRs from worktree
-			prList, ok := wt.PRs.([]config.PRInfo)
-			if !ok || len(prList) == 0 {
-				m.modal = noModal
-				m.prListMergeMode = false
-				return m, nil
-			}
+	var cmd tea.Cmd
+	m.pathInput, cmd = m.pathInput.Update(msg)
+	return m, cmd
+}
 
-			// Find matching PR by branch name
-			var selectedConfigPR config.PRInfo
-			for _, pr := range prList {
-				if pr.Branch == selectedPR.HeadRefName {
-					selectedConfigPR = pr
-					break
-				}
-			}
+func (m Model) handleScriptsModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	name := m.selectedScript()
+	run := m.scriptRuns[scriptRunKey(m.scriptsWorktreePath, name)]
+	isService := m.scriptServices[name]
+	service := m.serviceFor(m.scriptsWorktreePath, name)
+
+	switch msg.String() {
+	case "esc", "q":
+		m.modal = noModal
+		return m, nil
+
+	case "up", "k":
+		if m.scriptIndex > 0 {
+			m.scriptIndex--
+		}
+
+	case "down", "j":
+		if m.scriptIndex < len(m.scriptNames)-1 {
+			m.scriptIndex++
+		}
+
+	case "enter":
+		// Run in the foreground: jean exits and the shell wrapper runs the script in the worktree
+		if name == "" {
+			return m, nil
+		}
+		if os.Getenv("JEAN_SWITCH_FILE") == "" {
+			return m, m.showWarningNotification("Foreground runs need the shell wrapper (jean init). Press b to run in the background.")
+		}
+		return m, m.prepareScriptRunner(m.scriptsWorktreePath, m.scriptsBranch, name)
+
+	case "b":
+		// Run in the background with output going to the worktree's log
+		// Services keep running after jean exits and are tracked until stopped
+		if name == "" {
+			return m, nil
+		}
+		if (run != nil && run.Running()) || service != nil {
+			return m, m.showWarningNotification(fmt.Sprintf("'%s' is already running (s to stop)", name))
+		}
+		if isService {
+			return m, m.startService(m.scriptsWorktreePath, m.scriptsBranch, name)
+		}
+		return m, m.startScript(m.scriptsWorktreePath, m.scriptsBranch, name)
+
+	case "r":
+		// Restart a service
+		if isService {
+			return m, m.restartService(m.scriptsWorktreePath, m.scriptsBranch, name)
+		}
 
-			if selectedConfigPR.URL == "" {
-				m.modal = noModal
-				return m, m.showErrorNotification("PR not found in worktree", 3*time.Second)
-			}
+	case "l":
+		// View the output of the latest background run
+		if name != "" {
+			return m, m.openScriptLog(m.scriptsWorktreePath, m.scriptsBranch, name)
+		}
 
-			if m.prListMergeMode {
-				// User is merging a PR - proceed to merge strategy selection
-				m.selectedPRForMerge = selectedConfigPR.URL
-				m.mergeStrategyCursor = 0 // Default to squash
-				m.modal = mergeStrategyModal
-				m.prListMergeMode = false
-				return m, nil
-			} else {
-				// This should not happen, but handle it gracefully
-				m.modal = noModal
-				return m, m.showWarningNotification("Unknown PR modal mode")
+	case "s":
+		// Stop the service or background run
+		if service != nil {
+			return m, m.stopService(m.scriptsWorktreePath, m.scriptsBranch, name)
+		}
+		if run != nil && run.Running() {
+			if err := run.Stop(); err != nil {
+				return m, m.showErrorNotification(err.Error(), 4*time.Second)
 			}
 		}
+	}
 
-	default:
-		// Pass all other input to the search input when it's focused
-		if m.modalFocused == 0 {
-			oldValue := m.prSearchInput.Value()
-			m.prSearchInput, _ = m.prSearchInput.Update(msg)
-			newValue := m.prSearchInput.Value()
+	return m, nil
+}
 
-			// If search value changed, reset list index
-			if oldValue != newValue {
-				m.prListIndex = 0
+func (m Model) handleScriptLogModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	page := m.scriptLogVisibleLines()
+	run := m.scriptLogRun()
+	service := m.serviceFor(m.scriptLogWorktree, m.scriptLogName)
+
+	switch msg.String() {
+	case "esc", "q":
+		m.modal = m.scriptLogBack
+		m.scriptLogLines = nil
+		return m, nil
+
+	case "down", "j":
+		m.scriptLogScroll++
+	case "up", "k":
+		m.scriptLogScroll--
+	case "pgdown", "ctrl+d", " ":
+		m.scriptLogScroll += page / 2
+	case "pgup", "ctrl+u":
+		m.scriptLogScroll -= page / 2
+	case "g", "home":
+		m.scriptLogScroll = 0
+	case "G", "end":
+		m.scriptLogScroll = len(m.scriptLogLines)
+
+	case "s":
+		if service != nil {
+			return m, m.stopService(m.scriptLogWorktree, m.scriptLogBranch, m.scriptLogName)
+		}
+		if run != nil && run.Running() {
+			if err := run.Stop(); err != nil {
+				return m, m.showErrorNotification(err.Error(), 4*time.Second)
 			}
+		}
+		return m, nil
 
-			return m, nil
+	case "r":
+		// Run the script again in the background, or restart the service
+		if m.scriptLogService {
+			return m, m.restartService(m.scriptLogWorktree, m.scriptLogBranch, m.scriptLogName)
 		}
+		if run != nil && run.Running() {
+			return m, m.showWarningNotification(fmt.Sprintf("'%s' is still running (s to stop)", m.scriptLogName))
+		}
+		return m, m.startScript(m.scriptLogWorktree, m.scriptLogBranch, m.scriptLogName)
+
+	default:
 		return m, nil
 	}
+
+	// Following resumes once scrolled back to the end
+	maxScroll := len(m.scriptLogLines) - page
+	m.scriptLogFollow = m.scriptLogScroll >= maxScroll
+	m.clampScriptLogScroll()
+	return m, nil
 }
 
-func (m Model) handleMergeStrategyModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+func (m Model) handlePRStateSettingsModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "esc":
-		m.modal = noModal
-		m.selectedPRForMerge = ""
+		// Close PR state settings modal and return to settings
+		m.modal = settingsModal
+		m.settingsIndex = 5 // Go back to PR Default State option in settings
 		return m, nil
 
 	case "up":
-		if m.mergeStrategyCursor > 0 {
-			m.mergeStrategyCursor--
+		if m.prStateSettingsCursor > 0 {
+			m.prStateSettingsCursor--
 		}
 		return m, nil
 
 	case "down":
-		if m.mergeStrategyCursor < 2 {
-			m.mergeStrategyCursor++
+		if m.prStateSettingsCursor < 1 {
+			m.prStateSettingsCursor++
 		}
 		return m, nil
 
 	case "enter":
-		// User confirmed merge strategy selection
-		if m.selectedPRForMerge == "" {
-			m.modal = noModal
-			return m, m.showErrorNotification("No PR selected for merge", 3*time.Second)
-		}
-
-		// Get the selected merge method
-		mergeStrategies := []string{"squash", "merge", "rebase"}
-		mergeMethod := mergeStrategies[m.mergeStrategyCursor]
-
-		// Get the selected worktree to determine if PR is draft
-		wt := m.selectedWorktree()
-		if wt == nil {
-			m.modal = noModal
-			return m, m.showErrorNotification("No worktree selected", 3*time.Second)
-		}
-
-		// Get the PR info to check its status
-		prs, ok := wt.PRs.([]config.PRInfo)
-		if !ok || len(prs) == 0 {
-			m.modal = noModal
-			return m, m.showErrorNotification("No PR found", 3*time.Second)
+		// User confirmed PR state selection
+		var newState string
+		if m.prStateSettingsCursor == 0 {
+			newState = "draft"
+		} else {
+			newState = "ready"
 		}
 
-		// Find the selected PR
-		selectedPR := config.PRInfo{}
-		for _, pr := range prs {
-			if pr.URL == m.selectedPRForMerge {
-				selectedPR = pr
-				break
+		// Save to config
+		if m.configManager != nil {
+			if err := m.configManager.SetPRDefaultState(m.repoPath, newState); err != nil {
+				cmd := m.showErrorNotification("Failed to save PR default state: "+err.Error(), 3*time.Second)
+				return m, cmd
 			}
 		}
 
-		if selectedPR.URL == "" {
-			m.modal = noModal
-			return m, m.showErrorNotification("PR not found", 3*time.Second)
-		}
+		// Close modal and return to settings
+		m.modal = settingsModal
+		m.settingsIndex = 5
 
-		// Check PR status - if draft, mark as ready first
-		if selectedPR.Status == "draft" {
-			m.modal = noModal
-			m.selectedPRForMerge = ""
-			notifyCmd := m.showInfoNotification("⏳ Marking PR as ready...")
-			return m, tea.Batch(
-				notifyCmd,
-				m.markPRReady(wt.Path, selectedPR.URL),
-			)
+		// Show success notification
+		if newState == "draft" {
+			return m, m.showSuccessNotification("PR default state set to Draft", 2*time.Second)
+		} else {
+			return m, m.showSuccessNotification("PR default state set to Ready for Review", 2*time.Second)
 		}
-
-		// PR is already ready/open - proceed directly to merge
-		m.modal = noModal
-		m.selectedPRForMerge = ""
-		notifyCmd := m.showInfoNotification("⏳ Merging PR with " + mergeMethod + " strategy...")
-		return m, tea.Batch(
-			notifyCmd,
-			m.mergePR(wt.Path, selectedPR.URL, mergeMethod),
-		)
 	}
 
 	return m, nil
 }
 
-func (m Model) handleLocalMergeConfirmModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+func (m Model) handleAISettingsModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
 	switch msg.String() {
-	case "esc":
-		// Cancel merge and close modal
-		m.modal = noModal
-		m.localMergeBranch = ""
-		m.localMergeTarget = ""
-		m.localMergeWorktree = ""
+	case "esc", "q":
+		// Close without saving
+		m.modal = settingsModal
+		m.settingsIndex = 4 // Go back to AI Integration option in settings
 		return m, nil
 
 	case "tab":
-		// Cycle between confirm (0) and cancel (1) buttons
-		m.localMergeFocused = (m.localMergeFocused + 1) % 2
+		// Tab cycles through: API key input (0) -> Model (1) -> AI Commit toggle (2) -> AI Branch toggle (3) -> Test (4) -> Customize Prompts (5) -> Save (6) -> Cancel (7) -> Clear (8) -> back to API key
+		m.aiModalFocusedField = (m.aiModalFocusedField + 1) % 9
+		if m.aiModalFocusedField == 0 {
+			m.aiAPIKeyInput.Focus()
+		} else {
+			m.aiAPIKeyInput.Blur()
+		}
 		return m, nil
 
-	case "left", "shift+tab":
-		// Move focus left (backward)
-		if m.localMergeFocused > 0 {
-			m.localMergeFocused--
+	case "shift+tab":
+		// Shift+Tab goes backwards
+		m.aiModalFocusedField = (m.aiModalFocusedField - 1 + 9) % 9
+		if m.aiModalFocusedField == 0 {
+			m.aiAPIKeyInput.Focus()
 		} else {
-			m.localMergeFocused = 1 // Wrap around to cancel
+			m.aiAPIKeyInput.Blur()
 		}
 		return m, nil
 
-	case "right":
-		// Move focus right (forward)
-		if m.localMergeFocused < 1 {
-			m.localMergeFocused++
-		} else {
-			m.localMergeFocused = 0 // Wrap around to confirm
+	case "up":
+		if m.aiModalFocusedField == 1 && m.aiModelIndex > 0 {
+			// In model selection, move up
+			m.aiModelIndex--
 		}
 		return m, nil
 
-	case "enter":
-		// Execute based on focused button
-		if m.localMergeFocused == 0 {
-			// Confirm button - execute merge
-			m.debugLog(fmt.Sprintf("Local merge confirmed: %s -> %s", m.localMergeBranch, m.localMergeTarget))
+	case "down":
+		if m.aiModalFocusedField == 1 && m.aiModelIndex < len(m.aiModels)-1 {
+			// In model selection, move down
+			m.aiModelIndex++
+		}
+		return m, nil
 
-			branch := m.localMergeBranch
-			target := m.localMergeTarget
-			worktree := m.localMergeWorktree
+	case "space", "enter":
+		if m.aiModalFocusedField == 2 {
+			// Toggle AI commit enabled
+			m.aiCommitEnabled = !m.aiCommitEnabled
+			return m, nil
+		} else if m.aiModalFocusedField == 3 {
+			// Toggle AI branch name enabled
+			m.aiBranchNameEnabled = !m.aiBranchNameEnabled
+			return m, nil
+		} else if m.aiModalFocusedField == 4 {
+			// Test button - test Claude CLI connection
+			cmd := m.showInfoNotification("Testing Claude CLI connection...")
+			return m, tea.Batch(cmd, m.testClaudeConnection())
+		} else if m.aiModalFocusedField == 5 {
+			// Customize Prompts button
+			m.modal = aiPromptsModal
+			m.aiPromptsModalFocus = 0
+			m.aiPromptsStatus = ""
+			// Load current prompts
+			return m, m.loadAIPrompts()
+		} else if m.aiModalFocusedField == 6 {
+			// Save button
+			apiKey := m.aiAPIKeyInput.Value()
+			if apiKey == "" {
+				return m, m.showWarningNotification("API key cannot be empty")
+			}
 
-			// Close modal
-			m.modal = noModal
+			// Save all settings to config
+			var cmd tea.Cmd
+			if m.configManager != nil {
+				if err := m.configManager.SetAnthropicAPIKey(apiKey); err != nil {
+					return m, m.showErrorNotification("Failed to save API key: " + err.Error(), 3*time.Second)
+				}
+				if err := m.configManager.SetClaudeModel(m.aiModels[m.aiModelIndex]); err != nil {
+					return m, m.showErrorNotification("Failed to save model: " + err.Error(), 3*time.Second)
+				}
+				if err := m.configManager.SetAICommitEnabled(m.aiCommitEnabled); err != nil {
+					return m, m.showErrorNotification("Failed to save AI commit setting: " + err.Error(), 3*time.Second)
+				}
+				if err := m.configManager.SetAIBranchNameEnabled(m.aiBranchNameEnabled); err != nil {
+					return m, m.showErrorNotification("Failed to save AI branch name setting: " + err.Error(), 3*time.Second)
+				}
+				savedMsg := "AI settings saved successfully"
+				if os.Getenv("CLAUDE_CODE_OAUTH_TOKEN") == "" {
+					savedMsg = "AI settings saved, API key stored in " + m.configManager.APIKeyLocation()
+				}
+				cmd = m.showSuccessNotification(savedMsg, 3*time.Second)
+			}
 
-			// Show notification and execute merge
-			notifyCmd := m.showInfoNotification(fmt.Sprintf("Merging %s into %s...", branch, target))
-			return m, tea.Batch(
-				notifyCmd,
-				m.executeLocalMerge(worktree, branch, target),
-			)
-		} else {
-			// Cancel button - just close modal
-			m.modal = noModal
-			m.localMergeBranch = ""
-			m.localMergeTarget = ""
-			m.localMergeWorktree = ""
+			// Return to settings modal
+			m.modal = settingsModal
+			m.settingsIndex = 4
+			m.aiAPIKeyInput.Blur()
+			return m, cmd
+		} else if m.aiModalFocusedField == 7 {
+			// Cancel button
+			m.modal = settingsModal
+			m.settingsIndex = 4
+			m.aiAPIKeyInput.Blur()
 			return m, nil
+		} else if m.aiModalFocusedField == 8 {
+			// Clear button - remove API key
+			m.aiAPIKeyInput.SetValue("")
+			if m.configManager != nil {
+				if err := m.configManager.SetAnthropicAPIKey(""); err != nil {
+					return m, m.showErrorNotification("Failed to clear API key: " + err.Error(), 3*time.Second)
+				}
+			}
+			cmd := m.showSuccessNotification("API key cleared - AI features disabled", 2*time.Second)
+			m.modal = settingsModal
+			m.settingsIndex = 4
+			m.aiAPIKeyInput.Blur()
+			return m, cmd
+		}
+
+	default:
+		// If in API key input field, pass keystroke to text input
+		if m.aiModalFocusedField == 0 {
+			m.aiAPIKeyInput, cmd = m.aiAPIKeyInput.Update(msg)
+			return m, cmd
 		}
 	}
 
-	return m, nil
+	return m, cmd
 }
 
-func (m Model) handlePostMergeCleanupModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+// handleAIPromptsModalInput handles input for the AI prompts customization modal
+func (m Model) handleAIPromptsModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
 	switch msg.String() {
-	case "esc":
-		// Close modal without action
-		m.modal = noModal
+	case "esc", "q":
+		// Close without saving
+		m.modal = aiSettingsModal
+		m.aiModalFocusedField = 0
 		return m, nil
 
-	case "up":
-		if m.postMergeDeleteIndex > 0 {
-			m.postMergeDeleteIndex--
-		}
+	case "tab":
+		// Tab cycles through: commit (0) -> branch (1) -> pr (2) -> save (3) -> reset (4) -> cancel (5) -> back to commit
+		m.aiPromptsModalFocus = (m.aiPromptsModalFocus + 1) % 6
+		m.updateAIPromptsInputFocus()
 		return m, nil
 
-	case "down":
-		if m.postMergeDeleteIndex < 1 {
-			m.postMergeDeleteIndex++
-		}
+	case "shift+tab":
+		// Shift+Tab goes backwards
+		m.aiPromptsModalFocus = (m.aiPromptsModalFocus - 1 + 6) % 6
+		m.updateAIPromptsInputFocus()
 		return m, nil
 
 	case "enter":
-		branch := m.localMergeBranch
-		worktree := m.localMergeWorktree
+		if m.aiPromptsModalFocus == 3 {
+			// Save button
+			commitPrompt := m.aiPromptCommitInput.Value()
+			branchPrompt := m.aiPromptBranchInput.Value()
+			prPrompt := m.aiPromptPRInput.Value()
 
-		if m.postMergeDeleteIndex == 0 {
-			// User chose to delete worktree
-			m.debugLog(fmt.Sprintf("Post-merge cleanup: deleting worktree %s (branch: %s)", worktree, branch))
-			m.modal = noModal
+			// Validate that prompts contain {diff} placeholder
+			if !strings.Contains(commitPrompt, "{diff}") {
+				m.aiPromptsStatus = "Commit prompt must contain {diff} placeholder"
+				return m, nil
+			}
+			if !strings.Contains(branchPrompt, "{diff}") {
+				m.aiPromptsStatus = "Branch prompt must contain {diff} placeholder"
+				return m, nil
+			}
+			if !strings.Contains(prPrompt, "{diff}") {
+				m.aiPromptsStatus = "PR prompt must contain {diff} placeholder"
+				return m, nil
+			}
 
-			// Delete the worktree (force=false since we know it's clean)
-			notifyCmd := m.showInfoNotification("Deleting merged worktree...")
-			return m, tea.Batch(
-				notifyCmd,
-				m.deleteWorktree(worktree, branch, false),
-			)
-		} else {
-			// User chose to keep worktree
-			m.debugLog(fmt.Sprintf("Post-merge cleanup: keeping worktree %s", worktree))
-			m.modal = noModal
-			return m, m.showSuccessNotification("Merge complete. Worktree kept for reference.", 3*time.Second)
+			// Save prompts
+			cmd := m.saveAIPrompts(commitPrompt, branchPrompt, prPrompt)
+			return m, cmd
+		} else if m.aiPromptsModalFocus == 4 {
+			// Reset button - show confirmation or just reset
+			cmd := m.resetAIPromptsToDefaults()
+			return m, cmd
+		} else if m.aiPromptsModalFocus == 5 {
+			// Cancel button
+			m.modal = aiSettingsModal
+			m.aiModalFocusedField = 0
+			m.aiPromptCommitInput.Blur()
+			m.aiPromptBranchInput.Blur()
+			m.aiPromptPRInput.Blur()
+			return m, nil
+		}
+
+	default:
+		// Pass keystrokes to the focused text input
+		if m.aiPromptsModalFocus == 0 {
+			m.aiPromptCommitInput, cmd = m.aiPromptCommitInput.Update(msg)
+		} else if m.aiPromptsModalFocus == 1 {
+			m.aiPromptBranchInput, cmd = m.aiPromptBranchInput.Update(msg)
+		} else if m.aiPromptsModalFocus == 2 {
+			m.aiPromptPRInput, cmd = m.aiPromptPRInput.Update(msg)
 		}
 	}
 
-	return m, nil
+	return m, cmd
 }
 
-func (m Model) handlePRTypeModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "esc":
-		m.modal = noModal
-		return m, nil
-
-	case "up":
-		if m.prTypeCursor > 0 {
-			m.prTypeCursor--
-		}
-		return m, nil
+// updateAIPromptsInputFocus updates the focus state for AI prompts modal text inputs
+func (m *Model) updateAIPromptsInputFocus() {
+	// Blur all inputs first
+	m.aiPromptCommitInput.Blur()
+	m.aiPromptBranchInput.Blur()
+	m.aiPromptPRInput.Blur()
 
-	case "down":
-		if m.prTypeCursor < 1 {
-			m.prTypeCursor++
-		}
-		return m, nil
+	// Focus the selected input
+	if m.aiPromptsModalFocus == 0 {
+		m.aiPromptCommitInput.Focus()
+	} else if m.aiPromptsModalFocus == 1 {
+		m.aiPromptBranchInput.Focus()
+	} else if m.aiPromptsModalFocus == 2 {
+		m.aiPromptPRInput.Focus()
+	}
+}
 
-	case "enter":
-		// User confirmed PR type selection
-		// Set isDraft based on selection (0=draft, 1=ready for review)
-		m.prIsDraft = (m.prTypeCursor == 0)
+// buildRefreshStatusMessage constructs a detailed status message based on refresh results
+func buildRefreshStatusMessage(msg refreshWithPullMsg) string {
+	// If everything was already up to date
+	if msg.upToDate && len(msg.updatedBranches) == 0 && !msg.mergedBaseBranch {
+		return "Already up to date (0 new commits)"
+	}
 
-		// Close modal and create PR with commit message as title
-		m.modal = noModal
+	// Build a summary with commit counts
+	var totalCommits int
+	var branchDetails []string
 
-		// Use the last commit message as PR title
-		title := m.lastCommitMessage
-		if title == "" {
-			// Fallback if no commit message available (shouldn't happen in normal flow)
-			title = strings.ReplaceAll(m.prModalBranch, "-", " ")
-			title = strings.ReplaceAll(title, "_", " ")
-			title = strings.TrimSpace(title)
-			if len(title) > 0 {
-				title = strings.ToUpper(title[:1]) + title[1:]
-			}
+	// Add branch-specific updates
+	for branch, commits := range msg.updatedBranches {
+		if commits > 0 {
+			totalCommits += commits
+			branchDetails = append(branchDetails, fmt.Sprintf("%s (+%d)", branch, commits))
 		}
+	}
 
-		// Create PR with commit message as title and empty description
-		cmd := m.showInfoNotification("Creating draft PR...")
-		return m, tea.Batch(cmd, m.createOrUpdatePR(m.prModalWorktreePath, m.prModalBranch, title, ""))
+	// If we merged base branch into worktree, note that
+	if msg.mergedBaseBranch && len(branchDetails) > 0 {
+		return fmt.Sprintf("Pulled %d commits: %s", totalCommits, strings.Join(branchDetails, ", "))
+	} else if msg.mergedBaseBranch {
+		return "Merged base branch into worktree"
 	}
 
-	return m, nil
-}
+	// Summary message
+	if totalCommits == 0 {
+		return "Refreshed (no new commits)"
+	}
 
-func (m Model) handleEditorSelectModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	config := listSelectionConfig{
-		getCurrentIndex: func() int { return m.editorIndex },
-		getItemCount:    func(m Model) int { return len(m.editors) },
-		incrementIndex:  func(m *Model) { m.editorIndex++ },
-		decrementIndex:  func(m *Model) { m.editorIndex-- },
-		onConfirm: func(m Model) (tea.Model, tea.Cmd) {
-			if m.editorIndex >= 0 && m.editorIndex < len(m.editors) {
-				selectedEditor := m.editors[m.editorIndex]
-				if m.configManager != nil {
-					if err := m.configManager.SetEditor(m.repoPath, selectedEditor); err != nil {
-						m.showErrorNotification("Failed to save editor preference", 3*time.Second)
-					} else {
-						m.showSuccessNotification("Editor set to: " + selectedEditor, 3*time.Second)
-					}
-				}
-			}
-			m.modal = settingsModal
-			m.settingsIndex = 0
-			return m, nil
-		},
-		onCancel: func(m Model) (tea.Model, tea.Cmd) {
-			// Return to settings modal
-			m.modal = settingsModal
-			m.settingsIndex = 0
-			return m, nil
-		},
+	if len(branchDetails) == 1 {
+		return fmt.Sprintf("Pulled %d new commits in %s", totalCommits, branchDetails[0])
 	}
-	return m.handleListSelectionModalInput(msg, config)
+
+	return fmt.Sprintf("Pulled %d commits: %s", totalCommits, strings.Join(branchDetails, ", "))
 }
 
-func (m Model) handleThemeSelectModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+func (m Model) handleOnboardingModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
-	case "esc", "q":
-		// Revert to original theme when cancelling
-		if m.originalTheme != "" {
-			ApplyTheme(m.originalTheme)
-		}
-		m.modal = settingsModal
-		m.settingsIndex = 1
-		return m, nil
-
-	case "up":
-		if m.themeIndex > 0 {
-			m.themeIndex--
-			// Apply theme preview immediately
-			selectedTheme := m.availableThemes[m.themeIndex]
-			themeName := strings.ToLower(selectedTheme.Name)
-			if err := ApplyTheme(themeName); err == nil {
-				// Theme applied successfully, UI will refresh with new colors
-			}
-		}
-		return m, nil
-
-	case "down":
-		if m.themeIndex < len(m.availableThemes)-1 {
-			m.themeIndex++
-			// Apply theme preview immediately
-			selectedTheme := m.availableThemes[m.themeIndex]
-			themeName := strings.ToLower(selectedTheme.Name)
-			if err := ApplyTheme(themeName); err == nil {
-				// Theme applied successfully, UI will refresh with new colors
-			}
+	case "esc", "enter":
+		// Complete onboarding
+		if err := m.configManager.SetOnboarded(); err != nil {
+			m.debugLog(fmt.Sprintf("Failed to save onboarding status: %v", err))
 		}
+		m.modal = noModal
 		return m, nil
+	}
 
-	case "enter":
-		// Save the selected theme
-		if m.themeIndex >= 0 && m.themeIndex < len(m.availableThemes) {
-			selectedTheme := m.availableThemes[m.themeIndex]
-			themeName := strings.ToLower(selectedTheme.Name)
-			cmd := m.changeTheme(themeName)
-			m.modal = settingsModal
-			m.settingsIndex = 1
-			return m, cmd
-		}
-		m.modal = settingsModal
-		m.settingsIndex = 1
+	return m, nil
+}
+
+func (m Model) handleGitInitModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "enter":
+		// Initialize git repository
+		return m, m.initGitRepository()
+
+	case "n", "q", "esc":
+		// Quit application
+		return m, tea.Quit
+
+	default:
+		return m, nil
+	}
+}
+
+func (m Model) handleHelperModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "h", "q":
+		// Close helper modal with Esc, h, or q
+		m.modal = noModal
 		return m, nil
 	}
 
 	return m, nil
 }
 
-func (m Model) handleSettingsModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+func (m Model) handleStagingModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.stagingDiffOpen {
+		return m.handleStagingDiffInput(msg)
+	}
+
 	switch msg.String() {
 	case "esc", "q":
+		// Close staging modal
 		m.modal = noModal
-		return m, nil
+		m.stagingFiles = nil
+		m.stagingIndex = 0
+		// Refresh worktrees to update uncommitted changes indicator
+		return m, m.loadWorktrees()
 
-	case "up":
-		if m.settingsIndex > 0 {
-			m.settingsIndex--
+	case "up", "k":
+		// Navigate up
+		if m.stagingIndex > 0 {
+			m.stagingIndex--
 		}
+		return m, nil
 
-	case "down":
-		if m.settingsIndex < 5 { // 6 settings (editor, theme, base branch, AI integration, debug logs, PR default state)
-			m.settingsIndex++
+	case "down", "j":
+		// Navigate down
+		if m.stagingIndex < len(m.stagingFiles)-1 {
+			m.stagingIndex++
 		}
+		return m, nil
 
-	case "e":
-		// Quick key for Editor
-		m.settingsIndex = 0
-		msg = tea.KeyMsg{Type: tea.KeyEnter}
-		return m.handleSettingsModalInput(msg)
-
-	case "h":
-		// Quick key for Theme
-		m.settingsIndex = 1
-		msg = tea.KeyMsg{Type: tea.KeyEnter}
-		return m.handleSettingsModalInput(msg)
+	case "enter", " ":
+		// Toggle staging status of selected file
+		if m.stagingIndex >= 0 && m.stagingIndex < len(m.stagingFiles) {
+			file := m.stagingFiles[m.stagingIndex]
+			if file.IsStaged {
+				// Unstage the file
+				return m, m.unstageFile(file.Path)
+			} else {
+				// Stage the file
+				return m, m.stageFile(file.Path)
+			}
+		}
+		return m, nil
 
-	case "c":
-		// Quick key for Base Branch
-		m.settingsIndex = 2
-		msg = tea.KeyMsg{Type: tea.KeyEnter}
-		return m.handleSettingsModalInput(msg)
+	case "l", "right":
+		// Open the hunk view of the selected file
+		if m.stagingIndex >= 0 && m.stagingIndex < len(m.stagingFiles) {
+			return m, m.openStagingDiff(m.stagingFiles[m.stagingIndex])
+		}
+		return m, nil
 
 	case "a":
-		// Quick key for AI Integration
-		m.settingsIndex = 3
-		msg = tea.KeyMsg{Type: tea.KeyEnter}
-		return m.handleSettingsModalInput(msg)
-
-	case "d":
-		// Quick key for Debug Logs
-		m.settingsIndex = 4
-		msg = tea.KeyMsg{Type: tea.KeyEnter}
-		return m.handleSettingsModalInput(msg)
+		// Stage all files
+		return m, m.stageAllFiles()
 
-	case "p":
-		// Quick key for PR Default State
-		m.settingsIndex = 5
-		msg = tea.KeyMsg{Type: tea.KeyEnter}
-		return m.handleSettingsModalInput(msg)
+	case "u":
+		// Unstage all files
+		return m, m.unstageAllFiles()
 
-	case "enter":
-		// Open the selected setting's modal
-		switch m.settingsIndex {
-		case 0:
-			// Editor setting - open editor select modal
-			m.modal = editorSelectModal
-			m.modalFocused = 0
-			m.editorIndex = 0
+	case "r":
+		// Refresh file list
+		return m, m.loadStagingFiles()
 
-			// Find current editor in the list
-			if m.configManager != nil {
-				currentEditor := m.configManager.GetEditor(m.repoPath)
-				for i, editor := range m.editors {
-					if editor == currentEditor {
-						m.editorIndex = i
-						break
-					}
-				}
+	case "c":
+		// Commit changes (same as main panel)
+		if wt := m.selectedWorktree(); wt != nil {
+			// Check for uncommitted changes
+			hasUncommitted, err := m.gitManager.HasUncommittedChanges(wt.Path)
+			if err != nil {
+				return m, m.showErrorNotification("Failed to check for uncommitted changes: "+err.Error(), 3*time.Second)
 			}
-			return m, nil
-
-		case 1:
-			// Theme setting - open theme select modal
-			m.modal = themeSelectModal
-			m.modalFocused = 0
-			m.themeIndex = 0
-
-			// Store original theme for preview revert
-			if m.configManager != nil {
-				m.originalTheme = m.configManager.GetTheme(m.repoPath)
+			if !hasUncommitted {
+				return m, m.showInfoNotification("Nothing to commit - no uncommitted changes")
 			}
 
-			// Find current theme in the list
-			if m.configManager != nil {
-				currentTheme := m.configManager.GetTheme(m.repoPath)
-				for i, theme := range m.availableThemes {
-					if theme.Name == strings.ToTitle(currentTheme) || theme.Name == strings.Title(currentTheme) {
-						m.themeIndex = i
-						break
-					}
-				}
-			}
-			return m, nil
+			// Check if AI commit generation is enabled
+			aiEnabled := m.configManager.GetAICommitEnabled()
+			apiKey := m.configManager.GetAnthropicAPIKey()
 
-		case 2:
-			// Base branch setting - open change base branch modal
-			m.modal = changeBaseBranchModal
-			m.modalFocused = 0
-			m.branchIndex = 0
-			m.searchInput.SetValue("")
-			m.searchInput.Focus()
-			m.filteredBranches = nil
-			return m, m.loadBranches
+			if aiEnabled && apiKey != "" {
+				// Auto-generate and auto-commit with AI
+				m.generatingCommit = true
+				m.spinnerFrame = 0
+				m.autoCommitWithAI = true
+				m.commitFromStagingModal = true // Track we're from staging modal
+				notifyCmd := m.showInfoNotification("🤖 Generating commit message...")
+				return m, tea.Batch(
+					notifyCmd,
+					m.animateSpinner(),
+					m.generateCommitMessageWithAI(wt.Path),
+				)
+			} else {
+				// Manual commit mode - close staging modal, open commit modal
+				m.modal = commitModal
+				m.modalFocused = 0
+				m.commitSubjectInput.SetValue("")
+				m.commitSubjectInput.Focus()
+				m.commitModalStatus = ""
+				return m, nil
+			}
+		}
+		return m, nil
+	}
 
-		case 3:
-			// AI Integration setting - open AI settings modal
-			m.modal = aiSettingsModal
-			m.modalFocused = 0
-			m.aiSettingsIndex = 0
-			m.aiAPIKeyInput.Focus()
-			m.aiModalStatus = "" // Clear any previous status
-			return m, nil
+	return m, nil
+}
 
-		case 4:
-			// Debug Logs setting - toggle debug logging
-			if m.configManager != nil {
-				enabled := m.configManager.GetDebugLoggingEnabled()
-				if err := m.configManager.SetDebugLoggingEnabled(!enabled); err != nil {
-					cmd := m.showErrorNotification("Failed to save debug logs setting: "+err.Error(), 3*time.Second)
-					return m, cmd
-				}
-				if !enabled {
-					cmd := m.showSuccessNotification("Debug logging enabled", 2*time.Second)
-					return m, cmd
-				} else {
-					cmd := m.showSuccessNotification("Debug logging disabled", 2*time.Second)
-					return m, cmd
-				}
-			}
-			return m, nil
+func (m Model) handleConflictModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Any key other than a second 'A' cancels a pending abort
+	if msg.String() != "A" {
+		m.conflictConfirmAbort = false
+	}
 
-		case 5:
-			// PR Default State setting - open PR state settings modal
-			m.modal = prStateSettingsModal
-			m.prStateSettingsCursor = 0
+	switch msg.String() {
+	case "esc", "q":
+		// Close the modal; the operation stays in progress and can be resumed later
+		m.modal = noModal
+		m.conflictFiles = nil
+		m.conflictVersions = nil
+		if m.conflictOperation != git.OperationMerge {
+			return m, tea.Batch(
+				m.showInfoNotification(operationLabel(m.conflictOperation)+" still in progress. Press 'u' to resume resolving."),
+				m.loadWorktrees(),
+			)
+		}
+		return m, tea.Batch(
+			m.showInfoNotification("Merge still in progress. Press 'u' (or 'L' for local merges) to resume resolving."),
+			m.loadWorktrees(),
+		)
 
-			// Set cursor to current setting
-			if m.configManager != nil {
-				prState := m.configManager.GetPRDefaultState(m.repoPath)
-				if prState == "draft" {
-					m.prStateSettingsCursor = 0
-				} else {
-					m.prStateSettingsCursor = 1
-				}
-			}
-			return m, nil
+	case "up", "k":
+		if m.conflictIndex > 0 {
+			m.conflictIndex--
+			m.conflictVersions = nil
+			m.conflictScroll = 0
+			return m, m.loadConflictVersions()
 		}
-	}
+		return m, nil
 
-	return m, nil
-}
+	case "down", "j":
+		if m.conflictIndex < len(m.conflictFiles)-1 {
+			m.conflictIndex++
+			m.conflictVersions = nil
+			m.conflictScroll = 0
+			return m, m.loadConflictVersions()
+		}
+		return m, nil
 
-func (m Model) handlePRStateSettingsModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "esc":
-		// Close PR state settings modal and return to settings
-		m.modal = settingsModal
-		m.settingsIndex = 5 // Go back to PR Default State option in settings
+	case "tab":
+		// Cycle through ours -> theirs -> base
+		m.conflictView = (m.conflictView + 1) % 3
+		m.conflictScroll = 0
 		return m, nil
 
-	case "up":
-		if m.prStateSettingsCursor > 0 {
-			m.prStateSettingsCursor--
+	case "K", "pgup":
+		// Scroll preview up
+		m.conflictScroll -= 10
+		if m.conflictScroll < 0 {
+			m.conflictScroll = 0
 		}
 		return m, nil
 
-	case "down":
-		if m.prStateSettingsCursor < 1 {
-			m.prStateSettingsCursor++
+	case "J", "pgdown":
+		// Scroll preview down (bounded by the shown version's length)
+		if m.conflictVersions != nil {
+			content := m.conflictVersions.Ours
+			switch m.conflictView {
+			case 1:
+				content = m.conflictVersions.Theirs
+			case 2:
+				content = m.conflictVersions.Base
+			}
+			if m.conflictScroll+10 < strings.Count(content, "\n") {
+				m.conflictScroll += 10
+			}
 		}
 		return m, nil
 
-	case "enter":
-		// User confirmed PR state selection
-		var newState string
-		if m.prStateSettingsCursor == 0 {
-			newState = "draft"
-		} else {
-			newState = "ready"
+	case "o", "t", "m":
+		if m.conflictIndex < 0 || m.conflictIndex >= len(m.conflictFiles) {
+			return m, nil
+		}
+		file := m.conflictFiles[m.conflictIndex]
+		switch msg.String() {
+		case "o":
+			return m, m.resolveConflictFile(file, "ours")
+		case "t":
+			return m, m.resolveConflictFile(file, "theirs")
+		default:
+			return m, m.resolveConflictFile(file, "resolved")
 		}
 
-		// Save to config
-		if m.configManager != nil {
-			if err := m.configManager.SetPRDefaultState(m.repoPath, newState); err != nil {
-				cmd := m.showErrorNotification("Failed to save PR default state: "+err.Error(), 3*time.Second)
-				return m, cmd
-			}
+	case "e":
+		// Open the conflicted file in the configured editor
+		if m.conflictIndex >= 0 && m.conflictIndex < len(m.conflictFiles) {
+			return m, m.openInEditor(filepath.Join(m.conflictWorktreePath, m.conflictFiles[m.conflictIndex]))
 		}
+		return m, nil
 
-		// Close modal and return to settings
-		m.modal = settingsModal
-		m.settingsIndex = 6
+	case "r":
+		// Refresh conflicted files (e.g. after resolving in the editor)
+		return m, m.loadConflictFiles()
 
-		// Show success notification
-		if newState == "draft" {
-			return m, m.showSuccessNotification("PR default state set to Draft", 2*time.Second)
-		} else {
-			return m, m.showSuccessNotification("PR default state set to Ready for Review", 2*time.Second)
+	case "c":
+		// Continue: commit the merge (or continue the rebase) once everything is resolved
+		if len(m.conflictFiles) > 0 {
+			return m, m.showWarningNotification(fmt.Sprintf("%d file(s) still have conflicts", len(m.conflictFiles)))
+		}
+		return m, m.finishConflict(false)
+
+	case "s":
+		// Skip the commit the rebase/cherry-pick stopped on
+		if m.conflictOperation != git.OperationMerge {
+			return m, m.skipConflictCommit()
+		}
+		return m, nil
+
+	case "A":
+		// Abort the operation and return to the previous state
+		// Require a second press, since aborting throws away the resolutions made so far
+		if !m.conflictConfirmAbort {
+			m.conflictConfirmAbort = true
+			return m, nil
 		}
+		m.conflictConfirmAbort = false
+		return m, m.finishConflict(true)
 	}
 
 	return m, nil
 }
 
-func (m Model) handleAISettingsModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	var cmd tea.Cmd
+func (m Model) handleStashModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Typing a message for a new stash
+	if m.stashInputMode {
+		switch msg.String() {
+		case "esc":
+			m.stashInputMode = false
+			m.stashMessageInput.Blur()
+			return m, nil
+
+		case "enter":
+			m.stashInputMode = false
+			m.stashMessageInput.Blur()
+			return m, m.runStashAction("push", strings.TrimSpace(m.stashMessageInput.Value()))
+		}
+
+		var cmd tea.Cmd
+		m.stashMessageInput, cmd = m.stashMessageInput.Update(msg)
+		return m, cmd
+	}
+
+	// Any key other than a second 'd' cancels a pending drop
+	if msg.String() != "d" {
+		m.stashConfirmDrop = false
+	}
 
 	switch msg.String() {
 	case "esc", "q":
-		// Close without saving
-		m.modal = settingsModal
-		m.settingsIndex = 4 // Go back to AI Integration option in settings
+		m.modal = noModal
+		m.stashes = nil
+		m.stashPreview = ""
 		return m, nil
 
-	case "tab":
-		// Tab cycles through: API key input (0) -> Model (1) -> AI Commit toggle (2) -> AI Branch toggle (3) -> Test (4) -> Customize Prompts (5) -> Save (6) -> Cancel (7) -> Clear (8) -> back to API key
-		m.aiModalFocusedField = (m.aiModalFocusedField + 1) % 9
-		if m.aiModalFocusedField == 0 {
-			m.aiAPIKeyInput.Focus()
-		} else {
-			m.aiAPIKeyInput.Blur()
+	case "up", "k":
+		if m.stashIndex > 0 {
+			m.stashIndex--
+			m.stashPreview = ""
+			m.stashScroll = 0
+			return m, m.loadStashPreview()
 		}
 		return m, nil
 
-	case "shift+tab":
-		// Shift+Tab goes backwards
-		m.aiModalFocusedField = (m.aiModalFocusedField - 1 + 9) % 9
-		if m.aiModalFocusedField == 0 {
-			m.aiAPIKeyInput.Focus()
-		} else {
-			m.aiAPIKeyInput.Blur()
+	case "down", "j":
+		if m.stashIndex < len(m.visibleStashes())-1 {
+			m.stashIndex++
+			m.stashPreview = ""
+			m.stashScroll = 0
+			return m, m.loadStashPreview()
 		}
 		return m, nil
 
-	case "up":
-		if m.aiModalFocusedField == 1 && m.aiModelIndex > 0 {
-			// In model selection, move up
-			m.aiModelIndex--
+	case "tab":
+		// Toggle between this branch's stashes and all stashes
+		m.stashShowAll = !m.stashShowAll
+		m.stashIndex = 0
+		m.stashPreview = ""
+		m.stashScroll = 0
+		return m, m.loadStashPreview()
+
+	case "K", "pgup":
+		// Scroll preview up
+		m.stashScroll -= 10
+		if m.stashScroll < 0 {
+			m.stashScroll = 0
 		}
 		return m, nil
 
-	case "down":
-		if m.aiModalFocusedField == 1 && m.aiModelIndex < len(m.aiModels)-1 {
-			// In model selection, move down
-			m.aiModelIndex++
+	case "J", "pgdown":
+		// Scroll preview down (bounded by the preview length)
+		if m.stashScroll+10 < strings.Count(m.stashPreview, "\n") {
+			m.stashScroll += 10
 		}
 		return m, nil
 
-	case "space", "enter":
-		if m.aiModalFocusedField == 2 {
-			// Toggle AI commit enabled
-			m.aiCommitEnabled = !m.aiCommitEnabled
-			return m, nil
-		} else if m.aiModalFocusedField == 3 {
-			// Toggle AI branch name enabled
-			m.aiBranchNameEnabled = !m.aiBranchNameEnabled
-			return m, nil
-		} else if m.aiModalFocusedField == 4 {
-			// Test button - test Claude CLI connection
-			cmd := m.showInfoNotification("Testing Claude CLI connection...")
-			return m, tea.Batch(cmd, m.testClaudeConnection())
-		} else if m.aiModalFocusedField == 5 {
-			// Customize Prompts button
-			m.modal = aiPromptsModal
-			m.aiPromptsModalFocus = 0
-			m.aiPromptsStatus = ""
-			// Load current prompts
-			return m, m.loadAIPrompts()
-		} else if m.aiModalFocusedField == 6 {
-			// Save button
-			apiKey := m.aiAPIKeyInput.Value()
-			if apiKey == "" {
-				return m, m.showWarningNotification("API key cannot be empty")
-			}
-
-			// Save all settings to config
-			var cmd tea.Cmd
-			if m.configManager != nil {
-				if err := m.configManager.SetAnthropicAPIKey(apiKey); err != nil {
-					return m, m.showErrorNotification("Failed to save API key: " + err.Error(), 3*time.Second)
-				}
-				if err := m.configManager.SetClaudeModel(m.aiModels[m.aiModelIndex]); err != nil {
-					return m, m.showErrorNotification("Failed to save model: " + err.Error(), 3*time.Second)
-				}
-				if err := m.configManager.SetAICommitEnabled(m.aiCommitEnabled); err != nil {
-					return m, m.showErrorNotification("Failed to save AI commit setting: " + err.Error(), 3*time.Second)
-				}
-				if err := m.configManager.SetAIBranchNameEnabled(m.aiBranchNameEnabled); err != nil {
-					return m, m.showErrorNotification("Failed to save AI branch name setting: " + err.Error(), 3*time.Second)
-				}
-				cmd = m.showSuccessNotification("AI settings saved successfully", 2*time.Second)
-			}
+	case "n":
+		// Stash current changes with a message
+		m.stashInputMode = true
+		m.stashMessageInput.SetValue("")
+		m.stashMessageInput.Focus()
+		return m, nil
 
-			// Return to settings modal
-			m.modal = settingsModal
-			m.settingsIndex = 4
-			m.aiAPIKeyInput.Blur()
-			return m, cmd
-		} else if m.aiModalFocusedField == 7 {
-			// Cancel button
-			m.modal = settingsModal
-			m.settingsIndex = 4
-			m.aiAPIKeyInput.Blur()
+	case "a", "p":
+		stash := m.selectedStash()
+		if stash == nil {
 			return m, nil
-		} else if m.aiModalFocusedField == 8 {
-			// Clear button - remove API key
-			m.aiAPIKeyInput.SetValue("")
-			if m.configManager != nil {
-				if err := m.configManager.SetAnthropicAPIKey(""); err != nil {
-					return m, m.showErrorNotification("Failed to clear API key: " + err.Error(), 3*time.Second)
-				}
-			}
-			cmd := m.showSuccessNotification("API key cleared - AI features disabled", 2*time.Second)
-			m.modal = settingsModal
-			m.settingsIndex = 4
-			m.aiAPIKeyInput.Blur()
-			return m, cmd
 		}
+		if msg.String() == "a" {
+			return m, m.runStashAction("apply", stash.Ref)
+		}
+		return m, m.runStashAction("pop", stash.Ref)
 
-	default:
-		// If in API key input field, pass keystroke to text input
-		if m.aiModalFocusedField == 0 {
-			m.aiAPIKeyInput, cmd = m.aiAPIKeyInput.Update(msg)
-			return m, cmd
+	case "d":
+		stash := m.selectedStash()
+		if stash == nil {
+			return m, nil
 		}
+		// Require a second press to drop, since dropped stashes are hard to recover
+		if !m.stashConfirmDrop {
+			m.stashConfirmDrop = true
+			return m, nil
+		}
+		m.stashConfirmDrop = false
+		return m, m.runStashAction("drop", stash.Ref)
+
+	case "r":
+		return m, m.loadStashes()
 	}
 
-	return m, cmd
+	return m, nil
 }
 
-// handleAIPromptsModalInput handles input for the AI prompts customization modal
-func (m Model) handleAIPromptsModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	var cmd tea.Cmd
+// handleStagingDiffInput handles keys in the hunk view of the staging modal
+func (m Model) handleStagingDiffInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var hunk *git.DiffHunk
+	if m.stagingDiff != nil && m.stagingHunkIndex >= 0 && m.stagingHunkIndex < len(m.stagingDiff.Hunks) {
+		hunk = &m.stagingDiff.Hunks[m.stagingHunkIndex]
+	}
 
 	switch msg.String() {
-	case "esc", "q":
-		// Close without saving
-		m.modal = aiSettingsModal
-		m.aiModalFocusedField = 0
-		return m, nil
+	case "esc", "h", "left":
+		if m.stagingLineMode {
+			// Leave line selection first
+			m.stagingLineMode = false
+			m.stagingLineSelected = nil
+			return m, nil
+		}
+		// Back to the file list
+		m.stagingDiffOpen = false
+		m.stagingDiff = nil
+		return m, m.loadStagingFiles()
 
-	case "tab":
-		// Tab cycles through: commit (0) -> branch (1) -> pr (2) -> save (3) -> reset (4) -> cancel (5) -> back to commit
-		m.aiPromptsModalFocus = (m.aiPromptsModalFocus + 1) % 6
-		m.updateAIPromptsInputFocus()
+	case "up", "k":
+		if m.stagingLineMode && hunk != nil {
+			if i := prevChangeLine(hunk.Lines, m.stagingLineIndex); i >= 0 {
+				m.stagingLineIndex = i
+			}
+			return m, nil
+		}
+		if m.stagingHunkIndex > 0 {
+			m.stagingHunkIndex--
+		}
 		return m, nil
 
-	case "shift+tab":
-		// Shift+Tab goes backwards
-		m.aiPromptsModalFocus = (m.aiPromptsModalFocus - 1 + 6) % 6
-		m.updateAIPromptsInputFocus()
+	case "down", "j":
+		if m.stagingLineMode && hunk != nil {
+			if i := nextChangeLine(hunk.Lines, m.stagingLineIndex); i >= 0 {
+				m.stagingLineIndex = i
+			}
+			return m, nil
+		}
+		if m.stagingDiff != nil && m.stagingHunkIndex < len(m.stagingDiff.Hunks)-1 {
+			m.stagingHunkIndex++
+		}
 		return m, nil
 
-	case "enter":
-		if m.aiPromptsModalFocus == 3 {
-			// Save button
-			commitPrompt := m.aiPromptCommitInput.Value()
-			branchPrompt := m.aiPromptBranchInput.Value()
-			prPrompt := m.aiPromptPRInput.Value()
-
-			// Validate that prompts contain {diff} placeholder
-			if !strings.Contains(commitPrompt, "{diff}") {
-				m.aiPromptsStatus = "Commit prompt must contain {diff} placeholder"
-				return m, nil
-			}
-			if !strings.Contains(branchPrompt, "{diff}") {
-				m.aiPromptsStatus = "Branch prompt must contain {diff} placeholder"
-				return m, nil
-			}
-			if !strings.Contains(prPrompt, "{diff}") {
-				m.aiPromptsStatus = "PR prompt must contain {diff} placeholder"
-				return m, nil
-			}
+	case "tab":
+		// Switch between unstaged and staged changes of the file
+		m.stagingDiffStaged = !m.stagingDiffStaged
+		m.stagingDiff = nil
+		m.stagingHunkIndex = 0
+		m.stagingLineMode = false
+		m.stagingLineSelected = nil
+		return m, m.loadStagingDiff()
 
-			// Save prompts
-			cmd := m.saveAIPrompts(commitPrompt, branchPrompt, prPrompt)
-			return m, cmd
-		} else if m.aiPromptsModalFocus == 4 {
-			// Reset button - show confirmation or just reset
-			cmd := m.resetAIPromptsToDefaults()
-			return m, cmd
-		} else if m.aiPromptsModalFocus == 5 {
-			// Cancel button
-			m.modal = aiSettingsModal
-			m.aiModalFocusedField = 0
-			m.aiPromptCommitInput.Blur()
-			m.aiPromptBranchInput.Blur()
-			m.aiPromptPRInput.Blur()
+	case "v":
+		// Toggle line selection within the hunk
+		if hunk == nil {
 			return m, nil
 		}
+		m.stagingLineMode = !m.stagingLineMode
+		m.stagingLineSelected = nil
+		if m.stagingLineMode {
+			m.stagingLineSelected = make(map[int]bool)
+			m.stagingLineIndex = nextChangeLine(hunk.Lines, -1)
+		}
+		return m, nil
 
-	default:
-		// Pass keystrokes to the focused text input
-		if m.aiPromptsModalFocus == 0 {
-			m.aiPromptCommitInput, cmd = m.aiPromptCommitInput.Update(msg)
-		} else if m.aiPromptsModalFocus == 1 {
-			m.aiPromptBranchInput, cmd = m.aiPromptBranchInput.Update(msg)
-		} else if m.aiPromptsModalFocus == 2 {
-			m.aiPromptPRInput, cmd = m.aiPromptPRInput.Update(msg)
+	case " ", "enter":
+		if hunk == nil {
+			return m, nil
+		}
+
+		// In line mode space toggles the line under the cursor
+		if m.stagingLineMode && msg.String() == " " {
+			if m.stagingLineSelected[m.stagingLineIndex] {
+				delete(m.stagingLineSelected, m.stagingLineIndex)
+			} else {
+				m.stagingLineSelected[m.stagingLineIndex] = true
+			}
+			return m, nil
+		}
+
+		// Whole hunk, or the selected lines (the cursor line if nothing is selected)
+		var selected func(int) bool
+		if m.stagingLineMode {
+			lines := m.stagingLineSelected
+			if len(lines) == 0 {
+				lines = map[int]bool{m.stagingLineIndex: true}
+			}
+			selected = func(i int) bool { return lines[i] }
+		}
+		patch, err := m.stagingDiff.BuildHunkPatch(m.stagingHunkIndex, selected, m.stagingDiffStaged)
+		if err != nil {
+			return m, m.showWarningNotification(err.Error())
 		}
+		return m, m.applyStagingPatch(patch)
 	}
 
-	return m, cmd
+	return m, nil
 }
 
-// updateAIPromptsInputFocus updates the focus state for AI prompts modal text inputs
-func (m *Model) updateAIPromptsInputFocus() {
-	// Blur all inputs first
-	m.aiPromptCommitInput.Blur()
-	m.aiPromptBranchInput.Blur()
-	m.aiPromptPRInput.Blur()
-
-	// Focus the selected input
-	if m.aiPromptsModalFocus == 0 {
-		m.aiPromptCommitInput.Focus()
-	} else if m.aiPromptsModalFocus == 1 {
-		m.aiPromptBranchInput.Focus()
-	} else if m.aiPromptsModalFocus == 2 {
-		m.aiPromptPRInput.Focus()
+// resumeLocalMerge reopens the conflicts of a local merge of merging ("" if unknown) that stopped in the main repo
+// Only the worktree of the branch being merged resumes it, so cleanup and hooks apply to the right one
+func (m Model) resumeLocalMerge(wt *git.Worktree, repoRoot, merging string) (tea.Model, tea.Cmd) {
+	if merging == "" {
+		return m, m.showWarningNotification("A merge is in progress in the main repository. Finish or abort it there first.")
 	}
+	if merging != wt.Branch {
+		return m, m.showWarningNotification(fmt.Sprintf("A merge of %s is in progress in the main repository. Select its worktree and press 'L' to resume it.", merging))
+	}
+	m.localMergeBranch = wt.Branch
+	m.localMergeTarget = m.baseBranch
+	m.localMergeWorktree = wt.Path
+	m.conflictFromLocalMerge = true
+	return m, m.openConflictModal(repoRoot)
 }
 
-// buildRefreshStatusMessage constructs a detailed status message based on refresh results
-func buildRefreshStatusMessage(msg refreshWithPullMsg) string {
-	// If everything was already up to date
-	if msg.upToDate && len(msg.updatedBranches) == 0 && !msg.mergedBaseBranch {
-		return "Already up to date (0 new commits)"
+// nextChangeLine returns the index of the first added/removed line after i, or -1
+func nextChangeLine(lines []string, i int) int {
+	for j := i + 1; j < len(lines); j++ {
+		if git.IsChangeLine(lines[j]) {
+			return j
+		}
 	}
+	return -1
+}
 
-	// Build a summary with commit counts
-	var totalCommits int
-	var branchDetails []string
-
-	// Add branch-specific updates
-	for branch, commits := range msg.updatedBranches {
-		if commits > 0 {
-			totalCommits += commits
-			branchDetails = append(branchDetails, fmt.Sprintf("%s (+%d)", branch, commits))
+// prevChangeLine returns the index of the last added/removed line before i, or -1
+func prevChangeLine(lines []string, i int) int {
+	for j := i - 1; j >= 0; j-- {
+		if git.IsChangeLine(lines[j]) {
+			return j
 		}
 	}
+	return -1
+}
 
-	// If we merged base branch into worktree, note that
-	if msg.mergedBaseBranch && len(branchDetails) > 0 {
-		return fmt.Sprintf("Pulled %d commits: %s", totalCommits, strings.Join(branchDetails, ", "))
-	} else if msg.mergedBaseBranch {
-		return "Merged base branch into worktree"
-	}
+func (m Model) handleDiffViewerInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	page := m.diffViewerVisibleLines()
 
-	// Summary message
-	if totalCommits == 0 {
-		return "Refreshed (no new commits)"
-	}
+	switch msg.String() {
+	case "esc", "q":
+		m.modal = noModal
+		m.diffViewerFiles = nil
+		return m, nil
 
-	if len(branchDetails) == 1 {
-		return fmt.Sprintf("Pulled %d new commits in %s", totalCommits, branchDetails[0])
-	}
+	case "down", "j":
+		m.diffViewerScroll++
+	case "up", "k":
+		m.diffViewerScroll--
+	case "pgdown", "ctrl+d", " ":
+		m.diffViewerScroll += page / 2
+	case "pgup", "ctrl+u":
+		m.diffViewerScroll -= page / 2
+	case "g", "home":
+		m.diffViewerScroll = 0
+	case "G", "end":
+		if file := m.selectedDiffFile(); file != nil {
+			lines, _ := fileDiffLines(file)
+			m.diffViewerScroll = len(lines)
+		}
+
+	case "tab", "J", "]":
+		// Next file
+		if m.diffViewerIndex < len(m.diffViewerFiles)-1 {
+			m.diffViewerIndex++
+			m.diffViewerScroll = 0
+		}
+	case "shift+tab", "K", "[":
+		// Previous file
+		if m.diffViewerIndex > 0 {
+			m.diffViewerIndex--
+			m.diffViewerScroll = 0
+		}
 
-	return fmt.Sprintf("Pulled %d commits: %s", totalCommits, strings.Join(branchDetails, ", "))
-}
+	case "n":
+		// Next hunk, continuing into the next file
+		if file := m.selectedDiffFile(); file != nil {
+			_, hunkStarts := fileDiffLines(file)
+			for _, start := range hunkStarts {
+				if start > m.diffViewerScroll {
+					// Hunks near the end may already be visible without scrolling further
+					previous := m.diffViewerScroll
+					m.diffViewerScroll = start
+					m.clampDiffViewerScroll()
+					if m.diffViewerScroll != previous {
+						return m, nil
+					}
+					break
+				}
+			}
+			if m.diffViewerIndex < len(m.diffViewerFiles)-1 {
+				m.diffViewerIndex++
+				m.diffViewerScroll = 0
+			}
+		}
+	case "p":
+		// Previous hunk, continuing into the previous file
+		if file := m.selectedDiffFile(); file != nil {
+			_, hunkStarts := fileDiffLines(file)
+			for i := len(hunkStarts) - 1; i >= 0; i-- {
+				if hunkStarts[i] < m.diffViewerScroll {
+					m.diffViewerScroll = hunkStarts[i]
+					m.clampDiffViewerScroll()
+					return m, nil
+				}
+			}
+			if m.diffViewerIndex > 0 {
+				m.diffViewerIndex--
+				_, hunkStarts = fileDiffLines(m.diffViewerFiles[m.diffViewerIndex])
+				m.diffViewerScroll = 0
+				if len(hunkStarts) > 0 {
+					m.diffViewerScroll = hunkStarts[len(hunkStarts)-1]
+				}
+			}
+		}
 
-func (m Model) handleOnboardingModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "esc", "enter":
-		// Complete onboarding
-		if err := m.configManager.SetOnboarded(); err != nil {
-			m.debugLog(fmt.Sprintf("Failed to save onboarding status: %v", err))
+	case "1", "2", "3", "m":
+		// Switch between unstaged, staged and versus-base changes
+		modes := []string{git.DiffUnstaged, git.DiffStaged, git.DiffBase}
+		mode := m.diffViewerMode
+		switch msg.String() {
+		case "1", "2", "3":
+			mode = modes[msg.String()[0]-'1']
+		default:
+			for i, candidate := range modes {
+				if candidate == m.diffViewerMode {
+					mode = modes[(i+1)%len(modes)]
+					break
+				}
+			}
+		}
+		if mode == git.DiffBase && m.baseBranch == "" {
+			return m, m.showWarningNotification("Base branch not set. Press 'b' to set base branch")
+		}
+		if mode != m.diffViewerMode {
+			m.diffViewerMode = mode
+			m.diffViewerFiles = nil
+			m.diffViewerIndex = 0
+			m.diffViewerScroll = 0
+			return m, m.loadDiffViewer()
+		}
+
+	case "r":
+		return m, m.loadDiffViewer()
+
+	case "e":
+		// Open the selected file in the configured editor
+		if file := m.selectedDiffFile(); file != nil {
+			return m, m.openInEditor(filepath.Join(m.diffViewerPath, file.Path))
 		}
-		m.modal = noModal
-		return m, nil
 	}
 
+	m.clampDiffViewerScroll()
 	return m, nil
 }
 
-func (m Model) handleGitInitModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "y", "enter":
-		// Initialize git repository
-		return m, m.initGitRepository()
+// clampDiffViewerScroll keeps the diff viewer scroll position within the selected file's diff
+func (m *Model) clampDiffViewerScroll() {
+	maxScroll := 0
+	if file := m.selectedDiffFile(); file != nil {
+		lines, _ := fileDiffLines(file)
+		maxScroll = len(lines) - m.diffViewerVisibleLines()
+	}
+	if m.diffViewerScroll > maxScroll {
+		m.diffViewerScroll = maxScroll
+	}
+	if m.diffViewerScroll < 0 {
+		m.diffViewerScroll = 0
+	}
+}
 
-	case "n", "q", "esc":
-		// Quit application
-		return m, tea.Quit
+func (m Model) handleLogModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Choosing the worktree to cherry-pick onto
+	if m.logPickTarget {
+		targets := m.cherryPickTargets()
+		switch msg.String() {
+		case "esc", "q":
+			m.logPickTarget = false
+		case "up", "k":
+			if m.logTargetIndex > 0 {
+				m.logTargetIndex--
+			}
+		case "down", "j":
+			if m.logTargetIndex < len(targets)-1 {
+				m.logTargetIndex++
+			}
+		case "enter":
+			if m.logTargetIndex < len(targets) {
+				m.logPickTarget = false
+				target := targets[m.logTargetIndex]
+				hashes := m.cherryPickCommits()
+				return m, tea.Batch(
+					m.showInfoNotification(fmt.Sprintf("Cherry-picking %d commit(s) onto %s...", len(hashes), target.Branch)),
+					m.cherryPickOnto(target, hashes),
+				)
+			}
+		}
+		return m, nil
+	}
 
-	default:
+	// Viewing the full diff of a commit
+	if m.logDiffHash != "" {
+		page := m.logDiffVisibleLines()
+		switch msg.String() {
+		case "esc", "q", "h", "left":
+			m.logDiffHash = ""
+			m.logDiff = nil
+			return m, nil
+		case "down", "j":
+			m.logDiffScroll++
+		case "up", "k":
+			m.logDiffScroll--
+		case "pgdown", "ctrl+d", " ":
+			m.logDiffScroll += page / 2
+		case "pgup", "ctrl+u":
+			m.logDiffScroll -= page / 2
+		case "g", "home":
+			m.logDiffScroll = 0
+		case "G", "end":
+			m.logDiffScroll = len(m.logDiff)
+		}
+		if maxScroll := len(m.logDiff) - page; m.logDiffScroll > maxScroll {
+			m.logDiffScroll = maxScroll
+		}
+		if m.logDiffScroll < 0 {
+			m.logDiffScroll = 0
+		}
 		return m, nil
 	}
-}
 
-func (m Model) handleHelperModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
-	case "esc", "h", "q":
-		// Close helper modal with Esc, h, or q
+	case "esc", "q":
 		m.modal = noModal
+		m.logEntries = nil
+		m.logSelected = nil
+		return m, nil
+
+	case "down", "j":
+		m.moveLogCursor(1)
+	case "up", "k":
+		m.moveLogCursor(-1)
+	case "pgdown", "ctrl+d":
+		m.moveLogCursor(10)
+	case "pgup", "ctrl+u":
+		m.moveLogCursor(-10)
+	case "g", "home":
+		m.logIndex = 0
+		if m.selectedLogEntry() == nil {
+			m.moveLogCursor(1)
+		}
+	case "G", "end":
+		m.logIndex = len(m.logEntries) - 1
+		if m.selectedLogEntry() == nil {
+			m.moveLogCursor(-1)
+		}
+
+	case "enter", "l", "right":
+		// Show the full diff of the selected commit
+		if entry := m.selectedLogEntry(); entry != nil {
+			m.logDiffHash = entry.Hash
+			m.logDiff = nil
+			m.logDiffScroll = 0
+			return m, m.loadCommitDiff(entry.Hash)
+		}
+
+	case " ":
+		// Mark/unmark the commit for cherry-picking
+		if entry := m.selectedLogEntry(); entry != nil {
+			if m.logSelected[entry.Hash] {
+				delete(m.logSelected, entry.Hash)
+			} else {
+				m.logSelected[entry.Hash] = true
+			}
+			m.moveLogCursor(1)
+		}
+
+	case "c":
+		// Cherry-pick the marked commits (or the selected one) onto another worktree's branch
+		if len(m.cherryPickCommits()) == 0 {
+			return m, nil
+		}
+		if len(m.cherryPickTargets()) == 0 {
+			return m, m.showWarningNotification("No other worktree to cherry-pick onto")
+		}
+		m.logPickTarget = true
+		m.logTargetIndex = 0
 		return m, nil
+
+	case "r":
+		// Reload from the top, keeping marked commits
+		if m.logLoading {
+			return m, nil
+		}
+		m.logEntries = nil
+		m.logCommits = 0
+		m.logHasMore = true
+		m.logLoading = false
+		m.logIndex = 0
+		return m, m.loadLogPage()
 	}
 
+	// Load the next page when the selection gets close to the end
+	if m.logIndex >= len(m.logEntries)-10 {
+		return m, m.loadLogPage()
+	}
 	return m, nil
 }
 
-func (m Model) handleStagingModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
+func (m Model) handleRepairModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	item := m.selectedRepairItem()
+	key := msg.String()
+	if key != "d" {
+		m.repairConfirmDel = false
+	}
+
+	switch key {
 	case "esc", "q":
-		// Close staging modal
 		m.modal = noModal
-		m.stagingFiles = nil
-		m.stagingIndex = 0
-		// Refresh worktrees to update uncommitted changes indicator
-		return m, m.loadWorktrees()
+		m.repairItems = nil
+		return m, nil
 
 	case "up", "k":
-		// Navigate up
-		if m.stagingIndex > 0 {
-			m.stagingIndex--
+		if m.repairIndex > 0 {
+			m.repairIndex--
 		}
-		return m, nil
 
 	case "down", "j":
-		// Navigate down
-		if m.stagingIndex < len(m.stagingFiles)-1 {
-			m.stagingIndex++
+		if m.repairIndex < len(m.repairItems)-1 {
+			m.repairIndex++
 		}
-		return m, nil
 
-	case "enter", " ":
-		// Toggle staging status of selected file
-		if m.stagingIndex >= 0 && m.stagingIndex < len(m.stagingFiles) {
-			file := m.stagingFiles[m.stagingIndex]
-			if file.IsStaged {
-				// Unstage the file
-				return m, m.unstageFile(file.Path)
-			} else {
-				// Stage the file
-				return m, m.stageFile(file.Path)
+	case "p":
+		// Prune the missing worktree and clear its config entries
+		if item != nil && !item.orphan {
+			return m, m.runRepairAction("prune", []repairItem{*item})
+		}
+
+	case "P":
+		// Prune every missing worktree
+		var missing []repairItem
+		for _, candidate := range m.repairItems {
+			if !candidate.orphan {
+				missing = append(missing, candidate)
 			}
 		}
-		return m, nil
+		if len(missing) > 0 {
+			return m, m.runRepairAction("prune", missing)
+		}
 
 	case "a":
-		// Stage all files
-		return m, m.stageAllFiles()
+		// Re-create the missing worktree by checking out its branch again
+		if item != nil && !item.orphan {
+			if item.branch == "" || strings.HasPrefix(item.branch, "(detached") {
+				return m, m.showWarningNotification("Detached worktrees can't be re-added, prune them instead")
+			}
+			return m, m.runRepairAction("re-add", []repairItem{*item})
+		}
 
-	case "u":
-		// Unstage all files
-		return m, m.unstageAllFiles()
+	case "d":
+		// Delete the orphaned directory (press twice to confirm)
+		if item != nil && item.orphan {
+			if !m.repairConfirmDel {
+				m.repairConfirmDel = true
+				return m, nil
+			}
+			m.repairConfirmDel = false
+			return m, m.runRepairAction("delete", []repairItem{*item})
+		}
 
 	case "r":
-		// Refresh file list
-		return m, m.loadStagingFiles()
+		m.repairItems = nil
+		return m, m.scanRepairItems()
+	}
 
-	case "c":
-		// Commit changes (same as main panel)
-		if wt := m.selectedWorktree(); wt != nil {
-			// Check for uncommitted changes
-			hasUncommitted, err := m.gitManager.HasUncommittedChanges(wt.Path)
-			if err != nil {
-				return m, m.showErrorNotification("Failed to check for uncommitted changes: "+err.Error(), 3*time.Second)
-			}
-			if !hasUncommitted {
-				return m, m.showInfoNotification("Nothing to commit - no uncommitted changes")
-			}
+	return m, nil
+}
 
-			// Check if AI commit generation is enabled
-			aiEnabled := m.configManager.GetAICommitEnabled()
-			apiKey := m.configManager.GetAnthropicAPIKey()
+func (m Model) handleCleanupModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Ignore input while worktrees are being removed
+	if m.cleanupRunning {
+		return m, nil
+	}
 
-			if aiEnabled && apiKey != "" {
-				// Auto-generate and auto-commit with AI
-				m.generatingCommit = true
-				m.spinnerFrame = 0
-				m.autoCommitWithAI = true
-				m.commitFromStagingModal = true // Track we're from staging modal
-				notifyCmd := m.showInfoNotification("🤖 Generating commit message...")
-				return m, tea.Batch(
-					notifyCmd,
-					m.animateSpinner(),
-					m.generateCommitMessageWithAI(wt.Path),
-				)
+	key := msg.String()
+	if key != "enter" {
+		m.cleanupConfirm = false
+	}
+
+	switch key {
+	case "esc", "q":
+		m.modal = noModal
+		m.cleanupCandidates = nil
+		m.cleanupSelected = nil
+		return m, nil
+
+	case "up", "k":
+		if m.cleanupIndex > 0 {
+			m.cleanupIndex--
+		}
+
+	case "down", "j":
+		if m.cleanupIndex < len(m.cleanupCandidates)-1 {
+			m.cleanupIndex++
+		}
+
+	case " ":
+		// Check/uncheck the selected candidate
+		if m.cleanupIndex < len(m.cleanupCandidates) {
+			path := m.cleanupCandidates[m.cleanupIndex].worktree.Path
+			if m.cleanupSelected[path] {
+				delete(m.cleanupSelected, path)
 			} else {
-				// Manual commit mode - close staging modal, open commit modal
-				m.modal = commitModal
-				m.modalFocused = 0
-				m.commitSubjectInput.SetValue("")
-				m.commitSubjectInput.Focus()
-				m.commitModalStatus = ""
+				m.cleanupSelected[path] = true
+			}
+		}
+
+	case "a":
+		// Check all candidates, or uncheck all if they're all checked already
+		allChecked := len(m.selectedCleanupWorktrees()) == len(m.cleanupCandidates)
+		for _, candidate := range m.cleanupCandidates {
+			if allChecked {
+				delete(m.cleanupSelected, candidate.worktree.Path)
+			} else {
+				m.cleanupSelected[candidate.worktree.Path] = true
+			}
+		}
+
+	case "+", "=", "-":
+		// Adjust how long a worktree must be untouched to be suggested
+		if key == "-" {
+			if m.cleanupStaleDays <= 1 {
 				return m, nil
 			}
+			m.cleanupStaleDays--
+		} else {
+			m.cleanupStaleDays++
 		}
-		return m, nil
+		if m.configManager != nil {
+			_ = m.configManager.SetCleanupStaleDays(m.repoPath, m.cleanupStaleDays)
+		}
+		return m, m.loadCleanupCandidates()
+
+	case "enter":
+		selected := m.selectedCleanupWorktrees()
+		if len(selected) == 0 {
+			return m, m.showWarningNotification("No worktrees checked. Press space to check one.")
+		}
+		// Ask once more before removing anything
+		if !m.cleanupConfirm {
+			m.cleanupConfirm = true
+			return m, nil
+		}
+		m.cleanupConfirm = false
+		m.cleanupRunning = true
+		return m, tea.Batch(
+			m.showInfoNotification(fmt.Sprintf("Removing %d worktree(s)...", len(selected))),
+			m.removeCleanupWorktrees(selected),
+		)
 	}
 
 	return m, nil
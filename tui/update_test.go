@@ -1,10 +1,19 @@
 package tui
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/coollabsio/jean-tui/config"
+	"github.com/coollabsio/jean-tui/git"
+	"github.com/coollabsio/jean-tui/process"
 	"github.com/coollabsio/jean-tui/session"
 )
 
@@ -380,6 +389,509 @@ func TestListSelectionModalInput_BoundaryCheck_Down(t *testing.T) {
 	}
 }
 
+// TestAutoFetchBacksOffWhileFailing tests that failed background fetches back off and a success resets the backoff
+func TestAutoFetchBacksOffWhileFailing(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("JEAN_CREDENTIAL_STORE", config.CredentialStoreFile)
+	configManager, err := config.NewManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := setupTestModel()
+	m.repoPath = "/repo"
+	m.configManager = configManager
+	m.worktrees = []git.Worktree{{Path: "/repo/.workspaces/feature", Branch: "feature"}}
+	interval := m.getAutoFetchInterval()
+	if interval <= 0 || !m.shouldAutoFetch() {
+		t.Fatalf("Expected background fetching to be on by default, interval %s", interval)
+	}
+
+	for i, want := range []time.Duration{interval, 2 * interval, 4 * interval} {
+		updatedModel, _ := m.Update(autoFetchCompletedMsg{err: errors.New("offline")})
+		m = updatedModel.(Model)
+		if m.fetchBackoff != want {
+			t.Fatalf("Expected backoff %s after %d failures, got %s", want, i+1, m.fetchBackoff)
+		}
+	}
+	for i := 0; i < 10; i++ {
+		updatedModel, _ := m.Update(autoFetchCompletedMsg{err: errors.New("offline")})
+		m = updatedModel.(Model)
+	}
+	if m.fetchBackoff != maxFetchBackoff {
+		t.Errorf("Expected backoff to be capped at %s, got %s", maxFetchBackoff, m.fetchBackoff)
+	}
+
+	updatedModel, _ := m.Update(autoFetchCompletedMsg{statuses: map[string]git.BranchStatus{"feature": {Ahead: 1, Behind: 2}}})
+	m = updatedModel.(Model)
+	if m.fetchBackoff != 0 || m.worktrees[0].AheadCount != 1 || m.worktrees[0].BehindCount != 2 || !m.worktrees[0].IsOutdated {
+		t.Errorf("Expected a successful fetch to reset the backoff and update counts, got %s and %+v", m.fetchBackoff, m.worktrees[0])
+	}
+	if m.shouldAutoFetch() {
+		t.Error("Expected no new fetch right after one finished")
+	}
+}
+
+// TestConflictModalInput_Navigation tests file selection and version tab cycling in the conflict modal
+func TestConflictModalInput_Navigation(t *testing.T) {
+	m := setupTestModel()
+	m.modal = conflictModal
+	m.conflictFiles = []string{"a.go", "b.go"}
+	m.conflictScroll = 20
+
+	resultModel, _ := m.handleConflictModalInput(tea.KeyMsg{Type: tea.KeyDown})
+	result := resultModel.(Model)
+	if result.conflictIndex != 1 {
+		t.Errorf("Expected conflictIndex 1, got %d", result.conflictIndex)
+	}
+	if result.conflictScroll != 0 {
+		t.Errorf("Expected conflictScroll reset to 0, got %d", result.conflictScroll)
+	}
+
+	// Cycle ours -> theirs -> base -> ours
+	for _, expected := range []int{1, 2, 0} {
+		resultModel, _ = result.handleConflictModalInput(tea.KeyMsg{Type: tea.KeyTab})
+		result = resultModel.(Model)
+		if result.conflictView != expected {
+			t.Errorf("Expected conflictView %d, got %d", expected, result.conflictView)
+		}
+	}
+}
+
+// TestConflictModalInput_ContinueWithConflicts tests that continue is refused while files are unmerged
+func TestConflictModalInput_ContinueWithConflicts(t *testing.T) {
+	m := setupTestModel()
+	m.modal = conflictModal
+	m.conflictFiles = []string{"a.go"}
+
+	resultModel, _ := m.handleConflictModalInput(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+
+	if resultModel.(Model).modal != conflictModal {
+		t.Errorf("Expected conflict modal to stay open, got %v", resultModel.(Model).modal)
+	}
+}
+
+// TestConflictModalInput_AbortNeedsConfirmation tests that aborting requires pressing A twice
+func TestConflictModalInput_AbortNeedsConfirmation(t *testing.T) {
+	InitStyles()
+	m := setupTestModel()
+	m.modal = conflictModal
+	m.conflictOperation = git.OperationMerge
+	m.conflictFiles = []string{"a.go"}
+
+	resultModel, cmd := m.handleConflictModalInput(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'A'}})
+	result := resultModel.(Model)
+	if !result.conflictConfirmAbort || cmd != nil {
+		t.Fatal("Expected the first A to only ask for confirmation")
+	}
+	if !strings.Contains(result.renderConflictModal(), "Press A again") {
+		t.Error("Expected the modal to ask for confirmation")
+	}
+
+	resultModel, _ = result.handleConflictModalInput(tea.KeyMsg{Type: tea.KeyDown})
+	if resultModel.(Model).conflictConfirmAbort {
+		t.Error("Expected another key to cancel the pending abort")
+	}
+
+	resultModel, _ = result.handleConflictModalInput(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'A'}})
+	if resultModel.(Model).conflictConfirmAbort {
+		t.Error("Expected the second A to abort")
+	}
+}
+
+// TestLocalMergeResumeNeedsMergingBranch tests that L only resumes a conflicted merge from the worktree being merged
+// Which branch is being merged is covered by git's TestMergingBranch
+func TestLocalMergeResumeNeedsMergingBranch(t *testing.T) {
+	InitStyles()
+	repo := t.TempDir()
+	m := setupTestModel()
+	m.gitManager = git.NewManager(repo)
+	m.baseBranch = "main"
+	m.worktrees = []git.Worktree{
+		{Path: repo, Branch: "main", IsMain: true},
+		{Path: repo + "/.workspaces/feature", Branch: "feature"},
+		{Path: repo + "/.workspaces/other", Branch: "other"},
+	}
+
+	resultModel, _ := m.resumeLocalMerge(&m.worktrees[2], repo, "feature")
+	result := resultModel.(Model)
+	if result.modal == conflictModal || result.localMergeBranch != "" {
+		t.Fatal("Expected L on another worktree not to take over the merge")
+	}
+	if result.notification == nil || !strings.Contains(result.notification.Message, "feature") {
+		t.Error("Expected a warning naming the branch being merged")
+	}
+
+	resultModel, _ = m.resumeLocalMerge(&m.worktrees[1], repo, "")
+	if result := resultModel.(Model); result.modal == conflictModal || result.notification == nil {
+		t.Error("Expected a warning instead of resuming a merge of an unknown branch")
+	}
+
+	resultModel, _ = m.resumeLocalMerge(&m.worktrees[1], repo, "feature")
+	result = resultModel.(Model)
+	if result.modal != conflictModal || result.localMergeBranch != "feature" || result.localMergeWorktree != repo+"/.workspaces/feature" {
+		t.Errorf("Expected L on the merged branch's worktree to resume, got modal %v for %q", result.modal, result.localMergeBranch)
+	}
+	if result.localMergeTarget != "main" || !result.conflictFromLocalMerge || result.conflictWorktreePath != repo {
+		t.Errorf("Expected the conflicts of the main repo to be shown as a local merge into main, got %q into %q", result.conflictWorktreePath, result.localMergeTarget)
+	}
+}
+
+// TestConflictFinished_RebaseStoppedAgain tests that the modal stays open when a rebase stops on the next commit
+func TestConflictFinished_RebaseStoppedAgain(t *testing.T) {
+	m := setupTestModel()
+	m.modal = conflictModal
+	m.conflictOperation = git.OperationRebase
+	m.conflictIndex = 2
+
+	resultModel, _ := m.Update(conflictFinishedMsg{stillInProgress: true})
+	result := resultModel.(Model)
+
+	if result.modal != conflictModal {
+		t.Errorf("Expected conflict modal to stay open, got %v", result.modal)
+	}
+	if result.conflictIndex != 0 {
+		t.Errorf("Expected conflictIndex reset to 0, got %d", result.conflictIndex)
+	}
+}
+
+// TestStashModalInput_DropNeedsConfirmation tests that dropping a stash requires pressing d twice
+func TestStashModalInput_DropNeedsConfirmation(t *testing.T) {
+	m := setupTestModel()
+	m.modal = stashModal
+	m.stashBranch = "feature"
+	m.stashes = []git.Stash{{Ref: "stash@{0}", Branch: "feature", Message: "wip"}}
+
+	resultModel, cmd := m.handleStashModalInput(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	result := resultModel.(Model)
+	if !result.stashConfirmDrop || cmd != nil {
+		t.Fatalf("Expected first d to ask for confirmation without dropping")
+	}
+
+	// Any other key cancels the pending drop
+	resultModel, _ = result.handleStashModalInput(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+	if resultModel.(Model).stashConfirmDrop {
+		t.Errorf("Expected pending drop to be cancelled")
+	}
+}
+
+// TestStagingDiffInput_LineMode tests selecting individual lines of a hunk in the hunk view
+func TestStagingDiffInput_LineMode(t *testing.T) {
+	m := setupTestModel()
+	m.modal = stagingModal
+	m.stagingDiffOpen = true
+	m.stagingDiff = &git.FileDiff{Hunks: []git.DiffHunk{{Lines: []string{" a", "-b", " c", "+d"}}}}
+
+	// Entering line mode puts the cursor on the first changed line
+	resultModel, _ := m.handleStagingModalInput(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'v'}})
+	result := resultModel.(Model)
+	if !result.stagingLineMode || result.stagingLineIndex != 1 {
+		t.Fatalf("Expected line mode at index 1, got mode=%v index=%d", result.stagingLineMode, result.stagingLineIndex)
+	}
+
+	// Down skips context lines
+	resultModel, _ = result.handleStagingModalInput(tea.KeyMsg{Type: tea.KeyDown})
+	result = resultModel.(Model)
+	if result.stagingLineIndex != 3 {
+		t.Errorf("Expected line index 3, got %d", result.stagingLineIndex)
+	}
+
+	// Space selects the line without applying anything
+	resultModel, cmd := result.handleStagingModalInput(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	result = resultModel.(Model)
+	if !result.stagingLineSelected[3] || cmd != nil {
+		t.Errorf("Expected line 3 to be selected without a command")
+	}
+}
+
+// TestDiffViewerInput_HunkNavigation tests that next/previous hunk moves across files
+func TestDiffViewerInput_HunkNavigation(t *testing.T) {
+	m := setupTestModel()
+	m.modal = diffViewerModal
+	m.diffViewerFiles = []*git.FileDiff{
+		{Path: "a.go", Hunks: []git.DiffHunk{{Lines: []string{"+a"}}}},
+		{Path: "b.go", Hunks: []git.DiffHunk{{Lines: []string{"-b"}}}},
+	}
+
+	// The only hunk of the first file is already visible, so n moves to the next file
+	resultModel, _ := m.handleDiffViewerInput(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	result := resultModel.(Model)
+	if result.diffViewerIndex != 1 {
+		t.Errorf("Expected diffViewerIndex 1, got %d", result.diffViewerIndex)
+	}
+
+	resultModel, _ = result.handleDiffViewerInput(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}})
+	result = resultModel.(Model)
+	if result.diffViewerIndex != 0 || result.diffViewerScroll != 0 {
+		t.Errorf("Expected first file at scroll 0, got index=%d scroll=%d", result.diffViewerIndex, result.diffViewerScroll)
+	}
+}
+
+// TestLogModalInput_MarkCommitsSkipsGraphLines tests marking commits for cherry-pick in the graph log
+func TestLogModalInput_MarkCommitsSkipsGraphLines(t *testing.T) {
+	m := setupTestModel()
+	m.modal = logModal
+	m.logSelected = make(map[string]bool)
+	m.logEntries = []git.LogEntry{
+		{Graph: "*   ", Hash: "c3", Subject: "Merge"},
+		{Graph: "|\\  "},
+		{Graph: "| * ", Hash: "c2", Subject: "Second"},
+		{Graph: "* | ", Hash: "c1", Subject: "First"},
+	}
+
+	// Mark c3 and c2; the cursor skips the graph-only line in between
+	space := tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	resultModel, _ := m.handleLogModalInput(space)
+	result := resultModel.(Model)
+	if result.logIndex != 2 {
+		t.Fatalf("Expected logIndex 2, got %d", result.logIndex)
+	}
+	resultModel, _ = result.handleLogModalInput(space)
+	result = resultModel.(Model)
+
+	// Commits are cherry-picked oldest first
+	hashes := result.cherryPickCommits()
+	if len(hashes) != 2 || hashes[0] != "c2" || hashes[1] != "c3" {
+		t.Errorf("Expected [c2 c3], got %v", hashes)
+	}
+}
+
+// TestMainInput_LockedMissingWorktreeOpensRepair tests that switching to a locked, missing worktree opens the repair screen
+func TestMainInput_LockedMissingWorktreeOpensRepair(t *testing.T) {
+	InitStyles()
+	// git never reports a locked worktree as prunable, so only the directory check catches it
+	wt := git.Worktree{Path: filepath.Join(t.TempDir(), "gone"), Branch: "gone", IsLocked: true}
+	for _, key := range []string{"t", "x"} {
+		m := setupTestModel()
+		m.worktrees = []git.Worktree{wt}
+		resultModel, _ := m.handleMainInput(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		if result := resultModel.(Model); result.modal != repairModal || result.pendingSwitchInfo != nil {
+			t.Errorf("Expected %q on a locked missing worktree to open the repair screen", key)
+		}
+	}
+}
+
+// TestRepairModalInput_DeleteOrphanNeedsConfirmation tests that deleting an orphaned directory requires pressing d twice
+func TestRepairModalInput_DeleteOrphanNeedsConfirmation(t *testing.T) {
+	m := setupTestModel()
+	m.modal = repairModal
+	m.repairItems = []repairItem{
+		{path: "/repo/.workspaces/gone", branch: "gone", reason: "gitdir file points to non-existent location"},
+		{orphan: true, path: "/repo/.workspaces/leftover", reason: "not a registered worktree"},
+	}
+
+	// d does nothing on a missing worktree
+	resultModel, cmd := m.handleRepairModalInput(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	result := resultModel.(Model)
+	if cmd != nil || result.repairConfirmDel {
+		t.Fatal("Expected d to be ignored for a missing worktree")
+	}
+
+	resultModel, _ = result.handleRepairModalInput(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	result = resultModel.(Model)
+	resultModel, cmd = result.handleRepairModalInput(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	result = resultModel.(Model)
+	if cmd != nil || !result.repairConfirmDel {
+		t.Fatal("Expected first d to ask for confirmation")
+	}
+
+	resultModel, cmd = result.handleRepairModalInput(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	result = resultModel.(Model)
+	if cmd == nil || result.repairConfirmDel {
+		t.Error("Expected second d to delete the orphan")
+	}
+}
+
+// TestFindCleanupCandidates tests which worktrees are suggested for cleanup and why
+func TestFindCleanupCandidates(t *testing.T) {
+	now := time.Now()
+	worktrees := []git.Worktree{
+		{Path: "/repo", Branch: "main", IsMain: true, LastModified: now.AddDate(0, 0, -100)},
+		{Path: "/repo/.workspaces/merged", Branch: "merged", LastModified: now},
+		{Path: "/repo/.workspaces/closed", Branch: "closed", LastModified: now, PRs: []config.PRInfo{{Status: "closed", PRNumber: 7}}},
+		{Path: "/repo/.workspaces/old", Branch: "old", LastModified: now.AddDate(0, 0, -30)},
+		{Path: "/repo/.workspaces/active", Branch: "active", LastModified: now, PRs: []config.PRInfo{{Status: "open"}}},
+		{Path: "/repo/.workspaces/gone", Branch: "gone", IsPrunable: true},
+		{Path: "/repo/.workspaces/fresh", Branch: "fresh", LastModified: now},
+	}
+	// As GetMergedBranches reports them: the just-created "fresh" branch is reachable from main
+	// but has no commits of its own, so it isn't merged
+	merged := map[string]bool{"merged": true, "gone": true}
+
+	candidates := findCleanupCandidates(worktrees, "main", merged, 14, now)
+	got := map[string]string{}
+	for _, candidate := range candidates {
+		got[candidate.worktree.Branch] = strings.Join(candidate.reasons, ", ")
+	}
+	want := map[string]string{
+		"merged": "merged into main",
+		"closed": "PR #7 closed",
+		"old":    "untouched for 30 days",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for branch, reason := range want {
+		if got[branch] != reason {
+			t.Errorf("Expected %s to be suggested because %q, got %q", branch, reason, got[branch])
+		}
+	}
+}
+
+// TestNewBranchName tests that generated branch names follow the naming rules
+func TestNewBranchName(t *testing.T) {
+	rules := &config.BranchNamingRules{Prefixes: []string{"feat", "fix/"}, TicketPattern: "[A-Z]+-[0-9]+"}
+	if got := newBranchName("fix/ABC-9", rules, "happy-panda-42"); got != "fix/ABC-9-happy-panda-42" {
+		t.Errorf("Expected random name after prefix and ticket, got %q", got)
+	}
+	if got := newBranchName("", nil, "happy-panda-42"); got != "happy-panda-42" {
+		t.Errorf("Expected plain random name without rules, got %q", got)
+	}
+}
+
+// TestScriptsModalInput_ForegroundNeedsWrapper tests that foreground script runs are refused without the shell wrapper
+func TestScriptsModalInput_ForegroundNeedsWrapper(t *testing.T) {
+	t.Setenv("JEAN_SWITCH_FILE", "")
+	m := setupTestModel()
+	m.modal = scriptsModal
+	m.scriptsWorktreePath = "/repo/.workspaces/feature"
+	m.scriptsBranch = "feature"
+	m.scriptNames = []string{"dev", "test"}
+	m.scripts = map[string]string{"dev": "npm run dev", "test": "npm test"}
+
+	resultModel, _ := m.handleScriptsModalInput(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	result := resultModel.(Model)
+	if result.selectedScript() != "test" {
+		t.Fatalf("Expected test to be selected, got %q", result.selectedScript())
+	}
+
+	// Without the shell wrapper there's nobody to hand the script to
+	resultModel, _ = result.handleScriptsModalInput(tea.KeyMsg{Type: tea.KeyEnter})
+	result = resultModel.(Model)
+	if result.modal != scriptsModal || result.switchInfo.ScriptCommand != "" {
+		t.Error("Expected foreground run to be refused without the shell wrapper")
+	}
+	if result.notification == nil || result.notification.Type != NotificationWarning {
+		t.Error("Expected a warning notification")
+	}
+}
+
+// TestWorktreeDeletedShowsKeptBranchAndHookFailure tests the warning after deleting a worktree whose branch was kept
+func TestWorktreeDeletedShowsKeptBranchAndHookFailure(t *testing.T) {
+	m := setupTestModel()
+	hookErr := &git.HookError{Hook: git.HookTeardown, Output: "boom", LogPath: "/repo/.git/jean/logs/teardown-feature.log", Err: errors.New("exit status 1")}
+	resultModel, _ := m.Update(worktreeDeletedMsg{kept: &git.BranchKeptError{Branch: "feature", AtRisk: 2}, hookErr: hookErr})
+	result := resultModel.(Model)
+	if result.notification == nil || result.notification.Type != NotificationWarning {
+		t.Fatal("Expected a warning notification")
+	}
+	message := result.notification.Message
+	for _, want := range []string{"branch 'feature' kept", "teardown hook failed", hookErr.LogPath} {
+		if !strings.Contains(message, want) {
+			t.Errorf("Expected the notification to mention %q, got %q", want, message)
+		}
+	}
+}
+
+// TestSetupLogKey tests that S opens the setup log of the selected worktree
+func TestSetupLogKey(t *testing.T) {
+	repo := t.TempDir()
+	m := setupTestModel()
+	m.repoPath = repo
+	m.worktrees = []git.Worktree{{Path: filepath.Join(repo, ".workspaces", "feature"), Branch: "feature"}}
+
+	// Without a setup script there's no log to show
+	resultModel, _ := m.handleMainInput(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'S'}})
+	result := resultModel.(Model)
+	if result.modal != noModal || result.notification == nil || result.notification.Type != NotificationWarning {
+		t.Fatal("Expected a warning when jean.json has no setup script")
+	}
+
+	if err := os.WriteFile(filepath.Join(repo, "jean.json"), []byte(`{"scripts": {"setup": "npm install"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	resultModel, _ = m.handleMainInput(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'S'}})
+	result = resultModel.(Model)
+	if result.modal != scriptLogModal || result.scriptLogName != git.SetupScript || !result.scriptLogFollow {
+		t.Fatal("Expected S to open the setup log, following its output")
+	}
+
+	// Closing the log goes back to where it was opened from
+	resultModel, _ = result.handleScriptLogModalInput(tea.KeyMsg{Type: tea.KeyEsc})
+	if resultModel.(Model).modal != noModal {
+		t.Error("Expected esc to return to the worktree list")
+	}
+}
+
+// TestServicesShownUntilStopped tests the service indicator in the worktree list
+func TestServicesShownUntilStopped(t *testing.T) {
+	InitStyles()
+	m := setupTestModel()
+	m.worktrees = []git.Worktree{{Path: "/repo/.workspaces/feature", Branch: "feature"}}
+	m.services = []process.Service{{Name: "dev", Worktree: "/repo/.workspaces/feature", Branch: "feature", PID: 4242}}
+
+	if !strings.Contains(m.renderWorktreeList(), "▶ dev") {
+		t.Fatal("Expected the running service next to its worktree")
+	}
+
+	updatedModel, _ := m.Update(serviceStoppedMsg{path: "/repo/.workspaces/feature", branch: "feature", name: "dev"})
+	updated := updatedModel.(Model)
+	if len(updated.services) != 0 {
+		t.Errorf("Expected the stopped service to be dropped, got %v", updated.services)
+	}
+	if strings.Contains(updated.renderWorktreeList(), "▶ dev") {
+		t.Error("Expected no service indicator after stopping it")
+	}
+}
+
+// TestPortBlocksShownInDetails tests that the details panel shows the ports reserved for a worktree
+func TestPortBlocksShownInDetails(t *testing.T) {
+	InitStyles()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("JEAN_CREDENTIAL_STORE", config.CredentialStoreFile)
+	configManager, err := config.NewManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := setupTestModel()
+	m.repoPath = "/repo"
+	m.configManager = configManager
+	m.worktrees = []git.Worktree{{Path: "/repo/.workspaces/feature", Branch: "feature", Commit: "abc1234"}}
+
+	first, err := configManager.AllocatePortBlock("/repo", "feature", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(m.renderDetails(), fmt.Sprintf("%d-%d", first, first+config.PortBlockSize-1)) {
+		t.Error("Expected the details panel to show the reserved ports")
+	}
+}
+
+// TestAISettingsShowKeyLocation tests that the AI settings show where the API key is stored
+func TestAISettingsShowKeyLocation(t *testing.T) {
+	InitStyles()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("CLAUDE_CODE_OAUTH_TOKEN", "")
+	t.Setenv("JEAN_CREDENTIAL_STORE", config.CredentialStoreFile)
+	configDir := filepath.Join(home, ".config", "jean")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(configDir, "config.json"), []byte(`{"anthropic_api_key": "sk-ant-secret"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	configManager, err := config.NewManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := setupTestModel()
+	m.configManager = configManager
+	if !strings.Contains(m.renderAISettingsModal(), "credentials.json") {
+		t.Error("Expected the AI settings to show where the key is stored")
+	}
+}
+
 // Helper function to set up a basic test model
 func setupTestModel() Model {
 	return Model{
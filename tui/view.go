@@ -4,11 +4,13 @@ import (
 	"fmt"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/coollabsio/jean-tui/config"
 	"github.com/coollabsio/jean-tui/git"
 	"github.com/coollabsio/jean-tui/internal/version"
+	"github.com/coollabsio/jean-tui/process"
 )
 
 // View renders the TUI
@@ -135,6 +137,31 @@ func (m Model) renderWorktreeList() string {
 				behindIndicator := fmt.Sprintf(" ↓%d", wt.BehindCount)
 				line += normalItemStyle.Copy().Foreground(warningColor).Render(behindIndicator)
 			}
+
+			// Show missing (prunable) and locked worktrees
+			if wt.IsPrunable {
+				line += normalItemStyle.Copy().Foreground(errorColor).Render(" ⚠ missing")
+			}
+			if wt.IsLocked {
+				line += normalItemStyle.Copy().Foreground(mutedColor).Render(" 🔒")
+			}
+
+			// Show the state of the setup script running in the background
+			switch scriptRunStatus(m.scriptRuns[scriptRunKey(wt.Path, git.SetupScript)]) {
+			case "running":
+				line += normalItemStyle.Copy().Foreground(warningColor).Render(" ⟳ setup")
+			case "succeeded":
+				line += normalItemStyle.Copy().Foreground(successColor).Render(" ✓ setup")
+			case "failed":
+				line += normalItemStyle.Copy().Foreground(errorColor).Render(" ✗ setup")
+			case "stopped":
+				line += normalItemStyle.Copy().Foreground(mutedColor).Render(" ■ setup")
+			}
+		}
+
+		// Show the services running in the worktree
+		for _, name := range m.servicesIn(wt.Path) {
+			line += normalItemStyle.Copy().Foreground(successColor).Render(" ▶ " + name)
 		}
 
 
@@ -182,7 +209,7 @@ func (m Model) renderDetails() string {
 				b.WriteString(strings.Join(statusParts, ", "))
 
 				// Add pull hint directly on the same line if behind
-				if wt.BehindCount > 0 && !wt.IsCurrent && strings.Contains(wt.Path, ".workspaces") {
+				if wt.BehindCount > 0 && !wt.IsCurrent && !wt.IsMain {
 					b.WriteString(normalItemStyle.Copy().Foreground(mutedColor).Render(" (press 'u' to pull)"))
 				}
 			} else {
@@ -206,6 +233,32 @@ func (m Model) renderDetails() string {
 		b.WriteString("\n")
 	}
 
+	// Show the port block reserved for the worktree (JEAN_PORT, ...)
+	if m.configManager != nil {
+		if first := m.configManager.GetPortBlock(m.repoPath, wt.Branch); first != 0 {
+			b.WriteString(detailKeyStyle.Render("Ports: "))
+			b.WriteString(detailValueStyle.Render(fmt.Sprintf("%d-%d", first, first+config.PortBlockSize-1)))
+			b.WriteString("\n")
+		}
+	}
+
+	// Show worktree state problems
+	if wt.IsPrunable {
+		b.WriteString(detailKeyStyle.Render("State: "))
+		b.WriteString(normalItemStyle.Copy().Foreground(errorColor).Render("⚠ Missing (" + wt.PruneReason + ")"))
+		b.WriteString(normalItemStyle.Copy().Foreground(mutedColor).Render(" press 'R' to repair"))
+		b.WriteString("\n")
+	}
+	if wt.IsLocked {
+		lockInfo := "🔒 Locked"
+		if wt.LockReason != "" {
+			lockInfo += " (" + wt.LockReason + ")"
+		}
+		b.WriteString(detailKeyStyle.Render("Lock: "))
+		b.WriteString(detailValueStyle.Render(lockInfo))
+		b.WriteString("\n")
+	}
+
 	// Show uncommitted changes status
 	if wt.HasUncommitted {
 		b.WriteString("\n")
@@ -436,6 +489,30 @@ func (m Model) renderModal() string {
 		return m.renderAIPromptsModal()
 	case prStateSettingsModal:
 		return m.renderPRStateSettingsModal()
+	case autoFetchSettingsModal:
+		return m.renderAutoFetchSettingsModal()
+	case updateStrategySettingsModal:
+		return m.renderUpdateStrategySettingsModal()
+	case remoteSettingsModal:
+		return m.renderRemoteSettingsModal()
+	case worktreeLocationModal:
+		return m.renderWorktreeLocationModal()
+	case relocateModal:
+		return m.renderRelocateModal()
+	case scriptsModal:
+		return m.renderScriptsModal()
+	case scriptLogModal:
+		return m.renderScriptLogModal()
+	case stashModal:
+		return m.renderStashModal()
+	case diffViewerModal:
+		return m.renderDiffViewer()
+	case logModal:
+		return m.renderLogModal()
+	case repairModal:
+		return m.renderRepairModal()
+	case cleanupModal:
+		return m.renderCleanupModal()
 	case themeSelectModal:
 		return m.renderThemeSelectModal()
 	case commitModal:
@@ -458,6 +535,8 @@ func (m Model) renderModal() string {
 		return m.renderGitInitModal()
 	case stagingModal:
 		return m.renderStagingModal()
+	case conflictModal:
+		return m.renderConflictModal()
 	}
 	return ""
 }
@@ -488,7 +567,7 @@ func (m Model) renderCreateModal() string {
 	}
 
 	// Show info about auto-generated workspace location
-	b.WriteString(helpStyle.Render("Workspace location: .workspaces/<random-name>"))
+	b.WriteString(helpStyle.Render("Workspace location: " + m.gitManager.PathTemplate()))
 	b.WriteString("\n\n")
 
 	// Buttons (now only 2 buttons: Create and Cancel)
@@ -548,20 +627,16 @@ func (m Model) renderCreateWithNameModal() string {
 	b.WriteString(helpStyle.Render(fmt.Sprintf("Will create:")))
 	b.WriteString("\n")
 
-	if sessionName == "" {
-		// Empty input - will generate random name
-		b.WriteString(helpStyle.Render(fmt.Sprintf("  Branch: <random name will be generated>")))
-	} else {
-		// Custom name provided
-		sanitizedName := m.sessionManager.SanitizeBranchName(sessionName)
-		b.WriteString(helpStyle.Render(fmt.Sprintf("  Branch: %s", sanitizedName)))
+	// Without a description (empty input, or only a prefix and ticket ID) a random name is generated
+	branchName := newBranchName(sessionName, m.namingRules, "<random name will be generated>")
+	b.WriteString(helpStyle.Render(fmt.Sprintf("  Branch: %s", branchName)))
 
-		// Show sanitization notice if name was changed
-		if sanitizedName != sessionName {
-			b.WriteString("\n")
-			b.WriteString(helpStyle.Render(fmt.Sprintf("  (sanitized from '%s')", sessionName)))
-		}
+	// Show sanitization notice if name was changed
+	if branchName == git.SanitizeBranchName(sessionName) && branchName != sessionName {
+		b.WriteString("\n")
+		b.WriteString(helpStyle.Render(fmt.Sprintf("  (sanitized from '%s')", sessionName)))
 	}
+	b.WriteString(m.renderNamingRulesCheck(newBranchName(sessionName, m.namingRules, "happy-panda-42")))
 
 	b.WriteString("\n")
 	b.WriteString(helpStyle.Render(fmt.Sprintf("  Claude will automatically continue previous conversations")))
@@ -625,15 +700,36 @@ func (m Model) renderDeleteModal() string {
 		b.WriteString("\n\n")
 	}
 
+	// Show what happens to the branch
+	if m.deleteBranchProtected {
+		b.WriteString(helpStyle.Render(fmt.Sprintf("Branch '%s' is protected and will be kept.", wt.Branch)))
+		b.WriteString("\n\n")
+	} else if m.deleteAtRisk > 0 || m.deleteAtRiskErr != nil {
+		if m.deleteAtRiskErr != nil {
+			b.WriteString(errorStyle.Render(fmt.Sprintf("⚠️  Couldn't check '%s' for commits that are not on any remote or %s!", wt.Branch, m.baseBranch)))
+		} else {
+			b.WriteString(errorStyle.Render(fmt.Sprintf("⚠️  %d commit(s) on '%s' are not on any remote or %s!", m.deleteAtRisk, wt.Branch, m.baseBranch)))
+		}
+		b.WriteString("\n")
+		if m.deleteConfirmForce {
+			b.WriteString(errorStyle.Render("    They will be lost when the branch is deleted."))
+		} else if m.deleteHasUncommitted {
+			b.WriteString(errorStyle.Render("    'Force Delete' also deletes the branch and these commits."))
+		} else {
+			b.WriteString(errorStyle.Render("    'Yes' keeps the branch, 'Force Delete' deletes it with these commits."))
+		}
+		b.WriteString("\n\n")
+	}
+
 	// Buttons
-	if m.deleteHasUncommitted {
+	if m.deleteNeedsForce() {
 		// Show 3 buttons: Yes (disabled), Cancel, Force Delete
 		yesBtn := "Yes"
 		noBtn := "Cancel"
 		forceBtn := "Force Delete"
 
 		// Yes button (disabled if uncommitted changes and not confirmed)
-		if m.deleteConfirmForce {
+		if m.deleteConfirmForce || !m.deleteHasUncommitted {
 			if m.modalFocused == 0 {
 				b.WriteString(selectedDeleteButtonStyle.Render(yesBtn))
 			} else {
@@ -862,6 +958,27 @@ func min(a, b int) int {
 	return b
 }
 
+// renderNamingRulesCheck shows whether a branch name follows the naming rules from jean.json
+// and which prefixes the ↑/↓ picker offers; empty when there are no rules
+func (m Model) renderNamingRulesCheck(name string) string {
+	if m.namingRules == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("\n")
+	if err := m.namingRules.Validate(name); err != nil {
+		b.WriteString(normalItemStyle.Copy().Foreground(errorColor).Render("  ✗ Branch " + err.Error()))
+	} else {
+		b.WriteString(normalItemStyle.Copy().Foreground(successColor).Render("  ✓ Follows naming rules"))
+	}
+	if prefixes := m.namingRules.GetPrefixes(); len(prefixes) > 0 {
+		b.WriteString("\n")
+		b.WriteString(helpStyle.Render("  ↑/↓ prefix: " + strings.Join(prefixes, ", ")))
+	}
+	return b.String()
+}
+
 func (m Model) renderRenameModal() string {
 	var b strings.Builder
 
@@ -880,7 +997,7 @@ func (m Model) renderRenameModal() string {
 
 	// Show sanitization preview
 	newName := m.nameInput.Value()
-	sanitizedName := m.sessionManager.SanitizeBranchName(newName)
+	sanitizedName := git.SanitizeBranchName(newName)
 
 	if newName != "" {
 		b.WriteString(helpStyle.Render("Will rename to:"))
@@ -893,12 +1010,16 @@ func (m Model) renderRenameModal() string {
 			b.WriteString(helpStyle.Render(fmt.Sprintf("  (sanitized from '%s')", newName)))
 			b.WriteString("\n")
 		}
+		if check := m.renderNamingRulesCheck(sanitizedName); check != "" {
+			b.WriteString(strings.TrimPrefix(check, "\n"))
+			b.WriteString("\n")
+		}
 		b.WriteString("\n")
 	}
 
 	// Show info about what will be renamed
 	if wt := m.selectedWorktree(); wt != nil {
-		if strings.Contains(wt.Path, ".workspaces") {
+		if !wt.IsMain {
 			b.WriteString(helpStyle.Render("ℹ️  This will rename the git branch only"))
 			b.WriteString("\n")
 			b.WriteString(helpStyle.Render("   Directory path stays the same to preserve active sessions"))
@@ -1532,6 +1653,67 @@ func (m Model) renderSettingsModal() string {
 				return "Ready for Review"
 			},
 		},
+		{
+			name:        "Auto Fetch",
+			key:         "f",
+			description: "How often to fetch from origin in the background and refresh ahead/behind counts",
+			getCurrent: func() string {
+				if m.configManager != nil {
+					interval := m.configManager.GetAutoFetchInterval(m.repoPath)
+					if interval == 0 {
+						return "Off"
+					}
+					return fmt.Sprintf("Every %s", time.Duration(interval)*time.Second)
+				}
+				return "Off"
+			},
+		},
+		{
+			name:        "Update Strategy",
+			key:         "u",
+			description: "How 'u' and refresh bring in new commits: merge, rebase or fast-forward only",
+			getCurrent: func() string {
+				current := m.getUpdateStrategy()
+				for _, option := range updateStrategyOptions {
+					if option.strategy == current {
+						return option.name
+					}
+				}
+				return current
+			},
+		},
+		{
+			name:        "Auto Stash",
+			key:         "z",
+			description: "Stash, pull and re-apply changes during refresh instead of skipping dirty worktrees",
+			getCurrent: func() string {
+				if m.configManager != nil && m.configManager.GetAutoStash(m.repoPath) {
+					return "Enabled"
+				}
+				return "Disabled"
+			},
+		},
+		{
+			name:        "Remotes",
+			key:         "o",
+			description: "Remote to push branches to and remote PRs target (for forks)",
+			getCurrent: func() string {
+				push := m.gitManager.PushRemote()
+				base := m.gitManager.BaseRemote()
+				if push == base {
+					return push
+				}
+				return push + " → " + base
+			},
+		},
+		{
+			name:        "Worktree Location",
+			key:         "w",
+			description: "Where new worktrees are created, e.g. ~/wt/{repo}/{branch}",
+			getCurrent: func() string {
+				return m.gitManager.PathTemplate()
+			},
+		},
 	}
 
 	// Render settings list
@@ -1580,7 +1762,18 @@ func (m Model) renderAISettingsModal() string {
 	b.WriteString(apiKeyLabel)
 	b.WriteString("\n")
 	b.WriteString(m.aiAPIKeyInput.View())
-	b.WriteString("\n\n")
+	b.WriteString("\n")
+	// Where the key is kept (never in config.json)
+	if m.configManager != nil {
+		mutedStyle := lipgloss.NewStyle().Foreground(mutedColor)
+		if location := m.configManager.APIKeyLocation(); location != "" {
+			b.WriteString(mutedStyle.Render("Stored in: " + location))
+		} else {
+			b.WriteString(mutedStyle.Render("Saved to: " + m.configManager.CredentialStoreDescription()))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
 
 	// Model selection
 	modelLabel := "Model:"
@@ -1732,6 +1925,296 @@ func (m Model) renderPRStateSettingsModal() string {
 	)
 }
 
+func (m Model) renderAutoFetchSettingsModal() string {
+	var b strings.Builder
+
+	b.WriteString(modalTitleStyle.Render("Auto Fetch Interval"))
+	b.WriteString("\n\n")
+	b.WriteString(helpStyle.Render("Fetch from origin in the background and refresh ahead/behind counts."))
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("Paused while a modal is open; backs off automatically when offline."))
+	b.WriteString("\n\n")
+
+	for i, option := range autoFetchIntervalOptions {
+		if i == m.autoFetchSettingsCursor {
+			b.WriteString(selectedItemStyle.Render("▶ " + option.name))
+		} else {
+			b.WriteString(normalItemStyle.Render("  " + option.name))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("↑/↓ select • enter confirm • esc cancel"))
+
+	return lipgloss.Place(
+		m.width, m.height,
+		lipgloss.Center, lipgloss.Center,
+		modalStyle.Render(b.String()),
+	)
+}
+
+func (m Model) renderUpdateStrategySettingsModal() string {
+	var b strings.Builder
+
+	b.WriteString(modalTitleStyle.Render("Update Strategy"))
+	b.WriteString("\n\n")
+	b.WriteString(helpStyle.Render("Used by 'u' (update from base) and 'r' (refresh) for this repository."))
+	b.WriteString("\n\n")
+
+	for i, option := range updateStrategyOptions {
+		if i == m.updateStrategySettingsCursor {
+			b.WriteString(selectedItemStyle.Render("▶ " + option.name))
+		} else {
+			b.WriteString(normalItemStyle.Render("  " + option.name))
+		}
+		b.WriteString("\n")
+		b.WriteString(normalItemStyle.Copy().Foreground(mutedColor).Render("    " + option.description))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("↑/↓ select • enter confirm • esc cancel"))
+
+	return lipgloss.Place(
+		m.width, m.height,
+		lipgloss.Center, lipgloss.Center,
+		modalStyle.Render(b.String()),
+	)
+}
+
+func (m Model) renderRemoteSettingsModal() string {
+	var b strings.Builder
+
+	b.WriteString(modalTitleStyle.Render("Remotes"))
+	b.WriteString("\n\n")
+	b.WriteString(helpStyle.Render("For forks: push to your fork and open PRs against the upstream repository."))
+	b.WriteString("\n\n")
+
+	fields := []struct {
+		label       string
+		value       string
+		description string
+	}{
+		{"Push remote", m.remoteOptions[m.remotePushIndex], "Branches are pushed here and PRs are opened from it"},
+		{"Base remote", m.remoteOptions[m.remoteBaseIndex], "Base branch is fetched from here and PRs target it"},
+	}
+	for i, field := range fields {
+		line := fmt.Sprintf("%s: ◀ %s ▶", field.label, field.value)
+		if i == m.remoteField {
+			b.WriteString(selectedItemStyle.Render("▶ " + line))
+		} else {
+			b.WriteString(normalItemStyle.Render("  " + line))
+		}
+		b.WriteString("\n")
+		b.WriteString(normalItemStyle.Copy().Foreground(mutedColor).Render("    " + field.description))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("↑/↓ field • ←/→ change remote • enter save • esc cancel"))
+
+	return lipgloss.Place(
+		m.width, m.height,
+		lipgloss.Center, lipgloss.Center,
+		modalStyle.Render(b.String()),
+	)
+}
+
+func (m Model) renderWorktreeLocationModal() string {
+	var b strings.Builder
+
+	b.WriteString(modalTitleStyle.Render("Worktree Location"))
+	b.WriteString("\n\n")
+
+	scope := "This repository"
+	if m.worktreeLocationGlobal {
+		scope = "All repositories (default)"
+	}
+	b.WriteString(detailKeyStyle.Render("Applies to: "))
+	b.WriteString(detailValueStyle.Render(scope))
+	b.WriteString("\n\n")
+
+	b.WriteString(m.pathInput.View())
+	b.WriteString("\n\n")
+
+	// Preview the path for an example branch
+	template := strings.TrimSpace(m.pathInput.Value())
+	if template == "" {
+		if m.worktreeLocationGlobal {
+			b.WriteString(helpStyle.Render("Empty uses " + git.DefaultPathTemplate))
+		} else {
+			b.WriteString(helpStyle.Render("Empty uses the global default"))
+		}
+	} else if path, err := git.ExpandPathTemplate(template, m.repoPath, "feature-x"); err != nil {
+		b.WriteString(normalItemStyle.Copy().Foreground(errorColor).Render(err.Error()))
+	} else {
+		b.WriteString(helpStyle.Render("Branch feature-x → " + path))
+	}
+	b.WriteString("\n\n")
+
+	b.WriteString(helpStyle.Render("Placeholders: {repo}, {branch}, {user}. Relative paths start at the repo root."))
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("Existing worktrees stay where they are; move them with 'm'."))
+	b.WriteString("\n\n")
+	b.WriteString(helpStyle.Render("tab repo/global • enter save • esc cancel"))
+
+	return lipgloss.Place(
+		m.width, m.height,
+		lipgloss.Center, lipgloss.Center,
+		modalStyle.Width(70).Render(b.String()),
+	)
+}
+
+func (m Model) renderRelocateModal() string {
+	var b strings.Builder
+
+	b.WriteString(modalTitleStyle.Render("Move Worktree"))
+	b.WriteString("\n\n")
+
+	if wt := m.selectedWorktree(); wt != nil {
+		b.WriteString(detailKeyStyle.Render("Branch: "))
+		b.WriteString(detailValueStyle.Render(wt.Branch))
+		b.WriteString("\n")
+		b.WriteString(detailKeyStyle.Render("From:   "))
+		b.WriteString(detailValueStyle.Render(wt.Path))
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(inputLabelStyle.Render("To:"))
+	b.WriteString("\n")
+	b.WriteString(m.pathInput.View())
+	b.WriteString("\n\n")
+
+	b.WriteString(helpStyle.Render("Close sessions and editors running in this worktree first."))
+	b.WriteString("\n\n")
+	b.WriteString(helpStyle.Render("enter move • esc cancel"))
+
+	return lipgloss.Place(
+		m.width, m.height,
+		lipgloss.Center, lipgloss.Center,
+		modalStyle.Width(70).Render(b.String()),
+	)
+}
+
+// renderScriptRunBadge renders the state of a script's latest background run
+func renderScriptRunBadge(run *git.ScriptRun) string {
+	switch scriptRunStatus(run) {
+	case "running":
+		return lipgloss.NewStyle().Foreground(warningColor).Render("● running")
+	case "succeeded":
+		return lipgloss.NewStyle().Foreground(successColor).Render("✓ succeeded")
+	case "failed":
+		return lipgloss.NewStyle().Foreground(errorColor).Render("✗ failed")
+	case "stopped":
+		return lipgloss.NewStyle().Foreground(mutedColor).Render("■ stopped")
+	}
+	return ""
+}
+
+// renderServiceBadge renders a running service with its process ID
+func renderServiceBadge(service *process.Service) string {
+	if service == nil {
+		return ""
+	}
+	return lipgloss.NewStyle().Foreground(successColor).Render(fmt.Sprintf("▶ running (pid %d)", service.PID))
+}
+
+func (m Model) renderScriptsModal() string {
+	var b strings.Builder
+	mutedStyle := lipgloss.NewStyle().Foreground(mutedColor)
+
+	b.WriteString(modalTitleStyle.Render("Run Script: " + m.scriptsBranch))
+	b.WriteString("\n\n")
+
+	if len(m.scriptNames) == 0 {
+		b.WriteString(mutedStyle.Render("No scripts in jean.json."))
+		b.WriteString("\n")
+		b.WriteString(mutedStyle.Render(`Add them under "scripts", e.g. {"scripts": {"dev": "npm run dev"}}`))
+		b.WriteString("\n\n")
+		b.WriteString(helpStyle.Render("esc close"))
+	} else {
+		for i, name := range m.scriptNames {
+			line := name
+			if git.IsHook(name) {
+				line += mutedStyle.Render(" (hook)")
+			}
+			if m.scriptServices[name] {
+				line += mutedStyle.Render(" (service)")
+			}
+			if badge := renderServiceBadge(m.serviceFor(m.scriptsWorktreePath, name)); badge != "" {
+				line += "  " + badge
+			} else if badge := renderScriptRunBadge(m.scriptRuns[scriptRunKey(m.scriptsWorktreePath, name)]); badge != "" {
+				line += "  " + badge
+			}
+			if i == m.scriptIndex {
+				b.WriteString(selectedItemStyle.Render("› " + line))
+			} else {
+				b.WriteString(normalItemStyle.Render("  " + line))
+			}
+			b.WriteString("\n")
+			b.WriteString(mutedStyle.Render("    " + truncateDisplay(m.scripts[name], 60)))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+		if m.scriptServices[m.selectedScript()] {
+			b.WriteString(helpStyle.Render("enter run in terminal • b start service • r restart • l view log • s stop • esc close"))
+		} else {
+			b.WriteString(helpStyle.Render("enter run in terminal • b run in background • l view log • s stop • esc close"))
+		}
+	}
+
+	return lipgloss.Place(
+		m.width, m.height,
+		lipgloss.Center, lipgloss.Center,
+		modalStyle.Width(70).Render(b.String()),
+	)
+}
+
+func (m Model) renderScriptLogModal() string {
+	mutedStyle := lipgloss.NewStyle().Foreground(mutedColor)
+	width := m.width - 4
+	if width < 20 {
+		width = 20
+	}
+
+	header := titleStyle.Render(fmt.Sprintf("Log: %s on %s", m.scriptLogName, m.scriptLogBranch))
+	if badge := renderServiceBadge(m.serviceFor(m.scriptLogWorktree, m.scriptLogName)); badge != "" {
+		header += "  " + badge
+	} else if badge := renderScriptRunBadge(m.scriptLogRun()); badge != "" {
+		header += "  " + badge
+	}
+
+	var body strings.Builder
+	switch {
+	case m.scriptLogLines == nil:
+		body.WriteString(mutedStyle.Render("Loading..."))
+	case m.scriptLogMissing:
+		body.WriteString(mutedStyle.Render("No output yet. Press r to run the script in the background."))
+	case len(m.scriptLogLines) == 0:
+		body.WriteString(mutedStyle.Render("No output"))
+	default:
+		end := m.scriptLogScroll + m.scriptLogVisibleLines()
+		if end > len(m.scriptLogLines) {
+			end = len(m.scriptLogLines)
+		}
+		for _, line := range m.scriptLogLines[m.scriptLogScroll:end] {
+			body.WriteString(truncateDisplay(line, width))
+			body.WriteString("\n")
+		}
+		if end < len(m.scriptLogLines) {
+			body.WriteString(mutedStyle.Render(fmt.Sprintf("... %d more lines", len(m.scriptLogLines)-end)))
+		}
+	}
+
+	rerun := "r run again"
+	if m.scriptLogService {
+		rerun = "r restart"
+	}
+	help := helpStyle.Render("j/k scroll • space/ctrl+u page • g/G top/bottom (G follows output) • " + rerun + " • s stop • esc back")
+	return lipgloss.JoinVertical(lipgloss.Left, header, "", body.String(), help)
+}
+
 func (m Model) renderAIPromptsModal() string {
 	var b strings.Builder
 
@@ -1847,6 +2330,9 @@ func (m Model) renderHelperModal() string {
 				{"t", "Open terminal"},
 				{"o", "Open default editor"},
 				{"d", "Delete selected worktree"},
+				{"m", "Move worktree to another directory"},
+				{"x", "Run a jean.json script or service"},
+				{"S", "Setup script output (re-run, cancel)"},
 			},
 		},
 		{
@@ -1857,11 +2343,16 @@ func (m Model) renderHelperModal() string {
 			}{
 				{"c", "Commit all uncommitted changes (with AI)"},
 				{"p", "Push to remote (with AI)"},
-				{"u", "Update from base branch (pull/merge)"},
+				{"u", "Update from base branch (merge/rebase/ff-only)"},
 				{"r", "Refresh status (fetch from remote, no merging)"},
 				{"b", "Change base branch for new worktrees"},
 				{"B", "Rename current branch"},
 				{"K", "Checkout/switch branch in main repo"},
+				{"z", "Manage stashes"},
+				{"D", "Review changes (diff viewer)"},
+				{"l", "Commit log & cherry-pick"},
+				{"R", "Repair missing/orphaned worktrees"},
+				{"C", "Clean up merged/abandoned worktrees"},
 			},
 		},
 		{
@@ -2220,6 +2711,10 @@ func (m Model) renderGitInitModal() string {
 }
 
 func (m Model) renderStagingModal() string {
+	if m.stagingDiffOpen {
+		return m.renderStagingDiffView()
+	}
+
 	var b strings.Builder
 
 	b.WriteString(modalTitleStyle.Render("Staging Area"))
@@ -2333,7 +2828,7 @@ func (m Model) renderStagingModal() string {
 	b.WriteString("\n")
 
 	// Help text
-	b.WriteString(helpStyle.Render("↑/↓/j/k navigate • Enter/Space toggle • a stage all • u unstage all • c commit • r refresh • Esc close"))
+	b.WriteString(helpStyle.Render("↑/↓/j/k navigate • Enter/Space toggle • l/→ hunks • a stage all • u unstage all • c commit • r refresh • Esc close"))
 
 	// Center the modal
 	modalWidth := 80
@@ -2351,6 +2846,874 @@ func (m Model) renderStagingModal() string {
 	)
 }
 
+func (m Model) renderConflictModal() string {
+	var b strings.Builder
+
+	// During a rebase "ours" is the base being rebased onto and "theirs" is the commit being replayed
+	title := "⚠ Resolve Merge Conflicts"
+	tabs := []string{"Ours", "Theirs", "Base"}
+	continueHelp := "c commit merge • A abort merge"
+	switch m.conflictOperation {
+	case git.OperationRebase:
+		title = "⚠ Resolve Rebase Conflicts"
+		tabs = []string{"Ours (base)", "Theirs (your commit)", "Base"}
+		continueHelp = "c continue rebase • s skip commit • A abort rebase"
+	case git.OperationCherryPick:
+		title = "⚠ Resolve Cherry-pick Conflicts"
+		tabs = []string{"Ours (this branch)", "Theirs (picked commit)", "Base"}
+		continueHelp = "c continue cherry-pick • s skip commit • A abort cherry-pick"
+	}
+
+	abortWarning := ""
+	if m.conflictConfirmAbort {
+		abortWarning = normalItemStyle.Copy().Foreground(warningColor).Render("Press A again to abort the "+m.conflictOperation+" and discard the resolutions") + "\n"
+	}
+
+	b.WriteString(modalTitleStyle.Render(title))
+	b.WriteString("\n\n")
+
+	b.WriteString(detailKeyStyle.Render("Worktree: "))
+	b.WriteString(detailValueStyle.Render(m.conflictWorktreePath))
+	b.WriteString("\n\n")
+
+	// All conflicts resolved - ready to continue
+	if len(m.conflictFiles) == 0 {
+		b.WriteString(normalItemStyle.Copy().Foreground(successColor).Render("✓ No conflicted files left"))
+		b.WriteString("\n\n")
+		b.WriteString(abortWarning)
+		b.WriteString(helpStyle.Render(continueHelp + " • r refresh • Esc close"))
+		return lipgloss.Place(
+			m.width, m.height,
+			lipgloss.Center, lipgloss.Center,
+			modalStyle.Width(70).Render(b.String()),
+		)
+	}
+
+	// Conflicted files list
+	b.WriteString(detailKeyStyle.Render(fmt.Sprintf("Conflicted Files (%d):", len(m.conflictFiles))))
+	b.WriteString("\n")
+
+	maxVisible := 10
+	startIdx := 0
+	if m.conflictIndex >= maxVisible {
+		startIdx = m.conflictIndex - maxVisible + 1
+	}
+	endIdx := startIdx + maxVisible
+	if endIdx > len(m.conflictFiles) {
+		endIdx = len(m.conflictFiles)
+	}
+	for i := startIdx; i < endIdx; i++ {
+		if i == m.conflictIndex {
+			b.WriteString(selectedItemStyle.Render("› [U] " + m.conflictFiles[i]))
+		} else {
+			b.WriteString(normalItemStyle.Copy().Foreground(warningColor).Render("  [U] " + m.conflictFiles[i]))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	// Version tabs
+	for i, tab := range tabs {
+		if i == m.conflictView {
+			b.WriteString(selectedButtonStyle.Render(" " + tab + " "))
+		} else {
+			b.WriteString(buttonStyle.Render(" " + tab + " "))
+		}
+		b.WriteString(" ")
+	}
+	b.WriteString("\n\n")
+
+	// Version preview
+	previewLines := m.height - 26 - (endIdx - startIdx)
+	if previewLines < 5 {
+		previewLines = 5
+	}
+	mutedStyle := normalItemStyle.Copy().Foreground(mutedColor)
+	if m.conflictVersions == nil {
+		b.WriteString(mutedStyle.Render("Loading..."))
+	} else {
+		content, exists := m.conflictVersions.Ours, m.conflictVersions.HasOurs
+		switch m.conflictView {
+		case 1:
+			content, exists = m.conflictVersions.Theirs, m.conflictVersions.HasTheirs
+		case 2:
+			content, exists = m.conflictVersions.Base, m.conflictVersions.HasBase
+		}
+
+		if !exists {
+			b.WriteString(mutedStyle.Render("(file does not exist in this version)"))
+		} else {
+			lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+			start := m.conflictScroll
+			if start > len(lines)-1 {
+				start = len(lines) - 1
+			}
+			if start < 0 {
+				start = 0
+			}
+			end := start + previewLines
+			if end > len(lines) {
+				end = len(lines)
+			}
+			for i := start; i < end; i++ {
+				b.WriteString(mutedStyle.Render(fmt.Sprintf("%4d ", i+1)))
+				b.WriteString(normalItemStyle.Render(lines[i]))
+				b.WriteString("\n")
+			}
+			if end < len(lines) {
+				b.WriteString(mutedStyle.Render(fmt.Sprintf("     ... %d more lines", len(lines)-end)))
+			}
+		}
+	}
+	b.WriteString("\n\n")
+
+	b.WriteString(helpStyle.Render("↑/↓ select • tab ours/theirs/base • J/K scroll • o take ours • t take theirs • e edit • m mark resolved"))
+	b.WriteString("\n")
+	b.WriteString(abortWarning)
+	b.WriteString(helpStyle.Render(continueHelp + " • r refresh • Esc close (keeps it in progress)"))
+
+	modalWidth := 80
+	if m.width > 100 {
+		modalWidth = m.width - 20
+		if modalWidth > 140 {
+			modalWidth = 140
+		}
+	}
+	return lipgloss.Place(
+		m.width, m.height,
+		lipgloss.Center, lipgloss.Center,
+		modalStyle.Width(modalWidth).Render(b.String()),
+	)
+}
+
+func (m Model) renderStashModal() string {
+	var b strings.Builder
+
+	b.WriteString(modalTitleStyle.Render("Stashes"))
+	b.WriteString("\n\n")
+
+	b.WriteString(detailKeyStyle.Render("Branch: "))
+	b.WriteString(detailValueStyle.Render(m.stashBranch))
+	if m.stashShowAll {
+		b.WriteString(normalItemStyle.Copy().Foreground(mutedColor).Render("  (showing all branches)"))
+	}
+	b.WriteString("\n\n")
+
+	mutedStyle := normalItemStyle.Copy().Foreground(mutedColor)
+
+	// New stash message input
+	if m.stashInputMode {
+		b.WriteString(inputLabelStyle.Render("Stash message:"))
+		b.WriteString("\n")
+		b.WriteString(m.stashMessageInput.View())
+		b.WriteString("\n\n")
+		b.WriteString(helpStyle.Render("enter stash changes (including untracked) • esc cancel"))
+		return lipgloss.Place(
+			m.width, m.height,
+			lipgloss.Center, lipgloss.Center,
+			modalStyle.Width(70).Render(b.String()),
+		)
+	}
+
+	stashes := m.visibleStashes()
+	if len(stashes) == 0 {
+		if m.stashShowAll {
+			b.WriteString(mutedStyle.Render("No stashes"))
+		} else {
+			b.WriteString(mutedStyle.Render("No stashes for this branch"))
+		}
+		b.WriteString("\n\n")
+		b.WriteString(helpStyle.Render("n new stash • tab all branches • Esc close"))
+		return lipgloss.Place(
+			m.width, m.height,
+			lipgloss.Center, lipgloss.Center,
+			modalStyle.Width(70).Render(b.String()),
+		)
+	}
+
+	// Stash list
+	maxVisible := 10
+	startIdx := 0
+	if m.stashIndex >= maxVisible {
+		startIdx = m.stashIndex - maxVisible + 1
+	}
+	endIdx := startIdx + maxVisible
+	if endIdx > len(stashes) {
+		endIdx = len(stashes)
+	}
+	for i := startIdx; i < endIdx; i++ {
+		stash := stashes[i]
+		line := fmt.Sprintf("%s %s", stash.Ref, stash.Message)
+		if m.stashShowAll && stash.Branch != "" {
+			line += " [" + stash.Branch + "]"
+		}
+		if i == m.stashIndex {
+			b.WriteString(selectedItemStyle.Render("› " + line))
+		} else {
+			b.WriteString(normalItemStyle.Render("  " + line))
+		}
+		b.WriteString(mutedStyle.Render("  " + stash.Date))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	// Preview of the selected stash
+	previewLines := m.height - 22 - (endIdx - startIdx)
+	if previewLines < 5 {
+		previewLines = 5
+	}
+	if m.stashPreview == "" {
+		b.WriteString(mutedStyle.Render("Loading..."))
+	} else {
+		lines := strings.Split(strings.TrimRight(m.stashPreview, "\n"), "\n")
+		start := m.stashScroll
+		if start > len(lines)-1 {
+			start = len(lines) - 1
+		}
+		end := start + previewLines
+		if end > len(lines) {
+			end = len(lines)
+		}
+		rendered := renderDiffLines(lines)
+		for i := start; i < end; i++ {
+			b.WriteString(rendered[i])
+			b.WriteString("\n")
+		}
+		if end < len(lines) {
+			b.WriteString(mutedStyle.Render(fmt.Sprintf("... %d more lines", len(lines)-end)))
+		}
+	}
+	b.WriteString("\n\n")
+
+	if m.stashConfirmDrop {
+		b.WriteString(normalItemStyle.Copy().Foreground(warningColor).Render("Press d again to drop " + stashes[m.stashIndex].Ref))
+		b.WriteString("\n")
+	}
+	b.WriteString(helpStyle.Render("↑/↓ select • J/K scroll • n new stash • a apply • p pop • d drop • tab all branches • Esc close"))
+
+	modalWidth := 80
+	if m.width > 100 {
+		modalWidth = m.width - 20
+		if modalWidth > 140 {
+			modalWidth = 140
+		}
+	}
+	return lipgloss.Place(
+		m.width, m.height,
+		lipgloss.Center, lipgloss.Center,
+		modalStyle.Width(modalWidth).Render(b.String()),
+	)
+}
+
+// renderStagingDiffView renders the hunk view of the staging modal
+func (m Model) renderStagingDiffView() string {
+	var b strings.Builder
+
+	b.WriteString(modalTitleStyle.Render("Stage Hunks"))
+	b.WriteString("\n\n")
+
+	b.WriteString(detailKeyStyle.Render("File: "))
+	b.WriteString(detailValueStyle.Render(m.stagingDiffFile))
+	b.WriteString("\n\n")
+
+	// Unstaged/staged tabs
+	for i, tab := range []string{"Unstaged", "Staged"} {
+		if (i == 1) == m.stagingDiffStaged {
+			b.WriteString(selectedButtonStyle.Render(" " + tab + " "))
+		} else {
+			b.WriteString(buttonStyle.Render(" " + tab + " "))
+		}
+	}
+	b.WriteString("\n\n")
+
+	action := "stage"
+	if m.stagingDiffStaged {
+		action = "unstage"
+	}
+
+	mutedStyle := normalItemStyle.Copy().Foreground(mutedColor)
+	switch {
+	case m.stagingDiff == nil:
+		b.WriteString(mutedStyle.Render("Loading..."))
+	case m.stagingDiff.IsBinary:
+		b.WriteString(mutedStyle.Render("Binary file - use the file list to " + action + " it as a whole"))
+	case len(m.stagingDiff.Hunks) == 0:
+		if m.stagingDiffStaged {
+			b.WriteString(mutedStyle.Render("No staged changes in this file"))
+		} else {
+			b.WriteString(mutedStyle.Render("No unstaged changes in this file"))
+		}
+	default:
+		// Render every hunk, remembering where the cursor is so it can be scrolled into view
+		var lines []string
+		cursor := 0
+		for i, hunk := range m.stagingDiff.Hunks {
+			header := fmt.Sprintf("@@ -%d,%d +%d,%d @@%s", hunk.OldStart, hunk.OldLines, hunk.NewStart, hunk.NewLines, hunk.Section)
+			if i == m.stagingHunkIndex {
+				if !m.stagingLineMode {
+					cursor = len(lines)
+				}
+				lines = append(lines, selectedItemStyle.Render("› "+header))
+			} else {
+				lines = append(lines, "  "+diffHunkStyle.Render(header))
+			}
+
+			for j, line := range hunk.Lines {
+				marker := "  "
+				if i == m.stagingHunkIndex && m.stagingLineMode {
+					if j == m.stagingLineIndex {
+						cursor = len(lines)
+						marker = "›"
+					} else {
+						marker = " "
+					}
+					if m.stagingLineSelected[j] {
+						marker += "●"
+					} else {
+						marker += " "
+					}
+				}
+				lines = append(lines, marker+renderDiffBodyLine(line))
+			}
+		}
+
+		// Keep the cursor in the upper third of the visible window
+		visible := m.height - 18
+		if visible < 5 {
+			visible = 5
+		}
+		start := cursor - visible/3
+		if start > len(lines)-visible {
+			start = len(lines) - visible
+		}
+		if start < 0 {
+			start = 0
+		}
+		end := start + visible
+		if end > len(lines) {
+			end = len(lines)
+		}
+		if start > 0 {
+			b.WriteString(mutedStyle.Render(fmt.Sprintf("... %d lines above", start)))
+			b.WriteString("\n")
+		}
+		for _, line := range lines[start:end] {
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
+		if end < len(lines) {
+			b.WriteString(mutedStyle.Render(fmt.Sprintf("... %d lines below", len(lines)-end)))
+			b.WriteString("\n")
+		}
+	}
+	b.WriteString("\n")
+
+	if m.stagingLineMode {
+		b.WriteString(helpStyle.Render(fmt.Sprintf("↑/↓ line • space select line • enter %s selected lines • v/Esc hunk mode", action)))
+	} else {
+		b.WriteString(helpStyle.Render(fmt.Sprintf("↑/↓ hunk • space/enter %s hunk • v select lines • tab unstaged/staged • Esc back", action)))
+	}
+
+	modalWidth := 80
+	if m.width > 100 {
+		modalWidth = m.width - 20
+		if modalWidth > 140 {
+			modalWidth = 140
+		}
+	}
+	return lipgloss.Place(
+		m.width, m.height,
+		lipgloss.Center, lipgloss.Center,
+		modalStyle.Width(modalWidth).Render(b.String()),
+	)
+}
+
+// renderDiffViewer renders the full-screen diff viewer: changed files on the left, the selected file's diff on the right
+func (m Model) renderDiffViewer() string {
+	// Title with mode tabs
+	var header strings.Builder
+	header.WriteString(titleStyle.Render("Diff: " + m.diffViewerBranch))
+	header.WriteString("  ")
+	modes := []struct {
+		mode  string
+		label string
+	}{
+		{git.DiffUnstaged, "1 Unstaged"},
+		{git.DiffStaged, "2 Staged"},
+		{git.DiffBase, "3 vs " + m.baseBranch},
+	}
+	for _, mode := range modes {
+		if mode.mode == m.diffViewerMode {
+			header.WriteString(selectedButtonStyle.Render(mode.label))
+		} else {
+			header.WriteString(buttonStyle.Render(mode.label))
+		}
+	}
+
+	panelHeight := m.height - 6
+	if panelHeight < 8 {
+		panelHeight = 8
+	}
+	leftWidth := m.width / 3
+	if leftWidth < 24 {
+		leftWidth = 24
+	}
+	if leftWidth > 50 {
+		leftWidth = 50
+	}
+	rightWidth := m.width - leftWidth - 6
+	if rightWidth < 20 {
+		rightWidth = 20
+	}
+
+	mutedStyle := lipgloss.NewStyle().Foreground(mutedColor)
+
+	// File list
+	var left strings.Builder
+	listWidth := leftWidth - 4
+	switch {
+	case m.diffViewerFiles == nil:
+		left.WriteString(mutedStyle.Render("Loading..."))
+	case len(m.diffViewerFiles) == 0:
+		left.WriteString(mutedStyle.Render("No changes"))
+	default:
+		left.WriteString(detailKeyStyle.Render(fmt.Sprintf("Files (%d)", len(m.diffViewerFiles))))
+		left.WriteString("\n")
+
+		maxVisible := panelHeight - 4
+		startIdx := 0
+		if m.diffViewerIndex >= maxVisible {
+			startIdx = m.diffViewerIndex - maxVisible + 1
+		}
+		endIdx := startIdx + maxVisible
+		if endIdx > len(m.diffViewerFiles) {
+			endIdx = len(m.diffViewerFiles)
+		}
+		for i := startIdx; i < endIdx; i++ {
+			file := m.diffViewerFiles[i]
+			added, removed := file.Stats()
+			stats := fmt.Sprintf(" +%d -%d", added, removed)
+			if file.IsBinary {
+				stats = " bin"
+			}
+			// Leave room for the selected row's padding
+			name := truncateDisplay(file.Status()+" "+file.Path, listWidth-len(stats)-5)
+			if i == m.diffViewerIndex {
+				left.WriteString(selectedItemStyle.Render(name + stats))
+			} else {
+				left.WriteString(" " + name)
+				left.WriteString(diffAddStyle.Render(fmt.Sprintf(" +%d", added)))
+				left.WriteString(diffRemoveStyle.Render(fmt.Sprintf(" -%d", removed)))
+			}
+			left.WriteString("\n")
+		}
+	}
+
+	// Diff of the selected file
+	var right strings.Builder
+	diffWidth := rightWidth - 4
+	if file := m.selectedDiffFile(); file != nil {
+		right.WriteString(detailKeyStyle.Render(truncateDisplay(file.Path, diffWidth)))
+		right.WriteString("\n\n")
+
+		lines, _ := fileDiffLines(file)
+		switch {
+		case file.IsBinary:
+			right.WriteString(mutedStyle.Render("Binary file"))
+		case len(lines) == 0:
+			right.WriteString(mutedStyle.Render("No content changes (mode or rename only)"))
+		default:
+			start := m.diffViewerScroll
+			end := start + m.diffViewerVisibleLines()
+			if end > len(lines) {
+				end = len(lines)
+			}
+			for _, line := range lines[start:end] {
+				line = truncateDisplay(line, diffWidth)
+				if strings.HasPrefix(line, "@@") {
+					right.WriteString(diffHunkStyle.Render(line))
+				} else {
+					right.WriteString(renderDiffBodyLine(line))
+				}
+				right.WriteString("\n")
+			}
+			if end < len(lines) {
+				right.WriteString(mutedStyle.Render(fmt.Sprintf("... %d more lines", len(lines)-end)))
+			}
+		}
+	}
+
+	panels := lipgloss.JoinHorizontal(lipgloss.Top,
+		activePanelStyle.Width(leftWidth).Height(panelHeight).Render(left.String()),
+		panelStyle.Width(rightWidth).Height(panelHeight).Render(right.String()),
+	)
+
+	help := helpStyle.Render("j/k scroll • space/ctrl+u page • tab/shift+tab file • n/p hunk • 1/2/3 or m mode • e edit • r refresh • Esc close")
+
+	return lipgloss.JoinVertical(lipgloss.Left, header.String(), "", panels, help)
+}
+
+// renderCleanupModal renders the checklist of worktrees suggested for removal
+func (m Model) renderCleanupModal() string {
+	var b strings.Builder
+
+	b.WriteString(modalTitleStyle.Render("Clean Up Worktrees"))
+	b.WriteString("\n\n")
+
+	mutedStyle := normalItemStyle.Copy().Foreground(mutedColor)
+	criteria := fmt.Sprintf("Merged, merged/closed PR, or untouched for %d+ days", m.cleanupStaleDays)
+	if m.baseBranch != "" {
+		criteria = fmt.Sprintf("Merged into %s, merged/closed PR, or untouched for %d+ days", m.baseBranch, m.cleanupStaleDays)
+	}
+	b.WriteString(mutedStyle.Render(criteria))
+	b.WriteString("\n\n")
+
+	switch {
+	case m.cleanupCandidates == nil:
+		b.WriteString(mutedStyle.Render("Scanning..."))
+		b.WriteString("\n")
+	case len(m.cleanupCandidates) == 0:
+		b.WriteString(normalItemStyle.Copy().Foreground(successColor).Render("✓ Nothing to clean up"))
+		b.WriteString("\n")
+	default:
+		maxVisible := 10
+		startIdx := 0
+		if m.cleanupIndex >= maxVisible {
+			startIdx = m.cleanupIndex - maxVisible + 1
+		}
+		endIdx := startIdx + maxVisible
+		if endIdx > len(m.cleanupCandidates) {
+			endIdx = len(m.cleanupCandidates)
+		}
+		for i := startIdx; i < endIdx; i++ {
+			candidate := m.cleanupCandidates[i]
+			check := "[ ]"
+			if m.cleanupSelected[candidate.worktree.Path] {
+				check = "[x]"
+			}
+			label := fmt.Sprintf("%s %s", check, candidate.worktree.Branch)
+			reasons := " " + strings.Join(candidate.reasons, ", ")
+			if i == m.cleanupIndex {
+				line := label + reasons
+				if candidate.worktree.HasUncommitted {
+					line += " ● uncommitted changes"
+				}
+				b.WriteString(selectedItemStyle.Render(line))
+			} else {
+				b.WriteString(normalItemStyle.Render(label))
+				b.WriteString(mutedStyle.Render(reasons))
+				if candidate.worktree.HasUncommitted {
+					b.WriteString(normalItemStyle.Copy().Foreground(warningColor).Render(" ● uncommitted changes"))
+				}
+			}
+			b.WriteString("\n")
+		}
+	}
+	b.WriteString("\n")
+
+	selected := m.selectedCleanupWorktrees()
+	dirty := 0
+	for _, wt := range selected {
+		if wt.HasUncommitted {
+			dirty++
+		}
+	}
+	switch {
+	case m.cleanupRunning:
+		b.WriteString(mutedStyle.Render(fmt.Sprintf("Removing %d worktree(s)...", len(selected))))
+		b.WriteString("\n")
+	case m.cleanupConfirm:
+		warning := fmt.Sprintf("Press enter again to remove %d worktree(s) and their branches", len(selected))
+		if dirty > 0 {
+			warning += fmt.Sprintf(" (%d with uncommitted changes will be lost)", dirty)
+		}
+		b.WriteString(normalItemStyle.Copy().Foreground(warningColor).Render(warning))
+		b.WriteString("\n")
+	case len(selected) > 0:
+		b.WriteString(detailKeyStyle.Render(fmt.Sprintf("%d checked", len(selected))))
+		b.WriteString("\n")
+	}
+	b.WriteString(helpStyle.Render("↑/↓ select • space check • a check all • +/- stale days • enter remove • Esc close"))
+
+	return lipgloss.Place(
+		m.width, m.height,
+		lipgloss.Center, lipgloss.Center,
+		modalStyle.Width(90).Render(b.String()),
+	)
+}
+
+// renderRepairModal renders the list of missing worktrees and orphaned directories with repair actions
+func (m Model) renderRepairModal() string {
+	var b strings.Builder
+
+	b.WriteString(modalTitleStyle.Render("Repair Worktrees"))
+	b.WriteString("\n\n")
+
+	mutedStyle := normalItemStyle.Copy().Foreground(mutedColor)
+	switch {
+	case m.repairItems == nil:
+		b.WriteString(mutedStyle.Render("Scanning..."))
+		b.WriteString("\n")
+	case len(m.repairItems) == 0:
+		b.WriteString(normalItemStyle.Copy().Foreground(successColor).Render("✓ All worktrees are healthy"))
+		b.WriteString("\n")
+	default:
+		maxVisible := 10
+		startIdx := 0
+		if m.repairIndex >= maxVisible {
+			startIdx = m.repairIndex - maxVisible + 1
+		}
+		endIdx := startIdx + maxVisible
+		if endIdx > len(m.repairItems) {
+			endIdx = len(m.repairItems)
+		}
+		for i := startIdx; i < endIdx; i++ {
+			item := m.repairItems[i]
+			label := "⚠ missing  " + item.branch
+			if item.orphan {
+				label = "✗ orphan   " + filepath.Base(item.path)
+			} else if item.locked {
+				label += " 🔒"
+			}
+			if i == m.repairIndex {
+				b.WriteString(selectedItemStyle.Render("› " + label))
+			} else {
+				b.WriteString(normalItemStyle.Render("  " + label))
+			}
+			b.WriteString("\n")
+		}
+
+		if item := m.selectedRepairItem(); item != nil {
+			b.WriteString("\n")
+			b.WriteString(detailKeyStyle.Render("Path: "))
+			b.WriteString(detailValueStyle.Render(item.path))
+			b.WriteString("\n")
+			b.WriteString(detailKeyStyle.Render("Problem: "))
+			b.WriteString(detailValueStyle.Render(item.reason))
+			b.WriteString("\n")
+		}
+	}
+	b.WriteString("\n")
+
+	if m.repairConfirmDel {
+		b.WriteString(normalItemStyle.Copy().Foreground(warningColor).Render("Press d again to delete the directory and everything in it"))
+		b.WriteString("\n")
+	}
+	if item := m.selectedRepairItem(); item != nil && item.orphan {
+		b.WriteString(helpStyle.Render("↑/↓ select • d delete directory • r rescan • Esc close"))
+	} else {
+		b.WriteString(helpStyle.Render("↑/↓ select • a re-add • p prune • P prune all • r rescan • Esc close"))
+	}
+
+	return lipgloss.Place(
+		m.width, m.height,
+		lipgloss.Center, lipgloss.Center,
+		modalStyle.Width(80).Render(b.String()),
+	)
+}
+
+// renderLogModal renders the full-screen commit log, the diff of a commit or the cherry-pick target picker
+func (m Model) renderLogModal() string {
+	if m.logPickTarget {
+		return m.renderCherryPickTargetPicker()
+	}
+
+	mutedStyle := lipgloss.NewStyle().Foreground(mutedColor)
+	width := m.width - 4
+	if width < 20 {
+		width = 20
+	}
+
+	var header strings.Builder
+	header.WriteString(titleStyle.Render("Log: " + m.logBranch))
+	if len(m.logSelected) > 0 {
+		header.WriteString(mutedStyle.Render(fmt.Sprintf("  %d commit(s) marked", len(m.logSelected))))
+	}
+
+	// Full diff of a commit
+	if m.logDiffHash != "" {
+		var body strings.Builder
+		if m.logDiff == nil {
+			body.WriteString(mutedStyle.Render("Loading..."))
+		} else {
+			lines := make([]string, len(m.logDiff))
+			for i, line := range m.logDiff {
+				lines[i] = truncateDisplay(line, width)
+			}
+			rendered := renderDiffLines(lines)
+			end := m.logDiffScroll + m.logDiffVisibleLines()
+			if end > len(rendered) {
+				end = len(rendered)
+			}
+			for _, line := range rendered[m.logDiffScroll:end] {
+				body.WriteString(line)
+				body.WriteString("\n")
+			}
+			if end < len(rendered) {
+				body.WriteString(mutedStyle.Render(fmt.Sprintf("... %d more lines", len(rendered)-end)))
+			}
+		}
+		help := helpStyle.Render("j/k scroll • space/ctrl+u page • g/G top/bottom • Esc back to log")
+		return lipgloss.JoinVertical(lipgloss.Left, header.String(), "", body.String(), help)
+	}
+
+	var body strings.Builder
+	switch {
+	case m.logEntries == nil:
+		body.WriteString(mutedStyle.Render("Loading..."))
+	case len(m.logEntries) == 0:
+		body.WriteString(mutedStyle.Render("No commits"))
+	default:
+		maxVisible := m.height - 6
+		if maxVisible < 5 {
+			maxVisible = 5
+		}
+		startIdx := 0
+		if m.logIndex >= maxVisible {
+			startIdx = m.logIndex - maxVisible + 1
+		}
+		endIdx := startIdx + maxVisible
+		if endIdx > len(m.logEntries) {
+			endIdx = len(m.logEntries)
+		}
+		for i := startIdx; i < endIdx; i++ {
+			entry := m.logEntries[i]
+			if entry.Hash == "" {
+				body.WriteString("  " + mutedStyle.Render(truncateDisplay(entry.Graph, width-2)))
+				body.WriteString("\n")
+				continue
+			}
+
+			marker := "  "
+			if m.logSelected[entry.Hash] {
+				marker = "● "
+			}
+			refs := ""
+			if entry.Refs != "" {
+				refs = "(" + entry.Refs + ") "
+			}
+			meta := fmt.Sprintf(" %s, %s", entry.Author, entry.Date)
+			// Leave room for the selected row's padding
+			subject := truncateDisplay(refs+entry.Subject, width-len([]rune(entry.Graph+entry.ShortHash+meta))-8)
+
+			if i == m.logIndex {
+				body.WriteString(selectedItemStyle.Render(marker + entry.Graph + entry.ShortHash + " " + subject + meta))
+			} else {
+				body.WriteString(" " + marker + mutedStyle.Render(entry.Graph))
+				body.WriteString(detailKeyStyle.Render(entry.ShortHash) + " ")
+				if refs != "" && len([]rune(subject)) > len([]rune(refs)) {
+					body.WriteString(lipgloss.NewStyle().Foreground(warningColor).Render(refs))
+					subject = string([]rune(subject)[len([]rune(refs)):])
+				}
+				body.WriteString(subject)
+				body.WriteString(mutedStyle.Render(meta))
+			}
+			body.WriteString("\n")
+		}
+		if m.logLoading {
+			body.WriteString(mutedStyle.Render("  Loading more..."))
+		}
+	}
+
+	help := helpStyle.Render("↑/↓ select • enter view diff • space mark • c cherry-pick onto... • r refresh • Esc close")
+	return lipgloss.JoinVertical(lipgloss.Left, header.String(), "", body.String(), help)
+}
+
+// renderCherryPickTargetPicker renders the list of worktrees commits can be cherry-picked onto
+func (m Model) renderCherryPickTargetPicker() string {
+	var b strings.Builder
+
+	hashes := m.cherryPickCommits()
+	b.WriteString(modalTitleStyle.Render(fmt.Sprintf("Cherry-pick %d commit(s) onto...", len(hashes))))
+	b.WriteString("\n\n")
+
+	targets := m.cherryPickTargets()
+	maxVisible := 10
+	startIdx := 0
+	if m.logTargetIndex >= maxVisible {
+		startIdx = m.logTargetIndex - maxVisible + 1
+	}
+	endIdx := startIdx + maxVisible
+	if endIdx > len(targets) {
+		endIdx = len(targets)
+	}
+	for i := startIdx; i < endIdx; i++ {
+		label := targets[i].Branch
+		if targets[i].HasUncommitted {
+			label += " (uncommitted changes)"
+		}
+		if i == m.logTargetIndex {
+			b.WriteString(selectedItemStyle.Render("› " + label))
+		} else {
+			b.WriteString(normalItemStyle.Render("  " + label))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("↑/↓ select • enter cherry-pick • Esc cancel"))
+
+	return lipgloss.Place(
+		m.width, m.height,
+		lipgloss.Center, lipgloss.Center,
+		modalStyle.Width(60).Render(b.String()),
+	)
+}
+
+// truncateDisplay expands tabs and cuts a line to at most width characters
+func truncateDisplay(s string, width int) string {
+	s = strings.ReplaceAll(s, "\t", "    ")
+	if width <= 0 {
+		return ""
+	}
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	if width == 1 {
+		return "…"
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+// renderDiffLines colors the lines of a unified diff with the active theme
+// File headers are told apart from hunk bodies so removed lines starting with "--" aren't mistaken for them
+func renderDiffLines(lines []string) []string {
+	rendered := make([]string, len(lines))
+	inHunk := false
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "diff --git"):
+			inHunk = false
+			rendered[i] = diffHeaderStyle.Render(line)
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+			rendered[i] = diffHunkStyle.Render(line)
+		case inHunk:
+			rendered[i] = renderDiffBodyLine(line)
+		case strings.HasPrefix(line, "---") || strings.HasPrefix(line, "+++"):
+			rendered[i] = diffHeaderStyle.Render(line)
+		default:
+			rendered[i] = diffContextStyle.Render(line)
+		}
+	}
+	return rendered
+}
+
+// renderDiffBodyLine colors a single hunk body line
+func renderDiffBodyLine(line string) string {
+	switch {
+	case strings.HasPrefix(line, "+"):
+		return diffAddStyle.Render(line)
+	case strings.HasPrefix(line, "-"):
+		return diffRemoveStyle.Render(line)
+	case strings.HasPrefix(line, "\\"):
+		return lipgloss.NewStyle().Foreground(mutedColor).Render(line)
+	default:
+		return diffContextStyle.Render(line)
+	}
+}
+
 // getStagingStatusIcon returns a display icon for a git status character
 func (m Model) getStagingStatusIcon(status string) string {
 	switch status {